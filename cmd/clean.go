@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean [flags]",
+	Short: "Remove old scan artifacts from the output directory",
+	Long: `Remove scan artifacts from the output directory that have not been written
+for longer than --older-than: result files (JSON, JSONL, XML, CSV, HTML),
+SARIF reports, HAR recordings, screenshots and timestamped run directories.
+
+Artifacts written within an hour of the newest one belong to the current run
+and are always kept. Any other file, including the history directory used by
+--history, is never touched.
+
+Examples:
+  subdomain-finder clean --older-than 30d
+  subdomain-finder clean --older-than 2w --dry-run
+  subdomain-finder clean --older-than 72h --output-dir /var/lib/scans`,
+	Args: cobra.NoArgs,
+	Run:  runClean,
+}
+
+var (
+	cleanOlderThan string
+	cleanDryRun    bool
+)
+
+// currentRunWindow is how far before the newest artifact files still count
+// as part of the most recent run.
+const currentRunWindow = time.Hour
+
+func init() {
+	rootCmd.AddCommand(cleanCmd)
+
+	cleanCmd.Flags().StringVar(&cleanOlderThan, "older-than", "30d", "Remove artifacts last modified longer ago than this (e.g. 30d, 2w, 72h)")
+	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "List the files that would be removed without deleting them")
+
+	_ = cleanCmd.RegisterFlagCompletionFunc("older-than", completeValues("7d", "30d", "90d", "2w", "72h"))
+}
+
+func runClean(cmd *cobra.Command, args []string) {
+	age, err := parseAge(cleanOlderThan)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --older-than: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	outputDir := viper.GetString("output.dir")
+	if _, err := os.Stat(outputDir); os.IsNotExist(err) {
+		fmt.Printf("Output directory %s does not exist, nothing to clean\n", outputDir)
+		return
+	}
+
+	removed, err := pruneArtifacts(outputDir, time.Now().Add(-age), cleanDryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	verb := "Removed"
+	if cleanDryRun {
+		verb = "Would remove"
+	}
+	for _, path := range removed {
+		fmt.Printf("%s %s\n", verb, path)
+	}
+	fmt.Printf("%s %d artifact(s) older than %s from %s\n", verb, len(removed), cleanOlderThan, outputDir)
+}
+
+var ageUnits = []struct {
+	suffix string
+	name   string
+	unit   time.Duration
+}{
+	{"d", "days", 24 * time.Hour},
+	{"w", "weeks", 7 * 24 * time.Hour},
+}
+
+// parseAge accepts Go durations plus day ("30d") and week ("2w") suffixes.
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for _, u := range ageUnits {
+		if number, ok := strings.CutSuffix(value, u.suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%q is not a positive number of %s", value, u.name)
+			}
+			return time.Duration(n) * u.unit, nil
+		}
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a duration such as 30d, 2w or 72h", value)
+	}
+	if age <= 0 {
+		return 0, fmt.Errorf("%q must be positive", value)
+	}
+	return age, nil
+}
+
+// artifactExtensions are the files a scan writes into the output directory:
+// results (JSON, JSONL, XML, CSV, HTML), SARIF reports and HAR recordings.
+var artifactExtensions = map[string]bool{
+	".json": true, ".jsonl": true, ".xml": true, ".csv": true,
+	".html": true, ".sarif": true, ".har": true,
+}
+
+// runDirLayouts name timestamped run directories.
+var runDirLayouts = []string{"20060102-150405", "2006-01-02T15-04-05", "2006-01-02_15-04-05"}
+
+func isRunDir(name string) bool {
+	for _, layout := range runDirLayouts {
+		if _, err := time.Parse(layout, name); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isArtifact reports whether rel, a path relative to the output directory,
+// was written by a scan: a result file at the top level, a screenshot, or
+// anything inside a timestamped run directory.
+func isArtifact(rel string) bool {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	switch {
+	case len(parts) == 1:
+		return artifactExtensions[strings.ToLower(filepath.Ext(rel))]
+	case parts[0] == "screenshots":
+		return strings.EqualFold(filepath.Ext(rel), ".png")
+	default:
+		return isRunDir(parts[0])
+	}
+}
+
+// pruneArtifacts removes scan artifacts under dir modified before cutoff,
+// keeping the most recent run, then drops run directories left empty. Other
+// files, including the history store, are never touched.
+func pruneArtifacts(dir string, cutoff time.Time, dryRun bool) ([]string, error) {
+	type artifact struct {
+		path    string
+		modTime time.Time
+	}
+
+	var artifacts []artifact
+	var dirs []string
+	var newest time.Time
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if rel == "." {
+				return nil
+			}
+			top := strings.Split(filepath.ToSlash(rel), "/")[0]
+			if top != "screenshots" && !isRunDir(top) {
+				return filepath.SkipDir
+			}
+			dirs = append(dirs, path)
+			return nil
+		}
+		if !entry.Type().IsRegular() || !isArtifact(rel) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		artifacts = append(artifacts, artifact{path: path, modTime: info.ModTime()})
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan output directory: %w", err)
+	}
+
+	currentRun := newest.Add(-currentRunWindow)
+	if currentRun.Before(cutoff) {
+		cutoff = currentRun
+	}
+
+	var removed []string
+	for _, a := range artifacts {
+		if !a.modTime.Before(cutoff) {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(a.path); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %w", a.path, err)
+			}
+		}
+		removed = append(removed, a.path)
+	}
+
+	if !dryRun {
+		// Deepest first so nested empty directories collapse
+		sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+		for _, d := range dirs {
+			_ = os.Remove(d)
+		}
+	}
+
+	return removed, nil
+}
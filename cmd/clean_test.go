@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestPruneArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+
+	files := map[string]time.Time{
+		"example.com.json":                old,
+		"example.com.sarif":               old,
+		"screenshots/www_1700000000.png":  old,
+		"20250101-120000/example.com.xml": old,
+		"history/example.com.json":        old,
+		"notes.md":                        old,
+		"keep/example.com.json":           old,
+		"screenshots/readme.txt":          old,
+		"example.org.json":                time.Now(),
+	}
+	for name, modTime := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := pruneArtifacts(dir, time.Now().Add(-24*time.Hour), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, path := range removed {
+		rel, _ := filepath.Rel(dir, path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	sort.Strings(got)
+	want := []string{"20250101-120000/example.com.xml", "example.com.json", "example.com.sarif", "screenshots/www_1700000000.png"}
+	if len(got) != len(want) {
+		t.Fatalf("removed %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("removed %v, want %v", got, want)
+		}
+	}
+
+	for _, name := range []string{"notes.md", "history/example.com.json", "keep/example.com.json", "screenshots/readme.txt", "example.org.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s was removed: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "20250101-120000")); !os.IsNotExist(err) {
+		t.Errorf("empty run directory was kept")
+	}
+}
+
+func TestPruneArtifactsKeepsCurrentRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "example.com.json")
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-72 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := pruneArtifacts(dir, time.Now().Add(-24*time.Hour), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 0 {
+		t.Fatalf("removed the only run: %v", removed)
+	}
+}
@@ -80,6 +80,9 @@ func runShowConfig(cmd *cobra.Command, args []string) {
 	fmt.Printf("HTTP Retries: %d\n", cfg.HTTP.Retries)
 	fmt.Printf("HTTP Rate Limit: %d\n", cfg.HTTP.RateLimit)
 	fmt.Printf("HTTP Follow Redirects: %t\n", cfg.HTTP.FollowRedirects)
+	fmt.Printf("HTTP TLS Min Version: %s\n", cfg.HTTP.TLSMinVersion)
+	fmt.Printf("HTTP TLS Max Version: %s\n", cfg.HTTP.TLSMaxVersion)
+	fmt.Printf("HTTP CA Bundle: %s\n", cfg.HTTP.CABundle)
 	fmt.Println()
 	fmt.Printf("Output Format: %s\n", cfg.Output.Format)
 	fmt.Printf("Output Directory: %s\n", cfg.Output.Directory)
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"subdomain-finder/internal/diff"
+	"subdomain-finder/internal/fileutil"
+	"subdomain-finder/internal/finder"
+	"subdomain-finder/internal/output"
+	"subdomain-finder/internal/reporter"
+
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [flags] <old.json> <new.json>",
+	Short: "Compare the JSON results of two scans",
+	Long: `Compare two JSON result files and list the subdomains that appeared or
+disappeared, and for those in both, the ports opened or closed, the
+technologies and vulnerabilities added or gone, and changes to the IP,
+status, title, server, risk level and SSL grade.
+
+Examples:
+  subdomain-finder diff results/monday.json results/tuesday.json
+  subdomain-finder diff old.json new.json --json changes.json --exit-code`,
+	Args: cobra.ExactArgs(2),
+	Run:  runDiff,
+}
+
+var (
+	diffJSON     string
+	diffExitCode bool
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffJSON, "json", "", "Also write the differences to this JSON file")
+	diffCmd.Flags().BoolVar(&diffExitCode, "exit-code", false, "Exit with status 2 when the scans differ")
+}
+
+func runDiff(cmd *cobra.Command, args []string) {
+	old, _, err := reporter.LoadJSON(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	current, _, err := reporter.LoadJSON(args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	report := diff.Compare(old, current)
+	output.NewOutputter(finder.Config{}, nil).PrintDiff(report)
+
+	if diffJSON != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err == nil {
+			err = fileutil.WriteFile(diffJSON, append(data, '\n'))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write %s: %v\n", diffJSON, err)
+			os.Exit(exitError)
+		}
+	}
+
+	if diffExitCode && !report.Empty() {
+		os.Exit(exitFindings)
+	}
+}
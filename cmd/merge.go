@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"subdomain-finder/internal/fileutil"
+	"subdomain-finder/internal/merge"
+	"subdomain-finder/internal/reporter"
+	"subdomain-finder/internal/types"
+
+	"github.com/spf13/cobra"
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge [flags] <results.json>...",
+	Short: "Merge JSON result files into one deduplicated file",
+	Long: `Merge the JSON results of several scans, for example a passive and an
+active run or runs with different wordlists, into one result per subdomain.
+
+The most recently checked result of a subdomain provides its IP, status,
+SSL and other single values. Ports, technologies, vulnerabilities, web
+services, endpoints and tags are combined from every file, and first_seen
+and last_seen span all of them.
+
+Examples:
+  subdomain-finder merge results/passive.json results/active.json -o merged.json
+  subdomain-finder merge scans/*.json > merged.json`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runMerge,
+}
+
+var mergeOutput string
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+
+	mergeCmd.Flags().StringVarP(&mergeOutput, "output", "o", "", "File to write the merged results to (default stdout)")
+}
+
+func runMerge(cmd *cobra.Command, args []string) {
+	sets := make([][]types.Result, 0, len(args))
+	total := 0
+	for _, path := range args {
+		results, _, err := reporter.LoadJSON(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		sets = append(sets, results)
+		total += len(results)
+	}
+	merged := merge.Results(sets...)
+
+	var out io.Writer = os.Stdout
+	if mergeOutput != "" {
+		file, err := fileutil.Create(mergeOutput)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(merged); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write merged results: %v\n", err)
+		os.Exit(exitError)
+	}
+	fmt.Fprintf(os.Stderr, "Merged %d results from %d files into %d subdomains\n", total, len(args), len(merged))
+}
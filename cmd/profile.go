@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+
+	"subdomain-finder/internal/fileutil"
+)
+
+// startCPUProfile begins writing a pprof CPU profile to path and returns a
+// function that stops it. An empty path is a no-op.
+func startCPUProfile(path string) (func(), error) {
+	if path == "" {
+		return func() {}, nil
+	}
+
+	file, err := fileutil.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pprof.StartCPUProfile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+	}
+
+	return func() {
+		pprof.StopCPUProfile()
+		file.Close()
+	}, nil
+}
+
+func writeMemProfile(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	file, err := fileutil.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(file); err != nil {
+		return fmt.Errorf("failed to write memory profile: %w", err)
+	}
+	return nil
+}
@@ -46,6 +46,10 @@ func init() {
 	rootCmd.PersistentFlags().String("log-format", "text", "Log format (text, json)")
 	rootCmd.PersistentFlags().String("output-dir", "./results", "Output directory for results")
 
+	_ = rootCmd.RegisterFlagCompletionFunc("log-level", completeValues("debug", "info", "warn", "error"))
+	_ = rootCmd.RegisterFlagCompletionFunc("log-format", completeValues("text", "json"))
+	_ = rootCmd.MarkPersistentFlagDirname("output-dir")
+
 	_ = viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
 	_ = viper.BindPFlag("log.level", rootCmd.PersistentFlags().Lookup("log-level"))
 	_ = viper.BindPFlag("log.format", rootCmd.PersistentFlags().Lookup("log-format"))
@@ -1,21 +1,42 @@
 package cmd
 
 import (
+	"context"
+	"crypto/x509"
 	"fmt"
+	"net/netip"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
 	"time"
 
+	"subdomain-finder/internal/dns"
+	"subdomain-finder/internal/fileutil"
 	"subdomain-finder/internal/finder"
+	"subdomain-finder/internal/geoip"
+	"subdomain-finder/internal/history"
+	httpclient "subdomain-finder/internal/http"
 	"subdomain-finder/internal/logger"
 	"subdomain-finder/internal/output"
+	"subdomain-finder/internal/passive"
+	"subdomain-finder/internal/portscanner"
+	"subdomain-finder/internal/progress"
+	"subdomain-finder/internal/reporter"
+	"subdomain-finder/internal/tags"
+	"subdomain-finder/internal/telemetry"
+	"subdomain-finder/internal/types"
+	"subdomain-finder/internal/vulnscanner"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var scanCmd = &cobra.Command{
-	Use:   "scan [flags] <domain>",
+	Use:   "scan [flags] [domain]",
 	Short: "Scan for subdomains of the target domain",
 	Long: `Scan for subdomains using various enumeration techniques.
 This command will perform DNS resolution and HTTP checking on discovered subdomains.
@@ -24,26 +45,109 @@ Examples:
   subdomain-finder scan example.com
   subdomain-finder scan example.com --wordlist custom.txt --threads 50
   subdomain-finder scan example.com --output results.txt --json --xml
-  subdomain-finder scan example.com --timeout 10 --rate-limit 100`,
-	Args: cobra.ExactArgs(1),
+  subdomain-finder scan example.com --timeout 10 --rate-limit 100
+  cat domains.txt | subdomain-finder scan --stdin
+  subdomain-finder scan --input-list domains.txt
+  subdomain-finder scan --targets 10.0.0.0/24,10.0.1.5
+  subdomain-finder scan example.com --fail-on high
+  subdomain-finder scan example.com --exclude-ports 9100,515
+  subdomain-finder scan example.com --login-url https://app.example.com/login --login-data 'user=alice&pass=secret'
+  subdomain-finder scan example.com --format hosts
+  subdomain-finder scan example.com --format-template '{{.Subdomain}} {{.IP}} {{.Status}}'
+
+Exit codes:
+  0  scan completed and no finding reached the --fail-on threshold
+  1  the scan could not run or its output could not be written
+  2  at least one finding is at or above the --fail-on severity`,
+	Args: cobra.MaximumNArgs(1),
 	Run:  runScan,
 }
 
 var (
-	wordlist   string
-	threads    int
-	timeout    int
-	rateLimit  int
-	outputFile string
-	jsonOutput bool
-	xmlOutput  bool
-	progress   bool
-	stats      bool
-	noColor    bool
-	userAgent  string
-	headers    []string
-	retries    int
-	delay      int
+	wordlist      string
+	threads       int
+	timeout       int
+	rateLimit     int
+	outputFile    string
+	jsonOutput    bool
+	jsonlOutput   bool
+	xmlOutput     bool
+	sarifOutput   bool
+	showProgress  bool
+	stats         bool
+	noColor       bool
+	tableOutput   bool
+	tuiOutput     bool
+	userAgent     string
+	headers       []string
+	retries       int
+	delay         int
+	sni           string
+	noSNI         bool
+	dryRun        bool
+	useStdin      bool
+	inputList     string
+	excludeParked bool
+	unresolved    bool
+	requireHTTP   bool
+	harFile       string
+	tlsMin        string
+	tlsMax        string
+	caBundle      string
+	failOn        string
+	targetRanges  []string
+	trackHistory  bool
+	mergeResults  bool
+	jsonSummary   bool
+	maxConns      int
+	progressFile  string
+	progressEvery int
+	profileCPU    string
+	profileMem    string
+	discovery     bool
+	vuln          bool
+	vulnChecks    string
+	signatures    string
+	activeVuln    bool
+	safe          bool
+	activeScope   []string
+	takeover      bool
+	extractLinks  bool
+	maxResults    int
+	maxSockets    int
+	format        string
+	formatTmpl    string
+	dnsMatch      string
+	dnsRecords    bool
+	recursive     bool
+	depth         int
+	permute       bool
+	mineSANs      bool
+	reverseDNS    bool
+	reverseMask   int
+	tagRules      string
+	portSpec      string
+	udpScan       bool
+	excludePorts  string
+	sslPorts      string
+	deepTLS       bool
+	loginURL      string
+	loginData     string
+	ownedRanges   []string
+	geoipDBs      []string
+	dropExternal  bool
+	keepWildcard  bool
+
+	passiveEnum          bool
+	axfr                 bool
+	passiveConcurrency   int
+	passiveSourceTimeout int
+	passiveTimeout       int
+)
+
+const (
+	exitError    = 1
+	exitFindings = 2
 )
 
 func init() {
@@ -55,14 +159,88 @@ func init() {
 	scanCmd.Flags().IntVarP(&rateLimit, "rate-limit", "r", 0, "Rate limit (requests per second, 0 = no limit)")
 	scanCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file to save results")
 	scanCmd.Flags().BoolVar(&jsonOutput, "json", false, "Save results as JSON")
+	scanCmd.Flags().BoolVar(&jsonSummary, "json-summary", false, "Write the JSON results file as {\"summary\": ..., \"results\": [...]} (implies --json)")
+	scanCmd.Flags().BoolVar(&jsonlOutput, "jsonl", false, "Save results as JSON lines, one object per result")
+	scanCmd.Flags().StringVar(&format, "format", "", "Print each result to stdout using a preset template ("+strings.Join(output.PresetNames(), ", ")+")")
+	scanCmd.Flags().StringVar(&formatTmpl, "format-template", "", "Print each result to stdout using a Go text/template, e.g. '{{.Subdomain}} {{.IP}} {{.Status}}'")
 	scanCmd.Flags().BoolVar(&xmlOutput, "xml", false, "Save results as XML")
-	scanCmd.Flags().BoolVar(&progress, "progress", true, "Show progress bar")
+	scanCmd.Flags().BoolVar(&sarifOutput, "sarif", false, "Save vulnerabilities as a SARIF 2.1.0 report for code scanning")
+	scanCmd.Flags().BoolVar(&showProgress, "progress", true, "Show progress bar")
+	scanCmd.Flags().StringVar(&progressFile, "progress-file", "", "Periodically write scan progress as JSON to this file for external monitors")
+	scanCmd.Flags().IntVar(&progressEvery, "progress-interval", 2, "Seconds between --progress-file updates")
 	scanCmd.Flags().BoolVar(&stats, "stats", true, "Show statistics")
 	scanCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+	scanCmd.Flags().BoolVar(&tableOutput, "table", false, "Print the results as an aligned table when the scan finishes")
+	scanCmd.Flags().BoolVar(&tuiOutput, "tui", false, "Show a live table of results with progress and ETA while scanning (plain output when not a terminal)")
 	scanCmd.Flags().StringVar(&userAgent, "user-agent", "SubdomainFinder/1.0.0", "Custom User-Agent string")
 	scanCmd.Flags().StringArrayVar(&headers, "header", []string{}, "Custom headers (format: key:value)")
 	scanCmd.Flags().IntVar(&retries, "retries", 3, "Number of retries for failed requests")
 	scanCmd.Flags().IntVar(&delay, "delay", 0, "Delay between requests in milliseconds")
+	scanCmd.Flags().StringVar(&sni, "sni", "", "Override the TLS server name (SNI) sent during SSL analysis")
+	scanCmd.Flags().BoolVar(&noSNI, "no-sni", false, "Send no SNI during SSL analysis to inspect the default certificate")
+	scanCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the scan plan and exit without sending any requests")
+	scanCmd.Flags().StringVar(&dnsMatch, "dns-match", "a,aaaa,cname", "DNS record types that make a subdomain exist (comma-separated: a, aaaa, cname)")
+	scanCmd.Flags().BoolVar(&recursive, "recursive", false, "Search the wordlist again under each found subdomain, e.g. api.staging.example.com")
+	scanCmd.Flags().IntVar(&depth, "depth", 2, "Deepest wordlist pass with --recursive (1 is the first pass only)")
+	scanCmd.Flags().BoolVar(&permute, "permute", false, "After the first pass, check altdns-style permutations of the found subdomains built from the wordlist")
+	scanCmd.Flags().BoolVar(&reverseDNS, "reverse", false, "After the first pass, run PTR lookups across the networks found subdomains resolve into and check the names under the domain")
+	scanCmd.Flags().IntVar(&reverseMask, "reverse-mask", 24, "Prefix length of the IPv4 networks searched by --reverse (16-32)")
+	scanCmd.Flags().BoolVar(&mineSANs, "san-mining", false, "Check the names listed in the SSL certificates of found subdomains")
+	scanCmd.Flags().BoolVar(&dnsRecords, "dns-records", false, "Record each subdomain's A, AAAA, CNAME, MX, TXT, NS and SOA records (seven extra DNS queries per host)")
+	scanCmd.Flags().StringSliceVar(&targetRanges, "targets", []string{}, "Scan IPv4 addresses or CIDR ranges directly, skipping DNS (comma-separated or repeated)")
+	scanCmd.Flags().BoolVar(&useStdin, "stdin", false, "Read target domains from stdin, one per line")
+	scanCmd.Flags().StringVarP(&inputList, "input-list", "i", "", "File with target domains, one per line")
+	scanCmd.Flags().StringVar(&tlsMin, "tls-min-version", "", "Minimum TLS version for HTTP clients (1.0-1.3, default Go's secure default)")
+	scanCmd.Flags().StringVar(&tlsMax, "tls-max-version", "", "Maximum TLS version for HTTP clients (1.0-1.3)")
+	scanCmd.Flags().StringVar(&caBundle, "ca-bundle", "", "PEM file of extra root CAs to trust (e.g. a TLS-intercepting proxy), added to the system roots")
+	scanCmd.Flags().StringVar(&failOn, "fail-on", "", "Exit with code 2 when a finding is at or above this severity (low, medium, high, critical)")
+	scanCmd.Flags().BoolVar(&mergeResults, "merge", false, "Merge results into the existing JSON results file instead of overwriting it, marking entries added, changed, unchanged or stale (implies --json)")
+	scanCmd.Flags().BoolVar(&trackHistory, "history", false, "Track first-seen/last-seen per subdomain across runs and report new and disappeared subdomains")
+	scanCmd.Flags().StringVar(&profileCPU, "profile-cpu", "", "Write a pprof CPU profile of the scan to this file")
+	scanCmd.Flags().StringVar(&profileMem, "profile-mem", "", "Write a pprof heap profile to this file when the scan finishes")
+	scanCmd.Flags().BoolVar(&discovery, "content-discovery", false, "Probe well-known paths such as Swagger/OpenAPI documentation (extra requests per host)")
+	scanCmd.Flags().BoolVar(&vuln, "vuln", false, "Run vulnerability checks against each host; SQL injection, XSS and traversal probes also need --safe=false (many extra requests per host)")
+	scanCmd.Flags().StringVar(&vulnChecks, "vuln-checks", "", "Vulnerability check categories to run (comma-separated: "+vulnCheckNames()+"; implies --vuln, default all)")
+	scanCmd.Flags().StringVar(&signatures, "vuln-signatures", "", "YAML file of custom vulnerability signatures matched against each host's headers, body or status")
+	scanCmd.Flags().BoolVar(&activeVuln, "active-vuln", false, "Run vulnerability checks that send attack payloads, such as CRLF header injection (needs --safe=false, extra requests per host)")
+	scanCmd.Flags().BoolVar(&safe, "safe", true, "Never send attack payloads; use --safe=false to allow SQL injection, XSS, traversal and --active-vuln probes")
+	scanCmd.Flags().StringSliceVar(&activeScope, "active-scope", nil, "Hosts attack payloads may target, e.g. app.example.com,*.staging.example.com (default any)")
+	scanCmd.Flags().BoolVar(&takeover, "takeover", false, "Check CNAMEs against services known to allow subdomain takeover (one extra DNS lookup per host)")
+	scanCmd.Flags().BoolVar(&extractLinks, "extract-links", false, "Record same-domain links, script URLs and form endpoints found on each homepage")
+	scanCmd.Flags().StringVar(&harFile, "har", "", "Record all HTTP requests and responses to a HAR file")
+	scanCmd.Flags().StringVar(&portSpec, "ports", "none", "Ports to scan on each host: none, quick (23 common ports), full (1-65535) or a list such as 80,443,8000-8100")
+	scanCmd.Flags().BoolVar(&udpScan, "udp", false, "Also probe common UDP services (DNS, TFTP, NTP, NetBIOS, SNMP, SSDP, mDNS) on each host")
+	scanCmd.Flags().StringVar(&excludePorts, "exclude-ports", "", "Ports that are never dialed, e.g. 9100,515 or 6000-6010 (printers and other fragile services)")
+	scanCmd.Flags().StringVar(&sslPorts, "ssl-ports", "", "Ports whose TLS is analyzed when open, e.g. 443,8443 (default 25,110,143,443,465,587,636,993,995,8443)")
+	scanCmd.Flags().BoolVar(&deepTLS, "tls-deep", false, "Enumerate TLS protocols and cipher suites, probe session resumption and 0-RTT and fetch OCSP/CRL revocation data (dozens of handshakes per TLS endpoint)")
+	scanCmd.Flags().StringVar(&loginURL, "login-url", "", "Log in at this URL first and carry the session cookie through HTTP, tech and vuln requests")
+	scanCmd.Flags().StringVar(&loginData, "login-data", "", "URL-encoded form fields submitted to --login-url (e.g. user=alice&pass=secret)")
+	scanCmd.Flags().BoolVar(&passiveEnum, "passive", false, "Also check subdomains found in certificate transparency logs (crt.sh)")
+	scanCmd.Flags().BoolVar(&axfr, "axfr", false, "Also try a DNS zone transfer (AXFR) against the domain's nameservers and check the names it returns")
+	scanCmd.Flags().IntVar(&passiveConcurrency, "passive-concurrency", 5, "Number of passive sources queried at once")
+	scanCmd.Flags().IntVar(&passiveSourceTimeout, "passive-source-timeout", 30, "Seconds before a single passive source is abandoned (its partial results are kept)")
+	scanCmd.Flags().IntVar(&passiveTimeout, "passive-timeout", 120, "Seconds the whole passive phase may take before active scanning starts")
+	scanCmd.Flags().IntVar(&maxConns, "max-conns-per-host", 0, "Maximum simultaneous connections to one host, and subdomains of one resolved IP checked at once (0 = no limit)")
+	scanCmd.Flags().IntVar(&maxSockets, "max-sockets", 256, "Most sockets open at once across DNS, HTTP, port and SSL checks (0 for no limit)")
+	scanCmd.Flags().IntVar(&maxResults, "max-results", 0, "Stop the scan once this many subdomains are found (0 = no limit); results so far are still saved")
+	scanCmd.Flags().StringVar(&tagRules, "tag-rules", "", "YAML file of rules that tag results by subdomain, IP, title, server or technology patterns")
+	scanCmd.Flags().BoolVar(&excludeParked, "exclude-parked", false, "Drop subdomains that serve parking or domain-for-sale pages")
+	scanCmd.Flags().StringSliceVar(&ownedRanges, "owned-ranges", []string{}, "IP addresses or CIDR ranges you own; results resolving elsewhere are marked externally hosted (comma-separated or repeated)")
+	scanCmd.Flags().StringSliceVar(&geoipDBs, "geoip", []string{}, "MaxMind .mmdb databases (e.g. GeoLite2-City and GeoLite2-ASN) used to add location and ASN data to each IP (comma-separated or repeated)")
+	scanCmd.Flags().BoolVar(&dropExternal, "exclude-external", false, "Drop subdomains that resolve outside --owned-ranges")
+	scanCmd.Flags().BoolVar(&unresolved, "include-unresolved", false, "Keep names that fail to resolve but still answer over HTTP")
+	scanCmd.Flags().BoolVar(&requireHTTP, "require-http", false, "Drop subdomains that give no HTTP or HTTPS response")
+	scanCmd.Flags().BoolVar(&keepWildcard, "keep-wildcard", false, "Keep subdomains answered by wildcard DNS, tagged wildcard-dns, instead of dropping them")
+
+	_ = scanCmd.RegisterFlagCompletionFunc("format", completeValues(output.PresetNames()...))
+	_ = scanCmd.RegisterFlagCompletionFunc("fail-on", completeValues("low", "medium", "high", "critical"))
+	_ = scanCmd.RegisterFlagCompletionFunc("tls-min-version", completeValues("1.0", "1.1", "1.2", "1.3"))
+	_ = scanCmd.RegisterFlagCompletionFunc("tls-max-version", completeValues("1.0", "1.1", "1.2", "1.3"))
+	_ = scanCmd.RegisterFlagCompletionFunc("vuln-checks", completeList(strings.Split(vulnCheckNames(), ", ")...))
+	_ = scanCmd.RegisterFlagCompletionFunc("ports", completeValues("none", "quick", "full"))
+	_ = scanCmd.RegisterFlagCompletionFunc("dns-match", completeList("a", "aaaa", "cname"))
+	_ = scanCmd.MarkFlagFilename("tag-rules", "yaml", "yml", "json")
+	_ = scanCmd.MarkFlagFilename("ca-bundle", "pem", "crt")
 
 	_ = viper.BindPFlag("scan.wordlist", scanCmd.Flags().Lookup("wordlist"))
 	_ = viper.BindPFlag("scan.threads", scanCmd.Flags().Lookup("threads"))
@@ -70,76 +248,743 @@ func init() {
 	_ = viper.BindPFlag("scan.rate_limit", scanCmd.Flags().Lookup("rate-limit"))
 	_ = viper.BindPFlag("scan.output", scanCmd.Flags().Lookup("output"))
 	_ = viper.BindPFlag("scan.json", scanCmd.Flags().Lookup("json"))
+	_ = viper.BindPFlag("scan.json_summary", scanCmd.Flags().Lookup("json-summary"))
+	_ = viper.BindPFlag("scan.jsonl", scanCmd.Flags().Lookup("jsonl"))
 	_ = viper.BindPFlag("scan.xml", scanCmd.Flags().Lookup("xml"))
+	_ = viper.BindPFlag("scan.sarif", scanCmd.Flags().Lookup("sarif"))
+	_ = viper.BindPFlag("scan.format", scanCmd.Flags().Lookup("format"))
+	_ = viper.BindPFlag("scan.format_template", scanCmd.Flags().Lookup("format-template"))
 	_ = viper.BindPFlag("scan.progress", scanCmd.Flags().Lookup("progress"))
+	_ = viper.BindPFlag("scan.progress_file", scanCmd.Flags().Lookup("progress-file"))
+	_ = viper.BindPFlag("scan.progress_interval", scanCmd.Flags().Lookup("progress-interval"))
 	_ = viper.BindPFlag("scan.stats", scanCmd.Flags().Lookup("stats"))
 	_ = viper.BindPFlag("scan.no_color", scanCmd.Flags().Lookup("no-color"))
+	_ = viper.BindPFlag("scan.table", scanCmd.Flags().Lookup("table"))
+	_ = viper.BindPFlag("scan.tui", scanCmd.Flags().Lookup("tui"))
 	_ = viper.BindPFlag("scan.user_agent", scanCmd.Flags().Lookup("user-agent"))
 	_ = viper.BindPFlag("scan.headers", scanCmd.Flags().Lookup("header"))
 	_ = viper.BindPFlag("scan.retries", scanCmd.Flags().Lookup("retries"))
 	_ = viper.BindPFlag("scan.delay", scanCmd.Flags().Lookup("delay"))
+	_ = viper.BindPFlag("scan.sni", scanCmd.Flags().Lookup("sni"))
+	_ = viper.BindPFlag("scan.no_sni", scanCmd.Flags().Lookup("no-sni"))
+	_ = viper.BindPFlag("scan.max_conns_per_host", scanCmd.Flags().Lookup("max-conns-per-host"))
+	_ = viper.BindPFlag("scan.max_sockets", scanCmd.Flags().Lookup("max-sockets"))
+	_ = viper.BindPFlag("scan.max_results", scanCmd.Flags().Lookup("max-results"))
+	_ = viper.BindPFlag("scan.dns_match", scanCmd.Flags().Lookup("dns-match"))
+	_ = viper.BindPFlag("scan.dns_records", scanCmd.Flags().Lookup("dns-records"))
+	_ = viper.BindPFlag("scan.recursive", scanCmd.Flags().Lookup("recursive"))
+	_ = viper.BindPFlag("scan.depth", scanCmd.Flags().Lookup("depth"))
+	_ = viper.BindPFlag("scan.permute", scanCmd.Flags().Lookup("permute"))
+	_ = viper.BindPFlag("scan.san_mining", scanCmd.Flags().Lookup("san-mining"))
+	_ = viper.BindPFlag("scan.reverse", scanCmd.Flags().Lookup("reverse"))
+	_ = viper.BindPFlag("scan.reverse_mask", scanCmd.Flags().Lookup("reverse-mask"))
+	_ = viper.BindPFlag("scan.tag_rules", scanCmd.Flags().Lookup("tag-rules"))
+	_ = viper.BindPFlag("scan.exclude_parked", scanCmd.Flags().Lookup("exclude-parked"))
+	_ = viper.BindPFlag("scan.owned_ranges", scanCmd.Flags().Lookup("owned-ranges"))
+	_ = viper.BindPFlag("scan.geoip", scanCmd.Flags().Lookup("geoip"))
+	_ = viper.BindPFlag("scan.exclude_external", scanCmd.Flags().Lookup("exclude-external"))
+	_ = viper.BindPFlag("scan.keep_wildcard", scanCmd.Flags().Lookup("keep-wildcard"))
+	_ = viper.BindPFlag("scan.include_unresolved", scanCmd.Flags().Lookup("include-unresolved"))
+	_ = viper.BindPFlag("scan.require_http", scanCmd.Flags().Lookup("require-http"))
+	_ = viper.BindPFlag("scan.har", scanCmd.Flags().Lookup("har"))
+	_ = viper.BindPFlag("scan.content_discovery", scanCmd.Flags().Lookup("content-discovery"))
+	_ = viper.BindPFlag("scan.vuln", scanCmd.Flags().Lookup("vuln"))
+	_ = viper.BindPFlag("scan.vuln_checks", scanCmd.Flags().Lookup("vuln-checks"))
+	_ = viper.BindPFlag("scan.active_vuln", scanCmd.Flags().Lookup("active-vuln"))
+	_ = viper.BindPFlag("scan.safe", scanCmd.Flags().Lookup("safe"))
+	_ = viper.BindPFlag("scan.active_scope", scanCmd.Flags().Lookup("active-scope"))
+	_ = viper.BindPFlag("scan.vuln_signatures", scanCmd.Flags().Lookup("vuln-signatures"))
+	_ = viper.BindPFlag("scan.takeover", scanCmd.Flags().Lookup("takeover"))
+	_ = viper.BindPFlag("scan.extract_links", scanCmd.Flags().Lookup("extract-links"))
+	_ = viper.BindPFlag("scan.ports", scanCmd.Flags().Lookup("ports"))
+	_ = viper.BindPFlag("scan.udp", scanCmd.Flags().Lookup("udp"))
+	_ = viper.BindPFlag("scan.exclude_ports", scanCmd.Flags().Lookup("exclude-ports"))
+	_ = viper.BindPFlag("scan.ssl_ports", scanCmd.Flags().Lookup("ssl-ports"))
+	_ = viper.BindPFlag("scan.tls_deep", scanCmd.Flags().Lookup("tls-deep"))
+	_ = viper.BindPFlag("scan.login_url", scanCmd.Flags().Lookup("login-url"))
+	_ = viper.BindPFlag("scan.passive", scanCmd.Flags().Lookup("passive"))
+	_ = viper.BindPFlag("scan.axfr", scanCmd.Flags().Lookup("axfr"))
+	_ = viper.BindPFlag("scan.passive_concurrency", scanCmd.Flags().Lookup("passive-concurrency"))
+	_ = viper.BindPFlag("scan.passive_source_timeout", scanCmd.Flags().Lookup("passive-source-timeout"))
+	_ = viper.BindPFlag("scan.passive_timeout", scanCmd.Flags().Lookup("passive-timeout"))
+	_ = viper.BindPFlag("scan.history", scanCmd.Flags().Lookup("history"))
+	_ = viper.BindPFlag("scan.merge", scanCmd.Flags().Lookup("merge"))
+	_ = viper.BindPFlag("scan.fail_on", scanCmd.Flags().Lookup("fail-on"))
+	_ = viper.BindPFlag("http.tls_min_version", scanCmd.Flags().Lookup("tls-min-version"))
+	_ = viper.BindPFlag("http.tls_max_version", scanCmd.Flags().Lookup("tls-max-version"))
+	_ = viper.BindPFlag("http.ca_bundle", scanCmd.Flags().Lookup("ca-bundle"))
 }
 
 func runScan(cmd *cobra.Command, args []string) {
-	domain := args[0]
+	var domains, hosts []string
+
+	switch {
+	case len(targetRanges) > 0 && (useStdin || inputList != "" || len(args) > 0):
+		fmt.Fprintln(os.Stderr, "Error: --targets cannot be combined with a domain argument, --input-list or --stdin")
+		os.Exit(exitError)
+	case len(targetRanges) > 0:
+		expanded, err := expandTargets(targetRanges)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		if len(expanded) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: --targets did not contain any hosts")
+			os.Exit(exitError)
+		}
+		domains = []string{targetsName(targetRanges)}
+		hosts = expanded
+	default:
+		targets, err := loadTargets(args, inputList, useStdin, os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+		domains = targets
+	}
+
+	failThreshold := -1
+	if failOn != "" {
+		failThreshold = types.SeverityRank(failOn)
+		if failThreshold < 0 {
+			fmt.Fprintf(os.Stderr, "Error: invalid --fail-on severity %q (use low, medium, high or critical)\n", failOn)
+			os.Exit(exitError)
+		}
+	}
+
+	resultTemplate, err := output.ParseResultTemplate(viper.GetString("scan.format"), viper.GetString("scan.format_template"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	if !templatedOutput() {
+		resultTemplate = nil
+	}
+
+	tlsMinVersion, err := httpclient.ParseTLSVersion(viper.GetString("http.tls_min_version"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --tls-min-version: %v\n", err)
+		os.Exit(exitError)
+	}
+	tlsMaxVersion, err := httpclient.ParseTLSVersion(viper.GetString("http.tls_max_version"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --tls-max-version: %v\n", err)
+		os.Exit(exitError)
+	}
+	if _, err := httpclient.NewTransport(httpclient.TransportConfig{MinVersion: tlsMinVersion, MaxVersion: tlsMaxVersion}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+	var rootCAs *x509.CertPool
+	if path := viper.GetString("http.ca_bundle"); path != "" {
+		if rootCAs, err = httpclient.LoadCABundle(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+
+	var tagRuleSet *tags.RuleSet
+	if path := viper.GetString("scan.tag_rules"); path != "" {
+		if tagRuleSet, err = tags.LoadRules(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+
+	var vulnSignatures []vulnscanner.Signature
+	if path := viper.GetString("scan.vuln_signatures"); path != "" {
+		if vulnSignatures, err = vulnscanner.LoadSignatures(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+
+	if _, err := portscanner.SelectPorts(viper.GetString("scan.ports")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --ports: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	var excludedPorts []int
+	if spec := viper.GetString("scan.exclude_ports"); spec != "" {
+		excludedPorts, err = portscanner.ParsePorts(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --exclude-ports: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+
+	var tlsPorts []int
+	if spec := viper.GetString("scan.ssl_ports"); spec != "" {
+		tlsPorts, err = portscanner.ParsePorts(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --ssl-ports: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+
+	dnsMatchTypes, err := dns.ParseRecordTypes(viper.GetString("scan.dns_match"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --dns-match: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	owned, err := parseOwnedRanges(viper.GetStringSlice("scan.owned_ranges"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --owned-ranges: %v\n", err)
+		os.Exit(exitError)
+	}
+	if viper.GetBool("scan.exclude_external") && len(owned) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --exclude-external requires --owned-ranges")
+		os.Exit(exitError)
+	}
+
+	var locator geoip.Locator
+	if paths := viper.GetStringSlice("scan.geoip"); len(paths) > 0 {
+		databases, err := geoip.NewDatabaseLocator(paths...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --geoip: %v\n", err)
+			os.Exit(exitError)
+		}
+		locator = databases
+	}
 
+	if viper.GetInt("scan.max_results") < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --max-results must not be negative")
+		os.Exit(exitError)
+	}
+	if viper.GetInt("scan.max_sockets") < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --max-sockets must not be negative")
+		os.Exit(exitError)
+	}
+	if viper.GetInt("scan.delay") < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --delay must not be negative")
+		os.Exit(exitError)
+	}
+	if viper.GetInt("scan.max_conns_per_host") < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --max-conns-per-host must not be negative")
+		os.Exit(exitError)
+	}
+
+	if viper.GetInt("scan.progress_interval") < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --progress-interval must be at least 1 second")
+		os.Exit(exitError)
+	}
+
+	if viper.GetInt("scan.depth") < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --depth must be at least 1")
+		os.Exit(exitError)
+	}
+	// A /16 is already 65,536 lookups
+	if mask := viper.GetInt("scan.reverse_mask"); mask < 16 || mask > 32 {
+		fmt.Fprintln(os.Stderr, "Error: --reverse-mask must be between 16 and 32")
+		os.Exit(exitError)
+	}
+
+	if loginData != "" && viper.GetString("scan.login_url") == "" {
+		fmt.Fprintln(os.Stderr, "Error: --login-data requires --login-url")
+		os.Exit(exitError)
+	}
+
+	if _, err := vulnscanner.ParseActiveScope(viper.GetStringSlice("scan.active_scope")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --active-scope: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	var checks []vulnscanner.Check
+	if spec := viper.GetString("scan.vuln_checks"); spec != "" {
+		if checks, err = vulnscanner.ParseChecks(spec); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --vuln-checks: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+	if viper.GetBool("scan.safe") {
+		// Asking for attack payloads by name is a mistake, not something to skip quietly
+		if viper.GetBool("scan.active_vuln") {
+			fmt.Fprintln(os.Stderr, "Error: --active-vuln sends attack payloads and requires --safe=false")
+			os.Exit(exitError)
+		}
+		for _, check := range checks {
+			if slices.Contains(vulnscanner.AttackChecks, check) {
+				fmt.Fprintf(os.Stderr, "Error: --vuln-checks %s sends attack payloads and requires --safe=false\n", check)
+				os.Exit(exitError)
+			}
+		}
+	}
+
+	// Merging reads and rewrites the JSON results file
+	if viper.GetBool("scan.merge") || viper.GetBool("scan.json_summary") {
+		jsonOutput = true
+	}
+
+	if err := checkOutputPaths(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	stopCPUProfile, err := startCPUProfile(profileCPU)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	shutdownTracing, err := telemetry.Setup(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	var failed, findingsOverThreshold bool
+	for _, domain := range domains {
+		outputName := outputFile
+		harName := harFile
+		if len(domains) > 1 {
+			if outputFile != "" {
+				outputName = fmt.Sprintf("%s_%s", domain, outputFile)
+			}
+			if harFile != "" {
+				harName = filepath.Join(filepath.Dir(harFile), fmt.Sprintf("%s_%s", domain, filepath.Base(harFile)))
+			}
+		}
+		results, err := scanDomain(domain, hosts, outputName, harName, tlsMinVersion, tlsMaxVersion, excludedPorts, tlsPorts, dnsMatchTypes, owned, locator, checks, vulnSignatures, rootCAs, tagRuleSet)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error scanning %s: %v\n", domain, err)
+			failed = true
+		}
+		if resultTemplate != nil {
+			if err := output.PrintTemplated(os.Stdout, resultTemplate, results); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				failed = true
+			}
+		}
+		for _, result := range results {
+			if failThreshold >= 0 && result.HighestSeverity() >= failThreshold {
+				findingsOverThreshold = true
+			}
+		}
+	}
+
+	stopCPUProfile()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error flushing traces: %v\n", err)
+	}
+	cancel()
+	if err := writeMemProfile(profileMem); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		failed = true
+	}
+
+	if failed {
+		os.Exit(exitError)
+	}
+	if findingsOverThreshold {
+		fmt.Fprintf(os.Stderr, "Findings at or above %q severity were found\n", failOn)
+		os.Exit(exitFindings)
+	}
+}
+
+// dnsRetries is dns.retries from the config file, falling back to --retries.
+func dnsRetries() int {
+	if viper.IsSet("dns.retries") {
+		return viper.GetInt("dns.retries")
+	}
+	return retries
+}
+
+func templatedOutput() bool {
+	return viper.GetString("scan.format") != "" || viper.GetString("scan.format_template") != ""
+}
+
+// outputFormats lists what a scan of one domain writes, in the order
+// scanDomain saves it.
+func outputFormats(outputName, harName string) []string {
+	var formats []string
+	if templatedOutput() {
+		formats = append(formats, "template (stdout)")
+	}
+	if harName != "" {
+		formats = append(formats, "har ("+harName+")")
+	}
+	if outputName != "" {
+		formats = append(formats, "text ("+outputName+")")
+	}
+	if jsonOutput {
+		formats = append(formats, "json")
+	}
+	if jsonlOutput {
+		formats = append(formats, "jsonl")
+	}
+	if xmlOutput {
+		formats = append(formats, "xml")
+	}
+	if sarifOutput {
+		formats = append(formats, "sarif")
+	}
+	return formats
+}
+
+// recursionDepth is the deepest wordlist pass, 1 unless --recursive is set.
+func recursionDepth() int {
+	if !viper.GetBool("scan.recursive") {
+		return 1
+	}
+	return viper.GetInt("scan.depth")
+}
+
+func scanDomain(domain string, hosts []string, outputName, harName string, tlsMinVersion, tlsMaxVersion uint16, excludedPorts, tlsPorts []int, dnsMatchTypes []dns.RecordType, owned []netip.Prefix, locator geoip.Locator, checks []vulnscanner.Check, vulnSignatures []vulnscanner.Signature, rootCAs *x509.CertPool, tagRuleSet *tags.RuleSet) ([]types.Result, error) {
 	cfg := finder.Config{
-		Domain:     domain,
-		Wordlist:   wordlist,
-		Threads:    threads,
-		Timeout:    timeout,
-		RateLimit:  rateLimit,
-		OutputFile: outputFile,
-		Verbose:    viper.GetBool("verbose"),
-		JSON:       jsonOutput,
-		XML:        xmlOutput,
-		Progress:   progress,
-		Stats:      stats,
-		NoColor:    noColor,
-		UserAgent:  userAgent,
-		Headers:    headers,
-		Retries:    retries,
-		Delay:      delay,
+		Domain:           domain,
+		Hosts:            hosts,
+		ContentDiscovery: discovery,
+		Vuln:             viper.GetBool("scan.vuln") || len(checks) > 0,
+		VulnChecks:       checks,
+		ActiveVuln:       viper.GetBool("scan.active_vuln"),
+		Aggressive:       !viper.GetBool("scan.safe"),
+		ActiveScope:      viper.GetStringSlice("scan.active_scope"),
+		VulnSignatures:   vulnSignatures,
+		Takeover:         viper.GetBool("scan.takeover"),
+		ExtractLinks:     viper.GetBool("scan.extract_links"),
+		MaxResults:       viper.GetInt("scan.max_results"),
+		MaxSockets:       viper.GetInt("scan.max_sockets"),
+		MaxConnsPerHost:  viper.GetInt("scan.max_conns_per_host"),
+		DNSMatch:         dnsMatchTypes,
+		DNSRecords:       viper.GetBool("scan.dns_records"),
+		RecursionDepth:   recursionDepth(),
+		Permute:          viper.GetBool("scan.permute"),
+		MineSANs:         viper.GetBool("scan.san_mining"),
+		Reverse:          viper.GetBool("scan.reverse"),
+		ReverseMask:      viper.GetInt("scan.reverse_mask"),
+		TagRules:         tagRuleSet,
+		Ports:            viper.GetString("scan.ports"),
+		UDP:              viper.GetBool("scan.udp"),
+		ExcludePorts:     excludedPorts,
+		SSLPorts:         tlsPorts,
+		DeepTLS:          viper.GetBool("scan.tls_deep"),
+		LoginURL:         viper.GetString("scan.login_url"),
+		LoginData:        loginData,
+		Wordlist:         wordlist,
+		Threads:          threads,
+		Timeout:          timeout,
+		RateLimit:        rateLimit,
+		OutputFile:       outputName,
+		Verbose:          viper.GetBool("verbose"),
+		JSON:             jsonOutput,
+		JSONL:            jsonlOutput,
+		XML:              xmlOutput,
+		Progress:         showProgress,
+		Stats:            stats,
+		NoColor:          noColor,
+		UserAgent:        userAgent,
+		Headers:          headers,
+		Retries:          retries,
+		DNSRetries:       dnsRetries(),
+		DNSServers:       viper.GetStringSlice("dns.servers"),
+		Delay:            delay,
+		SNI:              sni,
+		NoSNI:            noSNI,
+		ExcludeParked:    excludeParked,
+		OwnedRanges:      owned,
+		ExcludeExternal:  viper.GetBool("scan.exclude_external"),
+		KeepWildcard:     viper.GetBool("scan.keep_wildcard"),
+		KeepUnresolved:   viper.GetBool("scan.include_unresolved"),
+		RequireHTTP:      viper.GetBool("scan.require_http"),
+		HARFile:          harName,
+		TLSMinVersion:    tlsMinVersion,
+		TLSMaxVersion:    tlsMaxVersion,
+		RootCAs:          rootCAs,
+
+		PassiveConcurrency:   viper.GetInt("scan.passive_concurrency"),
+		PassiveSourceTimeout: viper.GetInt("scan.passive_source_timeout"),
+		PassiveTimeout:       viper.GetInt("scan.passive_timeout"),
 	}
 
 	log := logger.NewLogger(viper.GetString("log.level"), viper.GetString("log.format"))
 
 	outputter := output.NewOutputter(cfg, log)
-	finder := finder.NewFinder(cfg)
+	// Templated results own stdout so they can be piped into other tools
+	logOutput := os.Stdout
+	if templatedOutput() {
+		logOutput = os.Stderr
+		outputter.SetWriter(os.Stderr)
+		log.SetOutput(os.Stderr)
+	}
+	opts := finder.Options{Logger: log, Locator: locator}
+	if viper.GetBool("scan.passive") {
+		opts.PassiveSources = append(opts.PassiveSources, passive.NewCTSource(nil))
+	}
+	if viper.GetBool("scan.axfr") {
+		resolver := dns.NewResolver(cfg.Timeout)
+		resolver.SetServers(cfg.DNSServers)
+		opts.PassiveSources = append(opts.PassiveSources, passive.NewAXFRSource(resolver))
+	}
+	finder := finder.NewFinderWithOptions(cfg, opts)
+
+	if dryRun {
+		outputter.PrintPlan(finder.Plan(), output.PlanSettings{
+			OutputDir: viper.GetString("output.dir"),
+			Formats:   outputFormats(outputName, harName),
+			CABundle:  viper.GetString("http.ca_bundle"),
+		})
+		return nil, nil
+	}
+
+	if err := finder.Login(); err != nil {
+		log.Error("Login failed", "error", err)
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+	if cfg.LoginURL != "" {
+		log.Info("Logged in", "url", cfg.LoginURL)
+	}
+
+	outputDir := viper.GetString("output.dir")
+	jsonFile := filepath.Join(outputDir, fmt.Sprintf("%s.json", domain))
+
+	// Load before scanning so an unreadable file fails fast and is never
+	// overwritten
+	var previous []types.Result
+	if viper.GetBool("scan.merge") {
+		var err error
+		previous, err = history.LoadResults(jsonFile)
+		if err != nil {
+			log.Error("Failed to load previous results", "error", err)
+			return nil, err
+		}
+	}
 
 	log.Info("Starting subdomain enumeration", "domain", domain)
 
+	var live *output.LiveView
+	if viper.GetBool("scan.tui") {
+		if live = outputter.NewLiveView(); live == nil {
+			log.Debug("Output is not a terminal, using plain output instead of --tui")
+		}
+	}
+
+	var bar *progress.Progress
+	if cfg.Progress && live == nil {
+		bar = progress.NewProgress(finder.Plan().Candidates, cfg.Stats)
+		bar.Start()
+	}
+
+	var progressWriter *progress.FileWriter
+	if path := viper.GetString("scan.progress_file"); path != "" {
+		interval := time.Duration(viper.GetInt("scan.progress_interval")) * time.Second
+		progressWriter = progress.NewFileWriter(path, domain, interval)
+		progressWriter.Update(progress.Stats{Total: finder.Plan().Candidates})
+		progressWriter.Start()
+	}
+
+	// Log lines go through the live view so they print above its frame
+	if live != nil {
+		log.SetOutput(live)
+		finder.OnResult(live.AddResult)
+		live.Start()
+	}
+
+	finder.OnProgress(func(stats progress.Stats) {
+		if live != nil {
+			live.Update(stats)
+		}
+		if bar != nil {
+			bar.Update(stats)
+		}
+		if progressWriter != nil {
+			progressWriter.Update(stats)
+		}
+	})
+
+	// Ctrl-C stops the scan but still reports what was found so far; a
+	// second one once the scan has stopped exits as usual
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	startTime := time.Now()
-	results := finder.Find()
+	results := finder.FindWithContext(ctx)
+	interrupted := ctx.Err() != nil
+	stop()
+	if interrupted {
+		log.Warn("Scan interrupted, results are partial", "found", len(results))
+	}
+	if traceID := finder.TraceID(); traceID != "" {
+		log.Info("Scan traced", "trace_id", traceID)
+	}
+	if finder.ResultLimitReached() {
+		log.Warn("Result limit reached, scan stopped early and results are partial", "max_results", cfg.MaxResults)
+	}
 	duration := time.Since(startTime)
 
+	if live != nil {
+		live.Stop()
+		log.SetOutput(logOutput)
+	}
+	if bar != nil {
+		bar.Stop()
+		bar.PrintStats()
+	}
+	if progressWriter != nil {
+		if err := progressWriter.Stop(); err != nil {
+			log.Error("Failed to write progress file", "error", err)
+		}
+	}
+
 	log.Info("Subdomain enumeration completed",
 		"domain", domain,
 		"found", len(results),
-		"duration", duration.String())
+		"duration", duration.String(),
+		"scan_id", finder.ScanID())
 
-	outputter.PrintSummary(len(results), duration)
+	if viper.GetBool("scan.table") {
+		outputter.PrintTable(results)
+	}
+	outputter.PrintSummary(len(results), duration, finder.WildcardIPs())
 
-	if outputFile != "" {
-		outputDir := viper.GetString("output.dir")
-		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			log.Error("Failed to create output directory", "error", err)
-			return
+	var saveErr error
+	var disappeared []string
+	if trackHistory && interrupted {
+		log.Warn("Scan history not updated for an interrupted scan")
+	} else if trackHistory {
+		historyPath := filepath.Join(viper.GetString("output.dir"), "history", domain+".json")
+		store, err := history.Load(historyPath)
+		if err != nil {
+			log.Error("Failed to load scan history", "error", err)
+			saveErr = err
+		} else {
+			changes := store.Update(results, time.Now())
+			outputter.PrintChanges(changes)
+			for _, record := range changes.Disappeared {
+				disappeared = append(disappeared, record.Subdomain)
+			}
+			if err := store.Save(); err != nil {
+				log.Error("Failed to save scan history", "error", err)
+				saveErr = err
+			}
 		}
+	}
+
+	if cfg.Stats {
+		outputter.PrintAttackSurface(reporter.AttackSurfaceSummary(results))
+	}
 
-		fullPath := filepath.Join(outputDir, outputFile)
-		outputter.SaveToFile(results, fullPath)
+	if err := finder.SaveHAR(); err != nil {
+		log.Error("Failed to save HAR file", "error", err)
+		saveErr = err
+	} else if harName != "" {
+		log.Info("HAR file saved", "file", harName)
+	}
+
+	if outputName != "" {
+		if err := outputter.SaveToFile(results, filepath.Join(outputDir, outputName)); err != nil {
+			log.Error("Failed to save results", "error", err)
+			saveErr = err
+		}
 	}
 
 	if jsonOutput {
-		outputDir := viper.GetString("output.dir")
-		jsonFile := filepath.Join(outputDir, fmt.Sprintf("%s.json", domain))
-		outputter.SaveAsJSON(results, jsonFile)
+		saved := results
+		if viper.GetBool("scan.merge") {
+			var summary history.MergeSummary
+			saved, summary = history.Merge(previous, results, time.Now())
+			log.Info("Merged with previous results",
+				"added", summary.Added,
+				"changed", summary.Changed,
+				"unchanged", summary.Unchanged,
+				"stale", summary.Stale)
+		}
+		var err error
+		if viper.GetBool("scan.json_summary") {
+			err = outputter.SaveAsJSONWithSummary(scanSummary(saved, startTime, duration, finder.WildcardIPs(), disappeared), saved, jsonFile)
+		} else {
+			err = outputter.SaveAsJSON(saved, jsonFile)
+		}
+		if err != nil {
+			log.Error("Failed to save JSON results", "error", err)
+			saveErr = err
+		}
+	}
+
+	if jsonlOutput {
+		jsonlFile := filepath.Join(outputDir, fmt.Sprintf("%s.jsonl", domain))
+		if err := outputter.SaveAsJSONL(results, jsonlFile); err != nil {
+			log.Error("Failed to save JSONL results", "error", err)
+			saveErr = err
+		}
 	}
 
 	if xmlOutput {
-		outputDir := viper.GetString("output.dir")
 		xmlFile := filepath.Join(outputDir, fmt.Sprintf("%s.xml", domain))
-		outputter.SaveAsXML(results, xmlFile)
+		if err := outputter.SaveAsXML(results, xmlFile); err != nil {
+			log.Error("Failed to save XML results", "error", err)
+			saveErr = err
+		}
+	}
+
+	if sarifOutput {
+		sarifName := fmt.Sprintf("%s.sarif", domain)
+		summary := scanSummary(results, startTime, duration, finder.WildcardIPs(), disappeared)
+		if err := reporter.NewReporter(outputDir).SaveAsSARIF(summary, results, sarifName); err != nil {
+			log.Error("Failed to save SARIF report", "error", err)
+			saveErr = err
+		} else {
+			log.Info("SARIF report saved", "file", filepath.Join(outputDir, sarifName))
+		}
+	}
+
+	return results, saveErr
+}
+
+// scanSummary describes a finished scan for the outputs that embed one.
+// disappeared are the names --history no longer found; stale merged results
+// are added by the reporter.
+func scanSummary(results []types.Result, startTime time.Time, duration time.Duration, wildcardIPs, disappeared []string) *types.ScanSummary {
+	summary := reporter.NewReporter("").GenerateSummaryReport(results)
+	for _, name := range disappeared {
+		if !slices.Contains(summary.Disappeared, name) {
+			summary.Disappeared = append(summary.Disappeared, name)
+		}
+	}
+	sort.Strings(summary.Disappeared)
+	summary.StartTime = startTime
+	summary.EndTime = startTime.Add(duration)
+	summary.ScanDuration = duration
+	summary.WildcardIPs = wildcardIPs
+	summary.WildcardDetected = len(wildcardIPs) > 0
+	return summary
+}
+
+// vulnCheckNames lists the --vuln-checks categories for help and completion.
+func vulnCheckNames() string {
+	names := make([]string, 0, len(vulnscanner.AllChecks))
+	for _, check := range vulnscanner.AllChecks {
+		names = append(names, string(check))
+	}
+	return strings.Join(names, ", ")
+}
+
+// checkOutputPaths verifies every location the scan will write to before any
+// request is sent, so a read-only or missing directory fails fast instead of
+// after a long scan.
+func checkOutputPaths() error {
+	if dryRun {
+		return nil
+	}
+
+	outputDir := viper.GetString("output.dir")
+	var dirs []string
+	if outputFile != "" || jsonOutput || jsonlOutput || xmlOutput || sarifOutput {
+		dirs = append(dirs, outputDir)
+	}
+	if trackHistory {
+		dirs = append(dirs, filepath.Join(outputDir, "history"))
+	}
+	for _, path := range []string{harFile, profileCPU, profileMem, viper.GetString("scan.progress_file")} {
+		if path != "" {
+			dirs = append(dirs, filepath.Dir(path))
+		}
+	}
+
+	for _, dir := range dirs {
+		if err := fileutil.EnsureWritableDir(dir); err != nil {
+			return err
+		}
 	}
+	return nil
 }
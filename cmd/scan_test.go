@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"subdomain-finder/internal/history"
+	"subdomain-finder/internal/types"
+)
+
+func TestScanSummaryDisappeared(t *testing.T) {
+	results := []types.Result{
+		{Subdomain: "www.example.com"},
+		{Subdomain: "old.example.com", Change: history.ChangeStale},
+	}
+	summary := scanSummary(results, time.Now(), time.Second, nil, []string{"legacy.example.com", "old.example.com"})
+
+	if want := []string{"legacy.example.com", "old.example.com"}; !reflect.DeepEqual(summary.Disappeared, want) {
+		t.Errorf("disappeared = %q, want %q", summary.Disappeared, want)
+	}
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"subdomain-finder/internal/ssl"
+
+	"github.com/spf13/cobra"
+)
+
+var sslCmd = &cobra.Command{
+	Use:   "ssl [flags] [host]",
+	Short: "Analyze the TLS certificate of one or more hosts",
+	Long: `Analyze the TLS certificate served by a host or IP address.
+The SNI value can be overridden or omitted, and several SNI values can be
+tested in one run to reveal SNI-based certificate selection. Many hosts can
+be read from a file with --input-list or from stdin with --stdin.
+
+Examples:
+  subdomain-finder ssl example.com
+  subdomain-finder ssl 203.0.113.10 --sni www.example.com
+  subdomain-finder ssl 203.0.113.10 --no-sni
+  subdomain-finder ssl mail.example.com --port 587
+  subdomain-finder ssl 203.0.113.10 --sni-test www.example.com --sni-test api.example.com
+  subdomain-finder ssl --input-list hosts.txt --threads 20 --rate-limit 10
+  cat hosts.txt | subdomain-finder ssl --stdin`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runSSL,
+}
+
+var (
+	sslPort    int
+	sslTimeout int
+	sslSNI     string
+	sslNoSNI   bool
+	sslSNITest []string
+	sslInput   string
+	sslStdin   bool
+	sslThreads int
+	sslRate    int
+	sslUpgrade string
+)
+
+func init() {
+	rootCmd.AddCommand(sslCmd)
+
+	sslCmd.Flags().IntVarP(&sslPort, "port", "p", 443, "TLS port to connect to")
+	sslCmd.Flags().IntVar(&sslTimeout, "timeout", 5, "Timeout in seconds for the TLS handshake")
+	sslCmd.Flags().StringVar(&sslSNI, "sni", "", "Server name (SNI) to send instead of the host")
+	sslCmd.Flags().BoolVar(&sslNoSNI, "no-sni", false, "Send no SNI to inspect the default certificate")
+	sslCmd.Flags().StringArrayVar(&sslSNITest, "sni-test", []string{}, "Additional SNI values to compare (repeatable)")
+	sslCmd.Flags().StringVarP(&sslInput, "input-list", "i", "", "File with hosts to analyze, one per line")
+	sslCmd.Flags().BoolVar(&sslStdin, "stdin", false, "Read hosts to analyze from stdin, one per line")
+	sslCmd.Flags().IntVarP(&sslThreads, "threads", "t", 10, "Number of hosts analyzed concurrently")
+	sslCmd.Flags().IntVarP(&sslRate, "rate-limit", "r", 0, "Hosts started per second (0 = no limit)")
+	sslCmd.Flags().StringVar(&sslUpgrade, "starttls", "", "STARTTLS protocol to speak before the handshake: smtp, imap, pop3 or none (default: picked from the port)")
+}
+
+func runSSL(cmd *cobra.Command, args []string) {
+	hosts, err := loadTargets(args, sslInput, sslStdin, os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	switch sslUpgrade {
+	case "", ssl.StartTLSSMTP, ssl.StartTLSIMAP, ssl.StartTLSPOP3, ssl.StartTLSNone:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid --starttls %q, use smtp, imap, pop3 or none\n", sslUpgrade)
+		os.Exit(exitError)
+	}
+
+	analyzer := ssl.NewSSLAnalyzer(time.Duration(sslTimeout) * time.Second)
+	analyzer.SetServerName(sslSNI)
+	analyzer.SetDisableSNI(sslNoSNI)
+	analyzer.SetStartTLS(sslUpgrade)
+	analyzer.SetDeepChecks(true)
+
+	// Each report is buffered so concurrent hosts do not interleave
+	var mu sync.Mutex
+	failed, printed := false, false
+	runTargets(hosts, sslThreads, sslRate, func(host string) {
+		var report bytes.Buffer
+		err := printSSLReport(&report, analyzer, host)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			failed = true
+			fmt.Fprintf(os.Stderr, "SSL analysis of %s failed: %v\n", host, err)
+			return
+		}
+		if printed {
+			fmt.Println()
+		}
+		printed = true
+		_, _ = report.WriteTo(os.Stdout)
+	})
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func printSSLReport(w io.Writer, analyzer *ssl.SSLAnalyzer, host string) error {
+	result, err := analyzer.Analyze(host, sslPort)
+	if err != nil {
+		return err
+	}
+
+	serverName := result.ServerName
+	if serverName == "" {
+		serverName = "(none)"
+	}
+
+	fmt.Fprintln(w, "SSL Analysis:")
+	fmt.Fprintln(w, "=============")
+	fmt.Fprintf(w, "Host: %s\n", net.JoinHostPort(result.Host, strconv.Itoa(result.Port)))
+	fmt.Fprintf(w, "SNI: %s\n", serverName)
+	fmt.Fprintf(w, "Subject: %s\n", result.Certificate.Subject)
+	fmt.Fprintf(w, "Issuer: %s\n", result.Certificate.Issuer)
+	fmt.Fprintf(w, "DNS Names: %v\n", result.Certificate.DNSNames)
+	fmt.Fprintf(w, "Expires: %s (%d days)\n", result.Certificate.NotAfter.Format("2006-01-02"), result.Certificate.DaysUntilExpiry)
+	fmt.Fprintf(w, "Revocation: %s\n", result.Certificate.RevocationStatus)
+	if result.Certificate.ChainValid {
+		fmt.Fprintln(w, "Chain: trusted")
+	} else {
+		fmt.Fprintf(w, "Chain: not trusted (%s)\n", result.Certificate.ChainError)
+	}
+	fmt.Fprintf(w, "Hostname Match: %s\n", yesNo(!result.Certificate.HostnameMismatch))
+	fmt.Fprintf(w, "Protocols: %s\n", strings.Join(result.SupportedProtocols, ", "))
+	fmt.Fprintln(w, "Cipher Suites:")
+	for _, cipher := range result.SupportedCiphers {
+		fmt.Fprintf(w, "  - %s\n", cipher)
+	}
+	fmt.Fprintf(w, "Session Resumption: session IDs %s, session tickets %s\n", yesNo(result.Resumption.SessionIDs), yesNo(result.Resumption.SessionTickets))
+	if result.Resumption.EarlyData {
+		fmt.Fprintf(w, "0-RTT: enabled (max early data %d bytes)\n", result.Resumption.MaxEarlyData)
+	} else {
+		fmt.Fprintln(w, "0-RTT: not offered")
+	}
+	fmt.Fprintf(w, "Grade: %s\n", result.Grade)
+	for _, recommendation := range result.Recommendations {
+		fmt.Fprintf(w, "  - %s\n", recommendation)
+	}
+
+	if len(sslSNITest) == 0 {
+		return nil
+	}
+
+	serverNames := append([]string{result.ServerName}, sslSNITest...)
+	sniResults := analyzer.TestSNI(host, sslPort, serverNames)
+
+	fmt.Fprintln(w)
+	writeSNIComparison(w, sniResults)
+	return nil
+}
+
+// writeSNIComparison lists the certificate served for each name, marking
+// those that differ from the first successful handshake, normally the one
+// with the primary server name.
+func writeSNIComparison(w io.Writer, sniResults []ssl.SNIResult) {
+	fmt.Fprintln(w, "SNI Comparison:")
+	fmt.Fprintln(w, "===============")
+
+	baseline := ""
+	for _, sniResult := range sniResults {
+		if sniResult.Error == "" {
+			baseline = sniResult.Fingerprint
+			break
+		}
+	}
+
+	for _, sniResult := range sniResults {
+		name := sniResult.ServerName
+		if name == "" {
+			name = "(none)"
+		}
+
+		if sniResult.Error != "" {
+			fmt.Fprintf(w, "%s -> error: %s\n", name, sniResult.Error)
+			continue
+		}
+
+		marker := ""
+		if sniResult.Fingerprint != baseline {
+			marker = " [different certificate]"
+		}
+		fmt.Fprintf(w, "%s -> %s (sha256 %s)%s\n", name, sniResult.Subject, sniResult.Fingerprint[:16], marker)
+	}
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
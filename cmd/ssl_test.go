@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"subdomain-finder/internal/ssl"
+)
+
+func TestWriteSNIComparison(t *testing.T) {
+	certA := strings.Repeat("a", 64)
+	certB := strings.Repeat("b", 64)
+
+	tests := []struct {
+		name      string
+		results   []ssl.SNIResult
+		different []string
+		same      []string
+	}{
+		{
+			name: "primary succeeds",
+			results: []ssl.SNIResult{
+				{ServerName: "www.example.com", Subject: "CN=www", Fingerprint: certA},
+				{ServerName: "api.example.com", Subject: "CN=www", Fingerprint: certA},
+				{ServerName: "other.example.com", Subject: "CN=other", Fingerprint: certB},
+			},
+			different: []string{"other.example.com"},
+			same:      []string{"www.example.com", "api.example.com"},
+		},
+		{
+			name: "primary handshake fails",
+			results: []ssl.SNIResult{
+				{ServerName: "www.example.com", Error: "handshake failure"},
+				{ServerName: "api.example.com", Subject: "CN=api", Fingerprint: certA},
+				{ServerName: "mail.example.com", Subject: "CN=api", Fingerprint: certA},
+				{ServerName: "other.example.com", Subject: "CN=other", Fingerprint: certB},
+			},
+			different: []string{"other.example.com"},
+			same:      []string{"api.example.com", "mail.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			writeSNIComparison(&out, tt.results)
+
+			lines := make(map[string]string)
+			for _, line := range strings.Split(out.String(), "\n") {
+				if name, _, ok := strings.Cut(line, " -> "); ok {
+					lines[name] = line
+				}
+			}
+			for _, name := range tt.different {
+				if !strings.HasSuffix(lines[name], "[different certificate]") {
+					t.Errorf("%s not marked different: %q", name, lines[name])
+				}
+			}
+			for _, name := range tt.same {
+				if strings.Contains(lines[name], "different") {
+					t.Errorf("%s marked different: %q", name, lines[name])
+				}
+			}
+		})
+	}
+}
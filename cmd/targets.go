@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"subdomain-finder/internal/limiter"
+)
+
+func readTargets(r io.Reader) ([]string, error) {
+	var targets []string
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		target := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if target == "" || strings.HasPrefix(target, "#") || seen[target] {
+			continue
+		}
+		seen[target] = true
+		targets = append(targets, target)
+	}
+
+	return targets, scanner.Err()
+}
+
+const maxTargetHosts = 4096
+
+// targetsName names the outputs and report of a --targets scan after the
+// ranges it covers, such as "10.0.0.0_24" or "10.0.0.0_24_and_2_more".
+func targetsName(specs []string) string {
+	var names []string
+	for _, spec := range specs {
+		if spec = strings.TrimSpace(spec); spec != "" {
+			names = append(names, strings.NewReplacer("/", "_", ":", "_").Replace(spec))
+		}
+	}
+	if len(names) == 0 {
+		return "targets"
+	}
+	if len(names) > 1 {
+		return fmt.Sprintf("%s_and_%d_more", names[0], len(names)-1)
+	}
+	return names[0]
+}
+
+// expandTargets turns IPv4 addresses and CIDR ranges into a deduplicated
+// host list. Network and broadcast addresses are skipped for ranges wider
+// than /31, and the total is capped at maxTargetHosts.
+func expandTargets(specs []string) ([]string, error) {
+	var hosts []string
+	seen := make(map[string]bool)
+
+	add := func(host string) error {
+		if seen[host] {
+			return nil
+		}
+		if len(hosts) >= maxTargetHosts {
+			return fmt.Errorf("targets expand to more than %d hosts; split the range into smaller scans", maxTargetHosts)
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+		return nil
+	}
+
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		if !strings.Contains(spec, "/") {
+			ip := net.ParseIP(spec)
+			if ip == nil || ip.To4() == nil {
+				return nil, fmt.Errorf("invalid target %q: expected an IPv4 address or CIDR range", spec)
+			}
+			if err := add(ip.To4().String()); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(spec)
+		if err != nil || network.IP.To4() == nil {
+			return nil, fmt.Errorf("invalid target %q: expected an IPv4 address or CIDR range", spec)
+		}
+
+		ones, bits := network.Mask.Size()
+		size := uint64(1) << uint(bits-ones)
+		if size > maxTargetHosts+2 {
+			return nil, fmt.Errorf("target range %s has %d addresses, more than the limit of %d", spec, size, maxTargetHosts)
+		}
+
+		base := binary.BigEndian.Uint32(network.IP.To4())
+		first, last := uint64(0), size-1
+		if ones < 31 {
+			first, last = 1, size-2
+		}
+		for offset := first; offset <= last; offset++ {
+			ip := make(net.IP, 4)
+			binary.BigEndian.PutUint32(ip, base+uint32(offset))
+			if err := add(ip.String()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return hosts, nil
+}
+
+// parseOwnedRanges parses IPv4 or IPv6 addresses and CIDR ranges. A bare
+// address is a single-host range.
+func parseOwnedRanges(specs []string) ([]netip.Prefix, error) {
+	var ranges []netip.Prefix
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		if !strings.Contains(spec, "/") {
+			addr, err := netip.ParseAddr(spec)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR range", spec)
+			}
+			addr = addr.Unmap()
+			ranges = append(ranges, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", spec)
+		}
+		ranges = append(ranges, prefix.Masked())
+	}
+	return ranges, nil
+}
+
+// loadTargets returns the hosts a command should process from exactly one
+// source: a single argument, an --input-list file, or stdin.
+func loadTargets(args []string, inputList string, useStdin bool, stdin io.Reader) ([]string, error) {
+	sources := len(args)
+	if inputList != "" {
+		sources++
+	}
+	if useStdin {
+		sources++
+	}
+	switch {
+	case sources == 0:
+		return nil, fmt.Errorf("a target argument, --input-list or --stdin is required")
+	case sources > 1:
+		return nil, fmt.Errorf("use only one of a target argument, --input-list or --stdin")
+	case len(args) == 1:
+		return []string{strings.ToLower(strings.TrimSpace(args[0]))}, nil
+	}
+
+	var targets []string
+	var err error
+	if useStdin {
+		targets, err = readTargets(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read targets from stdin: %w", err)
+		}
+		if len(targets) == 0 {
+			return nil, fmt.Errorf("no targets received on stdin")
+		}
+		return targets, nil
+	}
+
+	file, err := os.Open(inputList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input list: %w", err)
+	}
+	defer file.Close()
+
+	targets, err = readTargets(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", inputList, err)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%s does not contain any targets", inputList)
+	}
+	return targets, nil
+}
+
+// runTargets calls fn for every target from up to threads goroutines,
+// starting at most rate targets per second when rate is positive.
+func runTargets(targets []string, threads, rate int, fn func(target string)) {
+	if threads < 1 {
+		threads = 1
+	}
+	var rateLimiter *limiter.RateLimiter
+	if rate > 0 {
+		rateLimiter = limiter.NewRateLimiter(rate, time.Second)
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range jobs {
+				fn(target)
+			}
+		}()
+	}
+
+	for _, target := range targets {
+		if rateLimiter != nil {
+			_ = rateLimiter.Wait(context.Background())
+		}
+		jobs <- target
+	}
+	close(jobs)
+	wg.Wait()
+}
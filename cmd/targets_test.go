@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadTargets(t *testing.T) {
+	dir := t.TempDir()
+	list := "example.com\n\n# staging\n  Example.org  \nexample.com\n\t\nEXAMPLE.ORG\n#example.net\nexample.net\n"
+	listFile := filepath.Join(dir, "targets.txt")
+	emptyFile := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(listFile, []byte(list), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(emptyFile, []byte("# nothing yet\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		inputList string
+		useStdin  bool
+		stdin     string
+		want      []string
+		wantErr   string
+	}{
+		{
+			name: "argument",
+			args: []string{" Example.COM "},
+			want: []string{"example.com"},
+		},
+		{
+			name:      "file",
+			inputList: listFile,
+			want:      []string{"example.com", "example.org", "example.net"},
+		},
+		{
+			name:     "stdin",
+			useStdin: true,
+			stdin:    list,
+			want:     []string{"example.com", "example.org", "example.net"},
+		},
+		{
+			name:     "stdin without a trailing newline",
+			useStdin: true,
+			stdin:    "a.example.com\r\nb.example.com",
+			want:     []string{"a.example.com", "b.example.com"},
+		},
+		{
+			name:      "file with only comments and blanks",
+			inputList: emptyFile,
+			wantErr:   "does not contain any targets",
+		},
+		{
+			name:     "empty stdin",
+			useStdin: true,
+			stdin:    "\n# comment\n",
+			wantErr:  "no targets received on stdin",
+		},
+		{
+			name:      "missing file",
+			inputList: filepath.Join(dir, "missing.txt"),
+			wantErr:   "failed to open input list",
+		},
+		{
+			name:    "no source",
+			wantErr: "is required",
+		},
+		{
+			name:      "argument and file",
+			args:      []string{"example.com"},
+			inputList: listFile,
+			wantErr:   "use only one",
+		},
+		{
+			name:      "file and stdin",
+			inputList: listFile,
+			useStdin:  true,
+			wantErr:   "use only one",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadTargets(tt.args, tt.inputList, tt.useStdin, strings.NewReader(tt.stdin))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
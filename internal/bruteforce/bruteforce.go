@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
+
+	"subdomain-finder/internal/securitytxt"
 )
 
+const maxSecurityTxtSize = 32 * 1024
+
 type BruteforceConfig struct {
 	Threads     int
 	Timeout     time.Duration
@@ -27,6 +32,8 @@ type BruteforceResult struct {
 	Server        string
 	ResponseTime  time.Duration
 	Found         bool
+	Aliases       []string
+	SecurityTxt   *securitytxt.File
 }
 
 type DirectoryBruteforcer struct {
@@ -35,15 +42,27 @@ type DirectoryBruteforcer struct {
 }
 
 func NewDirectoryBruteforcer(config BruteforceConfig) *DirectoryBruteforcer {
+	return NewDirectoryBruteforcerWithClient(config, &http.Client{
+		Timeout: config.Timeout,
+	})
+}
+
+// NewDirectoryBruteforcerWithClient returns a bruteforcer that sends every
+// request through client. config.Timeout is ignored in favour of the
+// client's own.
+func NewDirectoryBruteforcerWithClient(config BruteforceConfig, client *http.Client) *DirectoryBruteforcer {
 	return &DirectoryBruteforcer{
 		config: config,
-		client: &http.Client{
-			Timeout: config.Timeout,
-		},
+		client: client,
 	}
 }
 
-func (db *DirectoryBruteforcer) Bruteforce(baseURL string, wordlist []string) map[string]*BruteforceResult {
+func (db *DirectoryBruteforcer) Bruteforce(baseURL string, wordlist []string) (map[string]*BruteforceResult, error) {
+	baseURL, err := NormalizeBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
 	results := make(map[string]*BruteforceResult)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -61,7 +80,7 @@ func (db *DirectoryBruteforcer) Bruteforce(baseURL string, wordlist []string) ma
 				result := db.checkURL(url)
 				if result.Found {
 					mu.Lock()
-					results[url] = result
+					db.mergeResult(results, url, result)
 					mu.Unlock()
 				}
 			}
@@ -69,7 +88,40 @@ func (db *DirectoryBruteforcer) Bruteforce(baseURL string, wordlist []string) ma
 	}
 
 	wg.Wait()
-	return results
+	return results, nil
+}
+
+// NormalizeBaseURL defaults a missing scheme to https, keeps any base path
+// and drops the trailing slash, query and fragment so words can be appended.
+func NormalizeBaseURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", fmt.Errorf("base URL is empty")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL %q: %w", rawURL, err)
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("invalid base URL %q: scheme must be http or https", rawURL)
+	}
+	if parsed.Hostname() == "" {
+		return "", fmt.Errorf("invalid base URL %q: missing host", rawURL)
+	}
+
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Path = strings.TrimRight(parsed.Path, "/")
+	parsed.RawPath = ""
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+
+	return parsed.String(), nil
 }
 
 func (db *DirectoryBruteforcer) generateURLs(baseURL, word string) []string {
@@ -124,6 +176,25 @@ func (db *DirectoryBruteforcer) checkURL(url string) *BruteforceResult {
 		return &BruteforceResult{URL: url, Found: false}
 	}
 
+	// security.txt is parsed in full so soft-404 pages are not reported
+	if strings.HasSuffix(resp.Request.URL.Path, "/security.txt") {
+		content, _ := io.ReadAll(io.LimitReader(resp.Body, maxSecurityTxtSize))
+		file, valid := securitytxt.Parse(string(content))
+		if !valid {
+			return &BruteforceResult{URL: url, Found: false}
+		}
+		return &BruteforceResult{
+			URL:           canonicalURL(resp.Request.URL.String()),
+			StatusCode:    resp.StatusCode,
+			ContentLength: resp.ContentLength,
+			Title:         "security.txt (Contact: " + strings.Join(file.Contacts, ", ") + ")",
+			Server:        resp.Header.Get("Server"),
+			ResponseTime:  responseTime,
+			Found:         true,
+			SecurityTxt:   file,
+		}
+	}
+
 	// Read limited content for title extraction
 	body := make([]byte, 1024)
 	n, _ := io.ReadFull(resp.Body, body)
@@ -132,8 +203,9 @@ func (db *DirectoryBruteforcer) checkURL(url string) *BruteforceResult {
 	title := db.extractTitle(content)
 	server := resp.Header.Get("Server")
 
+	// Redirects are followed, so /word and /word/ land on the same final URL
 	return &BruteforceResult{
-		URL:           url,
+		URL:           canonicalURL(resp.Request.URL.String()),
 		StatusCode:    resp.StatusCode,
 		ContentLength: resp.ContentLength,
 		Title:         title,
@@ -143,6 +215,65 @@ func (db *DirectoryBruteforcer) checkURL(url string) *BruteforceResult {
 	}
 }
 
+// mergeResult files result under its canonical URL. A redirect between
+// /word and /word/ on the same host merges both requests into one entry; a
+// redirect anywhere else, such as a login page or another host, is reported
+// under the URL that was requested so distinct paths are not merged.
+func (db *DirectoryBruteforcer) mergeResult(results map[string]*BruteforceResult, requestedURL string, result *BruteforceResult) {
+	requested := canonicalURL(requestedURL)
+	if !sameResource(requested, result.URL) {
+		result.URL = requested
+	} else if requested != result.URL {
+		result.Aliases = append(result.Aliases, requestedURL)
+	}
+
+	existing, exists := results[result.URL]
+	if !exists {
+		results[result.URL] = result
+		return
+	}
+
+	for _, alias := range result.Aliases {
+		if !containsString(existing.Aliases, alias) {
+			existing.Aliases = append(existing.Aliases, alias)
+		}
+	}
+}
+
+// sameResource reports whether two canonical URLs differ at most by a
+// trailing slash.
+func sameResource(a, b string) bool {
+	return a == b || strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+func canonicalURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	if (parsed.Scheme == "http" && parsed.Port() == "80") || (parsed.Scheme == "https" && parsed.Port() == "443") {
+		parsed.Host = parsed.Hostname()
+	}
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+	parsed.Fragment = ""
+
+	return parsed.String()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func (db *DirectoryBruteforcer) extractTitle(content string) string {
 	start := strings.Index(strings.ToLower(content), "<title>")
 	if start == -1 {
@@ -172,17 +303,20 @@ func (db *DirectoryBruteforcer) extractTitle(content string) string {
 	return title
 }
 
-func (db *DirectoryBruteforcer) BruteforceWithContext(ctx context.Context, baseURL string, wordlist []string) map[string]*BruteforceResult {
+func (db *DirectoryBruteforcer) BruteforceWithContext(ctx context.Context, baseURL string, wordlist []string) (map[string]*BruteforceResult, error) {
+	baseURL, err := NormalizeBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
 	results := make(map[string]*BruteforceResult)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, db.config.Threads)
 
 	for _, word := range wordlist {
-		select {
-		case <-ctx.Done():
-			return results
-		default:
+		if ctx.Err() != nil {
+			break
 		}
 
 		wg.Add(1)
@@ -202,7 +336,7 @@ func (db *DirectoryBruteforcer) BruteforceWithContext(ctx context.Context, baseU
 				result := db.checkURL(url)
 				if result.Found {
 					mu.Lock()
-					results[url] = result
+					db.mergeResult(results, url, result)
 					mu.Unlock()
 				}
 			}
@@ -210,10 +344,10 @@ func (db *DirectoryBruteforcer) BruteforceWithContext(ctx context.Context, baseU
 	}
 
 	wg.Wait()
-	return results
+	return results, ctx.Err()
 }
 
-func (db *DirectoryBruteforcer) BruteforceCommon(baseURL string) map[string]*BruteforceResult {
+func (db *DirectoryBruteforcer) BruteforceCommon(baseURL string) (map[string]*BruteforceResult, error) {
 	commonPaths := []string{
 		"admin", "administrator", "login", "wp-admin", "wp-login", "dashboard",
 		"panel", "control", "manage", "manager", "admin.php", "login.php",
@@ -227,7 +361,7 @@ func (db *DirectoryBruteforcer) BruteforceCommon(baseURL string) map[string]*Bru
 		"docs", "documentation", "wiki", "help", "faq", "support",
 		"status", "health", "ping", "monitor", "metrics", "stats",
 		"logs", "log", "debug", "trace", "error", "errors",
-		"robots.txt", "sitemap.xml", "crossdomain.xml", "security.txt",
+		"robots.txt", "sitemap.xml", "crossdomain.xml", "security.txt", ".well-known/security.txt",
 		".env", ".git", ".svn", ".hg", ".bzr", ".cvs",
 		"phpinfo.php", "info.php", "test.php", "debug.php",
 		"readme.txt", "readme.md", "changelog.txt", "license.txt",
@@ -254,7 +388,7 @@ func (db *DirectoryBruteforcer) BruteforceCommon(baseURL string) map[string]*Bru
 	return db.Bruteforce(baseURL, commonPaths)
 }
 
-func (db *DirectoryBruteforcer) BruteforceWithExtensions(baseURL string, wordlist []string, extensions []string) map[string]*BruteforceResult {
+func (db *DirectoryBruteforcer) BruteforceWithExtensions(baseURL string, wordlist []string, extensions []string) (map[string]*BruteforceResult, error) {
 	originalExtensions := db.config.Extensions
 	db.config.Extensions = extensions
 	defer func() { db.config.Extensions = originalExtensions }()
@@ -262,7 +396,7 @@ func (db *DirectoryBruteforcer) BruteforceWithExtensions(baseURL string, wordlis
 	return db.Bruteforce(baseURL, wordlist)
 }
 
-func (db *DirectoryBruteforcer) BruteforceWithStatusCodes(baseURL string, wordlist []string, statusCodes []int) map[string]*BruteforceResult {
+func (db *DirectoryBruteforcer) BruteforceWithStatusCodes(baseURL string, wordlist []string, statusCodes []int) (map[string]*BruteforceResult, error) {
 	originalStatusCodes := db.config.StatusCodes
 	db.config.StatusCodes = statusCodes
 	defer func() { db.config.StatusCodes = originalStatusCodes }()
@@ -0,0 +1,163 @@
+package bruteforce
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBruteforceMergesTrailingSlashRedirects(t *testing.T) {
+	sso := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<title>Sign in</title>"))
+	}))
+	defer sso.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<title>Admin</title>"))
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<title>Login</title>"))
+	})
+	mux.HandleFunc("/panel", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/login", http.StatusFound)
+	})
+	mux.HandleFunc("/manage", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/login", http.StatusFound)
+	})
+	mux.HandleFunc("/account", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, sso.URL+"/", http.StatusFound)
+	})
+	mux.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, sso.URL+"/", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	bruteforcer := NewDirectoryBruteforcer(BruteforceConfig{
+		Threads:     4,
+		Timeout:     5 * time.Second,
+		StatusCodes: []int{http.StatusOK},
+	})
+	results, err := bruteforcer.Bruteforce(server.URL, []string{"admin", "panel", "manage", "account", "profile", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		url     string
+		title   string
+		aliases []string
+	}{
+		// /admin redirects to /admin/, so both requests are one entry
+		{url: server.URL + "/admin/", title: "Admin", aliases: []string{server.URL + "/admin"}},
+		// Paths redirecting to one landing page stay separate
+		{url: server.URL + "/panel", title: "Login"},
+		{url: server.URL + "/manage", title: "Login"},
+		// So do paths redirecting to another host
+		{url: server.URL + "/account", title: "Sign in"},
+		{url: server.URL + "/profile", title: "Sign in"},
+	}
+
+	var got []string
+	for url := range results {
+		got = append(got, url)
+	}
+	sort.Strings(got)
+	if len(results) != len(tests) {
+		t.Fatalf("got results %q, want %d", got, len(tests))
+	}
+	for _, tt := range tests {
+		result, ok := results[tt.url]
+		if !ok {
+			t.Errorf("no result for %s in %q", tt.url, got)
+			continue
+		}
+		if result.URL != tt.url {
+			t.Errorf("%s: URL = %s", tt.url, result.URL)
+		}
+		if result.Title != tt.title {
+			t.Errorf("%s: title = %q, want %q", tt.url, result.Title, tt.title)
+		}
+		if !reflect.DeepEqual(result.Aliases, tt.aliases) {
+			t.Errorf("%s: aliases = %q, want %q", tt.url, result.Aliases, tt.aliases)
+		}
+	}
+}
+
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "example.com", want: "https://example.com"},
+		{in: " Example.COM/ ", want: "https://example.com"},
+		{in: "example.com:8443/app/", want: "https://example.com:8443/app"},
+		{in: "http://example.com/app/v2/?q=1#top", want: "http://example.com/app/v2"},
+		{in: "HTTPS://example.com/App", want: "https://example.com/App"},
+		{in: "", wantErr: true},
+		{in: "ftp://example.com", wantErr: true},
+		{in: "https://", wantErr: true},
+		{in: "http://exa mple.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := NormalizeBaseURL(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBruteforceKeepsBasePath(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		if r.URL.Path == "/app/login" {
+			w.Write([]byte("<title>Login</title>"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	bruteforcer := NewDirectoryBruteforcerWithClient(BruteforceConfig{
+		Threads:     1,
+		StatusCodes: []int{http.StatusOK},
+	}, server.Client())
+	// A base without a scheme defaults to https
+	base := strings.TrimPrefix(server.URL, "https://") + "/app/"
+	results, err := bruteforcer.BruteforceWithContext(context.Background(), base, []string{"login"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := results[server.URL+"/app/login"]; !ok || len(results) != 1 {
+		t.Errorf("got results %v, want only %s/app/login", results, server.URL)
+	}
+
+	sort.Strings(paths)
+	if want := []string{"/app/login", "/app/login/"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("requested %q, want %q", paths, want)
+	}
+}
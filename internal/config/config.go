@@ -37,6 +37,9 @@ type HTTPConfig struct {
 	Retries         int               `yaml:"retries"`
 	RateLimit       int               `yaml:"rate_limit"`
 	FollowRedirects bool              `yaml:"follow_redirects"`
+	TLSMinVersion   string            `yaml:"tls_min_version"`
+	TLSMaxVersion   string            `yaml:"tls_max_version"`
+	CABundle        string            `yaml:"ca_bundle"`
 }
 
 type OutputConfig struct {
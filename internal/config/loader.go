@@ -71,6 +71,15 @@ func (l *Loader) LoadFromViper() (*AppConfig, error) {
 	if viper.IsSet("http.follow_redirects") {
 		config.HTTP.FollowRedirects = viper.GetBool("http.follow_redirects")
 	}
+	if viper.IsSet("http.tls_min_version") {
+		config.HTTP.TLSMinVersion = viper.GetString("http.tls_min_version")
+	}
+	if viper.IsSet("http.tls_max_version") {
+		config.HTTP.TLSMaxVersion = viper.GetString("http.tls_max_version")
+	}
+	if viper.IsSet("http.ca_bundle") {
+		config.HTTP.CABundle = viper.GetString("http.ca_bundle")
+	}
 
 	if viper.IsSet("output.format") {
 		config.Output.Format = viper.GetString("output.format")
@@ -0,0 +1,185 @@
+// Package diff compares the results of two scans of the same domain.
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"subdomain-finder/internal/types"
+)
+
+// Report lists what changed between an old and a new scan. Subdomains are
+// matched by name, ignoring case.
+type Report struct {
+	Added   []types.Result `json:"added"`
+	Removed []types.Result `json:"removed"`
+	Changed []Change       `json:"changed"`
+}
+
+// Change is one subdomain found by both scans whose observed state differs.
+type Change struct {
+	Subdomain            string                `json:"subdomain"`
+	Fields               []FieldChange         `json:"fields,omitempty"`
+	OpenedPorts          []types.PortInfo      `json:"opened_ports,omitempty"`
+	ClosedPorts          []types.PortInfo      `json:"closed_ports,omitempty"`
+	AddedTechnologies    []types.Technology    `json:"added_technologies,omitempty"`
+	RemovedTechnologies  []types.Technology    `json:"removed_technologies,omitempty"`
+	NewVulnerabilities   []types.Vulnerability `json:"new_vulnerabilities,omitempty"`
+	FixedVulnerabilities []types.Vulnerability `json:"fixed_vulnerabilities,omitempty"`
+}
+
+// FieldChange is a single-valued field, such as the IP or SSL grade, that
+// has a different value in the new scan.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Empty reports whether the scans found the same subdomains in the same
+// state.
+func (r Report) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// Compare returns the subdomains only the new scan found, those only the
+// old scan found, and those whose state changed, each sorted by name.
+func Compare(old, current []types.Result) Report {
+	before := index(old)
+	after := index(current)
+
+	report := Report{
+		Added:   make([]types.Result, 0),
+		Removed: make([]types.Result, 0),
+		Changed: make([]Change, 0),
+	}
+	for key, result := range after {
+		previous, ok := before[key]
+		if !ok {
+			report.Added = append(report.Added, result)
+			continue
+		}
+		if change, changed := CompareResult(previous, result); changed {
+			report.Changed = append(report.Changed, change)
+		}
+	}
+	for key, result := range before {
+		if _, ok := after[key]; !ok {
+			report.Removed = append(report.Removed, result)
+		}
+	}
+
+	sort.Slice(report.Added, func(i, j int) bool { return report.Added[i].Subdomain < report.Added[j].Subdomain })
+	sort.Slice(report.Removed, func(i, j int) bool { return report.Removed[i].Subdomain < report.Removed[j].Subdomain })
+	sort.Slice(report.Changed, func(i, j int) bool { return report.Changed[i].Subdomain < report.Changed[j].Subdomain })
+	return report
+}
+
+// index keys results by lowercase name. A name listed twice keeps its last
+// result.
+func index(results []types.Result) map[string]types.Result {
+	byName := make(map[string]types.Result, len(results))
+	for _, result := range results {
+		if result.Subdomain != "" {
+			byName[strings.ToLower(result.Subdomain)] = result
+		}
+	}
+	return byName
+}
+
+// CompareResult compares two results of the same subdomain. Timings and
+// metadata are ignored. Ports are matched by number and protocol,
+// technologies by name, ignoring case, and version, and vulnerabilities by
+// name, severity and evidence. scan --merge uses the same comparison.
+func CompareResult(old, current types.Result) (Change, bool) {
+	change := Change{Subdomain: current.Subdomain}
+
+	field := func(name, a, b string) {
+		if a != b {
+			change.Fields = append(change.Fields, FieldChange{Field: name, Old: a, New: b})
+		}
+	}
+	field("ip", old.IP, current.IP)
+	field("status", old.Status, current.Status)
+	field("title", old.Title, current.Title)
+	field("server", old.Server, current.Server)
+	field("risk_level", old.RiskLevel, current.RiskLevel)
+	field("parked", strconv.FormatBool(old.Parked), strconv.FormatBool(current.Parked))
+	field("ssl_grade", sslGrade(old.SSL), sslGrade(current.SSL))
+	field("ssl_certificate", sslCertificate(old.SSL), sslCertificate(current.SSL))
+
+	change.OpenedPorts = missing(current.Ports, old.Ports, portKey)
+	change.ClosedPorts = missing(old.Ports, current.Ports, portKey)
+	change.AddedTechnologies = missing(current.Technologies, old.Technologies, techKey)
+	change.RemovedTechnologies = missing(old.Technologies, current.Technologies, techKey)
+	change.NewVulnerabilities = missing(current.Vulnerabilities, old.Vulnerabilities, vulnKey)
+	change.FixedVulnerabilities = missing(old.Vulnerabilities, current.Vulnerabilities, vulnKey)
+
+	return change, len(change.FieldNames()) > 0
+}
+
+// FieldNames lists what changed: the single-valued fields, then "ports",
+// "technologies" and "vulnerabilities" when any were added or removed.
+func (c Change) FieldNames() []string {
+	var names []string
+	for _, field := range c.Fields {
+		names = append(names, field.Field)
+	}
+	if len(c.OpenedPorts) > 0 || len(c.ClosedPorts) > 0 {
+		names = append(names, "ports")
+	}
+	if len(c.AddedTechnologies) > 0 || len(c.RemovedTechnologies) > 0 {
+		names = append(names, "technologies")
+	}
+	if len(c.NewVulnerabilities) > 0 || len(c.FixedVulnerabilities) > 0 {
+		names = append(names, "vulnerabilities")
+	}
+	return names
+}
+
+func portKey(port types.PortInfo) string {
+	return fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+}
+
+func techKey(tech types.Technology) string {
+	return strings.ToLower(tech.Name) + " " + tech.Version
+}
+
+func vulnKey(vuln types.Vulnerability) string {
+	return vuln.Name + "\x00" + vuln.Severity + "\x00" + vuln.Evidence
+}
+
+// missing returns the values of from whose key is not in other, in their
+// original order and without duplicates.
+func missing[T any](from, other []T, key func(T) string) []T {
+	present := make(map[string]bool, len(other))
+	for _, value := range other {
+		present[key(value)] = true
+	}
+	var values []T
+	for _, value := range from {
+		k := key(value)
+		if !present[k] {
+			present[k] = true
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func sslGrade(ssl *types.SSLInfo) string {
+	if ssl == nil {
+		return ""
+	}
+	return ssl.Grade
+}
+
+func sslCertificate(ssl *types.SSLInfo) string {
+	if ssl == nil {
+		return ""
+	}
+	return ssl.SerialNumber + " " + ssl.NotAfter.UTC().Format(time.RFC3339)
+}
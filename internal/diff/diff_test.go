@@ -0,0 +1,189 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"subdomain-finder/internal/types"
+)
+
+func TestCompareResult(t *testing.T) {
+	base := types.Result{
+		Subdomain:       "www.example.com",
+		IP:              "192.0.2.1",
+		Ports:           []types.PortInfo{{Port: 80, Protocol: "tcp"}, {Port: 443, Protocol: "tcp"}},
+		Technologies:    []types.Technology{{Name: "nginx", Version: "1.18"}},
+		Vulnerabilities: []types.Vulnerability{{Name: "Missing HSTS", Severity: "low", Evidence: "no header"}},
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(r *types.Result)
+		fields  []string
+		opened  []int
+		closed  []int
+		added   []string
+		removed []string
+		newV    []string
+		fixedV  []string
+	}{
+		{
+			name: "unchanged",
+			edit: func(r *types.Result) {},
+		},
+		{
+			name: "port order ignored",
+			edit: func(r *types.Result) {
+				r.Ports = []types.PortInfo{{Port: 443, Protocol: "tcp"}, {Port: 80, Protocol: "tcp"}}
+			},
+		},
+		{
+			name: "port opened and closed",
+			edit: func(r *types.Result) {
+				r.Ports = []types.PortInfo{{Port: 80, Protocol: "tcp"}, {Port: 8080, Protocol: "tcp"}}
+			},
+			fields: []string{"ports"},
+			opened: []int{8080},
+			closed: []int{443},
+		},
+		{
+			name: "same port number on another protocol",
+			edit: func(r *types.Result) {
+				r.Ports = append(r.Ports, types.PortInfo{Port: 443, Protocol: "udp"})
+			},
+			fields: []string{"ports"},
+			opened: []int{443},
+		},
+		{
+			name: "technology case ignored",
+			edit: func(r *types.Result) {
+				r.Technologies = []types.Technology{{Name: "Nginx", Version: "1.18"}}
+			},
+		},
+		{
+			name: "technology version change",
+			edit: func(r *types.Result) {
+				r.Technologies = []types.Technology{{Name: "nginx", Version: "1.20"}, {Name: "PHP"}}
+			},
+			fields:  []string{"technologies"},
+			added:   []string{"nginx", "PHP"},
+			removed: []string{"nginx"},
+		},
+		{
+			name: "duplicate technologies listed once",
+			edit: func(r *types.Result) {
+				r.Technologies = append(r.Technologies, types.Technology{Name: "PHP"}, types.Technology{Name: "php"})
+			},
+			fields: []string{"technologies"},
+			added:  []string{"PHP"},
+		},
+		{
+			name: "vulnerability severity change",
+			edit: func(r *types.Result) {
+				r.Vulnerabilities = []types.Vulnerability{{Name: "Missing HSTS", Severity: "medium", Evidence: "no header"}}
+			},
+			fields: []string{"vulnerabilities"},
+			newV:   []string{"Missing HSTS"},
+			fixedV: []string{"Missing HSTS"},
+		},
+		{
+			name: "vulnerability evidence change",
+			edit: func(r *types.Result) {
+				r.Vulnerabilities = []types.Vulnerability{{Name: "Missing HSTS", Severity: "low", Evidence: "max-age=0"}}
+			},
+			fields: []string{"vulnerabilities"},
+			newV:   []string{"Missing HSTS"},
+			fixedV: []string{"Missing HSTS"},
+		},
+		{
+			name: "vulnerability fixed",
+			edit: func(r *types.Result) {
+				r.Vulnerabilities = nil
+			},
+			fields: []string{"vulnerabilities"},
+			fixedV: []string{"Missing HSTS"},
+		},
+		{
+			name: "scalar fields",
+			edit: func(r *types.Result) {
+				r.IP = "192.0.2.2"
+				r.Parked = true
+			},
+			fields: []string{"ip", "parked"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := base
+			current.Ports = append([]types.PortInfo(nil), base.Ports...)
+			current.Technologies = append([]types.Technology(nil), base.Technologies...)
+			current.Vulnerabilities = append([]types.Vulnerability(nil), base.Vulnerabilities...)
+			tt.edit(&current)
+
+			change, changed := CompareResult(base, current)
+			if changed != (len(tt.fields) > 0) {
+				t.Errorf("changed = %v, want %v", changed, len(tt.fields) > 0)
+			}
+			check := func(what string, got, want []string) {
+				if len(got) == 0 && len(want) == 0 {
+					return
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s = %v, want %v", what, got, want)
+				}
+			}
+			check("fields", change.FieldNames(), tt.fields)
+			check("added technologies", techNames(change.AddedTechnologies), tt.added)
+			check("removed technologies", techNames(change.RemovedTechnologies), tt.removed)
+			check("new vulnerabilities", vulnNames(change.NewVulnerabilities), tt.newV)
+			check("fixed vulnerabilities", vulnNames(change.FixedVulnerabilities), tt.fixedV)
+			if got := portNumbers(change.OpenedPorts); !reflect.DeepEqual(got, tt.opened) {
+				t.Errorf("opened ports = %v, want %v", got, tt.opened)
+			}
+			if got := portNumbers(change.ClosedPorts); !reflect.DeepEqual(got, tt.closed) {
+				t.Errorf("closed ports = %v, want %v", got, tt.closed)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	old := []types.Result{{Subdomain: "a.example.com"}, {Subdomain: "B.example.com", IP: "192.0.2.1"}}
+	current := []types.Result{{Subdomain: "b.example.com", IP: "192.0.2.2"}, {Subdomain: "c.example.com"}}
+
+	report := Compare(old, current)
+	if len(report.Added) != 1 || report.Added[0].Subdomain != "c.example.com" {
+		t.Errorf("added = %v", report.Added)
+	}
+	if len(report.Removed) != 1 || report.Removed[0].Subdomain != "a.example.com" {
+		t.Errorf("removed = %v", report.Removed)
+	}
+	if len(report.Changed) != 1 || !reflect.DeepEqual(report.Changed[0].FieldNames(), []string{"ip"}) {
+		t.Errorf("changed = %v", report.Changed)
+	}
+}
+
+func techNames(techs []types.Technology) []string {
+	var names []string
+	for _, tech := range techs {
+		names = append(names, tech.Name)
+	}
+	return names
+}
+
+func vulnNames(vulns []types.Vulnerability) []string {
+	var names []string
+	for _, vuln := range vulns {
+		names = append(names, vuln.Name)
+	}
+	return names
+}
+
+func portNumbers(ports []types.PortInfo) []int {
+	var numbers []int
+	for _, port := range ports {
+		numbers = append(numbers, port.Port)
+	}
+	return numbers
+}
@@ -0,0 +1,75 @@
+package dns
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// AttemptAXFR asks each of domain's nameservers for a zone transfer and
+// returns the sorted names found in every zone that was handed over. Most
+// servers refuse, in which case the error says so and no names are
+// returned.
+func (r *Resolver) AttemptAXFR(domain string) ([]string, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	nameservers, err := r.ResolveNS(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool)
+	transferred := false
+	for _, nameserver := range nameservers {
+		names, err := r.transfer(domain, nameserver)
+		if err != nil {
+			continue
+		}
+		transferred = true
+		for _, name := range names {
+			found[name] = true
+		}
+	}
+	if !transferred {
+		return nil, fmt.Errorf("zone transfer refused by all %d nameservers of %s", len(nameservers), domain)
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (r *Resolver) transfer(domain, nameserver string) ([]string, error) {
+	msg := new(dns.Msg)
+	msg.SetAxfr(dns.Fqdn(domain))
+
+	transfer := &dns.Transfer{
+		DialTimeout:  r.timeout,
+		ReadTimeout:  r.timeout,
+		WriteTimeout: r.timeout,
+	}
+	envelopes, err := transfer.In(msg, net.JoinHostPort(nameserver, "53"))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, envelope.Error
+		}
+		for _, rr := range envelope.RR {
+			name := strings.ToLower(strings.TrimSuffix(rr.Header().Name, "."))
+			if strings.HasSuffix(name, "."+domain) {
+				names = append(names, strings.TrimPrefix(name, "*."))
+			}
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,133 @@
+package dns
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+type RecordType string
+
+const (
+	RecordA     RecordType = "a"
+	RecordAAAA  RecordType = "aaaa"
+	RecordCNAME RecordType = "cname"
+)
+
+// DefaultMatchTypes make a name exist when it has an address or is an
+// alias, so CNAME-only names are reported too.
+var DefaultMatchTypes = []RecordType{RecordA, RecordAAAA, RecordCNAME}
+
+func (t RecordType) qtype() uint16 {
+	switch t {
+	case RecordAAAA:
+		return dns.TypeAAAA
+	case RecordCNAME:
+		return dns.TypeCNAME
+	default:
+		return dns.TypeA
+	}
+}
+
+// ParseRecordTypes parses a comma-separated list such as "a,aaaa,cname".
+func ParseRecordTypes(spec string) ([]RecordType, error) {
+	var types []RecordType
+	for _, part := range strings.Split(spec, ",") {
+		recordType := RecordType(strings.ToLower(strings.TrimSpace(part)))
+		switch recordType {
+		case "":
+			continue
+		case RecordA, RecordAAAA, RecordCNAME:
+			if !containsType(types, recordType) {
+				types = append(types, recordType)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported record type %q (use a, aaaa or cname)", part)
+		}
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("no record types given")
+	}
+	return types, nil
+}
+
+// Records holds the answers for one name by type. CNAME targets have their
+// trailing dot removed.
+type Records struct {
+	A     []string
+	AAAA  []string
+	CNAME []string
+}
+
+func (r *Records) Get(t RecordType) []string {
+	switch t {
+	case RecordA:
+		return r.A
+	case RecordAAAA:
+		return r.AAAA
+	case RecordCNAME:
+		return r.CNAME
+	}
+	return nil
+}
+
+func (r *Records) Empty() bool {
+	return len(r.A) == 0 && len(r.AAAA) == 0 && len(r.CNAME) == 0
+}
+
+func (r *Records) merge(other *Records) {
+	r.A = appendUnique(r.A, other.A...)
+	r.AAAA = appendUnique(r.AAAA, other.AAAA...)
+	r.CNAME = appendUnique(r.CNAME, other.CNAME...)
+}
+
+func collectRecords(response *dns.Msg) *Records {
+	records := &Records{}
+	for _, answer := range response.Answer {
+		switch record := answer.(type) {
+		case *dns.A:
+			records.A = appendUnique(records.A, record.A.String())
+		case *dns.AAAA:
+			records.AAAA = appendUnique(records.AAAA, record.AAAA.String())
+		case *dns.CNAME:
+			records.CNAME = appendUnique(records.CNAME, strings.TrimSuffix(record.Target, "."))
+		}
+	}
+	return records
+}
+
+func appendUnique(values []string, added ...string) []string {
+	for _, value := range added {
+		if !containsString(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsType(types []RecordType, t RecordType) bool {
+	for _, v := range types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+func joinTypes(types []RecordType) string {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, strings.ToUpper(string(t)))
+	}
+	return strings.Join(names, "/")
+}
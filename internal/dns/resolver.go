@@ -3,86 +3,267 @@ package dns
 import (
 	"context"
 	"fmt"
-	"net"
+	"strings"
 	"time"
 
+	"subdomain-finder/internal/limiter"
+
 	"github.com/miekg/dns"
 )
 
+// DefaultServers are the recursive resolvers queried, in order, unless
+// SetServers replaces them.
+var DefaultServers = []string{"8.8.8.8:53", "1.1.1.1:53", "8.8.4.4:53"}
+
 type Resolver struct {
 	timeout time.Duration
 	client  *dns.Client
+	servers []string
+	match   []RecordType
+	retryer *limiter.Retryer
 }
 
 func NewResolver(timeoutSeconds int) *Resolver {
+	return NewResolverWithRetry(timeoutSeconds, limiter.RetryConfig{})
+}
+
+// NewResolverWithRetry returns a resolver that repeats a query across the
+// whole server list up to retry.MaxRetries more times when no server
+// answers, as happens on timeouts and SERVFAIL. NXDOMAIN and empty answers
+// are final.
+func NewResolverWithRetry(timeoutSeconds int, retry limiter.RetryConfig) *Resolver {
 	timeout := time.Duration(timeoutSeconds) * time.Second
 	client := &dns.Client{
 		Timeout: timeout,
 	}
+	retry.MaxRetries = max(retry.MaxRetries, 0)
+	if retry.Backoff == nil {
+		retry.Backoff = &limiter.ExponentialBackoff{BaseDelay: 250 * time.Millisecond, MaxDelay: 2 * time.Second}
+	}
 
 	return &Resolver{
 		timeout: timeout,
 		client:  client,
+		servers: DefaultServers,
+		match:   DefaultMatchTypes,
+		retryer: limiter.NewRetryer(retry),
+	}
+}
+
+// SetServers sets the resolvers queried, as host:port, in the order they
+// are tried. An empty list restores DefaultServers.
+func (r *Resolver) SetServers(servers []string) {
+	if len(servers) == 0 {
+		servers = DefaultServers
+	}
+	r.servers = servers
+}
+
+// SetMatchTypes sets which record types make a name exist for Resolve.
+// An empty list restores DefaultMatchTypes.
+func (r *Resolver) SetMatchTypes(types []RecordType) {
+	if len(types) == 0 {
+		types = DefaultMatchTypes
 	}
+	r.match = types
 }
 
+// Resolve returns the first address found for the configured match types.
+// A name that only matches as a CNAME returns its target's address, or ""
+// with no error when the target has none.
 func (r *Resolver) Resolve(domain string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
-	defer cancel()
+	return r.ResolveContext(context.Background(), domain)
+}
 
-	done := make(chan string, 1)
-	errChan := make(chan error, 1)
+// ResolveContext is Resolve that also gives up, along with any pending
+// query or retry, when ctx is done. There is no overall deadline: every
+// query is bounded by the timeout per server and the retries, so a name is
+// only reported missing once all of them have failed.
+func (r *Resolver) ResolveContext(ctx context.Context, domain string) (string, error) {
+	address, _, err := r.resolveWithContext(ctx, domain, false)
+	return address, err
+}
+
+// ResolveRecordsContext is ResolveContext that also returns the A, AAAA and
+// CNAME records its queries found. The AAAA records of a name that exists
+// are always included, looked up after the match if it did not need them,
+// so callers listing IPv6 addresses need no query of their own.
+func (r *Resolver) ResolveRecordsContext(ctx context.Context, domain string) (string, *Records, error) {
+	return r.resolveWithContext(ctx, domain, true)
+}
+
+func (r *Resolver) resolveWithContext(ctx context.Context, domain string, withAAAA bool) (string, *Records, error) {
+	type answer struct {
+		address string
+		records *Records
+		err     error
+	}
+	done := make(chan answer, 1)
 
 	go func() {
-		ip, err := r.resolveA(domain)
-		if err != nil {
-			errChan <- err
-			return
-		}
-		done <- ip
+		address, records, err := r.resolveMatch(ctx, domain, withAAAA)
+		done <- answer{address, records, err}
 	}()
 
 	select {
-	case ip := <-done:
-		return ip, nil
-	case err := <-errChan:
-		return "", err
+	case a := <-done:
+		return a.address, a.records, a.err
 	case <-ctx.Done():
-		return "", ctx.Err()
+		return "", nil, ctx.Err()
 	}
 }
 
-func (r *Resolver) resolveA(domain string) (string, error) {
+func (r *Resolver) resolveMatch(ctx context.Context, domain string, withAAAA bool) (string, *Records, error) {
+	found := &Records{}
+	queriedAAAA := false
+	for _, recordType := range r.match {
+		response, err := r.exchange(ctx, domain, recordType.qtype())
+		if err != nil {
+			continue
+		}
+		queriedAAAA = queriedAAAA || recordType == RecordAAAA
+		records := collectRecords(response)
+		found.merge(records)
+
+		address, matched := "", false
+		for _, candidate := range []RecordType{RecordA, RecordAAAA} {
+			if values := records.Get(candidate); len(values) > 0 && containsType(r.match, candidate) {
+				address, matched = values[0], true
+				break
+			}
+		}
+		if !matched && len(records.CNAME) > 0 && containsType(r.match, RecordCNAME) {
+			address, matched = r.targetAddress(ctx, records.CNAME[len(records.CNAME)-1]), true
+		}
+		if matched {
+			if withAAAA && !queriedAAAA {
+				if response, err := r.exchange(ctx, domain, dns.TypeAAAA); err == nil {
+					found.merge(collectRecords(response))
+				}
+			}
+			return address, found, nil
+		}
+
+		// NXDOMAIN holds for every record type, so stop querying
+		if response.Rcode == dns.RcodeNameError {
+			break
+		}
+	}
+
+	return "", nil, fmt.Errorf("no %s record found for %s", joinTypes(r.match), domain)
+}
+
+// targetAddress returns the first address of a CNAME target, or "" for a
+// dangling alias.
+func (r *Resolver) targetAddress(ctx context.Context, target string) string {
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		response, err := r.exchange(ctx, target, qtype)
+		if err != nil {
+			continue
+		}
+		records := collectRecords(response)
+		if len(records.A) > 0 {
+			return records.A[0]
+		}
+		if len(records.AAAA) > 0 {
+			return records.AAAA[0]
+		}
+	}
+	return ""
+}
+
+// ResolveAll queries each of types and returns every record found.
+func (r *Resolver) ResolveAll(domain string, types []RecordType) (*Records, error) {
+	records := &Records{}
+	for _, recordType := range types {
+		response, err := r.exchange(context.Background(), domain, recordType.qtype())
+		if err != nil {
+			continue
+		}
+		records.merge(collectRecords(response))
+	}
+
+	if records.Empty() {
+		return nil, fmt.Errorf("no %s record found for %s", joinTypes(types), domain)
+	}
+	return records, nil
+}
+
+// exchange sends the query to each server in turn and returns the first
+// usable answer. NXDOMAIN counts, since it can still carry the CNAME of a
+// dangling alias. When no server answers, the round is retried with
+// backoff.
+func (r *Resolver) exchange(ctx context.Context, domain string, qtype uint16) (*dns.Msg, error) {
+	response, err := r.retryer.ExecuteWithResult(ctx, func() (interface{}, error) {
+		return r.exchangeOnce(ctx, domain, qtype)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response.(*dns.Msg), nil
+}
+
+func (r *Resolver) exchangeOnce(ctx context.Context, domain string, qtype uint16) (*dns.Msg, error) {
 	msg := new(dns.Msg)
 	msg.Id = dns.Id()
 	msg.RecursionDesired = true
 	msg.Question = make([]dns.Question, 1)
 	msg.Question[0] = dns.Question{
 		Name:   dns.Fqdn(domain),
-		Qtype:  dns.TypeA,
+		Qtype:  qtype,
 		Qclass: dns.ClassINET,
 	}
 
-	servers := []string{"8.8.8.8:53", "1.1.1.1:53", "8.8.4.4:53"}
-
-	for _, server := range servers {
-		response, _, err := r.client.Exchange(msg, server)
+	for _, server := range r.servers {
+		response, _, err := r.client.ExchangeContext(ctx, msg, server)
 		if err != nil {
 			continue
 		}
 
-		if response.Rcode != dns.RcodeSuccess {
+		if response.Rcode != dns.RcodeSuccess && response.Rcode != dns.RcodeNameError {
 			continue
 		}
+		return response, nil
+	}
 
-		for _, answer := range response.Answer {
-			if aRecord, ok := answer.(*dns.A); ok {
-				return aRecord.A.String(), nil
-			}
-		}
+	return nil, fmt.Errorf("no DNS server answered for %s", domain)
+}
+
+// ResolveAAAA returns the IPv6 addresses of domain.
+func (r *Resolver) ResolveAAAA(domain string) ([]string, error) {
+	response, err := r.exchange(context.Background(), domain, dns.TypeAAAA)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := collectRecords(response).AAAA
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("no AAAA record found for %s", domain)
+	}
+	return addresses, nil
+}
+
+// ResolvePTR returns the names an IPv4 or IPv6 address maps back to.
+func (r *Resolver) ResolvePTR(ip string) ([]string, error) {
+	name, err := dns.ReverseAddr(ip)
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.exchange(context.Background(), name, dns.TypePTR)
+	if err != nil {
+		return nil, err
 	}
 
-	return "", fmt.Errorf("no A record found for %s", domain)
+	var names []string
+	for _, answer := range response.Answer {
+		if ptr, ok := answer.(*dns.PTR); ok {
+			names = append(names, strings.TrimSuffix(ptr.Ptr, "."))
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no PTR record found for %s", ip)
+	}
+	return names, nil
 }
 
 func (r *Resolver) ResolveCNAME(domain string) (string, error) {
@@ -96,9 +277,7 @@ func (r *Resolver) ResolveCNAME(domain string) (string, error) {
 		Qclass: dns.ClassINET,
 	}
 
-	servers := []string{"8.8.8.8:53", "1.1.1.1:53", "8.8.4.4:53"}
-
-	for _, server := range servers {
+	for _, server := range r.servers {
 		response, _, err := r.client.Exchange(msg, server)
 		if err != nil {
 			continue
@@ -130,9 +309,7 @@ func (r *Resolver) ResolveMX(domain string) ([]string, error) {
 	}
 
 	var mxRecords []string
-	servers := []string{"8.8.8.8:53", "1.1.1.1:53", "8.8.4.4:53"}
-
-	for _, server := range servers {
+	for _, server := range r.servers {
 		response, _, err := r.client.Exchange(msg, server)
 		if err != nil {
 			continue
@@ -169,9 +346,7 @@ func (r *Resolver) ResolveTXT(domain string) ([]string, error) {
 	}
 
 	var txtRecords []string
-	servers := []string{"8.8.8.8:53", "1.1.1.1:53", "8.8.4.4:53"}
-
-	for _, server := range servers {
+	for _, server := range r.servers {
 		response, _, err := r.client.Exchange(msg, server)
 		if err != nil {
 			continue
@@ -198,7 +373,49 @@ func (r *Resolver) ResolveTXT(domain string) ([]string, error) {
 	return txtRecords, nil
 }
 
+// ResolveNS returns the nameservers delegated for domain, without their
+// trailing dots.
+func (r *Resolver) ResolveNS(domain string) ([]string, error) {
+	response, err := r.exchange(context.Background(), domain, dns.TypeNS)
+	if err != nil {
+		return nil, err
+	}
+
+	var nameservers []string
+	for _, answer := range response.Answer {
+		if ns, ok := answer.(*dns.NS); ok {
+			nameservers = append(nameservers, strings.TrimSuffix(ns.Ns, "."))
+		}
+	}
+	if len(nameservers) == 0 {
+		return nil, fmt.Errorf("no NS records found for %s", domain)
+	}
+	return nameservers, nil
+}
+
+// ResolveSOA returns domain's SOA record in zone file order: primary
+// nameserver, contact mailbox, serial, refresh, retry, expire and minimum
+// TTL. Names below a zone apex have none of their own.
+func (r *Resolver) ResolveSOA(domain string) (string, error) {
+	response, err := r.exchange(context.Background(), domain, dns.TypeSOA)
+	if err != nil {
+		return "", err
+	}
+
+	for _, answer := range response.Answer {
+		if soa, ok := answer.(*dns.SOA); ok {
+			return fmt.Sprintf("%s %s %d %d %d %d %d",
+				strings.TrimSuffix(soa.Ns, "."), strings.TrimSuffix(soa.Mbox, "."),
+				soa.Serial, soa.Refresh, soa.Retry, soa.Expire, soa.Minttl), nil
+		}
+	}
+	return "", fmt.Errorf("no SOA record found for %s", domain)
+}
+
+// IsValidDomain reports whether domain has a record of a match type, queried
+// through the same servers and timeout as Resolve rather than the system
+// resolver.
 func (r *Resolver) IsValidDomain(domain string) bool {
-	_, err := net.LookupHost(domain)
+	_, err := r.Resolve(domain)
 	return err == nil
 }
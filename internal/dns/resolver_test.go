@@ -0,0 +1,172 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// stubServer answers from a fixed zone on a local UDP port and counts the
+// queries it receives.
+func stubServer(t *testing.T, zone map[string][]dns.RR) (string, *atomic.Int32) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var queries atomic.Int32
+	server := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		queries.Add(1)
+		reply := new(dns.Msg)
+		reply.SetReply(req)
+		question := req.Question[0]
+		records, ok := zone[question.Name]
+		if !ok {
+			reply.Rcode = dns.RcodeNameError
+		}
+		for _, record := range records {
+			if record.Header().Rrtype == question.Qtype || record.Header().Rrtype == dns.TypeCNAME {
+				reply.Answer = append(reply.Answer, record)
+			}
+		}
+		w.WriteMsg(reply)
+	})}
+	started := make(chan struct{})
+	server.NotifyStartedFunc = func() { close(started) }
+	go server.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { server.Shutdown() })
+	return conn.LocalAddr().String(), &queries
+}
+
+func rr(t *testing.T, record string) dns.RR {
+	t.Helper()
+	parsed, err := dns.NewRR(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestResolverUsesConfiguredServers(t *testing.T) {
+	address, queries := stubServer(t, map[string][]dns.RR{
+		"www.example.test.":      {rr(t, "www.example.test. 60 IN A 192.0.2.10")},
+		"v6.example.test.":       {rr(t, "v6.example.test. 60 IN AAAA 2001:db8::10")},
+		"alias.example.test.":    {rr(t, "alias.example.test. 60 IN CNAME www.example.test.")},
+		"dangling.example.test.": {rr(t, "dangling.example.test. 60 IN CNAME gone.example.test.")},
+	})
+
+	// A closed port first, so the stub is only reached by moving on to the
+	// next server
+	closed, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreachable := closed.LocalAddr().String()
+	closed.Close()
+
+	resolver := NewResolver(1)
+	resolver.SetServers([]string{unreachable, address})
+
+	tests := []struct {
+		name    string
+		ip      string
+		wantErr bool
+	}{
+		{name: "www.example.test", ip: "192.0.2.10"},
+		{name: "v6.example.test", ip: "2001:db8::10"},
+		// An alias resolves to its target's address, not the target's name
+		{name: "alias.example.test", ip: "192.0.2.10"},
+		// A dangling alias exists but has no address
+		{name: "dangling.example.test", ip: ""},
+		{name: "missing.example.test", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip, err := resolver.Resolve(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if ip != tt.ip {
+				t.Errorf("got %q, want %q", ip, tt.ip)
+			}
+			if valid := resolver.IsValidDomain(tt.name); valid == tt.wantErr {
+				t.Errorf("IsValidDomain = %v, want %v", valid, !tt.wantErr)
+			}
+		})
+	}
+
+	if target, err := resolver.ResolveCNAME("alias.example.test"); err != nil || target != "www.example.test." {
+		t.Errorf("ResolveCNAME = %q, %v, want www.example.test.", target, err)
+	}
+	if queries.Load() == 0 {
+		t.Error("the configured server was never queried")
+	}
+}
+
+func TestResolveRecordsContext(t *testing.T) {
+	address, queries := stubServer(t, map[string][]dns.RR{
+		"dual.example.test.": {
+			rr(t, "dual.example.test. 60 IN A 192.0.2.20"),
+			rr(t, "dual.example.test. 60 IN AAAA 2001:db8::20"),
+		},
+		"v6.example.test.":    {rr(t, "v6.example.test. 60 IN AAAA 2001:db8::10")},
+		"www.example.test.":   {rr(t, "www.example.test. 60 IN A 192.0.2.10")},
+		"alias.example.test.": {rr(t, "alias.example.test. 60 IN CNAME www.example.test.")},
+	})
+	resolver := NewResolver(1)
+	resolver.SetServers([]string{address})
+
+	tests := []struct {
+		name    string
+		ip      string
+		aaaa    []string
+		cname   []string
+		queries int32
+	}{
+		// The AAAA query after the A match replaces a separate ResolveAAAA
+		{name: "dual.example.test", ip: "192.0.2.20", aaaa: []string{"2001:db8::20"}, queries: 2},
+		// The AAAA match already queried it, so nothing is repeated
+		{name: "v6.example.test", ip: "2001:db8::10", aaaa: []string{"2001:db8::10"}, queries: 2},
+		{name: "alias.example.test", ip: "192.0.2.10", cname: []string{"www.example.test"}, queries: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := queries.Load()
+			ip, records, err := resolver.ResolveRecordsContext(context.Background(), tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ip != tt.ip {
+				t.Errorf("ip = %q, want %q", ip, tt.ip)
+			}
+			if !reflect.DeepEqual(records.AAAA, tt.aaaa) {
+				t.Errorf("AAAA = %v, want %v", records.AAAA, tt.aaaa)
+			}
+			if !reflect.DeepEqual(records.CNAME, tt.cname) {
+				t.Errorf("CNAME = %v, want %v", records.CNAME, tt.cname)
+			}
+			if sent := queries.Load() - before; sent != tt.queries {
+				t.Errorf("sent %d queries, want %d", sent, tt.queries)
+			}
+		})
+	}
+
+	if _, _, err := resolver.ResolveRecordsContext(context.Background(), "missing.example.test"); err == nil {
+		t.Error("missing name resolved")
+	}
+}
+
+func TestSetServersRestoresDefaults(t *testing.T) {
+	resolver := NewResolver(1)
+	resolver.SetServers([]string{"127.0.0.1:5353"})
+	resolver.SetServers(nil)
+	if len(resolver.servers) != len(DefaultServers) || resolver.servers[0] != DefaultServers[0] {
+		t.Errorf("servers = %v, want %v", resolver.servers, DefaultServers)
+	}
+}
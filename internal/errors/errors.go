@@ -2,6 +2,8 @@ package errors
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"runtime"
 	"sync"
 	"time"
@@ -201,6 +203,10 @@ func (ec *ErrorCollector) HasErrors() bool {
 }
 
 func (ec *ErrorCollector) PrintSummary() {
+	ec.WriteSummary(os.Stdout)
+}
+
+func (ec *ErrorCollector) WriteSummary(w io.Writer) {
 	ec.mu.RLock()
 	defer ec.mu.RUnlock()
 
@@ -208,8 +214,8 @@ func (ec *ErrorCollector) PrintSummary() {
 		return
 	}
 
-	fmt.Println("\nError Summary:")
-	fmt.Println("==============")
+	fmt.Fprintln(w, "\nError Summary:")
+	fmt.Fprintln(w, "==============")
 
 	typeCount := make(map[ErrorType]int)
 	for _, err := range ec.errors {
@@ -217,13 +223,17 @@ func (ec *ErrorCollector) PrintSummary() {
 	}
 
 	for errorType, count := range typeCount {
-		fmt.Printf("%s: %d\n", errorType, count)
+		fmt.Fprintf(w, "%s: %d\n", errorType, count)
 	}
 
-	fmt.Printf("Total: %d\n", len(ec.errors))
+	fmt.Fprintf(w, "Total: %d\n", len(ec.errors))
 }
 
 func (ec *ErrorCollector) PrintDetailed() {
+	ec.WriteDetailed(os.Stdout)
+}
+
+func (ec *ErrorCollector) WriteDetailed(w io.Writer) {
 	ec.mu.RLock()
 	defer ec.mu.RUnlock()
 
@@ -231,18 +241,18 @@ func (ec *ErrorCollector) PrintDetailed() {
 		return
 	}
 
-	fmt.Println("\nDetailed Errors:")
-	fmt.Println("================")
+	fmt.Fprintln(w, "\nDetailed Errors:")
+	fmt.Fprintln(w, "================")
 
 	for i, err := range ec.errors {
-		fmt.Printf("%d. [%s] %s\n", i+1, err.Type, err.Message)
+		fmt.Fprintf(w, "%d. [%s] %s\n", i+1, err.Type, err.Message)
 		if err.Err != nil {
-			fmt.Printf("   Caused by: %v\n", err.Err)
+			fmt.Fprintf(w, "   Caused by: %v\n", err.Err)
 		}
 		if len(err.Details) > 0 {
-			fmt.Printf("   Details: %v\n", err.Details)
+			fmt.Fprintf(w, "   Details: %v\n", err.Details)
 		}
-		fmt.Printf("   Location: %s:%d\n", err.File, err.Line)
-		fmt.Println()
+		fmt.Fprintf(w, "   Location: %s:%d\n", err.File, err.Line)
+		fmt.Fprintln(w)
 	}
 }
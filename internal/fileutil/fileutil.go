@@ -0,0 +1,145 @@
+package fileutil
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+
+	apperrors "subdomain-finder/internal/errors"
+)
+
+// EnsureWritableDir creates dir when missing and proves a file can be
+// created in it, so an unwritable output location fails before a scan
+// rather than after it.
+func EnsureWritableDir(dir string) error {
+	if dir == "" {
+		dir = "."
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ioError("cannot create output directory", dir, err)
+	}
+
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return ioError("output directory is not writable", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	return nil
+}
+
+// Create creates path, and any missing parent directories, for writing.
+func Create(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, ioError("cannot create output directory", filepath.Dir(path), err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, ioError("cannot create output file", path, err)
+	}
+	return file, nil
+}
+
+// WriteFile writes data to path, creating missing parent directories.
+func WriteFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return ioError("cannot create output directory", filepath.Dir(path), err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return ioError("cannot write output file", path, err)
+	}
+	return nil
+}
+
+// WritePrivateFile is WriteFile for files that may hold credentials. The
+// file is readable by its owner only, even when it already existed.
+func WritePrivateFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return ioError("cannot create output directory", filepath.Dir(path), err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return ioError("cannot create output file", path, err)
+	}
+	// An existing file keeps its mode on open, so narrow it before writing
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		return ioError("cannot write output file", path, err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return ioError("cannot write output file", path, err)
+	}
+	if err := file.Close(); err != nil {
+		return ioError("cannot write output file", path, err)
+	}
+	return nil
+}
+
+// WriteFileAtomic writes data to a temporary file next to path and renames
+// it into place, so concurrent readers see either the old or the new
+// contents and never a partial file.
+func WriteFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ioError("cannot create output directory", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return ioError("cannot create output file", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return ioError("cannot write output file", path, err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return ioError("cannot write output file", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return ioError("cannot write output file", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return ioError("cannot write output file", path, err)
+	}
+	return nil
+}
+
+// WriteBuffered creates path, and any missing parent directories, and fills
+// it through a buffered writer. The buffer keeps the first write error, so
+// write need not check each call. Write, flush and close failures are all
+// returned as IO errors; full and network filesystems often report a failed
+// write only on close.
+func WriteBuffered(path string, write func(w *bufio.Writer) error) (err error) {
+	file, err := Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = ioError("cannot write output file", path, closeErr)
+		}
+	}()
+
+	w := bufio.NewWriter(file)
+	if err := write(w); err != nil {
+		return ioError("cannot write output file", path, err)
+	}
+	if err := w.Flush(); err != nil {
+		return ioError("cannot write output file", path, err)
+	}
+	return nil
+}
+
+func ioError(message, path string, err error) error {
+	return apperrors.NewErrorWithError(apperrors.ErrorTypeIO, message, err).
+		WithDetails(map[string]interface{}{"path": path})
+}
@@ -0,0 +1,51 @@
+package fileutil
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apperrors "subdomain-finder/internal/errors"
+)
+
+func TestWriteBuffered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "results.txt")
+	err := WriteBuffered(path, func(w *bufio.Writer) error {
+		_, err := w.WriteString("www.example.com\n")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "www.example.com\n" {
+		t.Errorf("file = %q, want %q", data, "www.example.com\n")
+	}
+}
+
+func TestWriteBufferedReportsIOErrors(t *testing.T) {
+	failed := errors.New("encode failed")
+	err := WriteBuffered(filepath.Join(t.TempDir(), "results.json"), func(w *bufio.Writer) error {
+		return failed
+	})
+	if !errors.Is(err, failed) || !apperrors.IsErrorType(err, apperrors.ErrorTypeIO) {
+		t.Errorf("write error = %v, want an IO error wrapping %v", err, failed)
+	}
+
+	// /dev/full accepts the open and fails the buffered write on flush
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("/dev/full is not available")
+	}
+	err = WriteBuffered("/dev/full", func(w *bufio.Writer) error {
+		w.WriteString("www.example.com\n")
+		return nil
+	})
+	if !apperrors.IsErrorType(err, apperrors.ErrorTypeIO) {
+		t.Errorf("flush error = %v, want an IO error", err)
+	}
+}
@@ -0,0 +1,127 @@
+package finder
+
+import (
+	"context"
+
+	"subdomain-finder/internal/dns"
+	"subdomain-finder/internal/http"
+	"subdomain-finder/internal/portscanner"
+	"subdomain-finder/internal/ssl"
+	"subdomain-finder/internal/techdetect"
+	"subdomain-finder/internal/vulnscanner"
+)
+
+// The context interfaces are implemented by dependencies that can abandon
+// their network work when the scan is cancelled. Dependencies without them
+// still work but run each call to completion.
+type contextResolver interface {
+	ResolveContext(ctx context.Context, domain string) (string, error)
+}
+
+// recordsResolver is implemented by resolvers that also return the records
+// found while resolving, which spares checkSubdomain its own AAAA and CNAME
+// queries.
+type recordsResolver interface {
+	ResolveRecordsContext(ctx context.Context, domain string) (string, *dns.Records, error)
+}
+
+type contextHTTPChecker interface {
+	FetchContext(ctx context.Context, domain string) *http.HTTPResponse
+	FollowRedirectsContext(ctx context.Context, response *http.HTTPResponse) []http.Redirect
+}
+
+type contextPortScanner interface {
+	ScanHostContext(ctx context.Context, host string, ports []int, protocol portscanner.Protocol) *portscanner.ScanResult
+}
+
+type contextSSLAnalyzer interface {
+	AnalyzeContext(ctx context.Context, host string, port int) (*ssl.SSLResult, error)
+}
+
+type contextTechDetector interface {
+	DetectContext(ctx context.Context, url string) (*techdetect.TechResult, error)
+}
+
+type contextVulnScanner interface {
+	ScanURLContext(ctx context.Context, url string) ([]vulnscanner.Vulnerability, error)
+}
+
+func (f *Finder) resolve(ctx context.Context, domain string) (string, error) {
+	if err := f.wait(ctx); err != nil {
+		return "", err
+	}
+	if err := f.budget.Acquire(ctx); err != nil {
+		return "", err
+	}
+	defer f.budget.Release()
+	if resolver, ok := f.dns.(contextResolver); ok {
+		return resolver.ResolveContext(ctx, domain)
+	}
+	return f.dns.Resolve(domain)
+}
+
+// resolveRecords is resolve that also returns the records the resolver
+// found, or nil records when it cannot report them.
+func (f *Finder) resolveRecords(ctx context.Context, domain string) (string, *dns.Records, error) {
+	resolver, ok := f.dns.(recordsResolver)
+	if !ok {
+		address, err := f.resolve(ctx, domain)
+		return address, nil, err
+	}
+	if err := f.wait(ctx); err != nil {
+		return "", nil, err
+	}
+	if err := f.budget.Acquire(ctx); err != nil {
+		return "", nil, err
+	}
+	defer f.budget.Release()
+	return resolver.ResolveRecordsContext(ctx, domain)
+}
+
+func (f *Finder) fetch(ctx context.Context, domain string) *http.HTTPResponse {
+	if checker, ok := f.http.(contextHTTPChecker); ok {
+		return checker.FetchContext(ctx, domain)
+	}
+	return f.http.Fetch(domain)
+}
+
+func (f *Finder) followRedirects(ctx context.Context, response *http.HTTPResponse) []http.Redirect {
+	if checker, ok := f.http.(contextHTTPChecker); ok {
+		return checker.FollowRedirectsContext(ctx, response)
+	}
+	return f.http.FollowRedirects(response)
+}
+
+func (f *Finder) scanHost(ctx context.Context, host string, ports []int, protocol portscanner.Protocol) *portscanner.ScanResult {
+	if scanner, ok := f.portScanner.(contextPortScanner); ok {
+		return scanner.ScanHostContext(ctx, host, ports, protocol)
+	}
+	return f.portScanner.ScanHost(host, ports, protocol)
+}
+
+func (f *Finder) analyzeSSL(ctx context.Context, host string, port int) (*ssl.SSLResult, error) {
+	// The whole analysis counts as one slot, although it opens a few
+	// connections of its own
+	if err := f.budget.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer f.budget.Release()
+	if analyzer, ok := f.sslAnalyzer.(contextSSLAnalyzer); ok {
+		return analyzer.AnalyzeContext(ctx, host, port)
+	}
+	return f.sslAnalyzer.Analyze(host, port)
+}
+
+func (f *Finder) detectTech(ctx context.Context, url string) (*techdetect.TechResult, error) {
+	if detector, ok := f.techDetector.(contextTechDetector); ok {
+		return detector.DetectContext(ctx, url)
+	}
+	return f.techDetector.Detect(url)
+}
+
+func (f *Finder) scanURL(ctx context.Context, url string) ([]vulnscanner.Vulnerability, error) {
+	if scanner, ok := f.vulnScanner.(contextVulnScanner); ok {
+		return scanner.ScanURLContext(ctx, url)
+	}
+	return f.vulnScanner.ScanURL(url)
+}
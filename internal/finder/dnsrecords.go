@@ -0,0 +1,48 @@
+package finder
+
+import (
+	"context"
+
+	"subdomain-finder/internal/dns"
+	"subdomain-finder/internal/types"
+)
+
+// recordResolver is implemented by resolvers that can list every record
+// type kept in types.DNSInfo.
+type recordResolver interface {
+	ResolveAll(domain string, types []dns.RecordType) (*dns.Records, error)
+	ResolveMX(domain string) ([]string, error)
+	ResolveTXT(domain string) ([]string, error)
+	ResolveNS(domain string) ([]string, error)
+	ResolveSOA(domain string) (string, error)
+}
+
+// lookupRecords collects subdomain's A, AAAA, CNAME, MX, TXT, NS and SOA
+// records. Missing types are left empty, and it returns nil when the
+// resolver cannot list records or the scan was cancelled.
+func (f *Finder) lookupRecords(ctx context.Context, subdomain string) *types.DNSInfo {
+	resolver, ok := f.dns.(recordResolver)
+	if !ok {
+		return nil
+	}
+
+	info := &types.DNSInfo{}
+	lookups := []func(){
+		func() {
+			if records, err := resolver.ResolveAll(subdomain, dns.DefaultMatchTypes); err == nil {
+				info.ARecords, info.AAAARecords, info.CNAMERecords = records.A, records.AAAA, records.CNAME
+			}
+		},
+		func() { info.MXRecords, _ = resolver.ResolveMX(subdomain) },
+		func() { info.TXTRecords, _ = resolver.ResolveTXT(subdomain) },
+		func() { info.NSRecords, _ = resolver.ResolveNS(subdomain) },
+		func() { info.SOARecord, _ = resolver.ResolveSOA(subdomain) },
+	}
+	for _, lookup := range lookups {
+		if f.wait(ctx) != nil {
+			return nil
+		}
+		lookup()
+	}
+	return info
+}
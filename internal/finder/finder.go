@@ -1,92 +1,623 @@
 package finder
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	stdhttp "net/http"
+	"net/http/cookiejar"
+	"net/netip"
+	"strings"
 	"sync"
 	"time"
 
 	"subdomain-finder/internal/dns"
+	"subdomain-finder/internal/geoip"
+	"subdomain-finder/internal/har"
 	"subdomain-finder/internal/http"
+	"subdomain-finder/internal/idn"
+	"subdomain-finder/internal/limiter"
+	"subdomain-finder/internal/logger"
+	"subdomain-finder/internal/parking"
+	"subdomain-finder/internal/passive"
 	"subdomain-finder/internal/portscanner"
+	"subdomain-finder/internal/progress"
 	"subdomain-finder/internal/ssl"
+	"subdomain-finder/internal/tags"
+	"subdomain-finder/internal/takeover"
 	"subdomain-finder/internal/techdetect"
 	"subdomain-finder/internal/types"
 	"subdomain-finder/internal/vulnscanner"
 	"subdomain-finder/internal/wordlist"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/publicsuffix"
 )
 
 type Config struct {
-	Domain     string
-	Wordlist   string
-	Threads    int
-	Timeout    int
-	RateLimit  int
-	OutputFile string
-	Verbose    bool
-	JSON       bool
-	XML        bool
-	Progress   bool
-	Stats      bool
-	NoColor    bool
-	UserAgent  string
-	Headers    []string
-	Retries    int
-	Delay      int
+	Domain           string
+	Wordlist         string
+	Threads          int
+	Timeout          int
+	RateLimit        int
+	MaxSockets       int
+	OutputFile       string
+	Verbose          bool
+	JSON             bool
+	JSONL            bool
+	XML              bool
+	Progress         bool
+	Stats            bool
+	NoColor          bool
+	UserAgent        string
+	Headers          []string
+	Retries          int
+	DNSRetries       int
+	DNSServers       []string
+	Delay            int
+	SNI              string
+	NoSNI            bool
+	ExcludeParked    bool
+	KeepUnresolved   bool
+	RequireHTTP      bool
+	OwnedRanges      []netip.Prefix
+	ExcludeExternal  bool
+	KeepWildcard     bool
+	MaxConnsPerHost  int
+	HARFile          string
+	TLSMinVersion    uint16
+	TLSMaxVersion    uint16
+	RootCAs          *x509.CertPool // replaces the system roots when set
+	Hosts            []string
+	ContentDiscovery bool
+	Vuln             bool
+	VulnChecks       []vulnscanner.Check
+	VulnSignatures   []vulnscanner.Signature
+	ActiveVuln       bool
+	Aggressive       bool
+	ActiveScope      []string
+	Takeover         bool
+	ExtractLinks     bool
+	MaxResults       int
+	DNSMatch         []dns.RecordType
+	DNSRecords       bool
+	RecursionDepth   int
+	Permute          bool
+	MineSANs         bool
+	Reverse          bool
+	ReverseMask      int
+	Ports            string
+	SSLPorts         []int
+	DeepTLS          bool
+	UDP              bool
+	ExcludePorts     []int
+	TagRules         *tags.RuleSet
+	LoginURL         string
+	LoginData        string
+	// Passive sources run with their own concurrency and deadlines (seconds)
+	PassiveConcurrency   int
+	PassiveSourceTimeout int
+	PassiveTimeout       int
 }
 
 type Finder struct {
 	config       Config
-	dns          *dns.Resolver
-	http         *http.Checker
-	portScanner  *portscanner.PortScanner
-	sslAnalyzer  *ssl.SSLAnalyzer
-	techDetector *techdetect.TechDetector
-	vulnScanner  *vulnscanner.VulnScanner
+	dns          Resolver
+	http         HTTPChecker
+	portScanner  PortScanner
+	sslAnalyzer  SSLAnalyzer
+	techDetector TechDetector
+	vulnScanner  VulnScanner
 	wordlist     *wordlist.Wordlist
+	parking      *parking.Classifier
+	takeover     *takeover.Detector
+	locator      geoip.Locator
+	har          *har.Recorder
+	portCache    *portScanCache
+	ports        []int
+	udpPorts     []int
+	hostLimit    *hostLimit
+	rateLimiter  *limiter.RateLimiter
+	budget       *limiter.Budget
+	session      *stdhttp.Client
+	passive      *passive.Runner
+	traceID      string
+	scanID       string
+	limitReached bool
+	wildcard     *wildcardDNS
+	logger       *logger.Logger
+	tagRules     *tags.RuleSet
+	webProbes    chan struct{}
+	onProgress   func(progress.Stats)
+	onResult     func(types.Result)
+	callbackMu   sync.Mutex
 }
 
 func NewFinder(config Config) *Finder {
-	dnsResolver := dns.NewResolver(config.Timeout)
-	httpChecker := http.NewChecker(config.Timeout)
-	portScanner := portscanner.NewPortScanner(time.Duration(config.Timeout)*time.Second, config.Threads)
-	sslAnalyzer := ssl.NewSSLAnalyzer(time.Duration(config.Timeout) * time.Second)
-	techDetector := techdetect.NewTechDetector(time.Duration(config.Timeout) * time.Second)
-	vulnScanner := vulnscanner.NewVulnScanner(time.Duration(config.Timeout) * time.Second)
-	wordlistManager := wordlist.NewWordlist(config.Wordlist)
+	return NewFinderWithOptions(config, Options{})
+}
+
+func NewFinderWithOptions(config Config, opts Options) *Finder {
+	transportConfig := http.TransportConfig{
+		MinVersion:      config.TLSMinVersion,
+		MaxVersion:      config.TLSMaxVersion,
+		MaxConnsPerHost: config.MaxConnsPerHost,
+		RootCAs:         config.RootCAs,
+	}
+
+	var transport stdhttp.RoundTripper = stdhttp.DefaultTransport
+	if shared, err := http.NewTransport(transportConfig); err == nil {
+		transport = shared
+	}
+	transport = http.NewDecodingTransport(transport)
+
+	var recorder *har.Recorder
+	if config.HARFile != "" {
+		recorder = har.NewRecorder(har.DefaultMaxBodySize)
+		transport = recorder.Transport(transport)
+	}
+
+	// The socket budget is taken inside the rate limit so requests waiting
+	// for their turn do not hold a slot
+	budget := limiter.NewBudget(config.MaxSockets)
+	if budget != nil {
+		transport = http.NewBudgetTransport(transport, budget)
+	}
+
+	// One limiter paces DNS lookups and HTTP requests together; it wraps the
+	// HAR recorder so recorded timings leave out the wait
+	var rateLimiter *limiter.RateLimiter
+	if config.RateLimit > 0 {
+		rateLimiter = limiter.NewRateLimiter(config.RateLimit, time.Second)
+		transport = http.NewRateLimitedTransport(transport, rateLimiter)
+	}
+
+	// A login shares one cookie jar between every HTTP client so the
+	// session follows the scan
+	var jar stdhttp.CookieJar
+	if config.LoginURL != "" {
+		jar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	}
+
+	// The login, technology detection, vulnerability probes and revocation
+	// checks share one client; the HTTP checker keeps its own for its
+	// redirect policy
+	client := &stdhttp.Client{
+		Transport: transport,
+		Jar:       jar,
+		Timeout:   time.Duration(config.Timeout) * time.Second,
+	}
+	var session *stdhttp.Client
+	if config.LoginURL != "" {
+		session = client
+	}
+
+	if opts.Logger == nil {
+		opts.Logger = logger.NewLogger("info", "text")
+		opts.Logger.SetOutput(io.Discard)
+	}
+	if opts.Resolver == nil {
+		resolver := dns.NewResolverWithRetry(config.Timeout, limiter.RetryConfig{MaxRetries: config.DNSRetries})
+		resolver.SetMatchTypes(config.DNSMatch)
+		resolver.SetServers(config.DNSServers)
+		opts.Resolver = resolver
+	}
+	if opts.HTTPChecker == nil {
+		httpChecker := http.NewChecker(config.Timeout)
+		httpChecker.SetTransport(transport)
+		httpChecker.SetCookieJar(jar)
+		opts.HTTPChecker = httpChecker
+	}
+	if opts.PortScanner == nil {
+		portScanner := portscanner.NewPortScanner(time.Duration(config.Timeout)*time.Second, config.Threads)
+		portScanner.SetExcludedPorts(config.ExcludePorts)
+		portScanner.SetBudget(budget)
+		opts.PortScanner = portScanner
+	}
+	if opts.SSLAnalyzer == nil {
+		sslAnalyzer := ssl.NewSSLAnalyzerWithClient(time.Duration(config.Timeout)*time.Second, client)
+		sslAnalyzer.SetServerName(config.SNI)
+		sslAnalyzer.SetDisableSNI(config.NoSNI)
+		sslAnalyzer.SetDeepChecks(config.DeepTLS)
+		opts.SSLAnalyzer = sslAnalyzer
+	}
+	if opts.TechDetector == nil {
+		opts.TechDetector = techdetect.NewTechDetectorWithClient(client)
+	}
+	if opts.VulnScanner == nil {
+		vulnScanner := vulnscanner.NewVulnScannerWithClient(client)
+		vulnScanner.SetContentDiscovery(config.ContentDiscovery)
+		vulnScanner.SetActiveChecks(config.ActiveVuln)
+		vulnScanner.SetSafeMode(!config.Aggressive)
+		vulnScanner.SetActiveScope(config.ActiveScope)
+		switch {
+		case !config.Vuln:
+			vulnScanner.SetChecks(nil)
+		case len(config.VulnChecks) > 0:
+			vulnScanner.SetChecks(config.VulnChecks)
+		}
+		vulnScanner.SetSignatures(config.VulnSignatures)
+		opts.VulnScanner = vulnScanner
+	}
 
 	return &Finder{
 		config:       config,
-		dns:          dnsResolver,
-		http:         httpChecker,
-		portScanner:  portScanner,
-		sslAnalyzer:  sslAnalyzer,
-		techDetector: techDetector,
-		vulnScanner:  vulnScanner,
-		wordlist:     wordlistManager,
+		dns:          opts.Resolver,
+		http:         opts.HTTPChecker,
+		portScanner:  opts.PortScanner,
+		sslAnalyzer:  opts.SSLAnalyzer,
+		techDetector: opts.TechDetector,
+		vulnScanner:  opts.VulnScanner,
+		wordlist:     wordlist.NewWordlist(config.Wordlist),
+		parking:      parking.NewClassifier(),
+		takeover:     takeover.NewDetector(),
+		locator:      opts.Locator,
+		har:          recorder,
+		rateLimiter:  rateLimiter,
+		budget:       budget,
+		session:      session,
+		passive:      newPassiveRunner(config, opts),
+		logger:       opts.Logger,
+		tagRules:     config.TagRules,
+		webProbes:    make(chan struct{}, webProbeSlots(config.Threads)),
+		portCache:    newPortScanCache(),
+		ports:        scanPorts(config.Ports, config.ExcludePorts),
+		udpPorts:     udpPorts(config.UDP, config.ExcludePorts),
 	}
 }
 
-func (f *Finder) Find() []types.Result {
+// Login signs in to the configured login URL so later requests carry the
+// session cookie. It is a no-op when no login URL is configured.
+func (f *Finder) Login() error {
+	if f.session == nil {
+		return nil
+	}
+	return http.Login(f.session, f.config.LoginURL, f.config.LoginData)
+}
+
+// TraceID returns the trace id of the last Find call, or "" when tracing is
+// disabled.
+func (f *Finder) TraceID() string {
+	return f.traceID
+}
+
+// ScanID returns the id of the last Find call, attached to its log entries
+// as scan_id.
+func (f *Finder) ScanID() string {
+	return f.scanID
+}
+
+// ResultLimitReached reports whether the last Find found more than
+// Config.MaxResults subdomains and stopped early, so its results are partial.
+func (f *Finder) ResultLimitReached() bool {
+	return f.limitReached
+}
+
+// WildcardIPs returns the addresses random names resolved to during the
+// last Find, or nil when the domain has no wildcard DNS record.
+func (f *Finder) WildcardIPs() []string {
+	return f.wildcard.addresses()
+}
+
+// SaveHAR writes every HTTP exchange recorded during the scan to the
+// configured HAR file. It is a no-op when HAR recording is disabled.
+func (f *Finder) SaveHAR() error {
+	if f.har == nil {
+		return nil
+	}
+	return f.har.Save(f.config.HARFile)
+}
+
+// OnProgress registers a callback that receives updated stats after every
+// candidate is checked. Callbacks are never invoked concurrently.
+func (f *Finder) OnProgress(fn func(progress.Stats)) {
+	f.onProgress = fn
+}
+
+// OnResult registers a callback that receives each found subdomain as soon
+// as it is confirmed. Callbacks are never invoked concurrently.
+func (f *Finder) OnResult(fn func(types.Result)) {
+	f.onResult = fn
+}
+
+type ScanPlan struct {
+	Config          Config
+	Candidates      int
+	Ports           []int
+	Checks          []string
+	DNSQueries      int
+	RequestsPerHost int
+	MaxRequests     int
+	PassiveSources  []string
+}
+
+func (f *Finder) Plan() ScanPlan {
+	candidates := len(f.targets())
+	ports := f.ports
+
+	// HTTP tries http:// then https://, SSL and tech detection are one
+	// connection each, and every scanned port is one dial or datagram
+	requestsPerHost := 2 + len(ports) + len(f.udpPorts) + 1 + 1
+	if f.scansURLs() {
+		requestsPerHost += f.vulnScanner.RequestsPerURL()
+	}
+
+	checks := []string{"dns", "http", "ssl", "techdetect"}
+	if len(ports) > 0 || len(f.udpPorts) > 0 {
+		checks = []string{"dns", "http", "ports", "ssl", "techdetect"}
+	}
+	if f.config.Vuln {
+		checks = append(checks, "vuln")
+	}
+	if len(f.config.VulnSignatures) > 0 {
+		checks = append(checks, "vuln-signatures")
+	}
+	if f.config.ContentDiscovery {
+		checks = append(checks, "content-discovery")
+	}
+	if f.config.ExtractLinks {
+		checks = append(checks, "extract-links")
+	}
+	if f.config.ActiveVuln {
+		checks = append(checks, "active-vuln")
+	}
+	if f.config.Aggressive {
+		checks = append(checks, "aggressive")
+	}
+	if f.config.Takeover {
+		checks = append(checks, "takeover")
+	}
+	if f.config.DeepTLS {
+		checks = append(checks, "deep-tls")
+	}
+	if f.config.DNSRecords {
+		checks = append(checks, "dns-records")
+	}
+	if f.config.RecursionDepth > 1 && !f.directHosts() {
+		checks = append(checks, "recursive")
+	}
+	if f.config.Permute && !f.directHosts() {
+		checks = append(checks, "permute")
+	}
+	if f.config.Reverse && !f.directHosts() {
+		checks = append(checks, "reverse-dns")
+	}
+	if f.config.MineSANs && !f.directHosts() {
+		checks = append(checks, "san-mining")
+	}
+	var passiveSources []string
+	if f.passive != nil && !f.directHosts() {
+		checks = append([]string{"passive"}, checks...)
+		passiveSources = f.passive.Sources()
+	}
+	// One query per match type at most; NXDOMAIN stops after the first
+	matchTypes := len(f.config.DNSMatch)
+	if matchTypes == 0 {
+		matchTypes = len(dns.DefaultMatchTypes)
+	}
+	dnsQueries := candidates*matchTypes + wildcardProbes
+	if f.directHosts() {
+		checks = checks[1:]
+		dnsQueries = 0
+	}
+
+	return ScanPlan{
+		Config:          f.config,
+		Candidates:      candidates,
+		Ports:           ports,
+		Checks:          checks,
+		DNSQueries:      dnsQueries,
+		RequestsPerHost: requestsPerHost,
+		MaxRequests:     dnsQueries + candidates*requestsPerHost,
+		PassiveSources:  passiveSources,
+	}
+}
+
+// directHosts reports whether the scan targets explicit hosts, which skip
+// DNS resolution, instead of wordlist subdomains.
+func (f *Finder) directHosts() bool {
+	return len(f.config.Hosts) > 0
+}
+
+// linkScope is the domain whose links are kept when extracting endpoints:
+// the scanned domain, or the host itself when scanning explicit hosts.
+func (f *Finder) linkScope(host string) string {
+	if f.directHosts() || f.config.Domain == "" {
+		return host
+	}
+	return f.config.Domain
+}
+
+func (f *Finder) targets() []string {
+	if f.directHosts() {
+		return f.config.Hosts
+	}
+
 	words := f.wordlist.GetWords()
+	targets := make([]string, 0, len(words))
+	for _, word := range words {
+		targets = append(targets, word+"."+f.config.Domain)
+	}
+	return targets
+}
+
+// Find runs the scan and returns every found subdomain.
+func (f *Finder) Find() []types.Result {
+	return f.FindWithContext(context.Background())
+}
+
+// FindWithContext is Find that stops when ctx is cancelled. DNS, HTTP,
+// port, SSL, technology and vulnerability checks still in flight are
+// abandoned, and the subdomains confirmed before then are returned.
+func (f *Finder) FindWithContext(ctx context.Context) []types.Result {
+	results := make([]types.Result, 0)
+	f.run(ctx, func(result types.Result) {
+		results = append(results, result)
+	})
+	return results
+}
+
+// FindStream runs the scan in the background and sends each found subdomain
+// as soon as it is confirmed. The channel is closed when the scan finishes
+// or ctx is cancelled; candidates not yet checked by then are skipped. The
+// caller must drain the channel or cancel ctx.
+func (f *Finder) FindStream(ctx context.Context) <-chan types.Result {
+	out := make(chan types.Result)
+	go func() {
+		defer close(out)
+		f.run(ctx, func(result types.Result) {
+			select {
+			case out <- result:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return out
+}
+
+func (f *Finder) run(ctx context.Context, emit func(types.Result)) {
+	targets := f.targets()
+	wordlistTargets := len(targets)
+	if f.passive != nil && !f.directHosts() {
+		targets = mergeTargets(targets, f.passive.Run(ctx, f.config.Domain))
+	}
+
+	tracker := progress.NewTracker(len(targets))
+	f.portCache = newPortScanCache()
+	f.hostLimit = newHostLimit(f.config.MaxConnsPerHost)
+	f.scanID = newScanID()
+	log := f.logger.With("scan_id", f.scanID)
+	log.Debugf("Checking %d candidates for %s", len(targets), f.config.Domain)
+
+	f.wildcard = nil
+	if !f.directHosts() {
+		f.wildcard = f.detectWildcardDNS(ctx)
+		if ips := f.wildcard.addresses(); len(ips) > 0 {
+			log.Warnf("Wildcard DNS detected for %s (%s)", f.config.Domain, strings.Join(ips, ", "))
+		}
+	}
+
+	ctx, scanSpan := tracer.Start(ctx, "scan", trace.WithAttributes(
+		attribute.String("domain", f.config.Domain),
+		attribute.Int("candidates", len(targets)),
+	))
+	defer scanSpan.End()
+	if spanContext := scanSpan.SpanContext(); spanContext.IsValid() {
+		f.traceID = spanContext.TraceID().String()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	limit := newResultLimit(f.config.MaxResults, cancel)
+
+	candidates := make([]candidate, 0, len(targets))
+	for i, target := range targets {
+		c := candidate{name: target, depth: 1}
+		// mergeTargets appends passive names after the wordlist's
+		if i >= wordlistTargets {
+			c.source = "passive"
+		}
+		candidates = append(candidates, c)
+	}
+	results := f.scanCandidates(ctx, log, candidates, tracker, limit, emit)
+
+	// Found names are permuted once after the first pass, and the networks
+	// they resolve into are searched with reverse DNS. Wildcard
+	// certificate SANs name whole namespaces, so each new one is expanded
+	// with the wordlist until no further wildcards turn up. With recursion
+	// the namespaces of found subdomains are expanded too, and with SAN
+	// mining the other names on their certificates are checked.
+	if !f.directHosts() {
+		expansion := newExpansion(f.config.Domain, targets)
+		if f.config.Permute && ctx.Err() == nil {
+			next := expansion.permutationCandidates(log, results, f.wordlist.GetWords())
+			log.Debugf("Checking %d permutations of found names", len(next))
+			tracker.AddTotal(len(next))
+			results = append(results, f.scanCandidates(ctx, log, next, tracker, limit, emit)...)
+		}
+		if f.config.Reverse && ctx.Err() == nil {
+			next := f.reverseCandidates(ctx, log, expansion, results)
+			log.Debugf("Checking %d names found by reverse DNS", len(next))
+			tracker.AddTotal(len(next))
+			results = append(results, f.scanCandidates(ctx, log, next, tracker, limit, emit)...)
+		}
+		for checked := 0; checked < len(results) && ctx.Err() == nil; {
+			next := expansion.wildcardCandidates(results[checked:], f.wordlist.GetWords())
+			if f.config.MineSANs {
+				next = append(next, expansion.sanCandidates(results[checked:])...)
+			}
+			if f.config.RecursionDepth > 1 {
+				next = append(next, f.recursiveCandidates(ctx, log, expansion, results[checked:])...)
+			}
+			checked = len(results)
+			if len(next) == 0 {
+				break
+			}
+			log.Debugf("Expanding found namespaces into %d candidates", len(next))
+			tracker.AddTotal(len(next))
+			results = append(results, f.scanCandidates(ctx, log, next, tracker, limit, emit)...)
+		}
+	}
+
+	f.limitReached = limit.Reached()
+	if f.limitReached {
+		log.Warnf("Stopped after reaching the limit of %d results", f.config.MaxResults)
+	}
+}
+
+func (f *Finder) scanCandidates(ctx context.Context, log *logger.Logger, candidates []candidate, tracker *progress.Tracker, limit *resultLimit, emit func(types.Result)) []types.Result {
 	results := make([]types.Result, 0)
-	resultsChan := make(chan types.Result, len(words))
+	resultsChan := make(chan types.Result, len(candidates))
 
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, f.config.Threads)
+	delay := time.Duration(f.config.Delay) * time.Millisecond
 
-	for _, word := range words {
+	for _, c := range candidates {
 		wg.Add(1)
-		go func(w string) {
+		go func(c candidate) {
 			defer wg.Done()
-			semaphore <- struct{}{}
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
 			defer func() { <-semaphore }()
 
-			subdomain := w + "." + f.config.Domain
-			result := f.checkSubdomain(subdomain)
+			// Candidates still queued when the scan is cancelled or the
+			// result limit is hit are skipped
+			if ctx.Err() != nil {
+				return
+			}
+
+			// Each worker pauses before its next subdomain
+			if delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			// A check cut short by cancellation is incomplete, so it is
+			// not reported
+			result, err := f.checkSubdomain(ctx, log.With("subdomain", c.name), c, tracker)
+			if err != nil {
+				log.With("subdomain", c.name).Debugf("Skipped: %v", err)
+			}
+			found := err == nil && ctx.Err() == nil && limit.take()
 
-			if result.Subdomain != "" {
+			if found {
 				resultsChan <- result
 			}
-		}(word)
+			f.notify(tracker.Increment(found), result, found)
+		}(c)
 	}
 
 	go func() {
@@ -96,70 +627,256 @@ func (f *Finder) Find() []types.Result {
 
 	for result := range resultsChan {
 		results = append(results, result)
+		emit(result)
 	}
 
 	return results
 }
 
-func (f *Finder) checkSubdomain(subdomain string) types.Result {
+func (f *Finder) notify(stats progress.Stats, result types.Result, found bool) {
+	f.callbackMu.Lock()
+	defer f.callbackMu.Unlock()
+
+	if found && f.onResult != nil {
+		f.onResult(result)
+	}
+	if f.onProgress != nil {
+		f.onProgress(stats)
+	}
+}
+
+// aaaaResolver is implemented by resolvers that can list a name's IPv6
+// addresses.
+type aaaaResolver interface {
+	ResolveAAAA(domain string) ([]string, error)
+}
+
+// Reasons checkSubdomain leaves a candidate out of the results.
+var (
+	errInvalidName = errors.New("invalid name")
+	errUnresolved  = errors.New("does not resolve")
+	errExternal    = errors.New("hosted outside the owned ranges")
+	errWildcard    = errors.New("wildcard DNS answer")
+	errParked      = errors.New("parked page")
+	errNoHTTP      = errors.New("no HTTP response")
+	errNoResponse  = errors.New("no HTTP, port or TLS response")
+)
+
+// checkSubdomain runs every enabled check on c. Candidates the inclusion
+// flags leave out return an error saying why.
+func (f *Finder) checkSubdomain(ctx context.Context, log *logger.Logger, c candidate, tracker *progress.Tracker) (types.Result, error) {
+	subdomain := c.name
+	ctx, span := tracer.Start(ctx, "subdomain", trace.WithAttributes(attribute.String("subdomain", subdomain)))
+	defer span.End()
+
 	startTime := time.Now()
 	result := types.Result{
 		Subdomain: subdomain,
+		Source:    c.source,
+		Depth:     c.depth,
 		Timestamp: startTime,
 		Metadata:  make(map[string]interface{}),
 	}
+	if f.traceID != "" {
+		result.Metadata["trace_id"] = f.traceID
+	}
 
-	// DNS Resolution
-	ip, err := f.dns.Resolve(subdomain)
+	// IDN names are resolved and probed as punycode but reported in Unicode
+	asciiName, err := idn.ToASCII(subdomain)
 	if err != nil {
-		return types.Result{}
+		return types.Result{}, fmt.Errorf("%w: %v", errInvalidName, err)
+	}
+	if asciiName != subdomain || strings.Contains(asciiName, "xn--") {
+		result.Subdomain = idn.ToUnicode(asciiName)
+		result.Metadata["punycode"] = asciiName
+	}
+	subdomain = asciiName
+
+	// DNS Resolution
+	ip := subdomain
+	resolved := true
+	if !f.directHosts() {
+		stage := startStage(ctx, "dns")
+		address, records, err := f.resolveRecords(ctx, subdomain)
+		endStage(stage, err)
+		switch {
+		case err == nil:
+			ip = address
+		case f.config.KeepUnresolved && ctx.Err() == nil:
+			// Kept for the HTTP check, which may still reach the name
+			// through the system resolver or a proxy
+			ip, resolved = "", false
+			result.Metadata["dns_error"] = err.Error()
+		default:
+			return types.Result{}, fmt.Errorf("%w: %v", errUnresolved, err)
+		}
+
+		// An alias whose target has no address is kept without an IP
+		if resolved && ip == "" {
+			if records != nil && len(records.CNAME) > 0 {
+				result.Metadata["cname"] = records.CNAME[0]
+			} else if resolver, ok := f.dns.(cnameResolver); ok && f.wait(ctx) == nil {
+				if target, err := resolver.ResolveCNAME(subdomain); err == nil {
+					result.Metadata["cname"] = strings.TrimSuffix(target, ".")
+				}
+			}
+		}
+
+		// AAAA records are listed even when the host also has an A record,
+		// which Result.IP prefers. A name that did not resolve has none.
+		if resolved {
+			switch {
+			case f.config.DNSRecords:
+				result.DNS = f.lookupRecords(ctx, subdomain)
+			case records != nil:
+				if len(records.AAAA) > 0 {
+					result.DNS = &types.DNSInfo{AAAARecords: records.AAAA}
+				}
+			default:
+				if resolver, ok := f.dns.(aaaaResolver); ok && f.wait(ctx) == nil {
+					if addresses, err := resolver.ResolveAAAA(subdomain); err == nil {
+						result.DNS = &types.DNSInfo{AAAARecords: addresses}
+					}
+				}
+			}
+		}
 	}
 	result.IP = ip
+	result.Hosting = hosting(ip, f.config.OwnedRanges)
+	if f.locator != nil {
+		if addr := net.ParseIP(ip); addr != nil {
+			if location, err := f.locator.Locate(addr); err == nil {
+				result.GeoLocation = location
+			} else {
+				log.Debugf("GeoIP lookup failed: %v", err)
+			}
+		}
+	}
+	if f.config.ExcludeExternal && result.Hosting == hostingExternal {
+		return types.Result{}, fmt.Errorf("%w: %s", errExternal, ip)
+	}
+
+	// Subdomains sharing an address take turns once it is at its limit.
+	// Unresolved names are limited on their own.
+	limitKey := ip
+	if limitKey == "" {
+		limitKey = subdomain
+	}
+	release, err := f.hostLimit.acquire(ctx, limitKey)
+	if err != nil {
+		return types.Result{}, err
+	}
+	defer release()
 
 	// HTTP Check
-	status, response := f.http.Check(subdomain)
-	result.Status = status
-	result.Response = response
+	stage := startStage(ctx, "http")
+	fetchStart := time.Now()
+	httpResponse := f.fetch(ctx, subdomain)
+	if httpResponse != nil {
+		tracker.ObserveResponseTime(time.Since(fetchStart))
+	}
+	result.Status, result.Response = f.http.Describe(httpResponse)
+	for _, hop := range f.followRedirects(ctx, httpResponse) {
+		result.Redirects = append(result.Redirects, types.Redirect{
+			URL:        hop.URL,
+			StatusCode: hop.StatusCode,
+			Location:   hop.Location,
+		})
+	}
+	endStage(stage, nil)
+
+	// Wildcard DNS answers every name the same way
+	if f.wildcard.matches(subdomain, ip, httpResponse) {
+		if !f.config.KeepWildcard {
+			return types.Result{}, fmt.Errorf("%w: %s", errWildcard, ip)
+		}
+		result.WildcardDNS = true
+	}
+	// Unresolved names are only kept when HTTP reaches them
+	if httpResponse == nil && (f.config.RequireHTTP || !resolved) {
+		return types.Result{}, errNoHTTP
+	}
+
+	// Parked Domain Classification
+	if httpResponse != nil {
+		result.Title = httpResponse.Title
+		result.Headers = responseHeaders(httpResponse)
+		result.Cookies = responseCookies(httpResponse)
+		classification := f.parking.Classify(httpResponse.Title, httpResponse.Body)
+		result.Parked = classification.Parked
+		result.ParkingProvider = classification.Provider
+		if f.config.ExcludeParked && result.Parked {
+			return types.Result{}, fmt.Errorf("%w: %s", errParked, result.ParkingProvider)
+		}
+
+		if f.config.ExtractLinks {
+			result.Endpoints = http.ExtractLinks(httpResponse.URL, httpResponse.Body, f.linkScope(subdomain))
+		}
+	}
 
 	// Port Scanning
-	portResult := f.portScanner.QuickScan(ip)
-	if portResult != nil {
+	if ip != "" && (len(f.ports) > 0 || len(f.udpPorts) > 0) {
+		stage = startStage(ctx, "ports")
+		var scans []*portscanner.ScanResult
+		if len(f.ports) > 0 {
+			scans = append(scans, f.portCache.scan(ip, func(ip string) *portscanner.ScanResult {
+				return f.scanHost(ctx, ip, f.ports, portscanner.TCP)
+			}))
+		}
+		if len(f.udpPorts) > 0 {
+			scans = append(scans, f.portCache.scan("udp/"+ip, func(string) *portscanner.ScanResult {
+				return f.scanHost(ctx, ip, f.udpPorts, portscanner.UDP)
+			}))
+		}
+		endStage(stage, nil)
+
+		// UDP ports that stayed silent are kept as open|filtered
 		result.Ports = make([]types.PortInfo, 0)
-		for _, port := range portResult.Ports {
-			if port.State == "open" {
-				result.Ports = append(result.Ports, types.PortInfo{
-					Port:     port.Port,
-					Protocol: port.Protocol,
-					State:    port.State,
-					Service:  port.Service,
-					Banner:   port.Banner,
-					Version:  "",
-				})
+		for _, portResult := range scans {
+			if portResult == nil {
+				continue
+			}
+			for _, port := range portResult.Ports {
+				if port.State == portscanner.StateOpen || port.State == portscanner.StateOpenFiltered {
+					result.Ports = append(result.Ports, types.PortInfo{
+						Port:     port.Port,
+						Protocol: port.Protocol,
+						State:    port.State,
+						Service:  port.Service,
+						Banner:   port.Banner,
+						Version:  port.Version,
+					})
+				}
 			}
 		}
 	}
 
+	// Alternate Web Ports
+	if len(result.Ports) > 0 {
+		stage = startStage(ctx, "web-ports")
+		result.WebServices = f.probeWebPorts(ctx, subdomain, result.Ports)
+		endStage(stage, nil)
+	}
+
 	// SSL Analysis
-	if sslResult, err := f.sslAnalyzer.Analyze(subdomain, 443); err == nil {
-		result.SSL = &types.SSLInfo{
-			Valid:              sslResult.IsSecure,
-			Expired:            sslResult.Certificate.IsExpired,
-			ExpiresSoon:        sslResult.Certificate.IsExpiringSoon,
-			DaysUntilExpiry:    sslResult.Certificate.DaysUntilExpiry,
-			Issuer:             sslResult.Certificate.Issuer,
-			Subject:            sslResult.Certificate.Subject,
-			SerialNumber:       sslResult.Certificate.SerialNumber,
-			SignatureAlgorithm: sslResult.Certificate.SignatureAlgorithm,
-			PublicKeyAlgorithm: sslResult.Certificate.PublicKeyAlgorithm,
-			Grade:              sslResult.Grade,
-			Vulnerabilities:    sslResult.Certificate.Vulnerabilities,
-			NotBefore:          sslResult.Certificate.NotBefore,
-			NotAfter:           sslResult.Certificate.NotAfter,
-		}
+	stage = startStage(ctx, "ssl")
+	sslResult, sslServices := f.analyzeSSLPorts(ctx, log, subdomain, f.sslTargets(result.Ports))
+	endStage(stage, nil)
+	if sslResult != nil {
+		result.SSL = sslInfo(sslResult)
+	}
+	result.SSLServices = sslServices
+
+	// Explicit hosts skip DNS, so an answer is the only sign one exists
+	if f.directHosts() && httpResponse == nil && countOpen(result.Ports) == 0 && result.SSL == nil && len(result.SSLServices) == 0 {
+		return types.Result{}, errNoResponse
 	}
 
 	// Technology Detection
-	if techResult, err := f.techDetector.Detect("https://" + subdomain); err == nil {
+	stage = startStage(ctx, "techdetect")
+	techResult, err := f.detectTech(ctx, "https://"+subdomain)
+	endStage(stage, err)
+	if err == nil {
 		result.Technologies = make([]types.Technology, 0)
 		for _, tech := range techResult.Technologies {
 			result.Technologies = append(result.Technologies, types.Technology{
@@ -172,12 +889,57 @@ func (f *Finder) checkSubdomain(subdomain string) types.Result {
 			})
 		}
 		result.Server = techResult.Server
+	} else {
+		log.Debugf("Technology detection failed: %v", err)
 	}
 
 	// Vulnerability Scanning
-	if vulns, err := f.vulnScanner.ScanURL("https://" + subdomain); err == nil {
-		result.Vulnerabilities = make([]types.Vulnerability, 0)
-		for _, vuln := range vulns {
+	result.Vulnerabilities = make([]types.Vulnerability, 0)
+	if f.scansURLs() {
+		stage = startStage(ctx, "vuln")
+		vulns, err := f.scanURL(ctx, "https://"+subdomain)
+		endStage(stage, err)
+		if err == nil {
+			for _, vuln := range vulns {
+				result.Vulnerabilities = append(result.Vulnerabilities, types.Vulnerability{
+					Name:        vuln.Name,
+					Severity:    vuln.Severity,
+					Description: vuln.Description,
+					CVSS:        vuln.CVSS,
+					CVE:         vuln.CVE,
+					Solution:    vuln.Solution,
+					References:  vuln.References,
+					Evidence:    vuln.Evidence,
+				})
+			}
+		} else {
+			log.Debugf("Vulnerability scan failed: %v", err)
+		}
+	}
+
+	// JSONP on extracted API endpoints
+	if checker, ok := f.vulnScanner.(jsonpChecker); ok && f.config.Vuln {
+		if endpoints := apiEndpoints(result.Endpoints); len(endpoints) > 0 {
+			for _, vuln := range checker.CheckJSONP(ctx, endpoints) {
+				result.Vulnerabilities = append(result.Vulnerabilities, types.Vulnerability{
+					Name:        vuln.Name,
+					Severity:    vuln.Severity,
+					Description: vuln.Description,
+					Solution:    vuln.Solution,
+					Evidence:    vuln.Evidence,
+				})
+			}
+		}
+	}
+
+	// End-of-Life check for versioned technologies. The vulnerability scan
+	// already checks the Server header most of them are detected from.
+	var banner string
+	if httpResponse != nil {
+		banner = strings.Join(append(httpResponse.Headers["Server"], httpResponse.Headers["X-Powered-By"]...), " ")
+	}
+	for _, tech := range result.Technologies {
+		if vuln := f.vulnScanner.CheckTechnology(tech.Name, tech.Version, banner); vuln != nil && !hasVulnerability(result.Vulnerabilities, vuln.Name) {
 			result.Vulnerabilities = append(result.Vulnerabilities, types.Vulnerability{
 				Name:        vuln.Name,
 				Severity:    vuln.Severity,
@@ -186,16 +948,129 @@ func (f *Finder) checkSubdomain(subdomain string) types.Result {
 				CVE:         vuln.CVE,
 				Solution:    vuln.Solution,
 				References:  vuln.References,
+				Evidence:    vuln.Evidence,
+			})
+		}
+	}
+
+	// Default Page Detection
+	if httpResponse != nil {
+		pageType, vuln := f.vulnScanner.CheckDefaultPage(httpResponse.Title, httpResponse.Body)
+		if vuln != nil {
+			result.DefaultPage = pageType
+			result.Vulnerabilities = append(result.Vulnerabilities, types.Vulnerability{
+				Name:        vuln.Name,
+				Severity:    vuln.Severity,
+				Description: vuln.Description,
+				Solution:    vuln.Solution,
+				Evidence:    vuln.Evidence,
 			})
 		}
 	}
 
+	// TLS 1.3 Early Data
+	if result.SSL != nil && result.SSL.EarlyData {
+		result.Vulnerabilities = append(result.Vulnerabilities, types.Vulnerability{
+			Name:        "TLS 0-RTT Early Data Enabled",
+			Severity:    "Low",
+			Description: "Session tickets allow TLS 1.3 early data, which an attacker can replay to repeat a request",
+			Solution:    "Disable 0-RTT or accept early data only for idempotent requests",
+			Evidence:    fmt.Sprintf("max_early_data_size %d", sslResult.Resumption.MaxEarlyData),
+		})
+	}
+
+	// Revoked Certificate
+	if result.SSL != nil && result.SSL.Revoked {
+		result.Vulnerabilities = append(result.Vulnerabilities, types.Vulnerability{
+			Name:        "Revoked SSL Certificate",
+			Severity:    "High",
+			Description: "The certificate served by the host has been revoked by its issuer",
+			Solution:    "Replace the certificate with a newly issued one",
+			Evidence:    "serial " + result.SSL.SerialNumber,
+		})
+	}
+
+	// Off-Domain Redirect
+	if finalHost, ok := offDomainRedirect(subdomain, result.Redirects); ok {
+		result.Vulnerabilities = append(result.Vulnerabilities, f.redirectFinding(subdomain, finalHost, result.Redirects))
+	}
+
+	// Subdomain Takeover
+	if f.config.Takeover {
+		if vuln := f.checkTakeover(ctx, subdomain, httpResponse); vuln != nil {
+			result.Vulnerabilities = append(result.Vulnerabilities, *vuln)
+		}
+	}
+
+	// Homograph Detection
+	if indicator := idn.HomographIndicator(subdomain); indicator != "" {
+		result.Vulnerabilities = append(result.Vulnerabilities, types.Vulnerability{
+			Name:        "Possible Homograph Subdomain",
+			Severity:    "Medium",
+			Description: "Punycode subdomain renders as look-alike characters and may be used for phishing",
+			Solution:    "Verify the subdomain is legitimate and monitor for look-alike registrations",
+			Evidence:    indicator,
+		})
+	}
+
+	result.Vulnerabilities = mergeVulnerabilities(result.Vulnerabilities)
+
 	// Risk Assessment
 	result.RiskLevel = f.assessRisk(result)
 	result.Confidence = f.calculateConfidence(result)
+	result.Tags = tags.Merge(autoTags(result, httpResponse), f.tagRules.Apply(result)...)
 	result.ResponseTime = time.Since(startTime)
+	log.Debugf("Checked in %s: risk %s, %d vulnerabilities", result.ResponseTime.Round(time.Millisecond), result.RiskLevel, len(result.Vulnerabilities))
+
+	return result, nil
+}
 
-	return result
+// scansURLs reports whether anything the vulnerability scanner probes for
+// is enabled. The vuln checks are opt-in since they send attack payloads.
+func (f *Finder) scansURLs() bool {
+	return f.config.Vuln || f.config.ContentDiscovery || f.config.ActiveVuln || len(f.config.VulnSignatures) > 0
+}
+
+// scanPorts expands Config.Ports, a portscanner.SelectPorts value, into
+// the TCP ports dialed on each host, minus the excluded ones.
+func scanPorts(spec string, excluded []int) []int {
+	ports, err := portscanner.SelectPorts(spec)
+	if err != nil {
+		return nil
+	}
+	return withoutPorts(ports, excluded)
+}
+
+// udpPorts returns the UDP ports probed on each host when enabled.
+func udpPorts(enabled bool, excluded []int) []int {
+	if !enabled {
+		return nil
+	}
+	return withoutPorts(portscanner.UDPPorts(), excluded)
+}
+
+func withoutPorts(ports, excluded []int) []int {
+	skip := make(map[int]bool, len(excluded))
+	for _, port := range excluded {
+		skip[port] = true
+	}
+	kept := ports[:0]
+	for _, port := range ports {
+		if !skip[port] {
+			kept = append(kept, port)
+		}
+	}
+	return kept
+}
+
+// newScanID returns a short random id that ties a scan's log entries
+// together.
+func newScanID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405")
+	}
+	return hex.EncodeToString(b)
 }
 
 func (f *Finder) assessRisk(result types.Result) string {
@@ -229,7 +1104,7 @@ func (f *Finder) assessRisk(result types.Result) string {
 	}
 
 	// Check open ports
-	if len(result.Ports) > 10 {
+	if countOpen(result.Ports) > 10 {
 		riskScore += 3
 	}
 
@@ -241,6 +1116,11 @@ func (f *Finder) assessRisk(result types.Result) string {
 		riskScore += 5
 	}
 
+	// Parked pages are low-value noise regardless of other signals
+	if result.Parked {
+		return "info"
+	}
+
 	if riskScore >= 15 {
 		return "high"
 	} else if riskScore >= 8 {
@@ -251,6 +1131,26 @@ func (f *Finder) assessRisk(result types.Result) string {
 	return "info"
 }
 
+func hasVulnerability(vulns []types.Vulnerability, name string) bool {
+	for _, vuln := range vulns {
+		if vuln.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// countOpen counts the ports that answered, leaving out open|filtered UDP.
+func countOpen(ports []types.PortInfo) int {
+	count := 0
+	for _, port := range ports {
+		if port.Open() {
+			count++
+		}
+	}
+	return count
+}
+
 func (f *Finder) calculateConfidence(result types.Result) int {
 	confidence := 50
 
@@ -0,0 +1,427 @@
+package finder
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"subdomain-finder/internal/dns"
+	"subdomain-finder/internal/http"
+	"subdomain-finder/internal/portscanner"
+	"subdomain-finder/internal/progress"
+	"subdomain-finder/internal/techdetect"
+	"subdomain-finder/internal/types"
+	"subdomain-finder/internal/vulnscanner"
+)
+
+func testResolver() *mockResolver {
+	return &mockResolver{addresses: map[string]string{
+		"www.example.com":  "192.0.2.1",
+		"api.example.com":  "192.0.2.1",
+		"mail.example.com": "192.0.2.2",
+	}}
+}
+
+func testHTTPChecker() *mockHTTPChecker {
+	return &mockHTTPChecker{responses: map[string]*http.HTTPResponse{
+		"www.example.com": {URL: "https://www.example.com", StatusCode: 200, Title: "Home"},
+		"api.example.com": {URL: "https://api.example.com", StatusCode: 403, Title: "Forbidden"},
+	}}
+}
+
+func byName(results []types.Result) map[string]types.Result {
+	named := make(map[string]types.Result, len(results))
+	for _, result := range results {
+		named[result.Subdomain] = result
+	}
+	return named
+}
+
+func TestFindWithOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{
+			name: "resolved names",
+			want: []string{"api.example.com", "mail.example.com", "www.example.com"},
+		},
+		{
+			name:   "require HTTP",
+			config: Config{RequireHTTP: true},
+			want:   []string{"api.example.com", "www.example.com"},
+		},
+		{
+			name:   "explicit hosts skip DNS",
+			config: Config{Hosts: []string{"www.example.com", "other.example.net"}},
+			want:   []string{"www.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Domain = "example.com"
+			config.Wordlist = writeWordlist(t, "www", "api", "mail", "missing")
+			config.Threads = 4
+			resolver := testResolver()
+
+			finder := NewFinderWithOptions(config, mockOptions(Options{
+				Resolver:    resolver,
+				HTTPChecker: testHTTPChecker(),
+			}))
+			var got []string
+			for _, result := range finder.Find() {
+				got = append(got, result.Subdomain)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+
+			if len(config.Hosts) > 0 && len(resolver.calls) > 0 {
+				t.Errorf("resolved %v for explicit hosts", resolver.calls)
+			}
+		})
+	}
+}
+
+func TestFindScansSharedAddressOnce(t *testing.T) {
+	scanner := &mockPortScanner{open: map[string][]int{
+		"192.0.2.1": {22, 443},
+		"192.0.2.2": {25},
+	}}
+	finder := NewFinderWithOptions(Config{
+		Domain:   "example.com",
+		Wordlist: writeWordlist(t, "www", "api", "mail"),
+		Threads:  4,
+		Ports:    "22,25,443",
+	}, mockOptions(Options{Resolver: testResolver(), PortScanner: scanner}))
+	results := byName(finder.Find())
+
+	if count := scanner.scanCount("192.0.2.1"); count != 1 {
+		t.Errorf("192.0.2.1 scanned %d times, want 1", count)
+	}
+	if count := scanner.scanCount("192.0.2.2"); count != 1 {
+		t.Errorf("192.0.2.2 scanned %d times, want 1", count)
+	}
+	for _, name := range []string{"www.example.com", "api.example.com"} {
+		if ports := results[name].Ports; len(ports) != 2 || ports[0].Port != 22 || ports[1].Port != 443 {
+			t.Errorf("%s ports = %+v, want 22 and 443", name, ports)
+		}
+	}
+}
+
+func TestFindReportsOpenFilteredUDP(t *testing.T) {
+	scanner := &mockPortScanner{
+		open:   map[string][]int{"192.0.2.1": {53}},
+		silent: map[string][]int{"192.0.2.1": {161}, "192.0.2.2": {123}},
+	}
+	finder := NewFinderWithOptions(Config{
+		Domain:   "example.com",
+		Wordlist: writeWordlist(t, "www", "mail"),
+		Threads:  2,
+		Ports:    "none",
+		UDP:      true,
+	}, mockOptions(Options{Resolver: testResolver(), PortScanner: scanner}))
+	results := byName(finder.Find())
+
+	tests := []struct {
+		subdomain string
+		expected  []types.PortInfo
+	}{
+		{"www.example.com", []types.PortInfo{
+			{Port: 53, Protocol: "udp", State: portscanner.StateOpen},
+			{Port: 161, Protocol: "udp", State: portscanner.StateOpenFiltered},
+		}},
+		{"mail.example.com", []types.PortInfo{
+			{Port: 123, Protocol: "udp", State: portscanner.StateOpenFiltered},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subdomain, func(t *testing.T) {
+			ports := results[tt.subdomain].Ports
+			sort.Slice(ports, func(i, j int) bool { return ports[i].Port < ports[j].Port })
+			if !reflect.DeepEqual(ports, tt.expected) {
+				t.Errorf("ports = %+v, want %+v", ports, tt.expected)
+			}
+		})
+	}
+}
+
+// slowPortScanner makes every port scan take at least delay.
+type slowPortScanner struct {
+	mockPortScanner
+	delay time.Duration
+}
+
+func (s *slowPortScanner) ScanHost(host string, ports []int, protocol portscanner.Protocol) *portscanner.ScanResult {
+	time.Sleep(s.delay)
+	return s.mockPortScanner.ScanHost(host, ports, protocol)
+}
+
+func TestFindObservesHTTPResponseTime(t *testing.T) {
+	const delay = 200 * time.Millisecond
+	finder := NewFinderWithOptions(Config{
+		Domain:   "example.com",
+		Wordlist: writeWordlist(t, "www", "api", "mail"),
+		Threads:  3,
+		Ports:    "22",
+	}, mockOptions(Options{
+		Resolver:    testResolver(),
+		HTTPChecker: testHTTPChecker(),
+		PortScanner: &slowPortScanner{delay: delay},
+	}))
+
+	var last progress.Stats
+	finder.OnProgress(func(stats progress.Stats) { last = stats })
+	if results := finder.Find(); len(results) != 3 {
+		t.Fatalf("found %d results, want 3", len(results))
+	}
+
+	// mail resolves but serves no HTTP, so only www and api are sampled
+	if last.Response.Samples != 2 {
+		t.Errorf("response samples = %d, want 2", last.Response.Samples)
+	}
+	if last.Response.Max >= delay {
+		t.Errorf("response max = %s includes the %s port scan", last.Response.Max, delay)
+	}
+}
+
+func TestFindReportsEOLOnce(t *testing.T) {
+	eol := vulnscanner.DefaultEOLDatabase()
+	headerFinding := eol.Check("apache", "2.2.15", time.Now())
+	if headerFinding == nil {
+		t.Fatal("Apache 2.2.15 is not end-of-life in the default database")
+	}
+
+	techDetector := &mockTechDetector{technologies: map[string][]techdetect.Technology{
+		"www.example.com": {{Name: "Apache", Version: "2.2.15", Confidence: 100}},
+		"api.example.com": {{Name: "Apache", Version: "2.2.15", Confidence: 100}},
+	}}
+	// The vulnerability scan reports www's Server header; api's is hidden
+	vulnScanner := &mockVulnScanner{
+		vulns: map[string][]vulnscanner.Vulnerability{"www.example.com": {*headerFinding}},
+		eol:   eol,
+	}
+
+	finder := NewFinderWithOptions(Config{
+		Domain:   "example.com",
+		Wordlist: writeWordlist(t, "www", "api"),
+		Threads:  2,
+		Vuln:     true,
+	}, mockOptions(Options{
+		Resolver:     testResolver(),
+		HTTPChecker:  testHTTPChecker(),
+		TechDetector: techDetector,
+		VulnScanner:  vulnScanner,
+	}))
+	results := byName(finder.Find())
+
+	for _, name := range []string{"www.example.com", "api.example.com"} {
+		count := 0
+		for _, vuln := range results[name].Vulnerabilities {
+			if vuln.Name == headerFinding.Name {
+				count++
+			}
+		}
+		if count != 1 {
+			t.Errorf("%s has %d %q findings, want 1", name, count, headerFinding.Name)
+		}
+	}
+}
+
+// recordsMockResolver returns the AAAA records found while resolving and
+// counts the separate AAAA lookups the finder makes.
+type recordsMockResolver struct {
+	mockResolver
+	records     map[string]*dns.Records
+	aaaaLookups atomic.Int32
+}
+
+func (r *recordsMockResolver) ResolveRecordsContext(ctx context.Context, domain string) (string, *dns.Records, error) {
+	ip, err := r.Resolve(domain)
+	if err != nil {
+		return "", nil, err
+	}
+	records := r.records[domain]
+	if records == nil {
+		records = &dns.Records{}
+	}
+	return ip, records, nil
+}
+
+func (r *recordsMockResolver) ResolveAAAA(domain string) ([]string, error) {
+	r.aaaaLookups.Add(1)
+	return nil, errMock
+}
+
+func TestFindReusesResolvedAAAARecords(t *testing.T) {
+	resolver := &recordsMockResolver{
+		mockResolver: mockResolver{addresses: testResolver().addresses},
+		records: map[string]*dns.Records{
+			"www.example.com": {A: []string{"192.0.2.1"}, AAAA: []string{"2001:db8::1"}},
+		},
+	}
+	finder := NewFinderWithOptions(Config{
+		Domain:   "example.com",
+		Wordlist: writeWordlist(t, "www", "mail"),
+		Threads:  2,
+	}, mockOptions(Options{Resolver: resolver}))
+	results := byName(finder.Find())
+
+	if dns := results["www.example.com"].DNS; dns == nil || !reflect.DeepEqual(dns.AAAARecords, []string{"2001:db8::1"}) {
+		t.Errorf("www DNS = %+v, want AAAA 2001:db8::1", dns)
+	}
+	if dns := results["mail.example.com"].DNS; dns != nil {
+		t.Errorf("mail DNS = %+v, want none", dns)
+	}
+	if lookups := resolver.aaaaLookups.Load(); lookups != 0 {
+		t.Errorf("made %d separate AAAA lookups, want 0", lookups)
+	}
+}
+
+func TestFindRiskAndConfidence(t *testing.T) {
+	techDetector := &mockTechDetector{technologies: map[string][]techdetect.Technology{
+		"www.example.com": {{Name: "nginx", Version: "1.24.0", Confidence: 100}},
+	}}
+	vulnScanner := &mockVulnScanner{vulns: map[string][]vulnscanner.Vulnerability{
+		"www.example.com": {
+			{Name: "SQL Injection", Severity: "Critical"},
+			{Name: "Reflected XSS", Severity: "High"},
+		},
+		"api.example.com": {
+			{Name: "Missing Security Header", Severity: "Low"},
+		},
+	}}
+
+	finder := NewFinderWithOptions(Config{
+		Domain:   "example.com",
+		Wordlist: writeWordlist(t, "www", "api", "mail"),
+		Threads:  4,
+		Vuln:     true,
+	}, mockOptions(Options{
+		Resolver:     testResolver(),
+		HTTPChecker:  testHTTPChecker(),
+		TechDetector: techDetector,
+		VulnScanner:  vulnScanner,
+	}))
+	results := byName(finder.Find())
+
+	tests := []struct {
+		name       string
+		risk       string
+		confidence int
+		vulns      int
+	}{
+		// Critical and High findings
+		{name: "www.example.com", risk: "high", confidence: 95, vulns: 2},
+		// A Low finding and a 403
+		{name: "api.example.com", risk: "low", confidence: 85, vulns: 1},
+		// Resolved without HTTP, so the status is N/A
+		{name: "mail.example.com", risk: "info", confidence: 85, vulns: 0},
+	}
+	for _, tt := range tests {
+		result, ok := results[tt.name]
+		if !ok {
+			t.Errorf("%s not found", tt.name)
+			continue
+		}
+		if result.RiskLevel != tt.risk {
+			t.Errorf("%s risk = %s, want %s", tt.name, result.RiskLevel, tt.risk)
+		}
+		if result.Confidence != tt.confidence {
+			t.Errorf("%s confidence = %d, want %d", tt.name, result.Confidence, tt.confidence)
+		}
+		if len(result.Vulnerabilities) != tt.vulns {
+			t.Errorf("%s has %d vulnerabilities, want %d", tt.name, len(result.Vulnerabilities), tt.vulns)
+		}
+	}
+}
+
+// benchmarkFinder builds a finder over size candidates. Half of them
+// resolve, spread over 64 addresses, and half of those answer HTTP.
+func benchmarkFinder(b *testing.B, size int, config Config) *Finder {
+	b.Helper()
+	words := make([]string, size)
+	resolver := &mockResolver{addresses: make(map[string]string)}
+	checker := &mockHTTPChecker{responses: make(map[string]*http.HTTPResponse)}
+	scanner := &mockPortScanner{open: make(map[string][]int)}
+	for i := range words {
+		words[i] = fmt.Sprintf("host%d", i)
+		name := words[i] + ".example.com"
+		if i%2 == 1 {
+			continue
+		}
+		ip := fmt.Sprintf("192.0.2.%d", i%64)
+		resolver.addresses[name] = ip
+		scanner.open[ip] = []int{80, 443}
+		if i%4 == 0 {
+			checker.responses[name] = &http.HTTPResponse{URL: "https://" + name, StatusCode: 200, Title: name}
+		}
+	}
+
+	config.Domain = "example.com"
+	config.Wordlist = writeWordlist(b, words...)
+	config.Threads = 50
+	return NewFinderWithOptions(config, mockOptions(Options{
+		Resolver:    resolver,
+		HTTPChecker: checker,
+		PortScanner: scanner,
+	}))
+}
+
+func BenchmarkFind(b *testing.B) {
+	benchmarks := []struct {
+		name   string
+		size   int
+		config Config
+	}{
+		{name: "1k", size: 1000},
+		{name: "10k", size: 10000},
+		{name: "1k-ports", size: 1000, config: Config{Ports: "quick"}},
+		{name: "1k-vuln", size: 1000, config: Config{Vuln: true}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			finder := benchmarkFinder(b, bm.size, bm.config)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if results := finder.Find(); len(results) != bm.size/2 {
+					b.Fatalf("got %d results, want %d", len(results), bm.size/2)
+				}
+			}
+		})
+	}
+}
+
+func TestFindResolvesIDNAsPunycode(t *testing.T) {
+	resolver := &mockResolver{addresses: map[string]string{"xn--caf-dma.example.com": "192.0.2.1"}}
+	finder := NewFinderWithOptions(Config{
+		Domain:   "example.com",
+		Wordlist: writeWordlist(t, "café"),
+		Threads:  1,
+	}, mockOptions(Options{Resolver: resolver}))
+	results := finder.Find()
+
+	if resolver.calls["café.example.com"] > 0 {
+		t.Error("resolved the Unicode name")
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if results[0].Subdomain != "café.example.com" || results[0].Metadata["punycode"] != "xn--caf-dma.example.com" {
+		t.Errorf("got %s with punycode %v, want café.example.com with xn--caf-dma.example.com", results[0].Subdomain, results[0].Metadata["punycode"])
+	}
+	if results[0].IP != "192.0.2.1" {
+		t.Errorf("IP = %q, want 192.0.2.1", results[0].IP)
+	}
+}
@@ -0,0 +1,67 @@
+package finder
+
+import (
+	stdhttp "net/http"
+	"strings"
+	"time"
+
+	"subdomain-finder/internal/http"
+	"subdomain-finder/internal/redact"
+	"subdomain-finder/internal/types"
+)
+
+// responseHeaders flattens the response headers, joining repeated values
+// with commas. Set-Cookie values are kept one per line since cookies may
+// contain commas, with the cookie values redacted.
+func responseHeaders(response *http.HTTPResponse) map[string]string {
+	headers := make(map[string]string, len(response.Headers))
+	for name, values := range response.Headers {
+		if stdhttp.CanonicalHeaderKey(name) == "Set-Cookie" {
+			lines := make([]string, 0, len(values))
+			for _, value := range values {
+				lines = append(lines, redact.SetCookie(value))
+			}
+			headers[name] = strings.Join(lines, "\n")
+			continue
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+func responseCookies(response *http.HTTPResponse) []types.Cookie {
+	cookies := make([]types.Cookie, 0, len(response.Cookies))
+	for _, cookie := range response.Cookies {
+		expires := cookie.Expires
+		if cookie.MaxAge > 0 {
+			expires = time.Now().Add(time.Duration(cookie.MaxAge) * time.Second)
+		}
+		value := cookie.Value
+		if value != "" {
+			value = redact.Value
+		}
+		cookies = append(cookies, types.Cookie{
+			Name:     cookie.Name,
+			Value:    value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Expires:  expires,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+			SameSite: sameSite(cookie.SameSite),
+		})
+	}
+	return cookies
+}
+
+func sameSite(mode stdhttp.SameSite) string {
+	switch mode {
+	case stdhttp.SameSiteLaxMode:
+		return "Lax"
+	case stdhttp.SameSiteStrictMode:
+		return "Strict"
+	case stdhttp.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package finder
+
+import (
+	stdhttp "net/http"
+	"testing"
+
+	"subdomain-finder/internal/http"
+	"subdomain-finder/internal/redact"
+)
+
+func TestResponseHeadersRedactsCookies(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []string
+		want   string
+	}{
+		{"value with attributes", []string{"session=abc123; Path=/; HttpOnly"}, "session=[redacted]; Path=/; HttpOnly"},
+		{"bare value", []string{"id=42"}, "id=[redacted]"},
+		{"deleted cookie", []string{"session=; Max-Age=0"}, "session=; Max-Age=0"},
+		{"one per line", []string{"a=1; Secure", "b=2"}, "a=[redacted]; Secure\nb=[redacted]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &http.HTTPResponse{Headers: map[string][]string{
+				"Set-Cookie": tt.header,
+				"Server":     {"nginx"},
+			}}
+			headers := responseHeaders(response)
+			if got := headers["Set-Cookie"]; got != tt.want {
+				t.Errorf("Set-Cookie = %q, want %q", got, tt.want)
+			}
+			if headers["Server"] != "nginx" {
+				t.Errorf("Server = %q, want nginx", headers["Server"])
+			}
+		})
+	}
+}
+
+func TestResponseCookiesRedactsValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		cookie stdhttp.Cookie
+		want   string
+	}{
+		{"session token", stdhttp.Cookie{Name: "session", Value: "abc123", Secure: true, HttpOnly: true}, redact.Value},
+		{"empty value", stdhttp.Cookie{Name: "session", MaxAge: -1}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookies := responseCookies(&http.HTTPResponse{Cookies: []*stdhttp.Cookie{&tt.cookie}})
+			if len(cookies) != 1 {
+				t.Fatalf("got %d cookies, want 1", len(cookies))
+			}
+			got := cookies[0]
+			if got.Value != tt.want {
+				t.Errorf("Value = %q, want %q", got.Value, tt.want)
+			}
+			if got.Name != tt.cookie.Name || got.Secure != tt.cookie.Secure || got.HttpOnly != tt.cookie.HttpOnly {
+				t.Errorf("flags = %+v, want those of %+v", got, tt.cookie)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package finder
+
+import (
+	"context"
+	"sync"
+)
+
+// hostLimit caps how many subdomains resolving to the same address are
+// checked at once. The transport's own per-host cap is keyed by host name,
+// so it does not stop many subdomains of one origin from piling up.
+type hostLimit struct {
+	max   int
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// newHostLimit returns nil, which never blocks, when max is not positive.
+func newHostLimit(max int) *hostLimit {
+	if max <= 0 {
+		return nil
+	}
+	return &hostLimit{max: max, slots: make(map[string]chan struct{})}
+}
+
+// acquire blocks until address has a free slot and returns its release,
+// or returns ctx's error if the scan is cancelled first.
+func (l *hostLimit) acquire(ctx context.Context, address string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	slots, ok := l.slots[address]
+	if !ok {
+		slots = make(chan struct{}, l.max)
+		l.slots[address] = slots
+	}
+	l.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
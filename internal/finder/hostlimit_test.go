@@ -0,0 +1,66 @@
+package finder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHostLimitAcquire(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     int
+		held    int
+		wantErr bool
+	}{
+		{"no limit", 0, 5, false},
+		{"free slot", 2, 1, false},
+		{"full, cancelled while waiting", 2, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := newHostLimit(tt.max)
+			for i := 0; i < tt.held; i++ {
+				if _, err := limit.acquire(context.Background(), "192.0.2.1"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			release, err := limit.acquire(ctx, "192.0.2.1")
+			if tt.wantErr {
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("acquire() error = %v, want deadline exceeded", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("acquire() error = %v", err)
+			}
+			release()
+		})
+	}
+}
+
+func TestHostLimitRelease(t *testing.T) {
+	limit := newHostLimit(1)
+	release, err := limit.acquire(context.Background(), "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Other addresses have slots of their own
+	if _, err := limit.acquire(context.Background(), "192.0.2.2"); err != nil {
+		t.Fatalf("acquire() on another address error = %v", err)
+	}
+
+	release()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := limit.acquire(ctx, "192.0.2.1"); err != nil {
+		t.Fatalf("acquire() after release error = %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package finder
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"subdomain-finder/internal/vulnscanner"
+)
+
+// maxJSONPEndpoints caps how many extracted endpoints are probed per host.
+const maxJSONPEndpoints = 10
+
+// jsonpChecker is implemented by vulnerability scanners that can probe
+// extracted endpoints for JSONP callback reflection.
+type jsonpChecker interface {
+	CheckJSONP(ctx context.Context, endpoints []string) []vulnscanner.Vulnerability
+}
+
+// apiEndpoints picks the extracted links that look like data endpoints:
+// API paths, .json files and links that already take a callback.
+func apiEndpoints(endpoints []string) []string {
+	var picked []string
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			continue
+		}
+		path := strings.ToLower(u.Path)
+		query := u.Query()
+		if strings.Contains(path, "/api") || strings.HasSuffix(path, ".json") ||
+			query.Has("callback") || query.Has("jsonp") {
+			picked = append(picked, endpoint)
+		}
+		if len(picked) == maxJSONPEndpoints {
+			break
+		}
+	}
+	return picked
+}
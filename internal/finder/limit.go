@@ -0,0 +1,48 @@
+package finder
+
+import (
+	"context"
+	"sync"
+)
+
+// resultLimit caps how many results one scan keeps. The scan goes on until
+// a result beyond the cap turns up, which is refused and cancels the rest,
+// so a scan finding exactly max results is not reported as partial. A max
+// of zero means no limit.
+type resultLimit struct {
+	max     int
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	count   int
+	refused bool
+}
+
+func newResultLimit(max int, cancel context.CancelFunc) *resultLimit {
+	return &resultLimit{max: max, cancel: cancel}
+}
+
+// take reports whether another result may be kept.
+func (l *resultLimit) take() bool {
+	if l.max <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.count >= l.max {
+		if !l.refused {
+			l.refused = true
+			l.cancel()
+		}
+		return false
+	}
+	l.count++
+	return true
+}
+
+// Reached reports whether a result was refused, so the results are partial.
+func (l *resultLimit) Reached() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.refused
+}
@@ -0,0 +1,74 @@
+package finder
+
+import "testing"
+
+func TestResultLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		max         int
+		takes       int
+		kept        int
+		reached     bool
+		cancelCalls int
+	}{
+		{"no limit", 0, 5, 5, false, 0},
+		{"under the limit", 3, 2, 2, false, 0},
+		{"exactly the limit", 3, 3, 3, false, 0},
+		{"one refused", 3, 4, 3, true, 1},
+		{"many refused", 3, 6, 3, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cancelCalls := 0
+			limit := newResultLimit(tt.max, func() { cancelCalls++ })
+
+			kept := 0
+			for i := 0; i < tt.takes; i++ {
+				if limit.take() {
+					kept++
+				}
+			}
+
+			if kept != tt.kept {
+				t.Errorf("kept %d results, want %d", kept, tt.kept)
+			}
+			if limit.Reached() != tt.reached {
+				t.Errorf("Reached() = %v, want %v", limit.Reached(), tt.reached)
+			}
+			if cancelCalls != tt.cancelCalls {
+				t.Errorf("cancel called %d times, want %d", cancelCalls, tt.cancelCalls)
+			}
+		})
+	}
+}
+
+func TestFindResultLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxResults int
+		found      int
+		reached    bool
+	}{
+		{"all found fit", 3, 3, false},
+		{"one over", 2, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := NewFinderWithOptions(Config{
+				Domain:     "example.com",
+				Wordlist:   writeWordlist(t, "www", "api", "mail"),
+				Threads:    1,
+				MaxResults: tt.maxResults,
+			}, mockOptions(Options{Resolver: testResolver()}))
+
+			if results := finder.Find(); len(results) != tt.found {
+				t.Errorf("found %d results, want %d", len(results), tt.found)
+			}
+			if finder.ResultLimitReached() != tt.reached {
+				t.Errorf("ResultLimitReached() = %v, want %v", finder.ResultLimitReached(), tt.reached)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package finder
+
+import (
+	"strings"
+
+	"subdomain-finder/internal/types"
+)
+
+// mergeVulnerabilities collapses findings with the same name and severity
+// into one entry whose Indicators list every distinct piece of evidence.
+func mergeVulnerabilities(vulns []types.Vulnerability) []types.Vulnerability {
+	merged := make([]types.Vulnerability, 0, len(vulns))
+	index := make(map[string]int)
+
+	for _, vuln := range vulns {
+		key := strings.ToLower(vuln.Name) + "|" + strings.ToLower(vuln.Severity)
+		indicator := vuln.Evidence
+		if indicator == "" {
+			indicator = vuln.Description
+		}
+
+		i, exists := index[key]
+		if !exists {
+			index[key] = len(merged)
+			if indicator != "" {
+				vuln.Indicators = append(vuln.Indicators, indicator)
+			}
+			merged = append(merged, vuln)
+			continue
+		}
+
+		existing := &merged[i]
+		if indicator != "" && !containsString(existing.Indicators, indicator) {
+			existing.Indicators = append(existing.Indicators, indicator)
+		}
+		if vuln.Description != "" && !strings.Contains(existing.Description, vuln.Description) {
+			existing.Description += "; " + vuln.Description
+		}
+		for _, ref := range vuln.References {
+			if !containsString(existing.References, ref) {
+				existing.References = append(existing.References, ref)
+			}
+		}
+	}
+
+	for i := range merged {
+		if len(merged[i].Indicators) > 1 {
+			merged[i].Evidence = strings.Join(merged[i].Indicators, "; ")
+		}
+	}
+
+	return merged
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
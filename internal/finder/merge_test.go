@@ -0,0 +1,88 @@
+package finder
+
+import (
+	"reflect"
+	"testing"
+
+	"subdomain-finder/internal/types"
+)
+
+func TestMergeVulnerabilities(t *testing.T) {
+	disclosure := func(pattern, description string) types.Vulnerability {
+		return types.Vulnerability{
+			Name:        "Information Disclosure",
+			Severity:    "Medium",
+			Description: description,
+			Evidence:    `response contains "` + pattern + `"`,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		in         []types.Vulnerability
+		count      int
+		indicators []string
+		evidence   string
+	}{
+		{
+			name: "three sensitive patterns collapse to one finding",
+			in: []types.Vulnerability{
+				disclosure("password", "Password information disclosed"),
+				disclosure("api_key", "API key disclosed"),
+				disclosure("exception", "Exception information disclosed"),
+			},
+			count: 1,
+			indicators: []string{
+				`response contains "password"`,
+				`response contains "api_key"`,
+				`response contains "exception"`,
+			},
+			evidence: `response contains "password"; response contains "api_key"; response contains "exception"`,
+		},
+		{
+			name: "repeated evidence is listed once",
+			in: []types.Vulnerability{
+				disclosure("password", "Password information disclosed"),
+				disclosure("password", "Password information disclosed"),
+			},
+			count:      1,
+			indicators: []string{`response contains "password"`},
+			evidence:   `response contains "password"`,
+		},
+		{
+			name: "name and severity compared ignoring case",
+			in: []types.Vulnerability{
+				disclosure("password", "Password information disclosed"),
+				{Name: "information disclosure", Severity: "MEDIUM", Description: "Stack trace"},
+			},
+			count:      1,
+			indicators: []string{`response contains "password"`, "Stack trace"},
+			evidence:   `response contains "password"; Stack trace`,
+		},
+		{
+			name: "different severity stays separate",
+			in: []types.Vulnerability{
+				disclosure("password", "Password information disclosed"),
+				{Name: "Information Disclosure", Severity: "High", Evidence: "private key"},
+			},
+			count:      2,
+			indicators: []string{`response contains "password"`},
+			evidence:   `response contains "password"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeVulnerabilities(tt.in)
+			if len(got) != tt.count {
+				t.Fatalf("got %d findings, want %d: %+v", len(got), tt.count, got)
+			}
+			if !reflect.DeepEqual(got[0].Indicators, tt.indicators) {
+				t.Errorf("indicators = %q, want %q", got[0].Indicators, tt.indicators)
+			}
+			if got[0].Evidence != tt.evidence {
+				t.Errorf("evidence = %q, want %q", got[0].Evidence, tt.evidence)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package finder
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"subdomain-finder/internal/http"
+	"subdomain-finder/internal/portscanner"
+	"subdomain-finder/internal/ssl"
+	"subdomain-finder/internal/techdetect"
+	"subdomain-finder/internal/vulnscanner"
+)
+
+// The mocks answer from fixed tables and count their calls, so the finder's
+// orchestration runs without touching the network.
+
+var errMock = errors.New("no mock answer")
+
+type mockResolver struct {
+	mu        sync.Mutex
+	addresses map[string]string
+	calls     map[string]int
+}
+
+func (r *mockResolver) Resolve(domain string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.calls == nil {
+		r.calls = make(map[string]int)
+	}
+	r.calls[domain]++
+	if ip, ok := r.addresses[domain]; ok {
+		return ip, nil
+	}
+	return "", errMock
+}
+
+type mockHTTPChecker struct {
+	responses map[string]*http.HTTPResponse
+}
+
+func (c *mockHTTPChecker) Fetch(domain string) *http.HTTPResponse {
+	return c.responses[domain]
+}
+
+func (c *mockHTTPChecker) Describe(response *http.HTTPResponse) (string, string) {
+	return (&http.Checker{}).Describe(response)
+}
+
+func (c *mockHTTPChecker) FollowRedirects(response *http.HTTPResponse) []http.Redirect {
+	return nil
+}
+
+// mockPortScanner reports the ports listed for a host as open, and on UDP
+// scans the silent ones as open|filtered.
+type mockPortScanner struct {
+	mu     sync.Mutex
+	open   map[string][]int
+	silent map[string][]int
+	scans  map[string]int
+}
+
+func (s *mockPortScanner) ScanHost(host string, ports []int, protocol portscanner.Protocol) *portscanner.ScanResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.scans == nil {
+		s.scans = make(map[string]int)
+	}
+	s.scans[host]++
+
+	result := &portscanner.ScanResult{Host: host, TotalPorts: len(ports)}
+	for _, port := range s.open[host] {
+		for _, requested := range ports {
+			if port == requested {
+				result.Ports = append(result.Ports, portscanner.PortResult{
+					Port:     port,
+					Protocol: string(protocol),
+					State:    portscanner.StateOpen,
+				})
+				result.OpenPorts++
+			}
+		}
+	}
+	if protocol == portscanner.UDP {
+		for _, port := range s.silent[host] {
+			if slices.Contains(ports, port) {
+				result.Ports = append(result.Ports, portscanner.PortResult{
+					Port:     port,
+					Protocol: string(protocol),
+					State:    portscanner.StateOpenFiltered,
+				})
+			}
+		}
+	}
+	return result
+}
+
+func (s *mockPortScanner) scanCount(host string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scans[host]
+}
+
+type mockSSLAnalyzer struct {
+	results map[string]*ssl.SSLResult
+}
+
+func (a *mockSSLAnalyzer) Analyze(host string, port int) (*ssl.SSLResult, error) {
+	if result, ok := a.results[host]; ok && port == 443 {
+		return result, nil
+	}
+	return nil, errMock
+}
+
+type mockTechDetector struct {
+	technologies map[string][]techdetect.Technology
+}
+
+func (d *mockTechDetector) Detect(url string) (*techdetect.TechResult, error) {
+	technologies, ok := d.technologies[strings.TrimPrefix(url, "https://")]
+	if !ok {
+		return nil, errMock
+	}
+	return &techdetect.TechResult{URL: url, Technologies: technologies}, nil
+}
+
+// mockVulnScanner checks technologies against eol when it is set.
+type mockVulnScanner struct {
+	vulns map[string][]vulnscanner.Vulnerability
+	eol   *vulnscanner.EOLDatabase
+}
+
+func (s *mockVulnScanner) ScanURL(url string) ([]vulnscanner.Vulnerability, error) {
+	return s.vulns[strings.TrimPrefix(url, "https://")], nil
+}
+
+func (s *mockVulnScanner) CheckTechnology(name, version, banner string) *vulnscanner.Vulnerability {
+	if s.eol == nil {
+		return nil
+	}
+	return s.eol.CheckBanner(name, version, banner, time.Now())
+}
+
+func (s *mockVulnScanner) CheckDefaultPage(title, body string) (string, *vulnscanner.Vulnerability) {
+	return "", nil
+}
+
+func (s *mockVulnScanner) RequestsPerURL() int {
+	return 1
+}
+
+// mockOptions fills every dependency of opts left nil with an empty mock.
+func mockOptions(opts Options) Options {
+	if opts.Resolver == nil {
+		opts.Resolver = &mockResolver{}
+	}
+	if opts.HTTPChecker == nil {
+		opts.HTTPChecker = &mockHTTPChecker{}
+	}
+	if opts.PortScanner == nil {
+		opts.PortScanner = &mockPortScanner{}
+	}
+	if opts.SSLAnalyzer == nil {
+		opts.SSLAnalyzer = &mockSSLAnalyzer{}
+	}
+	if opts.TechDetector == nil {
+		opts.TechDetector = &mockTechDetector{}
+	}
+	if opts.VulnScanner == nil {
+		opts.VulnScanner = &mockVulnScanner{}
+	}
+	return opts
+}
+
+// writeWordlist writes words to a file for Config.Wordlist.
+func writeWordlist(tb testing.TB, words ...string) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(words, "\n")+"\n"), 0644); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,94 @@
+package finder
+
+import (
+	"time"
+
+	"subdomain-finder/internal/geoip"
+	"subdomain-finder/internal/http"
+	"subdomain-finder/internal/logger"
+	"subdomain-finder/internal/passive"
+	"subdomain-finder/internal/portscanner"
+	"subdomain-finder/internal/ssl"
+	"subdomain-finder/internal/techdetect"
+	"subdomain-finder/internal/vulnscanner"
+)
+
+type Resolver interface {
+	Resolve(domain string) (string, error)
+}
+
+type HTTPChecker interface {
+	Fetch(domain string) *http.HTTPResponse
+	Describe(response *http.HTTPResponse) (string, string)
+	FollowRedirects(response *http.HTTPResponse) []http.Redirect
+}
+
+type PortScanner interface {
+	ScanHost(host string, ports []int, protocol portscanner.Protocol) *portscanner.ScanResult
+}
+
+type SSLAnalyzer interface {
+	Analyze(host string, port int) (*ssl.SSLResult, error)
+}
+
+type TechDetector interface {
+	Detect(url string) (*techdetect.TechResult, error)
+}
+
+type VulnScanner interface {
+	ScanURL(url string) ([]vulnscanner.Vulnerability, error)
+	CheckTechnology(name, version, banner string) *vulnscanner.Vulnerability
+	CheckDefaultPage(title, body string) (string, *vulnscanner.Vulnerability)
+	RequestsPerURL() int
+}
+
+// Options overrides the finder's dependencies. Nil fields fall back to the
+// default implementations built from Config.
+type Options struct {
+	Resolver     Resolver
+	HTTPChecker  HTTPChecker
+	PortScanner  PortScanner
+	SSLAnalyzer  SSLAnalyzer
+	TechDetector TechDetector
+	VulnScanner  VulnScanner
+	// PassiveSources add subdomains from third-party data before active
+	// checks start. Logger receives their warnings.
+	PassiveSources []passive.Source
+	Logger         *logger.Logger
+	// Locator adds GeoIP data for each resolved address. Nil skips it.
+	Locator geoip.Locator
+}
+
+func newPassiveRunner(config Config, opts Options) *passive.Runner {
+	if len(opts.PassiveSources) == 0 {
+		return nil
+	}
+
+	passiveConfig := passive.DefaultConfig()
+	if config.PassiveConcurrency > 0 {
+		passiveConfig.Concurrency = config.PassiveConcurrency
+	}
+	if config.PassiveSourceTimeout > 0 {
+		passiveConfig.SourceTimeout = time.Duration(config.PassiveSourceTimeout) * time.Second
+	}
+	if config.PassiveTimeout > 0 {
+		passiveConfig.Timeout = time.Duration(config.PassiveTimeout) * time.Second
+	}
+	return passive.NewRunner(passiveConfig, opts.PassiveSources, opts.Logger)
+}
+
+// mergeTargets appends passively found names that the wordlist did not
+// already produce.
+func mergeTargets(targets, discovered []string) []string {
+	seen := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		seen[target] = true
+	}
+	for _, name := range discovered {
+		if !seen[name] {
+			seen[name] = true
+			targets = append(targets, name)
+		}
+	}
+	return targets
+}
@@ -0,0 +1,29 @@
+package finder
+
+import "net/netip"
+
+const (
+	hostingOwned    = "owned"
+	hostingExternal = "external"
+)
+
+// hosting reports whether ip falls inside one of the owned ranges. It is
+// empty when no ranges are configured or the resolver returned a CNAME
+// target rather than an address.
+func hosting(ip string, owned []netip.Prefix) string {
+	if len(owned) == 0 {
+		return ""
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+	for _, prefix := range owned {
+		if prefix.Contains(addr) {
+			return hostingOwned
+		}
+	}
+	return hostingExternal
+}
@@ -0,0 +1,40 @@
+package finder
+
+import (
+	"strings"
+
+	"subdomain-finder/internal/logger"
+	"subdomain-finder/internal/types"
+	"subdomain-finder/internal/wordlist"
+)
+
+// maxPermutations keeps a large wordlist times many found names from
+// queueing millions of lookups.
+const maxPermutations = 100000
+
+// permutationCandidates mutates the found subdomains with the wordlist,
+// leaving out names already checked.
+func (w *expansion) permutationCandidates(log *logger.Logger, results []types.Result, words []string) []candidate {
+	known := make([]string, 0, len(results))
+	for _, result := range results {
+		name := strings.ToLower(result.Subdomain)
+		if punycode, ok := result.Metadata["punycode"].(string); ok {
+			name = punycode
+		}
+		known = append(known, name)
+	}
+
+	var next []candidate
+	for name := range wordlist.GeneratePermutations(known, words) {
+		if w.seen[name] || !w.inScope(name) {
+			continue
+		}
+		if len(next) == maxPermutations {
+			log.Warnf("Stopped at %d permutations, use a smaller wordlist to cover them all", maxPermutations)
+			break
+		}
+		w.seen[name] = true
+		next = append(next, candidate{name: name, source: "permutation", depth: 1})
+	}
+	return next
+}
@@ -0,0 +1,39 @@
+package finder
+
+import (
+	"sync"
+
+	"subdomain-finder/internal/portscanner"
+)
+
+// portScanCache shares one quick scan per IP across every subdomain that
+// resolves to it. Concurrent lookups for the same IP wait for the first scan
+// instead of starting their own.
+type portScanCache struct {
+	mu      sync.Mutex
+	entries map[string]*portScanEntry
+}
+
+type portScanEntry struct {
+	once   sync.Once
+	result *portscanner.ScanResult
+}
+
+func newPortScanCache() *portScanCache {
+	return &portScanCache{entries: make(map[string]*portScanEntry)}
+}
+
+func (c *portScanCache) scan(ip string, scan func(string) *portscanner.ScanResult) *portscanner.ScanResult {
+	c.mu.Lock()
+	entry, ok := c.entries[ip]
+	if !ok {
+		entry = &portScanEntry{}
+		c.entries[ip] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.result = scan(ip)
+	})
+	return entry.result
+}
@@ -0,0 +1,46 @@
+package finder
+
+import (
+	"sync"
+	"testing"
+
+	"subdomain-finder/internal/portscanner"
+)
+
+func TestPortScanCacheScansEachIPOnce(t *testing.T) {
+	cache := newPortScanCache()
+	var mu sync.Mutex
+	scans := make(map[string]int)
+	scan := func(ip string) *portscanner.ScanResult {
+		mu.Lock()
+		scans[ip]++
+		mu.Unlock()
+		return &portscanner.ScanResult{Host: ip}
+	}
+
+	ips := []string{"192.0.2.1", "192.0.2.1", "192.0.2.2", "192.0.2.1", "192.0.2.2", "192.0.2.3"}
+	results := make([]*portscanner.ScanResult, len(ips)*5)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = cache.scan(ips[i%len(ips)], scan)
+		}(i)
+	}
+	wg.Wait()
+
+	for ip, count := range scans {
+		if count != 1 {
+			t.Errorf("%s scanned %d times, want 1", ip, count)
+		}
+	}
+	if len(scans) != 3 {
+		t.Errorf("scanned %d IPs, want 3", len(scans))
+	}
+	for i, result := range results {
+		if result == nil || result.Host != ips[i%len(ips)] {
+			t.Errorf("result %d = %+v, want the scan of %s", i, result, ips[i%len(ips)])
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package finder
+
+import "context"
+
+// wait blocks for the scan-wide rate limit, if any, before a DNS lookup.
+// HTTP requests wait in the shared transport instead.
+func (f *Finder) wait(ctx context.Context) error {
+	if f.rateLimiter == nil {
+		return nil
+	}
+	return f.rateLimiter.Wait(ctx)
+}
@@ -0,0 +1,36 @@
+package finder
+
+import (
+	"context"
+	"strings"
+
+	"subdomain-finder/internal/logger"
+	"subdomain-finder/internal/types"
+)
+
+// recursiveCandidates searches the namespaces of found subdomains with the
+// wordlist, one level deeper than where each was found, until
+// Config.RecursionDepth. Names answered by wildcard DNS are not searched,
+// since every word would resolve under them.
+func (f *Finder) recursiveCandidates(ctx context.Context, log *logger.Logger, w *expansion, results []types.Result) []candidate {
+	var next []candidate
+	for _, result := range results {
+		if result.Depth >= f.config.RecursionDepth || result.IP == "" || result.WildcardDNS {
+			continue
+		}
+		base := strings.ToLower(result.Subdomain)
+		if punycode, ok := result.Metadata["punycode"].(string); ok {
+			base = punycode
+		}
+		if w.expanded[base] || !w.inScope(base) {
+			continue
+		}
+		if f.wildcardNamespace(ctx, base) {
+			log.Debugf("Not recursing into %s, it answers for any name", base)
+			w.expanded[base] = true
+			continue
+		}
+		next = append(next, w.expand(base, f.wordlist.GetWords(), candidate{source: "recursive:" + result.Subdomain, depth: result.Depth + 1})...)
+	}
+	return next
+}
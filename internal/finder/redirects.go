@@ -0,0 +1,73 @@
+package finder
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"subdomain-finder/internal/types"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// cnameResolver is implemented by resolvers that can look up aliases, which
+// lets an off-domain redirect be checked for a dangling CNAME.
+type cnameResolver interface {
+	ResolveCNAME(domain string) (string, error)
+}
+
+// offDomainRedirect returns the final host of redirects when its registrable
+// domain (eTLD+1) differs from host's.
+func offDomainRedirect(host string, redirects []types.Redirect) (string, bool) {
+	if len(redirects) == 0 {
+		return "", false
+	}
+
+	final, err := url.Parse(redirects[len(redirects)-1].Location)
+	if err != nil || final.Hostname() == "" {
+		return "", false
+	}
+
+	origin, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(host))
+	if err != nil {
+		return "", false
+	}
+	target, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(final.Hostname()))
+	if err != nil || target == origin {
+		return "", false
+	}
+	return final.Hostname(), true
+}
+
+// redirectFinding describes an off-domain redirect as informational, or as
+// a likely takeover when host is also a CNAME whose target does not resolve.
+func (f *Finder) redirectFinding(host, finalHost string, redirects []types.Redirect) types.Vulnerability {
+	hops := make([]string, 0, len(redirects))
+	for _, redirect := range redirects {
+		hops = append(hops, fmt.Sprintf("%d %s", redirect.StatusCode, redirect.Location))
+	}
+	evidence := strings.Join(hops, " -> ")
+
+	if resolver, ok := f.dns.(cnameResolver); ok {
+		if target, err := resolver.ResolveCNAME(host); err == nil {
+			target = strings.TrimSuffix(target, ".")
+			if _, err := f.dns.Resolve(target); err != nil {
+				return types.Vulnerability{
+					Name:        "Off-Domain Redirect With Dangling CNAME",
+					Severity:    "High",
+					Description: fmt.Sprintf("Redirects to %s while its CNAME %s no longer resolves, which suggests the alias target was claimed by someone else", finalHost, target),
+					Solution:    "Remove the dangling CNAME or reclaim the resource it points to",
+					Evidence:    evidence,
+				}
+			}
+		}
+	}
+
+	return types.Vulnerability{
+		Name:        "Off-Domain Redirect",
+		Severity:    "Info",
+		Description: fmt.Sprintf("Redirects to %s, outside this subdomain's registrable domain", finalHost),
+		Solution:    "Confirm the redirect target is intended and owned by the organization",
+		Evidence:    evidence,
+	}
+}
@@ -0,0 +1,91 @@
+package finder
+
+import (
+	"context"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+
+	"subdomain-finder/internal/logger"
+	"subdomain-finder/internal/types"
+)
+
+// ptrResolver is implemented by resolvers that can look up the names an
+// address maps back to.
+type ptrResolver interface {
+	ResolvePTR(ip string) ([]string, error)
+}
+
+// reverseCandidates runs PTR lookups across the networks, sized by
+// Config.ReverseMask, around the IPv4 addresses of the results and returns
+// the names under the scanned domain that were not checked yet.
+func (f *Finder) reverseCandidates(ctx context.Context, log *logger.Logger, w *expansion, results []types.Result) []candidate {
+	resolver, ok := f.dns.(ptrResolver)
+	if !ok {
+		log.Warnf("Skipping reverse DNS, the resolver has no PTR lookups")
+		return nil
+	}
+
+	networks := make(map[netip.Prefix]bool)
+	for _, result := range results {
+		addr, err := netip.ParseAddr(result.IP)
+		if err != nil || !addr.Unmap().Is4() || result.WildcardDNS {
+			continue
+		}
+		network, err := addr.Unmap().Prefix(f.config.ReverseMask)
+		if err == nil {
+			networks[network] = true
+		}
+	}
+
+	var addresses []netip.Addr
+	for network := range networks {
+		for addr := network.Addr(); network.Contains(addr); addr = addr.Next() {
+			addresses = append(addresses, addr)
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i].Less(addresses[j]) })
+	if len(addresses) == 0 {
+		return nil
+	}
+	log.Debugf("Looking up PTR records for %d addresses in %d networks", len(addresses), len(networks))
+
+	names := make([][]string, len(addresses))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < min(f.config.Threads, len(addresses)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if f.wait(ctx) != nil || f.budget.Acquire(ctx) != nil {
+					continue
+				}
+				names[job], _ = resolver.ResolvePTR(addresses[job].String())
+				f.budget.Release()
+			}
+		}()
+	}
+	for i := range addresses {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var next []candidate
+	for i, found := range names {
+		for _, name := range found {
+			name = strings.ToLower(name)
+			if w.seen[name] || !w.inScope(name) || name == w.domain {
+				continue
+			}
+			w.seen[name] = true
+			next = append(next, candidate{name: name, source: "ptr:" + addresses[i].String(), depth: 1})
+		}
+	}
+	return next
+}
@@ -0,0 +1,33 @@
+package finder
+
+import (
+	"strings"
+
+	"subdomain-finder/internal/types"
+)
+
+// sanCandidates returns the names listed in the certificates of the results,
+// on HTTPS and on the other TLS services, that fall under the scanned domain
+// and were not checked yet. Wildcard SANs are left to wildcardCandidates.
+func (w *expansion) sanCandidates(results []types.Result) []candidate {
+	var next []candidate
+	for _, result := range results {
+		var sans []string
+		if result.SSL != nil {
+			sans = append(sans, result.SSL.SANs...)
+		}
+		for _, service := range result.SSLServices {
+			sans = append(sans, service.SANs...)
+		}
+
+		for _, san := range sans {
+			name := strings.TrimSuffix(strings.ToLower(san), ".")
+			if strings.HasPrefix(name, "*.") || w.seen[name] || !w.inScope(name) || name == w.domain {
+				continue
+			}
+			w.seen[name] = true
+			next = append(next, candidate{name: name, source: "san:" + result.Subdomain, depth: result.Depth})
+		}
+	}
+	return next
+}
@@ -0,0 +1,57 @@
+package finder
+
+import (
+	"reflect"
+	"testing"
+
+	"subdomain-finder/internal/types"
+)
+
+func TestSANCandidates(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []types.Result
+		want    []string
+	}{
+		{
+			name: "https certificate",
+			results: []types.Result{{
+				Subdomain: "www.example.com",
+				SSL:       &types.SSLInfo{SANs: []string{"www.example.com", "Shop.example.com.", "*.example.com", "other.org"}},
+			}},
+			want: []string{"shop.example.com"},
+		},
+		{
+			name: "skips the apex",
+			results: []types.Result{{
+				Subdomain: "www.example.com",
+				SSL:       &types.SSLInfo{SANs: []string{"example.com", "api.example.com"}},
+			}},
+			want: []string{"api.example.com"},
+		},
+		{
+			name: "other tls services",
+			results: []types.Result{{
+				Subdomain: "mail.example.com",
+				SSLServices: []types.SSLInfo{
+					{Port: 993, SANs: []string{"imap.example.com"}},
+					{Port: 465, SANs: []string{"smtp.example.com", "imap.example.com"}},
+				},
+			}},
+			want: []string{"imap.example.com", "smtp.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newExpansion("example.com", []string{"www.example.com"})
+			var got []string
+			for _, c := range w.sanCandidates(tt.results) {
+				got = append(got, c.name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package finder
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"subdomain-finder/internal/logger"
+	"subdomain-finder/internal/ssl"
+	"subdomain-finder/internal/types"
+)
+
+// DefaultSSLPorts are the ports analyzed for TLS when Config.SSLPorts is
+// empty: HTTPS, LDAPS and the mail ports, with or without STARTTLS.
+var DefaultSSLPorts = []int{25, 110, 143, 443, 465, 587, 636, 993, 995, 8443}
+
+// sslTargets returns the ports to analyze on a host. Port 443 is always
+// analyzed when it is in the set, the others only when the port scanner
+// found them open.
+func (f *Finder) sslTargets(open []types.PortInfo) []int {
+	set := f.config.SSLPorts
+	if len(set) == 0 {
+		set = DefaultSSLPorts
+	}
+
+	isOpen := map[int]bool{443: true}
+	for _, port := range open {
+		if port.Open() && port.Protocol != "udp" {
+			isOpen[port.Port] = true
+		}
+	}
+
+	seen := make(map[int]bool)
+	var targets []int
+	for _, port := range set {
+		if isOpen[port] && !seen[port] {
+			seen[port] = true
+			targets = append(targets, port)
+		}
+	}
+	sort.Ints(targets)
+	return targets
+}
+
+// analyzeSSLPorts analyzes every port concurrently. The main result is the
+// one for 443, or the lowest port that answered when 443 did not; the
+// others are returned as services.
+func (f *Finder) analyzeSSLPorts(ctx context.Context, log *logger.Logger, host string, ports []int) (*ssl.SSLResult, []types.SSLInfo) {
+	results := make([]*ssl.SSLResult, len(ports))
+	var wg sync.WaitGroup
+	for i, port := range ports {
+		wg.Add(1)
+		go func(i, port int) {
+			defer wg.Done()
+			result, err := f.analyzeSSL(ctx, host, port)
+			if err != nil {
+				log.Debugf("SSL analysis of port %d failed: %v", port, err)
+				return
+			}
+			results[i] = result
+		}(i, port)
+	}
+	wg.Wait()
+
+	primary := -1
+	for i, result := range results {
+		if result != nil && (primary == -1 || ports[i] == 443) {
+			primary = i
+		}
+	}
+
+	var services []types.SSLInfo
+	for i, result := range results {
+		if result != nil && i != primary {
+			services = append(services, *sslInfo(result))
+		}
+	}
+	if primary == -1 {
+		return nil, services
+	}
+	return results[primary], services
+}
+
+func sslInfo(result *ssl.SSLResult) *types.SSLInfo {
+	info := &types.SSLInfo{
+		Port:               result.Port,
+		Valid:              result.IsSecure,
+		Expired:            result.Certificate.IsExpired,
+		ExpiresSoon:        result.Certificate.IsExpiringSoon,
+		DaysUntilExpiry:    result.Certificate.DaysUntilExpiry,
+		Issuer:             result.Certificate.Issuer,
+		Subject:            result.Certificate.Subject,
+		SerialNumber:       result.Certificate.SerialNumber,
+		SignatureAlgorithm: result.Certificate.SignatureAlgorithm,
+		PublicKeyAlgorithm: result.Certificate.PublicKeyAlgorithm,
+		Grade:              result.Grade,
+		ServerName:         result.ServerName,
+		StartTLS:           ssl.StartTLSProtocol(result.Port),
+		Vulnerabilities:    result.Certificate.Vulnerabilities,
+		NotBefore:          result.Certificate.NotBefore,
+		NotAfter:           result.Certificate.NotAfter,
+		SANs:               result.Certificate.DNSNames,
+		Protocols:          result.SupportedProtocols,
+		Ciphers:            result.SupportedCiphers,
+		Revoked:            result.Certificate.Revoked,
+		RevocationStatus:   result.Certificate.RevocationStatus,
+		ChainValid:         result.Certificate.ChainValid,
+		UntrustedRoot:      result.Certificate.UntrustedRoot,
+		HostnameMismatch:   result.Certificate.HostnameMismatch,
+	}
+	// Resumption is only probed with deep checks
+	if result.Resumption != nil {
+		info.SessionIDs = result.Resumption.SessionIDs
+		info.SessionTickets = result.Resumption.SessionTickets
+		info.EarlyData = result.Resumption.EarlyData
+	}
+	return info
+}
@@ -0,0 +1,98 @@
+package finder
+
+import (
+	"strings"
+
+	"subdomain-finder/internal/http"
+	"subdomain-finder/internal/types"
+)
+
+// autoTags derives tags from what the checks found. response may be nil.
+func autoTags(result types.Result, response *http.HTTPResponse) []string {
+	var tags []string
+
+	for _, tech := range result.Technologies {
+		if tech.Category == "CDN" {
+			tags = append(tags, "cdn")
+			break
+		}
+	}
+
+	if result.Hosting == hostingExternal {
+		tags = append(tags, "external")
+	}
+
+	if strings.HasPrefix(result.Source, "wildcard-san:") {
+		tags = append(tags, "wildcard")
+	}
+
+	if result.WildcardDNS {
+		tags = append(tags, "wildcard-dns")
+	}
+
+	if result.SSL != nil && result.SSL.Expired {
+		tags = append(tags, "expired-cert")
+	}
+
+	if strings.EqualFold(result.RiskLevel, "high") || strings.EqualFold(result.RiskLevel, "critical") {
+		tags = append(tags, "high-risk")
+	}
+
+	if result.Parked {
+		tags = append(tags, "parked")
+	}
+
+	if isAPI(result, response) {
+		tags = append(tags, "api")
+	}
+
+	if response != nil && isLoginPage(response) {
+		tags = append(tags, "login")
+	}
+
+	return tags
+}
+
+func isAPI(result types.Result, response *http.HTTPResponse) bool {
+	label, _, _ := strings.Cut(strings.ToLower(result.Subdomain), ".")
+	if label == "api" || strings.HasPrefix(label, "api-") || strings.HasSuffix(label, "-api") {
+		return true
+	}
+
+	if response != nil {
+		if contentType := stdHeader(response, "Content-Type"); strings.Contains(contentType, "json") {
+			return true
+		}
+	}
+
+	for _, vuln := range result.Vulnerabilities {
+		if vuln.Name == "Exposed API Documentation" {
+			return true
+		}
+	}
+	return false
+}
+
+func isLoginPage(response *http.HTTPResponse) bool {
+	body := strings.ToLower(response.Body)
+	if strings.Contains(body, `type="password"`) || strings.Contains(body, `type='password'`) || strings.Contains(body, "type=password") {
+		return true
+	}
+
+	title := strings.ToLower(response.Title)
+	for _, word := range []string{"login", "log in", "sign in", "signin"} {
+		if strings.Contains(title, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func stdHeader(response *http.HTTPResponse, name string) string {
+	for key, values := range response.Headers {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package finder
+
+import (
+	"context"
+
+	"subdomain-finder/internal/http"
+	"subdomain-finder/internal/types"
+)
+
+// checkTakeover looks up subdomain's CNAME and matches it, along with the
+// page it served, against known takeover fingerprints. Resolvers that cannot
+// look up aliases skip the check.
+func (f *Finder) checkTakeover(ctx context.Context, subdomain string, response *http.HTTPResponse) *types.Vulnerability {
+	resolver, ok := f.dns.(cnameResolver)
+	if !ok {
+		return nil
+	}
+	if err := f.wait(ctx); err != nil {
+		return nil
+	}
+	target, err := resolver.ResolveCNAME(subdomain)
+	if err != nil || target == "" {
+		return nil
+	}
+
+	_, err = f.resolve(ctx, target)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	body := ""
+	if response != nil {
+		body = response.Body
+	}
+	return f.takeover.Check(subdomain, target, err == nil, body)
+}
@@ -0,0 +1,25 @@
+package finder
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("subdomain-finder/internal/finder")
+
+// startStage opens a span for one check on a subdomain. Spans are no-ops
+// unless telemetry.Setup installed an exporter.
+func startStage(ctx context.Context, name string) trace.Span {
+	_, span := tracer.Start(ctx, name)
+	return span
+}
+
+func endStage(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
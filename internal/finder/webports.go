@@ -0,0 +1,88 @@
+package finder
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"subdomain-finder/internal/types"
+)
+
+// webProbeSlots bounds alternate-port probes across the whole scan to the
+// worker count, so hosts with many web ports cannot double the load.
+func webProbeSlots(threads int) int {
+	if threads < 1 {
+		return 1
+	}
+	return threads
+}
+
+// isAlternateWebPort reports whether an open port serves HTTP on something
+// other than the default 80/443, which the main HTTP check already covers.
+func isAlternateWebPort(port types.PortInfo) bool {
+	return port.Open() && port.Protocol != "udp" && port.Port != 80 && port.Port != 443 && strings.HasPrefix(port.Service, "http")
+}
+
+// probeWebPorts fetches every alternate web port of host concurrently,
+// sharing f.webProbes with the rest of the scan so extra probes stay
+// bounded, and returns one entry per port that answered.
+func (f *Finder) probeWebPorts(ctx context.Context, host string, ports []types.PortInfo) []types.WebService {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var services []types.WebService
+
+	for _, port := range ports {
+		if !isAlternateWebPort(port) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(port int) {
+			defer wg.Done()
+			f.webProbes <- struct{}{}
+			defer func() { <-f.webProbes }()
+
+			if service, ok := f.probeWebPort(ctx, host, port); ok {
+				mu.Lock()
+				services = append(services, service)
+				mu.Unlock()
+			}
+		}(port.Port)
+	}
+	wg.Wait()
+
+	sort.Slice(services, func(i, j int) bool { return services[i].Port < services[j].Port })
+	return services
+}
+
+func (f *Finder) probeWebPort(ctx context.Context, host string, port int) (types.WebService, bool) {
+	response := f.fetch(ctx, fmt.Sprintf("%s:%d", host, port))
+	if response == nil {
+		return types.WebService{}, false
+	}
+
+	service := types.WebService{
+		Port:   port,
+		URL:    response.URL,
+		Status: response.StatusCode,
+		Title:  response.Title,
+		Server: response.Server,
+	}
+
+	if techResult, err := f.detectTech(ctx, response.URL); err == nil {
+		for _, tech := range techResult.Technologies {
+			service.Technologies = append(service.Technologies, types.Technology{
+				Name:        tech.Name,
+				Version:     tech.Version,
+				Category:    tech.Category,
+				Confidence:  tech.Confidence,
+				Description: tech.Description,
+				Website:     tech.Website,
+			})
+		}
+	}
+
+	return service, true
+}
@@ -0,0 +1,85 @@
+package finder
+
+import (
+	"strings"
+
+	"subdomain-finder/internal/types"
+)
+
+// candidate is a name to check and, when it did not come from the wordlist
+// or passive sources, how it was discovered. depth counts the wordlist
+// passes that led to it, starting at 1.
+type candidate struct {
+	name   string
+	source string
+	depth  int
+}
+
+// expansion turns namespaces under the scanned domain, from wildcard
+// certificate SANs or recursion, into wordlist candidates. Each namespace
+// is expanded only once and each name checked only once.
+type expansion struct {
+	domain   string
+	expanded map[string]bool
+	seen     map[string]bool
+}
+
+func newExpansion(domain string, targets []string) *expansion {
+	domain = strings.ToLower(domain)
+	seen := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		seen[strings.ToLower(target)] = true
+	}
+	return &expansion{
+		domain:   domain,
+		expanded: map[string]bool{domain: true}, // already covered by the wordlist
+		seen:     seen,
+	}
+}
+
+// wildcardCandidates expands the wildcard SANs of the results' certificates.
+// The names keep the depth of the result that carried the certificate.
+func (w *expansion) wildcardCandidates(results []types.Result, words []string) []candidate {
+	var next []candidate
+	for _, result := range results {
+		if result.SSL == nil {
+			continue
+		}
+		for _, san := range result.SSL.SANs {
+			base, ok := strings.CutPrefix(strings.ToLower(san), "*.")
+			if !ok {
+				continue
+			}
+			next = append(next, w.expand(base, words, candidate{source: "wildcard-san:" + san, depth: result.Depth})...)
+		}
+	}
+	return next
+}
+
+// expand returns the wordlist names under base that were not checked yet,
+// with the source and depth of template. It returns nil for namespaces
+// already expanded or outside the scanned domain.
+func (w *expansion) expand(base string, words []string, template candidate) []candidate {
+	base = strings.ToLower(base)
+	if w.expanded[base] || !w.inScope(base) {
+		return nil
+	}
+	w.expanded[base] = true
+
+	var next []candidate
+	for _, word := range words {
+		name := word + "." + base
+		if w.seen[name] {
+			continue
+		}
+		w.seen[name] = true
+		c := template
+		c.name = name
+		next = append(next, c)
+	}
+	return next
+}
+
+func (w *expansion) inScope(name string) bool {
+	return name == w.domain || strings.HasSuffix(name, "."+w.domain)
+}
@@ -0,0 +1,112 @@
+package finder
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"subdomain-finder/internal/http"
+)
+
+// wildcardProbes is how many random names are resolved to detect wildcard
+// DNS before enumeration starts. Wildcards that spread names over a pool of
+// addresses or pages need several probes to show most of them.
+const wildcardProbes = 8
+
+// wildcardDNS records the addresses and HTTP fingerprints that names which
+// cannot exist resolve to, so wordlist hits answered by a wildcard record
+// can be told apart from real hosts.
+type wildcardDNS struct {
+	ips          map[string]bool
+	fingerprints map[string]bool
+}
+
+// detectWildcardDNS resolves a few random labels under the domain. It
+// returns nil when none of them resolve.
+func (f *Finder) detectWildcardDNS(ctx context.Context) *wildcardDNS {
+	wildcard := &wildcardDNS{
+		ips:          make(map[string]bool),
+		fingerprints: make(map[string]bool),
+	}
+
+	for i := 0; i < wildcardProbes; i++ {
+		label, err := randomLabel()
+		if err != nil {
+			continue
+		}
+		name := label + "." + f.config.Domain
+		ip, err := f.resolve(ctx, name)
+		if err != nil {
+			continue
+		}
+		wildcard.ips[ip] = true
+		wildcard.fingerprints[httpFingerprint(name, f.fetch(ctx, name))] = true
+	}
+
+	if len(wildcard.ips) == 0 {
+		return nil
+	}
+	return wildcard
+}
+
+// wildcardNamespace reports whether a random label under base resolves.
+func (f *Finder) wildcardNamespace(ctx context.Context, base string) bool {
+	label, err := randomLabel()
+	if err != nil {
+		return false
+	}
+	_, err = f.resolve(ctx, label+"."+base)
+	return err == nil
+}
+
+// matches reports whether a host answered like the random names did: same
+// address and same status, server and title. Hosts on a wildcard address
+// that serve something else are real virtual hosts and do not match.
+func (w *wildcardDNS) matches(host, ip string, response *http.HTTPResponse) bool {
+	return w != nil && w.ips[ip] && w.fingerprints[httpFingerprint(host, response)]
+}
+
+func (w *wildcardDNS) addresses() []string {
+	if w == nil {
+		return nil
+	}
+	ips := make([]string, 0, len(w.ips))
+	for ip := range w.ips {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	return ips
+}
+
+// httpFingerprint identifies a response by status, server and title. A
+// wildcard page often names the host it was asked for in its title, so the
+// host and its first label are replaced before comparing.
+func httpFingerprint(host string, response *http.HTTPResponse) string {
+	if response == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d|%s|%s", response.StatusCode, response.Server, normalizeTitle(host, response.Title))
+}
+
+func normalizeTitle(host, title string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return title
+	}
+	title = strings.ToLower(title)
+	title = strings.ReplaceAll(title, host, "{host}")
+	label, _, _ := strings.Cut(host, ".")
+	return regexp.MustCompile(`\b`+regexp.QuoteMeta(label)+`\b`).ReplaceAllString(title, "{host}")
+}
+
+func randomLabel() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "sf-wildcard-" + hex.EncodeToString(buf), nil
+}
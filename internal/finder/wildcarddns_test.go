@@ -0,0 +1,84 @@
+package finder
+
+import (
+	"testing"
+
+	"subdomain-finder/internal/http"
+)
+
+func TestWildcardMatches(t *testing.T) {
+	probe := "sf-wildcard-0123456789abcdef.example.com"
+	wildcard := &wildcardDNS{
+		ips: map[string]bool{"192.0.2.1": true},
+		fingerprints: map[string]bool{
+			httpFingerprint(probe, &http.HTTPResponse{StatusCode: 200, Server: "nginx", Title: "Welcome to " + probe}):                            true,
+			httpFingerprint(probe, &http.HTTPResponse{StatusCode: 404, Server: "nginx", Title: "sf-wildcard-0123456789abcdef is not configured"}): true,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		host     string
+		ip       string
+		response *http.HTTPResponse
+		expected bool
+	}{
+		{"title names the host", "www.example.com", "192.0.2.1", &http.HTTPResponse{StatusCode: 200, Server: "nginx", Title: "Welcome to www.example.com"}, true},
+		{"title case differs", "www.example.com", "192.0.2.1", &http.HTTPResponse{StatusCode: 200, Server: "nginx", Title: "Welcome to WWW.EXAMPLE.COM"}, true},
+		{"title names the label", "shop.example.com", "192.0.2.1", &http.HTTPResponse{StatusCode: 404, Server: "nginx", Title: "shop is not configured"}, true},
+		{"real virtual host", "www.example.com", "192.0.2.1", &http.HTTPResponse{StatusCode: 200, Server: "nginx", Title: "Example Store"}, false},
+		{"label inside a word", "api.example.com", "192.0.2.1", &http.HTTPResponse{StatusCode: 404, Server: "nginx", Title: "rapid is not configured"}, false},
+		{"other status", "www.example.com", "192.0.2.1", &http.HTTPResponse{StatusCode: 302, Server: "nginx", Title: "Welcome to www.example.com"}, false},
+		{"other address", "www.example.com", "192.0.2.9", &http.HTTPResponse{StatusCode: 200, Server: "nginx", Title: "Welcome to www.example.com"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wildcard.matches(tt.host, tt.ip, tt.response); got != tt.expected {
+				t.Errorf("matches(%q) = %v, want %v", tt.host, got, tt.expected)
+			}
+		})
+	}
+}
+
+// wildcardResolver answers every name with the same address.
+type wildcardResolver struct {
+	mockResolver
+}
+
+func (r *wildcardResolver) Resolve(domain string) (string, error) {
+	return "192.0.2.1", nil
+}
+
+// echoHTTPChecker serves a page titled with the requested host, except for
+// the hosts in responses.
+type echoHTTPChecker struct {
+	mockHTTPChecker
+}
+
+func (c *echoHTTPChecker) Fetch(domain string) *http.HTTPResponse {
+	if response, ok := c.responses[domain]; ok {
+		return response
+	}
+	return &http.HTTPResponse{URL: "https://" + domain, StatusCode: 200, Server: "nginx", Title: "Welcome to " + domain}
+}
+
+func TestFindSkipsWildcardPagesNamingTheHost(t *testing.T) {
+	checker := &echoHTTPChecker{mockHTTPChecker{responses: map[string]*http.HTTPResponse{
+		"shop.example.com": {URL: "https://shop.example.com", StatusCode: 200, Server: "nginx", Title: "Example Store"},
+	}}}
+	finder := NewFinderWithOptions(Config{
+		Domain:   "example.com",
+		Wordlist: writeWordlist(t, "www", "api", "shop"),
+		Threads:  2,
+	}, mockOptions(Options{Resolver: &wildcardResolver{}, HTTPChecker: checker}))
+
+	results := finder.Find()
+	if len(results) != 1 || results[0].Subdomain != "shop.example.com" {
+		names := make([]string, 0, len(results))
+		for _, result := range results {
+			names = append(names, result.Subdomain)
+		}
+		t.Errorf("found %v, want only shop.example.com", names)
+	}
+}
@@ -0,0 +1,125 @@
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"subdomain-finder/internal/types"
+)
+
+// Locator finds where an IP address is hosted. Lookups that find nothing
+// return nil without an error.
+type Locator interface {
+	Locate(ip net.IP) (*types.GeoLocation, error)
+}
+
+// DatabaseLocator answers from local MaxMind databases such as GeoLite2-City
+// and GeoLite2-ASN. Each database fills the fields it knows, so a City and
+// an ASN database can be combined.
+type DatabaseLocator struct {
+	databases []*maxminddb.Reader
+}
+
+// NewDatabaseLocator loads the given .mmdb files into memory.
+func NewDatabaseLocator(paths ...string) (*DatabaseLocator, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no GeoIP database given")
+	}
+
+	locator := &DatabaseLocator{}
+	for _, path := range paths {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		db, err := maxminddb.FromBytes(buf)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		locator.databases = append(locator.databases, db)
+	}
+	return locator, nil
+}
+
+func (l *DatabaseLocator) Locate(ip net.IP) (*types.GeoLocation, error) {
+	var location *types.GeoLocation
+	for _, db := range l.databases {
+		var rec record
+		_, found, err := db.LookupNetwork(ip, &rec)
+		if err != nil {
+			return nil, fmt.Errorf("%s lookup for %s: %w", db.Metadata.DatabaseType, ip, err)
+		}
+		if !found {
+			continue
+		}
+		if location == nil {
+			location = &types.GeoLocation{}
+		}
+		rec.fill(location)
+	}
+	return location, nil
+}
+
+type names struct {
+	Names map[string]string `maxminddb:"names"`
+}
+
+type country struct {
+	ISOCode string            `maxminddb:"iso_code"`
+	Names   map[string]string `maxminddb:"names"`
+}
+
+// record holds the City, Country, ASN and ISP database fields used.
+type record struct {
+	Country           *country `maxminddb:"country"`
+	RegisteredCountry *country `maxminddb:"registered_country"`
+	Subdivisions      []names  `maxminddb:"subdivisions"`
+	City              names    `maxminddb:"city"`
+	Location          struct {
+		Latitude  *float64 `maxminddb:"latitude"`
+		Longitude *float64 `maxminddb:"longitude"`
+		TimeZone  string   `maxminddb:"time_zone"`
+	} `maxminddb:"location"`
+
+	ISP            string `maxminddb:"isp"`
+	ASN            uint   `maxminddb:"autonomous_system_number"`
+	Organization   string `maxminddb:"organization"`
+	ASOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// fill copies rec into location without overwriting what an earlier
+// database set.
+func (rec *record) fill(location *types.GeoLocation) {
+	country := rec.Country
+	if country == nil {
+		country = rec.RegisteredCountry
+	}
+	if country != nil {
+		setString(&location.Country, country.Names["en"])
+		setString(&location.CountryCode, country.ISOCode)
+	}
+	if len(rec.Subdivisions) > 0 {
+		setString(&location.Region, rec.Subdivisions[0].Names["en"])
+	}
+	setString(&location.City, rec.City.Names["en"])
+	setString(&location.Timezone, rec.Location.TimeZone)
+	if location.Latitude == 0 && location.Longitude == 0 && rec.Location.Latitude != nil && rec.Location.Longitude != nil {
+		location.Latitude, location.Longitude = *rec.Location.Latitude, *rec.Location.Longitude
+	}
+
+	setString(&location.ISP, rec.ISP)
+	if rec.ASN != 0 && location.ASN == "" {
+		location.ASN = fmt.Sprintf("AS%d", rec.ASN)
+	}
+	setString(&location.Organization, rec.Organization)
+	setString(&location.Organization, rec.ASOrganization)
+}
+
+func setString(field *string, value string) {
+	if value != "" && *field == "" {
+		*field = value
+	}
+}
@@ -0,0 +1,71 @@
+package geoip
+
+import (
+	"testing"
+
+	"subdomain-finder/internal/types"
+)
+
+func TestRecordFill(t *testing.T) {
+	latitude, longitude := 52.37, 4.89
+
+	city := record{
+		Country:      &country{ISOCode: "NL", Names: map[string]string{"en": "Netherlands"}},
+		Subdivisions: []names{{Names: map[string]string{"en": "North Holland"}}},
+		City:         names{Names: map[string]string{"en": "Amsterdam"}},
+	}
+	city.Location.Latitude, city.Location.Longitude = &latitude, &longitude
+	city.Location.TimeZone = "Europe/Amsterdam"
+
+	registered := record{RegisteredCountry: &country{ISOCode: "US", Names: map[string]string{"en": "United States"}}}
+	asn := record{ASN: 13335, ASOrganization: "CLOUDFLARENET"}
+
+	tests := []struct {
+		name    string
+		records []record
+		want    types.GeoLocation
+	}{
+		{
+			name:    "city",
+			records: []record{city},
+			want: types.GeoLocation{
+				Country: "Netherlands", CountryCode: "NL", Region: "North Holland", City: "Amsterdam",
+				Timezone: "Europe/Amsterdam", Latitude: latitude, Longitude: longitude,
+			},
+		},
+		{
+			name:    "registered country when the country is missing",
+			records: []record{registered},
+			want:    types.GeoLocation{Country: "United States", CountryCode: "US"},
+		},
+		{
+			name:    "city and ASN combined",
+			records: []record{city, asn},
+			want: types.GeoLocation{
+				Country: "Netherlands", CountryCode: "NL", Region: "North Holland", City: "Amsterdam",
+				Timezone: "Europe/Amsterdam", Latitude: latitude, Longitude: longitude,
+				ASN: "AS13335", Organization: "CLOUDFLARENET",
+			},
+		},
+		{
+			name:    "earlier database wins",
+			records: []record{registered, city},
+			want: types.GeoLocation{
+				Country: "United States", CountryCode: "US", Region: "North Holland", City: "Amsterdam",
+				Timezone: "Europe/Amsterdam", Latitude: latitude, Longitude: longitude,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var location types.GeoLocation
+			for i := range tt.records {
+				tt.records[i].fill(&location)
+			}
+			if location != tt.want {
+				t.Errorf("got %+v, want %+v", location, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"subdomain-finder/internal/fileutil"
+	"subdomain-finder/internal/redact"
+)
+
+const DefaultMaxBodySize = 64 * 1024
+
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	Time            float64  `json:"time"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Timings         Timings  `json:"timings"`
+	Comment         string   `json:"comment,omitempty"`
+}
+
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Recorder captures every request sent through its transport so a scan can
+// be exported as a HAR file for independent verification.
+type Recorder struct {
+	mu          sync.Mutex
+	entries     []Entry
+	maxBodySize int
+}
+
+func NewRecorder(maxBodySize int) *Recorder {
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+	return &Recorder{maxBodySize: maxBodySize}
+}
+
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &recordingTransport{recorder: r, base: base}
+}
+
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+func (r *Recorder) Save(path string) error {
+	r.mu.Lock()
+	entries := make([]Entry, len(r.entries))
+	copy(entries, r.entries)
+	r.mu.Unlock()
+
+	log := struct {
+		Log Log `json:"log"`
+	}{
+		Log: Log{
+			Version: "1.2",
+			Creator: Creator{Name: "subdomain-finder", Version: "1.0.0"},
+			Entries: entries,
+		},
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode HAR: %w", err)
+	}
+
+	// Recordings may still hold session data in bodies, so keep them private
+	return fileutil.WritePrivateFile(path, data)
+}
+
+func (r *Recorder) add(entry Entry) {
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+}
+
+type recordingTransport struct {
+	recorder *Recorder
+	base     http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := Entry{
+		StartedDateTime: time.Now().Format(time.RFC3339Nano),
+		Request:         t.recorder.captureRequest(req),
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	entry.Time = float64(time.Since(start).Microseconds()) / 1000
+	entry.Timings = Timings{Wait: entry.Time}
+
+	if err != nil {
+		entry.Response = Response{HTTPVersion: req.Proto, Headers: []NameValue{}, HeadersSize: -1, BodySize: -1}
+		entry.Comment = err.Error()
+		t.recorder.add(entry)
+		return nil, err
+	}
+
+	entry.Response = t.recorder.captureResponse(resp)
+	t.recorder.add(entry)
+	return resp, nil
+}
+
+// captureRequest records a request with its cookies, authorization and
+// password-like query and form fields redacted, since the login request of
+// --login-url goes through the recorder too.
+func (r *Recorder) captureRequest(req *http.Request) Request {
+	captured := Request{
+		Method:      req.Method,
+		URL:         redact.URL(req.URL.String()),
+		HTTPVersion: req.Proto,
+		Headers:     nameValues(req.Header),
+		QueryString: []NameValue{},
+		HeadersSize: -1,
+		BodySize:    0,
+	}
+
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			if redact.SensitiveField(name) {
+				value = redact.Value
+			}
+			captured.QueryString = append(captured.QueryString, NameValue{Name: name, Value: value})
+		}
+	}
+
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			text, _, _ := r.readPrefix(body)
+			body.Close()
+			if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+				text = redact.Form(text)
+			}
+			captured.PostData = &PostData{MimeType: req.Header.Get("Content-Type"), Text: text}
+			captured.BodySize = int(req.ContentLength)
+		}
+	}
+
+	return captured
+}
+
+// captureResponse records up to maxBodySize bytes of the body and splices
+// them back in front of the unread remainder so callers see the full body.
+func (r *Recorder) captureResponse(resp *http.Response) Response {
+	captured := Response{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Headers:     nameValues(resp.Header),
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    int(resp.ContentLength),
+		Content: Content{
+			Size:     int(resp.ContentLength),
+			MimeType: resp.Header.Get("Content-Type"),
+		},
+	}
+
+	if resp.Body == nil {
+		return captured
+	}
+
+	text, prefix, truncated := r.readPrefix(resp.Body)
+	captured.Content.Text = text
+	if truncated {
+		captured.Content.Comment = fmt.Sprintf("truncated to %d bytes", r.maxBodySize)
+	}
+
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix), resp.Body), resp.Body}
+
+	return captured
+}
+
+func (r *Recorder) readPrefix(body io.Reader) (string, []byte, bool) {
+	prefix, _ := io.ReadAll(io.LimitReader(body, int64(r.maxBodySize)+1))
+	if len(prefix) > r.maxBodySize {
+		return string(prefix[:r.maxBodySize]), prefix, true
+	}
+	return string(prefix), prefix, false
+}
+
+// nameValues lists the headers with cookie and authorization values
+// redacted.
+func nameValues(header http.Header) []NameValue {
+	values := []NameValue{}
+	for name, list := range header {
+		for _, value := range list {
+			values = append(values, NameValue{Name: name, Value: redact.Header(name, value)})
+		}
+	}
+	return values
+}
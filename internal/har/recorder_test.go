@@ -0,0 +1,96 @@
+package har
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorderRedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t-session", HttpOnly: true})
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		request func() *http.Request
+	}{
+		{"form post", func() *http.Request {
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/login", strings.NewReader("username=admin&password=hunter2"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("Cookie", "session=s3cr3t-session")
+			return req
+		}},
+		{"form get", func() *http.Request {
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/login?username=admin&password=hunter2", nil)
+			req.Header.Set("Authorization", "Basic hunter2")
+			return req
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := NewRecorder(0)
+			client := &http.Client{Transport: recorder.Transport(nil)}
+			resp, err := client.Do(tt.request())
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			path := filepath.Join(t.TempDir(), "scan.har")
+			if err := recorder.Save(path); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Also catch values that were only percent-encoded
+			decoded, _ := url.QueryUnescape(string(data))
+			for _, secret := range []string{"hunter2", "s3cr3t-session"} {
+				if strings.Contains(decoded, secret) {
+					t.Errorf("HAR file contains %q", secret)
+				}
+			}
+			if !strings.Contains(decoded, "admin") {
+				t.Error("HAR file lost the username")
+			}
+			if !json.Valid(data) {
+				t.Error("HAR file is not valid JSON")
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mode := info.Mode().Perm(); mode != 0600 {
+				t.Errorf("HAR file mode = %o, want 600", mode)
+			}
+		})
+	}
+}
+
+func TestSaveNarrowsExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.har")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewRecorder(0).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("HAR file mode = %o, want 600", mode)
+	}
+}
@@ -0,0 +1,123 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"subdomain-finder/internal/diff"
+	"subdomain-finder/internal/types"
+)
+
+// Change markers set on merged results
+const (
+	ChangeAdded     = "added"
+	ChangeChanged   = "changed"
+	ChangeUnchanged = "unchanged"
+	// ChangeStale marks a previous result this run did not find again
+	ChangeStale = "stale"
+)
+
+type MergeSummary struct {
+	Added     int
+	Changed   int
+	Unchanged int
+	Stale     int
+}
+
+// LoadResults reads a results file written with --json. A missing file is
+// an empty result set.
+func LoadResults(path string) ([]types.Result, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read previous results: %w", err)
+	}
+
+	results, _, err := types.DecodeResults(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse previous results %s: %w", path, err)
+	}
+	return results, nil
+}
+
+// Merge combines the results of a previous run with this run's. Current
+// results win, keeping the earliest FirstSeen, and are marked added, changed
+// (with the fields that differ) or unchanged. Previous results not found
+// again are kept as they were and marked stale.
+func Merge(previous, current []types.Result, now time.Time) ([]types.Result, MergeSummary) {
+	var summary MergeSummary
+	prior := make(map[string]types.Result, len(previous))
+	for _, result := range previous {
+		if result.Subdomain != "" {
+			prior[result.Subdomain] = result
+		}
+	}
+
+	merged := make([]types.Result, 0, len(prior)+len(current))
+	seen := make(map[string]bool)
+	for _, result := range current {
+		if result.Subdomain == "" || seen[result.Subdomain] {
+			continue
+		}
+		seen[result.Subdomain] = true
+		result.LastSeen = now
+		result.ChangedFields = nil
+
+		old, existed := prior[result.Subdomain]
+		if !existed {
+			result.FirstSeen = Earliest(result.FirstSeen, now)
+			result.Change = ChangeAdded
+			summary.Added++
+		} else {
+			result.FirstSeen = Earliest(old.FirstSeen, old.Timestamp, result.FirstSeen, now)
+			if fields := changedFields(old, result); len(fields) > 0 {
+				result.Change = ChangeChanged
+				result.ChangedFields = fields
+				summary.Changed++
+			} else {
+				result.Change = ChangeUnchanged
+				summary.Unchanged++
+			}
+		}
+		merged = append(merged, result)
+	}
+
+	for _, old := range previous {
+		if old.Subdomain == "" || seen[old.Subdomain] {
+			continue
+		}
+		seen[old.Subdomain] = true
+		old.FirstSeen = Earliest(old.FirstSeen, old.Timestamp)
+		old.Change = ChangeStale
+		old.ChangedFields = nil
+		merged = append(merged, old)
+		summary.Stale++
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Subdomain < merged[j].Subdomain
+	})
+	return merged, summary
+}
+
+// changedFields lists the observed properties that differ between two
+// results of the same subdomain, as the diff command compares them.
+func changedFields(old, current types.Result) []string {
+	change, _ := diff.CompareResult(old, current)
+	return change.FieldNames()
+}
+
+// Earliest returns the earliest of times, ignoring zero values.
+func Earliest(times ...time.Time) time.Time {
+	var first time.Time
+	for _, t := range times {
+		if !t.IsZero() && (first.IsZero() || t.Before(first)) {
+			first = t
+		}
+	}
+	return first
+}
@@ -0,0 +1,71 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"subdomain-finder/internal/types"
+)
+
+func TestMergeChangedFields(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	previous := []types.Result{{
+		Subdomain:       "www.example.com",
+		Technologies:    []types.Technology{{Name: "nginx"}},
+		Vulnerabilities: []types.Vulnerability{{Name: "Missing HSTS", Severity: "low", Evidence: "no header"}},
+	}}
+
+	tests := []struct {
+		name    string
+		current types.Result
+		change  string
+		fields  []string
+	}{
+		{
+			name: "technology case only",
+			current: types.Result{
+				Subdomain:       "www.example.com",
+				Technologies:    []types.Technology{{Name: "Nginx"}},
+				Vulnerabilities: []types.Vulnerability{{Name: "Missing HSTS", Severity: "low", Evidence: "no header"}},
+			},
+			change: ChangeUnchanged,
+		},
+		{
+			name: "vulnerability evidence",
+			current: types.Result{
+				Subdomain:       "www.example.com",
+				Technologies:    []types.Technology{{Name: "nginx"}},
+				Vulnerabilities: []types.Vulnerability{{Name: "Missing HSTS", Severity: "low", Evidence: "max-age=0"}},
+			},
+			change: ChangeChanged,
+			fields: []string{"vulnerabilities"},
+		},
+		{
+			name: "port opened",
+			current: types.Result{
+				Subdomain:       "www.example.com",
+				Ports:           []types.PortInfo{{Port: 22, Protocol: "tcp"}},
+				Technologies:    []types.Technology{{Name: "nginx"}},
+				Vulnerabilities: []types.Vulnerability{{Name: "Missing HSTS", Severity: "low", Evidence: "no header"}},
+			},
+			change: ChangeChanged,
+			fields: []string{"ports"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, _ := Merge(previous, []types.Result{tt.current}, now)
+			if len(merged) != 1 {
+				t.Fatalf("got %d results, want 1", len(merged))
+			}
+			if merged[0].Change != tt.change {
+				t.Errorf("change = %q, want %q", merged[0].Change, tt.change)
+			}
+			if !reflect.DeepEqual(merged[0].ChangedFields, tt.fields) {
+				t.Errorf("changed fields = %v, want %v", merged[0].ChangedFields, tt.fields)
+			}
+		})
+	}
+}
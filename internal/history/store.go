@@ -0,0 +1,108 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"subdomain-finder/internal/fileutil"
+	"subdomain-finder/internal/types"
+)
+
+type Record struct {
+	Subdomain string    `json:"subdomain"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Present   bool      `json:"present"`
+}
+
+type Changes struct {
+	New         []string `json:"new"`
+	Disappeared []Record `json:"disappeared"`
+}
+
+// Store persists first-seen/last-seen timestamps per subdomain between runs
+// as a JSON file, one file per target.
+type Store struct {
+	path    string
+	LastRun time.Time          `json:"last_run"`
+	Records map[string]*Record `json:"records"`
+}
+
+func Load(path string) (*Store, error) {
+	store := &Store{path: path, Records: make(map[string]*Record)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse history %s: %w", path, err)
+	}
+	if store.Records == nil {
+		store.Records = make(map[string]*Record)
+	}
+	return store, nil
+}
+
+// Update records this run's results, fills in their FirstSeen, LastSeen and
+// New fields, and reports what changed. A subdomain is new when it was not
+// present in the previous run; nothing is new on the very first run.
+func (s *Store) Update(results []types.Result, now time.Time) Changes {
+	changes := Changes{New: make([]string, 0), Disappeared: make([]Record, 0)}
+	hadPriorRun := !s.LastRun.IsZero()
+	seen := make(map[string]bool)
+
+	for i := range results {
+		subdomain := results[i].Subdomain
+		if subdomain == "" {
+			continue
+		}
+		seen[subdomain] = true
+
+		record, exists := s.Records[subdomain]
+		if !exists {
+			record = &Record{Subdomain: subdomain, FirstSeen: now}
+			s.Records[subdomain] = record
+		}
+		if hadPriorRun && !record.Present {
+			results[i].New = true
+			changes.New = append(changes.New, subdomain)
+		}
+		record.LastSeen = now
+		record.Present = true
+
+		results[i].FirstSeen = record.FirstSeen
+		results[i].LastSeen = record.LastSeen
+	}
+
+	for subdomain, record := range s.Records {
+		if record.Present && !seen[subdomain] {
+			record.Present = false
+			changes.Disappeared = append(changes.Disappeared, *record)
+		}
+	}
+
+	sort.Strings(changes.New)
+	sort.Slice(changes.Disappeared, func(i, j int) bool {
+		return changes.Disappeared[i].Subdomain < changes.Disappeared[j].Subdomain
+	})
+
+	s.LastRun = now
+	return changes
+}
+
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+
+	return fileutil.WriteFile(s.path, data)
+}
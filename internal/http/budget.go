@@ -0,0 +1,30 @@
+package http
+
+import (
+	"net/http"
+
+	"subdomain-finder/internal/limiter"
+)
+
+type budgetTransport struct {
+	base   http.RoundTripper
+	budget *limiter.Budget
+}
+
+// NewBudgetTransport wraps base so each request, redirects included, holds
+// a slot of budget until its response headers arrive. A nil base uses
+// http.DefaultTransport.
+func NewBudgetTransport(base http.RoundTripper, budget *limiter.Budget) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &budgetTransport{base: base, budget: budget}
+}
+
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.budget.Acquire(req.Context()); err != nil {
+		return nil, err
+	}
+	defer t.budget.Release()
+	return t.base.RoundTrip(req)
+}
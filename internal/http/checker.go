@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -13,9 +14,14 @@ type Checker struct {
 	client  *http.Client
 }
 
+// maxBodySize caps how much of a response body is kept.
+const maxBodySize = 1 << 20
+
 type HTTPResponse struct {
+	URL        string
 	StatusCode int
 	Headers    map[string][]string
+	Cookies    []*http.Cookie
 	Body       string
 	Title      string
 	Server     string
@@ -25,7 +31,8 @@ type HTTPResponse struct {
 func NewChecker(timeoutSeconds int) *Checker {
 	timeout := time.Duration(timeoutSeconds) * time.Second
 	client := &http.Client{
-		Timeout: timeout,
+		Timeout:   timeout,
+		Transport: NewDecodingTransport(nil),
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
@@ -37,27 +44,52 @@ func NewChecker(timeoutSeconds int) *Checker {
 	}
 }
 
+func (c *Checker) SetTransport(transport http.RoundTripper) {
+	c.client.Transport = transport
+}
+
+func (c *Checker) SetCookieJar(jar http.CookieJar) {
+	c.client.Jar = jar
+}
+
 func (c *Checker) Check(domain string) (string, string) {
+	return c.Describe(c.Fetch(domain))
+}
+
+func (c *Checker) Fetch(domain string) *HTTPResponse {
+	return c.FetchContext(context.Background(), domain)
+}
+
+// FetchContext is Fetch with the requests bound to ctx.
+func (c *Checker) FetchContext(ctx context.Context, domain string) *HTTPResponse {
 	urls := []string{
 		fmt.Sprintf("http://%s", domain),
 		fmt.Sprintf("https://%s", domain),
 	}
 
 	for _, url := range urls {
-		response := c.makeRequest(url)
+		response := c.makeRequest(ctx, url)
 		if response != nil {
-			status := fmt.Sprintf("%d", response.StatusCode)
-			info := fmt.Sprintf("Status: %d, Server: %s, Title: %s, Length: %d",
-				response.StatusCode, response.Server, response.Title, response.Length)
-			return status, info
+			return response
 		}
 	}
 
-	return "N/A", "No HTTP response"
+	return nil
 }
 
-func (c *Checker) makeRequest(url string) *HTTPResponse {
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+func (c *Checker) Describe(response *HTTPResponse) (string, string) {
+	if response == nil {
+		return "N/A", "No HTTP response"
+	}
+
+	status := fmt.Sprintf("%d", response.StatusCode)
+	info := fmt.Sprintf("Status: %d, Server: %s, Title: %s, Length: %d",
+		response.StatusCode, response.Server, response.Title, response.Length)
+	return status, info
+}
+
+func (c *Checker) makeRequest(ctx context.Context, url string) *HTTPResponse {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -74,22 +106,32 @@ func (c *Checker) makeRequest(url string) *HTTPResponse {
 	defer resp.Body.Close()
 
 	response := &HTTPResponse{
+		URL:        resp.Request.URL.String(),
 		StatusCode: resp.StatusCode,
 		Headers:    resp.Header,
+		Cookies:    resp.Cookies(),
 		Server:     resp.Header.Get("Server"),
 		Length:     int(resp.ContentLength),
 	}
 
-	if resp.ContentLength > 0 && resp.ContentLength < 1024*1024 {
-		buffer := make([]byte, resp.ContentLength)
-		_, _ = resp.Body.Read(buffer)
-		response.Body = string(buffer)
-		response.Title = c.extractTitle(response.Body)
-	}
+	c.readBody(resp, response)
 
 	return response
 }
 
+// readBody reads up to maxBodySize bytes whatever the Content-Length says,
+// so chunked and streamed pages get a title too. A body read only in part
+// before an error is still used. Length falls back to the bytes read when
+// the server sent no Content-Length.
+func (c *Checker) readBody(resp *http.Response, response *HTTPResponse) {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	response.Body = string(body)
+	response.Title = c.extractTitle(response.Body)
+	if response.Length < 0 {
+		response.Length = len(body)
+	}
+}
+
 func (c *Checker) extractTitle(body string) string {
 	start := strings.Index(strings.ToLower(body), "<title>")
 	if start == -1 {
@@ -129,7 +171,7 @@ func (c *Checker) CheckMultiple(domains []string) map[string]*HTTPResponse {
 		}
 
 		for _, url := range urls {
-			response := c.makeRequest(url)
+			response := c.makeRequest(context.Background(), url)
 			if response != nil {
 				results[domain] = response
 				break
@@ -174,12 +216,7 @@ func (c *Checker) CheckWithCustomHeaders(domain string, headers map[string]strin
 			Length:     int(resp.ContentLength),
 		}
 
-		if resp.ContentLength > 0 && resp.ContentLength < 1024*1024 {
-			buffer := make([]byte, resp.ContentLength)
-			_, _ = resp.Body.Read(buffer)
-			response.Body = string(buffer)
-			response.Title = c.extractTitle(response.Body)
-		}
+		c.readBody(resp, response)
 
 		return response
 	}
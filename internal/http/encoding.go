@@ -0,0 +1,103 @@
+package http
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// AcceptEncoding is advertised on every scanning request that does not set
+// its own Accept-Encoding header.
+const AcceptEncoding = "gzip, deflate, br"
+
+type decodingTransport struct {
+	base http.RoundTripper
+}
+
+// NewDecodingTransport wraps base so requests advertise gzip, deflate and
+// brotli and responses reach body analysis already decoded. A nil base uses
+// http.DefaultTransport.
+func NewDecodingTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &decodingTransport{base: base}
+}
+
+func (t *decodingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", AcceptEncoding)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	DecodeBody(resp)
+	return resp, nil
+}
+
+// DecodeBody replaces resp.Body with a reader that undoes a gzip, deflate or
+// br Content-Encoding. Other encodings are left untouched.
+func DecodeBody(resp *http.Response) {
+	var newReader func(io.Reader) (io.Reader, error)
+
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		newReader = func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }
+	case "deflate":
+		newReader = newDeflateReader
+	case "br":
+		newReader = func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil }
+	default:
+		return
+	}
+
+	resp.Body = &decodedBody{body: resp.Body, newReader: newReader}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// newDeflateReader accepts both zlib-wrapped deflate, which the RFC
+// requires, and the raw deflate streams some servers send instead.
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+// decodedBody creates its decompressor on first read so empty bodies, such
+// as HEAD responses, never fail on a missing compression header.
+type decodedBody struct {
+	body      io.ReadCloser
+	newReader func(io.Reader) (io.Reader, error)
+	reader    io.Reader
+	err       error
+}
+
+func (b *decodedBody) Read(p []byte) (int, error) {
+	if b.reader == nil && b.err == nil {
+		b.reader, b.err = b.newReader(b.body)
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.reader.Read(p)
+}
+
+func (b *decodedBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+const page = "<html><head><title>Encoded Page</title></head><body><p>content</p></body></html>"
+
+func compress(t *testing.T, encoding string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	default:
+		return []byte(page)
+	}
+	if _, err := w.Write([]byte(page)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// encodedServer answers with page compressed as its encoding query
+// parameter asks. "raw-deflate" is sent as deflate without the zlib wrapper.
+func encodedServer(t *testing.T) (*httptest.Server, *string) {
+	var acceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		encoding := r.URL.Query().Get("encoding")
+		body := compress(t, encoding)
+		switch encoding {
+		case "raw-deflate":
+			w.Header().Set("Content-Encoding", "deflate")
+		case "":
+		default:
+			w.Header().Set("Content-Encoding", encoding)
+		}
+		w.Write(body)
+	}))
+	return server, &acceptEncoding
+}
+
+func TestDecodingTransport(t *testing.T) {
+	server, acceptEncoding := encodedServer(t)
+	defer server.Close()
+	client := &http.Client{Transport: NewDecodingTransport(nil)}
+
+	for _, encoding := range []string{"", "gzip", "deflate", "raw-deflate", "br"} {
+		t.Run(encoding, func(t *testing.T) {
+			resp, err := client.Get(server.URL + "/?encoding=" + encoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(body) != page {
+				t.Errorf("body = %q, want the decoded page", body)
+			}
+			if resp.Header.Get("Content-Encoding") != "" {
+				t.Errorf("Content-Encoding %q left on a decoded body", resp.Header.Get("Content-Encoding"))
+			}
+			if *acceptEncoding != AcceptEncoding {
+				t.Errorf("Accept-Encoding = %q, want %q", *acceptEncoding, AcceptEncoding)
+			}
+		})
+	}
+}
+
+func TestCheckerDecodesBrotli(t *testing.T) {
+	server, _ := encodedServer(t)
+	defer server.Close()
+
+	response := NewChecker(5).Fetch(strings.TrimPrefix(server.URL, "http://") + "/?encoding=br")
+	if response == nil {
+		t.Fatal("no response")
+	}
+	if response.Title != "Encoded Page" {
+		t.Errorf("title = %q, want %q", response.Title, "Encoded Page")
+	}
+	if response.Length != len(page) {
+		t.Errorf("length = %d, want %d", response.Length, len(page))
+	}
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// linkAttributes maps the elements that lead to further endpoints to the
+// attribute holding the URL.
+var linkAttributes = map[string]string{
+	"a":      "href",
+	"script": "src",
+	"form":   "action",
+	"iframe": "src",
+}
+
+// ExtractLinks returns the sorted, deduplicated links, script URLs and form
+// endpoints in body that point at domain or one of its subdomains. Relative
+// URLs are resolved against pageURL and fragments are dropped.
+func ExtractLinks(pageURL, body, domain string) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	seen := make(map[string]bool)
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			links := make([]string, 0, len(seen))
+			for link := range seen {
+				links = append(links, link)
+			}
+			sort.Strings(links)
+			return links
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			name, ok := linkAttributes[token.Data]
+			if !ok {
+				continue
+			}
+
+			value := attribute(token, name)
+			// A form without an action submits to the page itself
+			if value == "" && token.Data != "form" {
+				continue
+			}
+			if link, ok := resolveLink(base, value, domain); ok {
+				seen[link] = true
+			}
+		}
+	}
+}
+
+func resolveLink(base *url.URL, value, domain string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "#") {
+		return "", false
+	}
+
+	link, err := base.Parse(value)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") {
+		return "", false
+	}
+
+	host := strings.ToLower(link.Hostname())
+	if host != domain && !strings.HasSuffix(host, "."+domain) {
+		return "", false
+	}
+
+	link.Fragment = ""
+	link.RawFragment = ""
+	return link.String(), true
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const maxLoginPageSize = 1 << 20
+
+type loginForm struct {
+	action      string
+	method      string
+	hidden      url.Values
+	hasPassword bool
+}
+
+// Login signs in by fetching loginURL, copying the hidden fields of its login
+// form (CSRF tokens and the like) into data, and submitting the form. The
+// session cookies end up in client's jar, which must be set. Many sites set
+// a cookie on the login page already, so the login only counts when the
+// submit sets a new or changed cookie and does not show the password form
+// again.
+func Login(client *http.Client, loginURL, data string) error {
+	if client.Jar == nil {
+		return fmt.Errorf("login requires a cookie jar")
+	}
+
+	values, err := url.ParseQuery(data)
+	if err != nil {
+		return fmt.Errorf("invalid login data: %w", err)
+	}
+
+	resp, err := client.Get(loginURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch login page: %w", err)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxLoginPageSize))
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read login page: %w", err)
+	}
+
+	pageURL := resp.Request.URL
+	target := pageURL
+	method := http.MethodPost
+	if form := findLoginForm(string(body)); form != nil {
+		for name, fieldValues := range form.hidden {
+			if _, ok := values[name]; !ok {
+				values[name] = fieldValues
+			}
+		}
+		if action, err := pageURL.Parse(strings.TrimSpace(form.action)); err == nil {
+			target = action
+		}
+		if strings.EqualFold(form.method, http.MethodGet) {
+			method = http.MethodGet
+		}
+	}
+
+	var req *http.Request
+	if method == http.MethodGet {
+		submitURL := *target
+		submitURL.RawQuery = values.Encode()
+		req, err = http.NewRequest(method, submitURL.String(), nil)
+	} else {
+		req, err = http.NewRequest(method, target.String(), strings.NewReader(values.Encode()))
+		if req != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to build login request: %w", err)
+	}
+	req.Header.Set("Referer", pageURL.String())
+
+	before := cookieValues(client.Jar, target)
+	resp, err = client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to submit login form: %w", err)
+	}
+	body, err = io.ReadAll(io.LimitReader(resp.Body, maxLoginPageSize))
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read login response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("login form returned status %d", resp.StatusCode)
+	}
+	if !cookiesChanged(before, cookieValues(client.Jar, target)) && !cookiesChanged(before, cookieValues(client.Jar, resp.Request.URL)) {
+		return fmt.Errorf("login to %s did not set a session cookie", target.Host)
+	}
+	if form := findLoginForm(string(body)); form != nil && form.hasPassword {
+		return fmt.Errorf("login to %s returned the login form again, check the credentials", target.Host)
+	}
+	return nil
+}
+
+// cookieValues returns the name and value of each cookie jar sends to u.
+func cookieValues(jar http.CookieJar, u *url.URL) map[string]string {
+	values := make(map[string]string)
+	for _, cookie := range jar.Cookies(u) {
+		values[cookie.Name] = cookie.Value
+	}
+	return values
+}
+
+// cookiesChanged reports whether after holds a cookie that before did not,
+// or one whose value changed.
+func cookiesChanged(before, after map[string]string) bool {
+	for name, value := range after {
+		if old, ok := before[name]; !ok || old != value {
+			return true
+		}
+	}
+	return false
+}
+
+// findLoginForm returns the first form with a password input, falling back
+// to the first form on the page.
+func findLoginForm(body string) *loginForm {
+	var forms []*loginForm
+	var current *loginForm
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			for _, form := range forms {
+				if form.hasPassword {
+					return form
+				}
+			}
+			if len(forms) > 0 {
+				return forms[0]
+			}
+			return nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "form":
+				current = &loginForm{
+					action: attribute(token, "action"),
+					method: attribute(token, "method"),
+					hidden: url.Values{},
+				}
+				forms = append(forms, current)
+			case "input":
+				if current == nil {
+					continue
+				}
+				switch strings.ToLower(strings.TrimSpace(attribute(token, "type"))) {
+				case "hidden":
+					if name := attribute(token, "name"); name != "" {
+						current.hidden.Add(name, attribute(token, "value"))
+					}
+				case "password":
+					current.hasPassword = true
+				}
+			}
+		case html.EndTagToken:
+			if tokenizer.Token().Data == "form" {
+				current = nil
+			}
+		}
+	}
+}
+
+func attribute(token html.Token, name string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const loginPage = `<html><body>
+<form method="post" action="/login">
+<input type="hidden" name="csrf" value="token123">
+<input name="user"><input type="password" name="password">
+</form></body></html>`
+
+// loginServer serves a login page that sets an anonymous session cookie,
+// like most frameworks, and accepts the password "right".
+func loginServer(t *testing.T, failure func(w http.ResponseWriter)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/login" && r.Method == http.MethodGet:
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "anonymous", Path: "/"})
+			w.Write([]byte(loginPage))
+		case r.URL.Path == "/login" && r.Method == http.MethodPost:
+			if r.FormValue("csrf") != "token123" {
+				http.Error(w, "bad csrf", http.StatusForbidden)
+				return
+			}
+			if r.FormValue("password") != "right" {
+				failure(w)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "authenticated", Path: "/"})
+			http.Redirect(w, r, "/home", http.StatusFound)
+		default:
+			w.Write([]byte("home"))
+		}
+	}))
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		failure func(w http.ResponseWriter)
+		wantErr string
+	}{
+		{
+			name: "right password",
+			data: "user=admin&password=right",
+		},
+		{
+			name: "wrong password shows the form again",
+			data: "user=admin&password=wrong",
+			failure: func(w http.ResponseWriter) {
+				http.SetCookie(w, &http.Cookie{Name: "flash", Value: "invalid", Path: "/"})
+				w.Write([]byte(loginPage))
+			},
+			wantErr: "login form again",
+		},
+		{
+			name: "wrong password keeps the page cookie",
+			data: "user=admin&password=wrong",
+			failure: func(w http.ResponseWriter) {
+				w.Write([]byte("<p>Invalid credentials</p>"))
+			},
+			wantErr: "did not set a session cookie",
+		},
+		{
+			name: "wrong password resets the same cookie",
+			data: "user=admin&password=wrong",
+			failure: func(w http.ResponseWriter) {
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "anonymous", Path: "/"})
+				w.Write([]byte("<p>Invalid credentials</p>"))
+			},
+			wantErr: "did not set a session cookie",
+		},
+		{
+			name: "error status",
+			data: "user=admin&password=wrong",
+			failure: func(w http.ResponseWriter) {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+			},
+			wantErr: "status 401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := loginServer(t, tt.failure)
+			defer server.Close()
+
+			jar, _ := cookiejar.New(nil)
+			err := Login(&http.Client{Jar: jar}, server.URL+"/login", tt.data)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Login: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Login error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"net/http"
+
+	"subdomain-finder/internal/limiter"
+)
+
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *limiter.RateLimiter
+}
+
+// NewRateLimitedTransport wraps base so every request, redirects included,
+// first waits for a token from rl. The wait ends early when the request's
+// context is done. A nil base uses http.DefaultTransport.
+func NewRateLimitedTransport(base http.RoundTripper, rl *limiter.RateLimiter) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &rateLimitedTransport{base: base, limiter: rl}
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
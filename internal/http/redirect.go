@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// maxRedirects matches net/http's own redirect limit.
+const maxRedirects = 10
+
+type Redirect struct {
+	URL        string
+	StatusCode int
+	Location   string
+}
+
+// CheckWithRedirects is Check that also returns the redirect chain the
+// first response starts.
+func (c *Checker) CheckWithRedirects(domain string) (string, string, []Redirect) {
+	response := c.Fetch(domain)
+	status, info := c.Describe(response)
+	return status, info, c.FollowRedirects(response)
+}
+
+// FollowRedirects walks the Location chain starting at response and returns
+// one hop per redirect, ending at the first non-redirect, a loop or
+// maxRedirects. Locations are resolved to absolute URLs.
+func (c *Checker) FollowRedirects(response *HTTPResponse) []Redirect {
+	return c.FollowRedirectsContext(context.Background(), response)
+}
+
+// FollowRedirectsContext is FollowRedirects with the requests bound to ctx.
+func (c *Checker) FollowRedirectsContext(ctx context.Context, response *HTTPResponse) []Redirect {
+	var hops []Redirect
+	visited := make(map[string]bool)
+
+	for response != nil && len(hops) < maxRedirects {
+		location := http.Header(response.Headers).Get("Location")
+		if !isRedirect(response.StatusCode) || location == "" {
+			break
+		}
+
+		base, err := url.Parse(response.URL)
+		if err != nil {
+			break
+		}
+		next, err := base.Parse(location)
+		if err != nil {
+			break
+		}
+
+		hops = append(hops, Redirect{
+			URL:        response.URL,
+			StatusCode: response.StatusCode,
+			Location:   next.String(),
+		})
+		if visited[next.String()] {
+			break
+		}
+		visited[response.URL] = true
+
+		response = c.makeRequest(ctx, next.String())
+	}
+
+	return hops
+}
+
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
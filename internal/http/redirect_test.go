@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// redirectServer serves a 301 → 302 → 200 chain from /, a two-page loop
+// under /loop and an endless chain of new pages under /endless.
+func redirectServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/":
+			http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+		case r.URL.Path == "/moved":
+			// Relative to /moved, so it resolves to /final
+			w.Header().Set("Location", "final")
+			w.WriteHeader(http.StatusFound)
+		case r.URL.Path == "/loop/a":
+			http.Redirect(w, r, "/loop/b", http.StatusFound)
+		case r.URL.Path == "/loop/b":
+			http.Redirect(w, r, "/loop/a", http.StatusFound)
+		case strings.HasPrefix(r.URL.Path, "/endless/"):
+			n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/endless/"))
+			http.Redirect(w, r, fmt.Sprintf("/endless/%d", n+1), http.StatusTemporaryRedirect)
+		default:
+			w.Write([]byte("<title>Final</title>"))
+		}
+	}))
+}
+
+func TestCheckWithRedirects(t *testing.T) {
+	server := redirectServer()
+	defer server.Close()
+
+	c := NewChecker(5)
+	status, _, hops := c.CheckWithRedirects(strings.TrimPrefix(server.URL, "http://"))
+	// The status is the first response's; the chain is reported separately
+	if status != "301" {
+		t.Errorf("status = %s, want 301", status)
+	}
+
+	want := []Redirect{
+		{URL: server.URL, StatusCode: http.StatusMovedPermanently, Location: server.URL + "/moved"},
+		{URL: server.URL + "/moved", StatusCode: http.StatusFound, Location: server.URL + "/final"},
+	}
+	if !reflect.DeepEqual(hops, want) {
+		t.Errorf("hops = %+v, want %+v", hops, want)
+	}
+}
+
+func TestFollowRedirectsStops(t *testing.T) {
+	server := redirectServer()
+	defer server.Close()
+
+	c := NewChecker(5)
+	tests := []struct {
+		name     string
+		path     string
+		wantHops int
+		wantLast string
+	}{
+		{"loop", "/loop/a", 2, server.URL + "/loop/a"},
+		{"hop limit", "/endless/0", maxRedirects, fmt.Sprintf("%s/endless/%d", server.URL, maxRedirects)},
+		{"no redirect", "/final", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hops := c.FollowRedirectsContext(context.Background(), c.makeRequest(context.Background(), server.URL+tt.path))
+			if len(hops) != tt.wantHops {
+				t.Fatalf("got %d hops, want %d: %+v", len(hops), tt.wantHops, hops)
+			}
+			if tt.wantHops > 0 && hops[len(hops)-1].Location != tt.wantLast {
+				t.Errorf("last Location = %s, want %s", hops[len(hops)-1].Location, tt.wantLast)
+			}
+		})
+	}
+}
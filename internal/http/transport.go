@@ -0,0 +1,78 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseTLSVersion maps "1.0" through "1.3" (optionally prefixed with "TLS")
+// to a crypto/tls version constant. An empty string returns 0, which keeps
+// Go's default.
+func ParseTLSVersion(version string) (uint16, error) {
+	version = strings.TrimSpace(strings.ToLower(version))
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "tls"), "v")
+	if version == "" {
+		return 0, nil
+	}
+
+	value, ok := tlsVersions[version]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version %q (use 1.0, 1.1, 1.2 or 1.3)", version)
+	}
+	return value, nil
+}
+
+type TransportConfig struct {
+	MinVersion      uint16
+	MaxVersion      uint16
+	RootCAs         *x509.CertPool
+	MaxConnsPerHost int
+}
+
+// NewTransport returns a transport shared by the scanning clients. Zero
+// versions leave Go's secure defaults in place, a nil RootCAs uses the
+// system roots and a zero MaxConnsPerHost does not limit connections.
+func NewTransport(config TransportConfig) (*http.Transport, error) {
+	if config.MinVersion != 0 && config.MaxVersion != 0 && config.MinVersion > config.MaxVersion {
+		return nil, fmt.Errorf("TLS minimum version is higher than the maximum version")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion: config.MinVersion,
+		MaxVersion: config.MaxVersion,
+		RootCAs:    config.RootCAs,
+	}
+	transport.MaxConnsPerHost = config.MaxConnsPerHost
+	return transport, nil
+}
+
+// LoadCABundle returns the system roots extended with the PEM certificates
+// in path, for networks where a TLS-intercepting proxy re-signs traffic.
+func LoadCABundle(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
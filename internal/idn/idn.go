@@ -0,0 +1,108 @@
+package idn
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// Latin look-alikes from other scripts commonly used in homograph attacks
+var confusables = map[rune]bool{
+	'а': true, 'с': true, 'е': true, 'һ': true, 'і': true, 'ј': true, 'ӏ': true,
+	'о': true, 'р': true, 'ѕ': true, 'у': true, 'х': true, 'ԁ': true, 'ԛ': true,
+	'ԝ': true, 'ɡ': true, 'α': true, 'ο': true, 'ν': true, 'τ': true, 'ρ': true,
+	'κ': true, 'ι': true, 'υ': true, 'ε': true,
+}
+
+var scripts = map[string]*unicode.RangeTable{
+	"Latin":    unicode.Latin,
+	"Cyrillic": unicode.Cyrillic,
+	"Greek":    unicode.Greek,
+	"Armenian": unicode.Armenian,
+}
+
+// ToASCII converts a possibly Unicode host name to its punycode form for DNS.
+// Pure ASCII names are returned unchanged so labels such as "_dmarc" that
+// IDNA validation rejects keep working.
+func ToASCII(name string) (string, error) {
+	if isASCII(name) {
+		return name, nil
+	}
+
+	ascii, err := idna.Lookup.ToASCII(strings.TrimSuffix(name, "."))
+	if err != nil {
+		return "", fmt.Errorf("invalid internationalized name %q: %w", name, err)
+	}
+	return ascii, nil
+}
+
+// ToUnicode returns the display form of a punycode host name, falling back
+// to the input when it cannot be decoded.
+func ToUnicode(name string) string {
+	if !strings.Contains(name, "xn--") {
+		return name
+	}
+
+	unicodeName, err := idna.Display.ToUnicode(name)
+	if err != nil {
+		return name
+	}
+	return unicodeName
+}
+
+// HomographIndicator explains why a punycode host looks like a homograph
+// of a Latin name, or returns "" when none of its labels are suspicious.
+func HomographIndicator(asciiName string) string {
+	for _, label := range strings.Split(asciiName, ".") {
+		if !strings.HasPrefix(label, "xn--") {
+			continue
+		}
+
+		display := ToUnicode(label)
+		found := make(map[string]bool)
+		allConfusable := true
+		for _, r := range display {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			for name, table := range scripts {
+				if unicode.Is(table, r) {
+					found[name] = true
+				}
+			}
+			if !unicode.Is(unicode.Latin, r) && !confusables[r] {
+				allConfusable = false
+			}
+		}
+
+		if len(found) > 1 {
+			return fmt.Sprintf("label %s displays as %q and mixes %s scripts", label, display, strings.Join(sortedKeys(found), "/"))
+		}
+		if allConfusable && !found["Latin"] && len(found) == 1 {
+			return fmt.Sprintf("label %s displays as %q using only Latin look-alike characters", label, display)
+		}
+	}
+	return ""
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for _, name := range []string{"Latin", "Cyrillic", "Greek", "Armenian"} {
+		if set[name] {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
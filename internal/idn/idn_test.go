@@ -0,0 +1,78 @@
+package idn
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		ascii   string
+		display string
+	}{
+		{name: "café.example.com", ascii: "xn--caf-dma.example.com", display: "café.example.com"},
+		{name: "Bücher.example.com", ascii: "xn--bcher-kva.example.com", display: "bücher.example.com"},
+		{name: "münchen.example.com.", ascii: "xn--mnchen-3ya.example.com", display: "münchen.example.com"},
+		{name: "例え.テスト", ascii: "xn--r8jz45g.xn--zckzah", display: "例え.テスト"},
+		{name: "www.example.com", ascii: "www.example.com", display: "www.example.com"},
+		{name: "_dmarc.example.com", ascii: "_dmarc.example.com", display: "_dmarc.example.com"},
+		{name: "xn--caf-dma.example.com", ascii: "xn--caf-dma.example.com", display: "café.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ascii, err := ToASCII(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ascii != tt.ascii {
+				t.Errorf("ToASCII = %q, want %q", ascii, tt.ascii)
+			}
+			display := ToUnicode(ascii)
+			if display != tt.display {
+				t.Errorf("ToUnicode = %q, want %q", display, tt.display)
+			}
+			if again, err := ToASCII(display); err != nil || again != ascii {
+				t.Errorf("ToASCII(%q) = %q, %v, want %q", display, again, err, ascii)
+			}
+		})
+	}
+}
+
+func TestToASCIIRejectsInvalidNames(t *testing.T) {
+	if _, err := ToASCII("bad\x00é.example.com"); err == nil {
+		t.Error("want an error for a disallowed rune")
+	}
+}
+
+func TestHomographIndicator(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "www.example.com"},
+		{name: "café.example.com"},
+		{name: "例え.example.com"},
+		// Cyrillic а followed by Latin pple
+		{name: "аpple.example.com", want: "mixes Latin/Cyrillic scripts"},
+		// Only Cyrillic letters that look Latin
+		{name: "раура.example.com", want: "only Latin look-alike characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ascii, err := ToASCII(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := HomographIndicator(ascii)
+			if tt.want == "" && got != "" {
+				t.Errorf("got %q, want no indicator", got)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("got %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package limiter
+
+import (
+	"context"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// Budget caps how many sockets a scan holds at once across every check
+// that shares it, however the checks fan out. A nil Budget is unlimited.
+// Holders must not acquire a second slot while holding one, or a full
+// budget can deadlock.
+type Budget struct {
+	slots *semaphore.Weighted
+}
+
+// NewBudget returns a budget of size slots, or nil (unlimited) when size is
+// zero or less.
+func NewBudget(size int) *Budget {
+	if size <= 0 {
+		return nil
+	}
+	return &Budget{slots: semaphore.NewWeighted(int64(size))}
+}
+
+// Acquire blocks until a slot is free or ctx is done.
+func (b *Budget) Acquire(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	return b.slots.Acquire(ctx, 1)
+}
+
+func (b *Budget) Release() {
+	if b != nil {
+		b.slots.Release(1)
+	}
+}
@@ -2,6 +2,7 @@ package limiter
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 )
@@ -23,17 +24,21 @@ func NewRateLimiter(rate int, interval time.Duration) *RateLimiter {
 	}
 }
 
+// Wait takes a token, blocking until one is free. Tokens refill evenly,
+// rate of them per interval, and at most rate are saved up while idle.
 func (rl *RateLimiter) Wait(ctx context.Context) error {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
+	perToken := rl.interval / time.Duration(max(rl.rate, 1))
 	now := time.Now()
-	elapsed := now.Sub(rl.lastTime)
 
-	tokensToAdd := int(elapsed / rl.interval)
-	if tokensToAdd > 0 {
-		rl.tokens = min(rl.tokens+tokensToAdd, rl.rate)
-		rl.lastTime = now
+	if added := int(now.Sub(rl.lastTime) / perToken); added > 0 {
+		rl.tokens = min(rl.tokens+added, rl.rate)
+		rl.lastTime = rl.lastTime.Add(time.Duration(added) * perToken)
+		if rl.tokens == rl.rate {
+			rl.lastTime = now
+		}
 	}
 
 	if rl.tokens > 0 {
@@ -41,16 +46,15 @@ func (rl *RateLimiter) Wait(ctx context.Context) error {
 		return nil
 	}
 
-	waitTime := rl.interval - elapsed
+	waitTime := rl.lastTime.Add(perToken).Sub(now)
 	if waitTime > 0 {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-time.After(waitTime):
-			rl.tokens = rl.rate - 1
-			rl.lastTime = time.Now()
 		}
 	}
+	rl.lastTime = rl.lastTime.Add(perToken)
 
 	return nil
 }
@@ -98,6 +102,26 @@ func (eb *ExponentialBackoff) GetDelay(attempt int) time.Duration {
 	return delay
 }
 
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps err so the Retryer returns it without further attempts.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
 type Retryer struct {
 	config RetryConfig
 }
@@ -123,6 +147,10 @@ func (r *Retryer) Execute(ctx context.Context, fn func() error) error {
 		if err == nil {
 			return nil
 		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
 
 		lastErr = err
 	}
@@ -148,6 +176,10 @@ func (r *Retryer) ExecuteWithResult(ctx context.Context, fn func() (interface{},
 		if err == nil {
 			return res, nil
 		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return res, permanent.err
+		}
 
 		result = res
 		lastErr = err
@@ -8,8 +8,11 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Logger writes through an entry so loggers derived with With keep their
+// fields; the underlying logrus.Logger is shared and safe for concurrent use.
 type Logger struct {
 	logger *logrus.Logger
+	entry  *logrus.Entry
 }
 
 func NewLogger(level, format string) *Logger {
@@ -42,7 +45,13 @@ func NewLogger(level, format string) *Logger {
 
 	logger.SetOutput(os.Stdout)
 
-	return &Logger{logger: logger}
+	return &Logger{logger: logger, entry: logrus.NewEntry(logger)}
+}
+
+// With returns a logger that adds key=value to every entry, sharing the
+// output and level of l.
+func (l *Logger) With(key string, value interface{}) *Logger {
+	return &Logger{logger: l.logger, entry: l.entry.WithField(key, value)}
 }
 
 func (l *Logger) SetOutput(w io.Writer) {
@@ -65,53 +74,53 @@ func (l *Logger) SetFile(filename string) error {
 }
 
 func (l *Logger) Debug(args ...interface{}) {
-	l.logger.Debug(args...)
+	l.entry.Debug(args...)
 }
 
 func (l *Logger) Debugf(format string, args ...interface{}) {
-	l.logger.Debugf(format, args...)
+	l.entry.Debugf(format, args...)
 }
 
 func (l *Logger) Info(args ...interface{}) {
-	l.logger.Info(args...)
+	l.entry.Info(args...)
 }
 
 func (l *Logger) Infof(format string, args ...interface{}) {
-	l.logger.Infof(format, args...)
+	l.entry.Infof(format, args...)
 }
 
 func (l *Logger) Warn(args ...interface{}) {
-	l.logger.Warn(args...)
+	l.entry.Warn(args...)
 }
 
 func (l *Logger) Warnf(format string, args ...interface{}) {
-	l.logger.Warnf(format, args...)
+	l.entry.Warnf(format, args...)
 }
 
 func (l *Logger) Error(args ...interface{}) {
-	l.logger.Error(args...)
+	l.entry.Error(args...)
 }
 
 func (l *Logger) Errorf(format string, args ...interface{}) {
-	l.logger.Errorf(format, args...)
+	l.entry.Errorf(format, args...)
 }
 
 func (l *Logger) Fatal(args ...interface{}) {
-	l.logger.Fatal(args...)
+	l.entry.Fatal(args...)
 }
 
 func (l *Logger) Fatalf(format string, args ...interface{}) {
-	l.logger.Fatalf(format, args...)
+	l.entry.Fatalf(format, args...)
 }
 
 func (l *Logger) WithField(key string, value interface{}) *logrus.Entry {
-	return l.logger.WithField(key, value)
+	return l.entry.WithField(key, value)
 }
 
 func (l *Logger) WithFields(fields logrus.Fields) *logrus.Entry {
-	return l.logger.WithFields(fields)
+	return l.entry.WithFields(fields)
 }
 
 func (l *Logger) WithError(err error) *logrus.Entry {
-	return l.logger.WithError(err)
+	return l.entry.WithError(err)
 }
@@ -0,0 +1,156 @@
+// Package merge combines result files from several scans of the same
+// domain into one result per subdomain.
+package merge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"subdomain-finder/internal/history"
+	"subdomain-finder/internal/types"
+)
+
+// Results merges result sets into one result per subdomain, sorted by name.
+// The most recently checked result of a subdomain is the base, so its
+// single-valued fields (IP, status, SSL, DNS, ...) win; ties go to the later
+// set. Ports, technologies, vulnerabilities, web services, endpoints and
+// tags are the union of every result, the risk level and confidence the
+// highest seen, and FirstSeen and LastSeen span them all.
+func Results(sets ...[]types.Result) []types.Result {
+	groups := make(map[string][]types.Result)
+	for _, set := range sets {
+		for _, result := range set {
+			if result.Subdomain == "" {
+				continue
+			}
+			key := strings.ToLower(result.Subdomain)
+			groups[key] = append(groups[key], result)
+		}
+	}
+
+	merged := make([]types.Result, 0, len(groups))
+	for _, group := range groups {
+		merged = append(merged, mergeGroup(group))
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Subdomain < merged[j].Subdomain
+	})
+	return merged
+}
+
+// mergeGroup merges the results of one subdomain, given in input order.
+func mergeGroup(group []types.Result) types.Result {
+	// Oldest first, so later results overwrite earlier ones
+	sort.SliceStable(group, func(i, j int) bool {
+		return checkedAt(group[i]).Before(checkedAt(group[j]))
+	})
+
+	merged := group[len(group)-1]
+	merged.Ports = nil
+	merged.Technologies = nil
+	merged.Vulnerabilities = nil
+	merged.WebServices = nil
+	merged.Endpoints = nil
+	merged.Tags = nil
+
+	for _, result := range group {
+		merged.Ports = unionPorts(merged.Ports, result.Ports)
+		merged.Technologies = unionTechnologies(merged.Technologies, result.Technologies)
+		merged.Vulnerabilities = unionVulnerabilities(merged.Vulnerabilities, result.Vulnerabilities)
+		merged.WebServices = unionWebServices(merged.WebServices, result.WebServices)
+		merged.Endpoints = unionStrings(merged.Endpoints, result.Endpoints)
+		merged.Tags = unionStrings(merged.Tags, result.Tags)
+
+		if types.SeverityRank(result.RiskLevel) > types.SeverityRank(merged.RiskLevel) {
+			merged.RiskLevel = result.RiskLevel
+		}
+		merged.Confidence = max(merged.Confidence, result.Confidence)
+		merged.FirstSeen = history.Earliest(merged.FirstSeen, result.FirstSeen, result.Timestamp)
+		if seen := checkedAt(result); seen.After(merged.LastSeen) {
+			merged.LastSeen = seen
+		}
+	}
+
+	// The most recent older result fills what the newest did not record
+	for i := len(group) - 2; i >= 0; i-- {
+		if merged.SSL == nil {
+			merged.SSL = group[i].SSL
+		}
+		if merged.DNS == nil {
+			merged.DNS = group[i].DNS
+		}
+		if merged.GeoLocation == nil {
+			merged.GeoLocation = group[i].GeoLocation
+		}
+	}
+
+	sort.Slice(merged.Ports, func(i, j int) bool {
+		if merged.Ports[i].Port != merged.Ports[j].Port {
+			return merged.Ports[i].Port < merged.Ports[j].Port
+		}
+		return merged.Ports[i].Protocol < merged.Ports[j].Protocol
+	})
+	sort.Slice(merged.WebServices, func(i, j int) bool {
+		return merged.WebServices[i].Port < merged.WebServices[j].Port
+	})
+	merged.Change = ""
+	merged.ChangedFields = nil
+	return merged
+}
+
+// checkedAt is when a result was last observed.
+func checkedAt(result types.Result) time.Time {
+	if result.LastSeen.After(result.Timestamp) {
+		return result.LastSeen
+	}
+	return result.Timestamp
+}
+
+// The union helpers let later entries replace earlier ones with the same
+// key, since results are merged oldest first.
+
+func unionPorts(ports, added []types.PortInfo) []types.PortInfo {
+	return union(ports, added, func(port types.PortInfo) string {
+		return fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+	})
+}
+
+func unionTechnologies(technologies, added []types.Technology) []types.Technology {
+	return union(technologies, added, func(tech types.Technology) string {
+		return strings.ToLower(tech.Name)
+	})
+}
+
+func unionVulnerabilities(vulns, added []types.Vulnerability) []types.Vulnerability {
+	return union(vulns, added, func(vuln types.Vulnerability) string {
+		return vuln.Name + "\x00" + vuln.Evidence
+	})
+}
+
+func unionWebServices(services, added []types.WebService) []types.WebService {
+	return union(services, added, func(service types.WebService) string {
+		return service.URL
+	})
+}
+
+func unionStrings(values, added []string) []string {
+	return union(values, added, func(value string) string { return value })
+}
+
+func union[T any](values, added []T, key func(T) string) []T {
+	index := make(map[string]int, len(values))
+	for i, value := range values {
+		index[key(value)] = i
+	}
+	for _, value := range added {
+		if i, ok := index[key(value)]; ok {
+			values[i] = value
+			continue
+		}
+		index[key(value)] = len(values)
+		values = append(values, value)
+	}
+	return values
+}
@@ -0,0 +1,126 @@
+package merge
+
+import (
+	"testing"
+	"time"
+
+	"subdomain-finder/internal/types"
+)
+
+var (
+	day1 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 = day1.Add(24 * time.Hour)
+	day3 = day2.Add(24 * time.Hour)
+)
+
+func TestResultsNewestWins(t *testing.T) {
+	older := types.Result{Subdomain: "www.example.com", IP: "192.0.2.1", Status: "200", Timestamp: day1}
+	newer := types.Result{Subdomain: "WWW.example.com", IP: "192.0.2.2", Status: "301", Timestamp: day2}
+
+	// Input order does not matter, only when each result was checked
+	merged := Results([]types.Result{newer}, []types.Result{older})
+	if len(merged) != 1 {
+		t.Fatalf("got %d results, want 1", len(merged))
+	}
+	if merged[0].IP != "192.0.2.2" || merged[0].Status != "301" {
+		t.Errorf("IP, status = %s, %s; want the newest 192.0.2.2, 301", merged[0].IP, merged[0].Status)
+	}
+}
+
+func TestResultsTimestamps(t *testing.T) {
+	merged := Results(
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day2, FirstSeen: day2}},
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day1}},
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day2, LastSeen: day3}},
+	)[0]
+
+	if !merged.FirstSeen.Equal(day1) {
+		t.Errorf("FirstSeen = %s, want %s", merged.FirstSeen, day1)
+	}
+	if !merged.LastSeen.Equal(day3) {
+		t.Errorf("LastSeen = %s, want %s", merged.LastSeen, day3)
+	}
+}
+
+func TestResultsPorts(t *testing.T) {
+	merged := Results(
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day1, Ports: []types.PortInfo{
+			{Port: 443, Protocol: "tcp", Service: "https"},
+			{Port: 22, Protocol: "tcp", Service: "ssh", Version: "OpenSSH 7.4"},
+		}}},
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day2, Ports: []types.PortInfo{
+			{Port: 22, Protocol: "tcp", Service: "ssh", Version: "OpenSSH 9.6"},
+			{Port: 53, Protocol: "udp", Service: "dns"},
+		}}},
+	)[0]
+
+	want := []types.PortInfo{
+		{Port: 22, Protocol: "tcp", Service: "ssh", Version: "OpenSSH 9.6"},
+		{Port: 53, Protocol: "udp", Service: "dns"},
+		{Port: 443, Protocol: "tcp", Service: "https"},
+	}
+	if len(merged.Ports) != len(want) {
+		t.Fatalf("ports = %+v, want %+v", merged.Ports, want)
+	}
+	for i := range want {
+		if merged.Ports[i] != want[i] {
+			t.Errorf("port %d = %+v, want %+v", i, merged.Ports[i], want[i])
+		}
+	}
+}
+
+func TestResultsVulnerabilities(t *testing.T) {
+	merged := Results(
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day1, Vulnerabilities: []types.Vulnerability{
+			{Name: "Missing HSTS", Severity: "Low"},
+			{Name: "Outdated Software", Severity: "Medium", Evidence: "nginx/1.14"},
+		}}},
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day2, Vulnerabilities: []types.Vulnerability{
+			{Name: "Missing HSTS", Severity: "Medium"},
+			{Name: "Outdated Software", Severity: "Medium", Evidence: "nginx/1.18"},
+		}}},
+	)[0]
+
+	if len(merged.Vulnerabilities) != 3 {
+		t.Fatalf("vulnerabilities = %+v, want 3", merged.Vulnerabilities)
+	}
+	if got := merged.Vulnerabilities[0]; got.Name != "Missing HSTS" || got.Severity != "Medium" {
+		t.Errorf("Missing HSTS = %+v, want the newest severity Medium", got)
+	}
+}
+
+func TestResultsSSL(t *testing.T) {
+	oldSSL := &types.SSLInfo{Grade: "B"}
+	newSSL := &types.SSLInfo{Grade: "A"}
+
+	// The newest result without SSL takes the most recent older one
+	merged := Results(
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day1, SSL: oldSSL}},
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day2, SSL: newSSL}},
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day3}},
+	)[0]
+	if merged.SSL != newSSL {
+		t.Errorf("SSL = %+v, want the day 2 grade A", merged.SSL)
+	}
+
+	merged = Results(
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day1, SSL: newSSL}},
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day2, SSL: oldSSL}},
+	)[0]
+	if merged.SSL != oldSSL {
+		t.Errorf("SSL = %+v, want the newest grade B", merged.SSL)
+	}
+}
+
+func TestResultsRisk(t *testing.T) {
+	merged := Results(
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day1, RiskLevel: "high", Confidence: 90}},
+		[]types.Result{{Subdomain: "a.example.com", Timestamp: day2, RiskLevel: "low", Confidence: 60}},
+	)[0]
+	if merged.RiskLevel != "high" {
+		t.Errorf("RiskLevel = %s, want the highest, high", merged.RiskLevel)
+	}
+	if merged.Confidence != 90 {
+		t.Errorf("Confidence = %d, want 90", merged.Confidence)
+	}
+}
@@ -0,0 +1,12 @@
+package netutil
+
+import "strings"
+
+// Unbracket strips the brackets of an IPv6 literal such as [2001:db8::1],
+// which net.JoinHostPort adds again when dialing.
+func Unbracket(host string) string {
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		return host[1 : len(host)-1]
+	}
+	return host
+}
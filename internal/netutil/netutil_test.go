@@ -0,0 +1,23 @@
+package netutil
+
+import "testing"
+
+func TestUnbracket(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"[2001:db8::1]", "2001:db8::1"},
+		{"2001:db8::1", "2001:db8::1"},
+		{"192.0.2.1", "192.0.2.1"},
+		{"www.example.com", "www.example.com"},
+		{"[2001:db8::1", "[2001:db8::1"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Unbracket(tt.host); got != tt.expected {
+			t.Errorf("Unbracket(%q) = %q, want %q", tt.host, got, tt.expected)
+		}
+	}
+}
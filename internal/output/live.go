@@ -0,0 +1,163 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"subdomain-finder/internal/progress"
+	"subdomain-finder/internal/types"
+)
+
+const (
+	// liveRows is how many of the latest results the live view shows, so a
+	// frame fits on one screen.
+	liveRows         = 15
+	liveCellWidth    = 50
+	liveBarWidth     = 30
+	liveRedrawPeriod = 250 * time.Millisecond
+)
+
+// LiveView redraws a table of the latest results and a progress line in
+// place while a scan runs. Log lines written to it are printed above the
+// frame.
+type LiveView struct {
+	out     io.Writer
+	colored bool
+
+	mu    sync.Mutex
+	rows  [][]string
+	stats progress.Stats
+	lines int
+	dirty bool
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewLiveView returns nil when console output is not a terminal, so callers
+// fall back to plain output.
+func (o *Outputter) NewLiveView() *LiveView {
+	if !o.isTerminal() {
+		return nil
+	}
+	return &LiveView{
+		out:     o.writer,
+		colored: o.colorEnabled(),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Start redraws the frame in the background until Stop.
+func (lv *LiveView) Start() {
+	go func() {
+		defer close(lv.stopped)
+		ticker := time.NewTicker(liveRedrawPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				lv.mu.Lock()
+				if lv.dirty {
+					lv.redraw()
+				}
+				lv.mu.Unlock()
+			case <-lv.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop draws the final frame and leaves it on screen.
+func (lv *LiveView) Stop() {
+	close(lv.done)
+	<-lv.stopped
+
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	lv.redraw()
+}
+
+func (lv *LiveView) AddResult(result types.Result) {
+	row := tableRow(result)
+	for i, cell := range row {
+		row[i] = truncate(cell, liveCellWidth)
+	}
+
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	lv.rows = append(lv.rows, row)
+	lv.dirty = true
+}
+
+func (lv *LiveView) Update(stats progress.Stats) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	lv.stats = stats
+	lv.dirty = true
+}
+
+// Write prints p above the frame, so log output does not break the redraw.
+func (lv *LiveView) Write(p []byte) (int, error) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	lv.clear()
+	n, err := lv.out.Write(p)
+	lv.lines = 0
+	lv.draw()
+	return n, err
+}
+
+func (lv *LiveView) redraw() {
+	lv.clear()
+	lv.draw()
+}
+
+// clear moves the cursor to the start of the last frame and erases it.
+func (lv *LiveView) clear() {
+	if lv.lines > 0 {
+		fmt.Fprintf(lv.out, "\x1b[%dA\x1b[J", lv.lines)
+		lv.lines = 0
+	}
+}
+
+func (lv *LiveView) draw() {
+	rows := lv.rows
+	var lines []string
+	if hidden := len(rows) - liveRows; hidden > 0 {
+		rows = rows[hidden:]
+		lines = append(lines, fmt.Sprintf("... %d earlier results", hidden))
+	}
+	lines = append(lines, tableLines(rows, lv.colored)...)
+	lines = append(lines, "", progressLine(lv.stats))
+
+	fmt.Fprintln(lv.out, strings.Join(lines, "\n"))
+	lv.lines = len(lines)
+	lv.dirty = false
+}
+
+func progressLine(stats progress.Stats) string {
+	var done float64
+	if stats.Total > 0 {
+		done = min(float64(stats.Completed)/float64(stats.Total), 1)
+	}
+	filled := int(done * liveBarWidth)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", liveBarWidth-filled)
+
+	return fmt.Sprintf("[%s] %3.0f%%  %d/%d  found %d  %.1f/s  elapsed %s  ETA %s",
+		bar, done*100, stats.Completed, stats.Total, stats.Found, stats.Rate,
+		stats.Elapsed.Round(time.Second), stats.ETA.Round(time.Second))
+}
+
+func truncate(text string, width int) string {
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:width-3]) + "..."
+}
@@ -1,14 +1,22 @@
 package output
 
 import (
+	"bufio"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"subdomain-finder/internal/diff"
+	"subdomain-finder/internal/fileutil"
 	"subdomain-finder/internal/finder"
+	"subdomain-finder/internal/history"
 	"subdomain-finder/internal/logger"
+	"subdomain-finder/internal/reporter"
 	"subdomain-finder/internal/types"
 
 	"github.com/fatih/color"
@@ -18,6 +26,7 @@ type Outputter struct {
 	config  finder.Config
 	logger  *logger.Logger
 	results []types.Result
+	writer  io.Writer
 }
 
 func NewOutputter(cfg finder.Config, log *logger.Logger) *Outputter {
@@ -25,9 +34,15 @@ func NewOutputter(cfg finder.Config, log *logger.Logger) *Outputter {
 		config:  cfg,
 		logger:  log,
 		results: make([]types.Result, 0),
+		writer:  os.Stdout,
 	}
 }
 
+// SetWriter redirects console output, which defaults to stdout.
+func (o *Outputter) SetWriter(w io.Writer) {
+	o.writer = w
+}
+
 func (o *Outputter) PrintResult(result types.Result, verbose bool) {
 	o.results = append(o.results, result)
 
@@ -36,124 +51,394 @@ func (o *Outputter) PrintResult(result types.Result, verbose bool) {
 	blue := color.New(color.FgBlue).SprintFunc()
 	white := color.New(color.FgWhite).SprintFunc()
 
-	fmt.Printf("[%s] %s -> %s",
+	fmt.Fprintf(o.writer, "[%s] %s -> %s",
 		green("FOUND"),
 		white(result.Subdomain),
 		blue(result.IP))
 
 	if result.Status != "N/A" {
-		fmt.Printf(" [%s]", yellow(result.Status))
+		fmt.Fprintf(o.writer, " [%s]", yellow(result.Status))
 	}
 
 	if verbose && result.Response != "" {
-		fmt.Printf(" | %s", result.Response)
+		fmt.Fprintf(o.writer, " | %s", result.Response)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(o.writer)
 }
 
 func (o *Outputter) PrintHeader(domain string) {
 	cyan := color.New(color.FgCyan).SprintFunc()
 	bold := color.New(color.Bold).SprintFunc()
 
-	fmt.Println()
-	fmt.Printf("%s %s %s\n",
+	fmt.Fprintln(o.writer)
+	fmt.Fprintf(o.writer, "%s %s %s\n",
 		cyan("="),
 		bold("SUBDOMAIN FINDER"),
 		cyan("="))
-	fmt.Printf("Target: %s\n", bold(domain))
-	fmt.Printf("Started: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	fmt.Println()
+	fmt.Fprintf(o.writer, "Target: %s\n", bold(domain))
+	fmt.Fprintf(o.writer, "Started: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintln(o.writer)
 }
 
-func (o *Outputter) PrintSummary(totalFound int, duration time.Duration) {
+// PrintSummary prints the totals; wildcardIPs are the addresses wildcard DNS
+// answered with, if the domain has it.
+func (o *Outputter) PrintSummary(totalFound int, duration time.Duration, wildcardIPs []string) {
 	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
 	cyan := color.New(color.FgCyan).SprintFunc()
 	bold := color.New(color.Bold).SprintFunc()
 
-	fmt.Println()
-	fmt.Printf("%s %s %s\n",
+	fmt.Fprintln(o.writer)
+	fmt.Fprintf(o.writer, "%s %s %s\n",
 		cyan("="),
 		bold("SUMMARY"),
 		cyan("="))
-	fmt.Printf("Total subdomains found: %s\n", green(totalFound))
-	fmt.Printf("Duration: %s\n", duration.String())
-	fmt.Println()
+	fmt.Fprintf(o.writer, "Total subdomains found: %s\n", green(totalFound))
+	fmt.Fprintf(o.writer, "Duration: %s\n", duration.String())
+	if len(wildcardIPs) > 0 {
+		fmt.Fprintf(o.writer, "Wildcard DNS: %s (%s)\n", yellow("detected"), strings.Join(wildcardIPs, ", "))
+	}
+	fmt.Fprintln(o.writer)
 }
 
-func (o *Outputter) SaveToFile(results []types.Result, filename string) {
-	if filename == "" {
+func (o *Outputter) PrintAttackSurface(surface *reporter.AttackSurface) {
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	bold := color.New(color.Bold).SprintFunc()
+
+	if len(surface.TopRisks) == 0 && len(surface.ExposedServices) == 0 && len(surface.ExpiringCerts) == 0 {
 		return
 	}
 
-	file, err := os.Create(filename)
-	if err != nil {
-		fmt.Printf("Error creating output file: %v\n", err)
-		return
+	fmt.Fprintf(o.writer, "%s %s %s\n",
+		cyan("="),
+		bold("ATTACK SURFACE"),
+		cyan("="))
+
+	if len(surface.TopRisks) > 0 {
+		fmt.Fprintln(o.writer, bold("Highest-risk subdomains:"))
+		for i, ranked := range surface.TopRisks {
+			fmt.Fprintf(o.writer, "  %d. %s (%s) score %s, risk %s\n", i+1, bold(ranked.Subdomain), ranked.IP, red(ranked.Score), ranked.RiskLevel)
+			for _, finding := range ranked.KeyFindings {
+				fmt.Fprintf(o.writer, "       - %s\n", finding)
+			}
+		}
+	}
+
+	if len(surface.ExposedServices) > 0 {
+		fmt.Fprintln(o.writer, bold("Most exposed services:"))
+		for _, service := range surface.ExposedServices {
+			marker := ""
+			if service.Sensitive {
+				marker = red(" (sensitive)")
+			}
+			fmt.Fprintf(o.writer, "  %d/%s on %d host(s)%s\n", service.Port, service.Service, service.Hosts, marker)
+		}
+	}
+
+	if len(surface.ExpiringCerts) > 0 {
+		fmt.Fprintln(o.writer, bold("Expiring certificates:"))
+		for _, cert := range surface.ExpiringCerts {
+			if cert.Expired {
+				fmt.Fprintf(o.writer, "  %s %s\n", cert.Subdomain, red("expired"))
+			} else {
+				fmt.Fprintf(o.writer, "  %s %s\n", cert.Subdomain, yellow(fmt.Sprintf("expires in %d days", cert.DaysUntilExpiry)))
+			}
+		}
+	}
+	fmt.Fprintln(o.writer)
+}
+
+func (o *Outputter) PrintChanges(changes history.Changes) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	bold := color.New(color.Bold).SprintFunc()
+
+	fmt.Fprintf(o.writer, "%s %s %s\n",
+		cyan("="),
+		bold("CHANGES SINCE LAST SCAN"),
+		cyan("="))
+	fmt.Fprintf(o.writer, "New: %s, Disappeared: %s\n", green(len(changes.New)), red(len(changes.Disappeared)))
+	for _, subdomain := range changes.New {
+		fmt.Fprintf(o.writer, "  %s %s\n", green("+"), subdomain)
+	}
+	for _, record := range changes.Disappeared {
+		fmt.Fprintf(o.writer, "  %s %s (last seen %s)\n", red("-"), record.Subdomain, record.LastSeen.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Fprintln(o.writer)
+}
+
+// PrintDiff lists the subdomains added, removed and changed between two
+// scans, with the ports, technologies and vulnerabilities that moved.
+func (o *Outputter) PrintDiff(report diff.Report) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	bold := color.New(color.Bold).SprintFunc()
+
+	fmt.Fprintf(o.writer, "%s %s %s\n",
+		cyan("="),
+		bold("SCAN DIFF"),
+		cyan("="))
+	fmt.Fprintf(o.writer, "Added: %s, Removed: %s, Changed: %s\n",
+		green(len(report.Added)), red(len(report.Removed)), yellow(len(report.Changed)))
+
+	for _, result := range report.Added {
+		fmt.Fprintf(o.writer, "  %s %s %s\n", green("+"), result.Subdomain, result.IP)
+	}
+	for _, result := range report.Removed {
+		fmt.Fprintf(o.writer, "  %s %s %s\n", red("-"), result.Subdomain, result.IP)
+	}
+	for _, change := range report.Changed {
+		fmt.Fprintf(o.writer, "  %s %s\n", yellow("~"), change.Subdomain)
+		for _, field := range change.Fields {
+			fmt.Fprintf(o.writer, "      %s: %s -> %s\n", field.Field, orDash(field.Old), orDash(field.New))
+		}
+		for _, port := range change.OpenedPorts {
+			fmt.Fprintf(o.writer, "      %s port %d/%s %s\n", green("+"), port.Port, port.Protocol, port.Service)
+		}
+		for _, port := range change.ClosedPorts {
+			fmt.Fprintf(o.writer, "      %s port %d/%s %s\n", red("-"), port.Port, port.Protocol, port.Service)
+		}
+		for _, tech := range change.AddedTechnologies {
+			fmt.Fprintf(o.writer, "      %s tech %s %s\n", green("+"), tech.Name, tech.Version)
+		}
+		for _, tech := range change.RemovedTechnologies {
+			fmt.Fprintf(o.writer, "      %s tech %s %s\n", red("-"), tech.Name, tech.Version)
+		}
+		for _, vuln := range change.NewVulnerabilities {
+			fmt.Fprintf(o.writer, "      %s vuln [%s] %s\n", red("+"), vuln.Severity, vuln.Name)
+		}
+		for _, vuln := range change.FixedVulnerabilities {
+			fmt.Fprintf(o.writer, "      %s vuln [%s] %s\n", green("-"), vuln.Severity, vuln.Name)
+		}
+	}
+	fmt.Fprintln(o.writer)
+}
+
+// PlanSettings are the settings a dry run reports that only the command
+// sees: where results go and the CA bundle the finder's pool came from.
+type PlanSettings struct {
+	OutputDir string
+	Formats   []string
+	CABundle  string
+}
+
+// PrintPlan prints what a scan would do with the effective settings, after
+// profile, environment and flag merging, without sending anything.
+func (o *Outputter) PrintPlan(plan finder.ScanPlan, settings PlanSettings) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	bold := color.New(color.Bold).SprintFunc()
+
+	wordlistSource := plan.Config.Wordlist
+	if wordlistSource == "" {
+		wordlistSource = "built-in"
+	}
+
+	fmt.Fprintln(o.writer)
+	fmt.Fprintf(o.writer, "%s %s %s\n",
+		cyan("="),
+		bold("DRY RUN"),
+		cyan("="))
+	fmt.Fprintf(o.writer, "Target: %s\n", bold(plan.Config.Domain))
+	if len(plan.Config.Hosts) > 0 {
+		fmt.Fprintf(o.writer, "Hosts: %d (DNS resolution skipped)\n", plan.Candidates)
+	} else {
+		fmt.Fprintf(o.writer, "Wordlist: %s (%d entries)\n", wordlistSource, plan.Candidates)
+	}
+	fmt.Fprintf(o.writer, "Threads: %d\n", plan.Config.Threads)
+	fmt.Fprintf(o.writer, "Timeout: %ds\n", plan.Config.Timeout)
+	fmt.Fprintf(o.writer, "Rate Limit: %d req/s\n", plan.Config.RateLimit)
+	fmt.Fprintf(o.writer, "Delay: %dms\n", plan.Config.Delay)
+	fmt.Fprintf(o.writer, "Retries: %d\n", plan.Config.Retries)
+	fmt.Fprintf(o.writer, "Max Sockets: %s\n", formatLimit(plan.Config.MaxSockets))
+	fmt.Fprintf(o.writer, "Max Conns Per Host: %s\n", formatLimit(plan.Config.MaxConnsPerHost))
+	fmt.Fprintf(o.writer, "User Agent: %s\n", plan.Config.UserAgent)
+	fmt.Fprintf(o.writer, "TLS Versions: %s to %s\n", formatTLSVersion(plan.Config.TLSMinVersion, "default"), formatTLSVersion(plan.Config.TLSMaxVersion, "highest"))
+	fmt.Fprintf(o.writer, "CA Bundle: %s\n", orNone(settings.CABundle, "system roots"))
+	fmt.Fprintf(o.writer, "Safe Mode: %s\n", onOff(!plan.Config.Aggressive))
+	fmt.Fprintf(o.writer, "Active Scope: %s\n", orNone(strings.Join(plan.Config.ActiveScope, ", "), "any host"))
+	fmt.Fprintf(o.writer, "Keep Wildcard: %s\n", onOff(plan.Config.KeepWildcard))
+	fmt.Fprintf(o.writer, "Passive Sources: %s\n", orNone(strings.Join(plan.PassiveSources, ", "), "none"))
+	fmt.Fprintf(o.writer, "Checks: %s\n", strings.Join(plan.Checks, ", "))
+	if len(plan.Config.OwnedRanges) > 0 {
+		fmt.Fprintf(o.writer, "Owned Ranges (%d): %v\n", len(plan.Config.OwnedRanges), plan.Config.OwnedRanges)
+	}
+	fmt.Fprintf(o.writer, "Ports (%d): %s\n", len(plan.Ports), formatPortRanges(plan.Ports))
+	fmt.Fprintf(o.writer, "DNS queries: %d\n", plan.DNSQueries)
+	fmt.Fprintf(o.writer, "Requests per resolved host: %d\n", plan.RequestsPerHost)
+	fmt.Fprintf(o.writer, "Estimated requests (all candidates resolve): %d\n", plan.MaxRequests)
+	fmt.Fprintf(o.writer, "Output Directory: %s\n", orNone(settings.OutputDir, "."))
+	fmt.Fprintf(o.writer, "Output Formats: %s\n", orNone(strings.Join(settings.Formats, ", "), "none"))
+	fmt.Fprintln(o.writer)
+}
+
+func formatLimit(n int) string {
+	if n <= 0 {
+		return "no limit"
+	}
+	return strconv.Itoa(n)
+}
+
+// formatTLSVersion names a crypto/tls version constant; zero leaves the
+// bound to Go's default.
+func formatTLSVersion(version uint16, unset string) string {
+	if version == 0 {
+		return unset
+	}
+	return strings.TrimPrefix(tls.VersionName(version), "TLS ")
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
+func orNone(value, none string) string {
+	if value == "" {
+		return none
 	}
-	defer file.Close()
+	return value
+}
 
-	for _, result := range results {
-		line := fmt.Sprintf("%s,%s,%s,%s\n",
-			result.Subdomain,
-			result.IP,
-			result.Status,
-			strings.ReplaceAll(result.Response, ",", ";"))
-		_, _ = file.WriteString(line)
+// formatPortRanges writes sorted ports with consecutive runs collapsed, as
+// in "21-23,80,443", so a full scan stays one line.
+func formatPortRanges(ports []int) string {
+	if len(ports) == 0 {
+		return "none"
 	}
 
-	fmt.Printf("Results saved to: %s\n", filename)
+	var parts []string
+	for i := 0; i < len(ports); {
+		j := i
+		for j+1 < len(ports) && ports[j+1] == ports[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", ports[i], ports[j]))
+		} else {
+			parts = append(parts, strconv.Itoa(ports[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
 }
 
-func (o *Outputter) SaveAsJSON(results []types.Result, filename string) {
+func (o *Outputter) SaveToFile(results []types.Result, filename string) error {
 	if filename == "" {
-		return
+		return nil
 	}
 
-	file, err := os.Create(filename)
+	err := fileutil.WriteBuffered(filename, func(w *bufio.Writer) error {
+		for _, result := range results {
+			fmt.Fprintf(w, "%s,%s,%s,%s\n",
+				result.Subdomain,
+				result.IP,
+				result.Status,
+				strings.ReplaceAll(result.Response, ",", ";"))
+		}
+		return nil
+	})
 	if err != nil {
-		fmt.Printf("Error creating JSON file: %v\n", err)
-		return
+		return err
 	}
-	defer file.Close()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
+	fmt.Fprintf(o.writer, "Results saved to: %s\n", filename)
+	return nil
+}
 
-	if err := encoder.Encode(results); err != nil {
-		fmt.Printf("Error encoding JSON: %v\n", err)
-		return
+func (o *Outputter) SaveAsJSON(results []types.Result, filename string) error {
+	return o.saveJSON(results, filename)
+}
+
+// SaveAsJSONWithSummary writes summary and results together as a
+// types.ScanReport, so the summary need not be recomputed when loading.
+func (o *Outputter) SaveAsJSONWithSummary(summary *types.ScanSummary, results []types.Result, filename string) error {
+	return o.saveJSON(types.ScanReport{Summary: summary, Results: results}, filename)
+}
+
+// WriteReport writes summary and results to filename as a types.ScanReport,
+// the format the scan command and the web interface share.
+func WriteReport(filename string, summary *types.ScanSummary, results []types.Result) error {
+	return writeJSON(types.ScanReport{Summary: summary, Results: results}, filename)
+}
+
+func (o *Outputter) saveJSON(value interface{}, filename string) error {
+	if filename == "" {
+		return nil
+	}
+	if err := writeJSON(value, filename); err != nil {
+		return err
 	}
 
-	fmt.Printf("JSON results saved to: %s\n", filename)
+	fmt.Fprintf(o.writer, "JSON results saved to: %s\n", filename)
+	return nil
+}
+
+func writeJSON(value interface{}, filename string) error {
+	return fileutil.WriteBuffered(filename, func(w *bufio.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	})
 }
 
-func (o *Outputter) SaveAsXML(results []types.Result, filename string) {
+// SaveAsJSONL writes one compact JSON object per result and line.
+func (o *Outputter) SaveAsJSONL(results []types.Result, filename string) error {
 	if filename == "" {
-		return
+		return nil
 	}
 
-	file, err := os.Create(filename)
+	err := fileutil.WriteBuffered(filename, func(w *bufio.Writer) error {
+		encoder := json.NewEncoder(w)
+		for _, result := range results {
+			if err := encoder.Encode(result); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		fmt.Printf("Error creating XML file: %v\n", err)
-		return
+		return err
 	}
-	defer file.Close()
 
-	_, _ = file.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
-	_, _ = file.WriteString("<subdomains>\n")
+	fmt.Fprintf(o.writer, "JSONL results saved to: %s\n", filename)
+	return nil
+}
 
-	for _, result := range results {
-		file.WriteString("  <subdomain>\n")
-		file.WriteString(fmt.Sprintf("    <name>%s</name>\n", result.Subdomain))
-		file.WriteString(fmt.Sprintf("    <ip>%s</ip>\n", result.IP))
-		file.WriteString(fmt.Sprintf("    <status>%s</status>\n", result.Status))
-		file.WriteString(fmt.Sprintf("    <response>%s</response>\n", result.Response))
-		file.WriteString("  </subdomain>\n")
+func (o *Outputter) SaveAsXML(results []types.Result, filename string) error {
+	if filename == "" {
+		return nil
+	}
+
+	err := fileutil.WriteBuffered(filename, func(w *bufio.Writer) error {
+		w.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+		w.WriteString("<subdomains>\n")
+
+		for _, result := range results {
+			w.WriteString("  <subdomain>\n")
+			fmt.Fprintf(w, "    <name>%s</name>\n", result.Subdomain)
+			fmt.Fprintf(w, "    <ip>%s</ip>\n", result.IP)
+			fmt.Fprintf(w, "    <status>%s</status>\n", result.Status)
+			fmt.Fprintf(w, "    <response>%s</response>\n", result.Response)
+			w.WriteString("  </subdomain>\n")
+		}
+
+		w.WriteString("</subdomains>\n")
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	file.WriteString("</subdomains>\n")
-	fmt.Printf("XML results saved to: %s\n", filename)
+	fmt.Fprintf(o.writer, "XML results saved to: %s\n", filename)
+	return nil
 }
 
 func (o *Outputter) PrintProgress(current, total int) {
@@ -161,21 +446,21 @@ func (o *Outputter) PrintProgress(current, total int) {
 	bar := strings.Repeat("=", int(percent/2))
 	spaces := strings.Repeat(" ", 50-int(percent/2))
 
-	fmt.Printf("\r[%s%s] %.1f%% (%d/%d)",
+	fmt.Fprintf(o.writer, "\r[%s%s] %.1f%% (%d/%d)",
 		bar, spaces, percent, current, total)
 }
 
 func (o *Outputter) PrintError(message string) {
 	red := color.New(color.FgRed).SprintFunc()
-	fmt.Printf("[%s] %s\n", red("ERROR"), message)
+	fmt.Fprintf(o.writer, "[%s] %s\n", red("ERROR"), message)
 }
 
 func (o *Outputter) PrintWarning(message string) {
 	yellow := color.New(color.FgYellow).SprintFunc()
-	fmt.Printf("[%s] %s\n", yellow("WARNING"), message)
+	fmt.Fprintf(o.writer, "[%s] %s\n", yellow("WARNING"), message)
 }
 
 func (o *Outputter) PrintInfo(message string) {
 	blue := color.New(color.FgBlue).SprintFunc()
-	fmt.Printf("[%s] %s\n", blue("INFO"), message)
+	fmt.Fprintf(o.writer, "[%s] %s\n", blue("INFO"), message)
 }
@@ -0,0 +1,46 @@
+package output
+
+import (
+	"bytes"
+	"crypto/tls"
+	"strings"
+	"testing"
+
+	"subdomain-finder/internal/finder"
+)
+
+func TestPrintPlanSettings(t *testing.T) {
+	plan := finder.ScanPlan{
+		Config: finder.Config{
+			Domain:        "example.com",
+			MaxSockets:    256,
+			TLSMinVersion: tls.VersionTLS12,
+			ActiveScope:   []string{"app.example.com", "*.dev.example.com"},
+			KeepWildcard:  true,
+		},
+		PassiveSources: []string{"crt.sh", "axfr"},
+	}
+	settings := PlanSettings{OutputDir: "./results", Formats: []string{"json", "sarif"}, CABundle: "proxy.pem"}
+
+	var buf bytes.Buffer
+	o := NewOutputter(plan.Config, nil)
+	o.SetWriter(&buf)
+	o.PrintPlan(plan, settings)
+
+	for _, want := range []string{
+		"Max Sockets: 256\n",
+		"Max Conns Per Host: no limit\n",
+		"TLS Versions: 1.2 to highest\n",
+		"CA Bundle: proxy.pem\n",
+		"Safe Mode: on\n",
+		"Active Scope: app.example.com, *.dev.example.com\n",
+		"Keep Wildcard: on\n",
+		"Passive Sources: crt.sh, axfr\n",
+		"Output Directory: ./results\n",
+		"Output Formats: json, sarif\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("plan is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"subdomain-finder/internal/reporter"
+	"subdomain-finder/internal/types"
+)
+
+func TestWriteReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary *types.ScanSummary
+		results []types.Result
+	}{
+		{"with summary", &types.ScanSummary{FoundSubdomains: 2, WildcardIPs: []string{"192.0.2.9"}}, []types.Result{{Subdomain: "www.example.com"}, {Subdomain: "api.example.com"}}},
+		{"no results", &types.ScanSummary{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out", "results.json")
+			if err := WriteReport(path, tt.summary, tt.results); err != nil {
+				t.Fatal(err)
+			}
+
+			results, summary, err := reporter.LoadJSON(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if summary == nil || summary.FoundSubdomains != tt.summary.FoundSubdomains || len(summary.WildcardIPs) != len(tt.summary.WildcardIPs) {
+				t.Errorf("summary = %+v, want %+v", summary, tt.summary)
+			}
+			if len(results) != len(tt.results) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.results))
+			}
+			for i := range results {
+				if results[i].Subdomain != tt.results[i].Subdomain {
+					t.Errorf("result %d = %s, want %s", i, results[i].Subdomain, tt.results[i].Subdomain)
+				}
+			}
+		})
+	}
+}
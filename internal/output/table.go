@@ -0,0 +1,155 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"subdomain-finder/internal/types"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+var tableColumns = []string{"SUBDOMAIN", "IP", "STATUS", "RISK", "PORTS", "TECH"}
+
+// PrintTable writes the results as an aligned table. Cells are colored only
+// when the writer is a terminal and --no-color is not set, so piped output
+// stays plain text.
+func (o *Outputter) PrintTable(results []types.Result) {
+	if len(results) == 0 {
+		return
+	}
+
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		rows = append(rows, tableRow(result))
+	}
+
+	fmt.Fprintln(o.writer)
+	for _, line := range tableLines(rows, o.colorEnabled()) {
+		fmt.Fprintln(o.writer, line)
+	}
+	fmt.Fprintln(o.writer)
+}
+
+// tableLines lays out the header, rule and rows with every column as wide
+// as its widest cell.
+func tableLines(rows [][]string, colored bool) []string {
+	widths := make([]int, len(tableColumns))
+	for i, column := range tableColumns {
+		widths[i] = utf8.RuneCountInString(column)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	bold := tableColor(colored, color.Bold)
+
+	header := make([]string, len(tableColumns))
+	rule := make([]string, len(tableColumns))
+	for i, column := range tableColumns {
+		header[i] = bold(pad(column, widths[i]))
+		rule[i] = strings.Repeat("-", widths[i])
+	}
+	lines := []string{
+		strings.TrimRight(strings.Join(header, "  "), " "),
+		strings.Join(rule, "  "),
+	}
+
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			// Pad before coloring, escape codes would throw off the widths
+			cells[i] = pad(cell, widths[i])
+		}
+		cells[2] = tableColor(colored, statusColor(row[2]))(cells[2])
+		cells[3] = tableColor(colored, riskColor(row[3]))(cells[3])
+		lines = append(lines, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+	return lines
+}
+
+func tableRow(result types.Result) []string {
+	ports := make([]string, 0, len(result.Ports))
+	for _, port := range result.Ports {
+		if port.Open() {
+			ports = append(ports, strconv.Itoa(port.Port))
+		}
+	}
+
+	return []string{
+		result.Subdomain,
+		orDash(result.IP),
+		orDash(strings.TrimPrefix(result.Status, "N/A")),
+		orDash(result.RiskLevel),
+		orDash(strings.Join(ports, ",")),
+		strconv.Itoa(len(result.Technologies)),
+	}
+}
+
+// colorEnabled reports whether console output should carry ANSI colors.
+func (o *Outputter) colorEnabled() bool {
+	if o.config.NoColor || color.NoColor {
+		return false
+	}
+	return o.isTerminal()
+}
+
+func (o *Outputter) isTerminal() bool {
+	file, ok := o.writer.(*os.File)
+	return ok && (isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd()))
+}
+
+func tableColor(enabled bool, attribute color.Attribute) func(a ...interface{}) string {
+	c := color.New(attribute)
+	if enabled {
+		c.EnableColor()
+	} else {
+		c.DisableColor()
+	}
+	return c.SprintFunc()
+}
+
+func statusColor(status string) color.Attribute {
+	switch {
+	case strings.HasPrefix(status, "2"):
+		return color.FgGreen
+	case strings.HasPrefix(status, "3"):
+		return color.FgCyan
+	case strings.HasPrefix(status, "4"):
+		return color.FgYellow
+	case strings.HasPrefix(status, "5"):
+		return color.FgRed
+	default:
+		return color.Reset
+	}
+}
+
+func riskColor(risk string) color.Attribute {
+	switch risk {
+	case "critical", "high":
+		return color.FgRed
+	case "medium":
+		return color.FgYellow
+	case "low":
+		return color.FgGreen
+	default:
+		return color.Reset
+	}
+}
+
+func pad(text string, width int) string {
+	return text + strings.Repeat(" ", width-utf8.RuneCountInString(text))
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
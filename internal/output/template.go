@@ -0,0 +1,70 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"subdomain-finder/internal/types"
+)
+
+// FormatPresets are the named templates accepted by --format.
+var FormatPresets = map[string]string{
+	"simple": "{{.Subdomain}}",
+	"hosts":  "{{.IP}} {{.Subdomain}}",
+	"status": "{{.Subdomain}} {{.IP}} {{.Status}}",
+}
+
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// PresetNames returns the --format preset names in sorted order.
+func PresetNames() []string {
+	names := make([]string, 0, len(FormatPresets))
+	for name := range FormatPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseResultTemplate parses a per-result template, given either as a
+// preset name or as text/template source. It executes the template once
+// against an empty result so unknown fields fail before the scan starts.
+func ParseResultTemplate(preset, text string) (*template.Template, error) {
+	if preset != "" && text != "" {
+		return nil, fmt.Errorf("--format and --format-template cannot be combined")
+	}
+	if preset != "" {
+		var ok bool
+		text, ok = FormatPresets[preset]
+		if !ok {
+			return nil, fmt.Errorf("unknown format %q (available: %s)", preset, strings.Join(PresetNames(), ", "))
+		}
+	}
+
+	tmpl, err := template.New("result").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, types.Result{}); err != nil {
+		return nil, fmt.Errorf("invalid format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// PrintTemplated writes one line per result rendered with tmpl.
+func PrintTemplated(w io.Writer, tmpl *template.Template, results []types.Result) error {
+	for _, result := range results {
+		if err := tmpl.Execute(w, result); err != nil {
+			return fmt.Errorf("failed to render %s: %w", result.Subdomain, err)
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
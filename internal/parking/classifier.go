@@ -0,0 +1,68 @@
+package parking
+
+import (
+	"strings"
+)
+
+type Classification struct {
+	Parked   bool
+	Provider string
+	Evidence string
+}
+
+type fingerprint struct {
+	provider string
+	patterns []string
+}
+
+type Classifier struct {
+	fingerprints []fingerprint
+	generic      []string
+}
+
+func NewClassifier() *Classifier {
+	return &Classifier{
+		fingerprints: []fingerprint{
+			{provider: "Sedo", patterns: []string{"sedoparking.com", "sedo.com/search/details", "sedo domain parking"}},
+			{provider: "GoDaddy", patterns: []string{"parking-lander", "godaddy.com/domainsearch", "img1.wsimg.com/parking"}},
+			{provider: "ParkingCrew", patterns: []string{"parkingcrew.net"}},
+			{provider: "Bodis", patterns: []string{"bodis.com", "bodiscdn.com"}},
+			{provider: "Dan.com", patterns: []string{"dan.com/buy-domain", "dan.com/domain-lander"}},
+			{provider: "Afternic", patterns: []string{"afternic.com/forsale", "afternic.com/domain"}},
+			{provider: "HugeDomains", patterns: []string{"hugedomains.com"}},
+			{provider: "Namecheap", patterns: []string{"parkingpage.namecheap.com", "namecheap.com/domains/registration"}},
+			{provider: "Above.com", patterns: []string{"above.com/marketplace", "trafficz.com"}},
+			{provider: "Uniregistry", patterns: []string{"uniregistry.com/buy", "uni.domains"}},
+			{provider: "Undeveloped", patterns: []string{"undeveloped.com"}},
+			{provider: "DomainMarket", patterns: []string{"domainmarket.com"}},
+		},
+		generic: []string{
+			"this domain is for sale",
+			"this domain may be for sale",
+			"buy this domain",
+			"domain is parked",
+			"parked free, courtesy of",
+			"the domain owner may be interested in selling",
+		},
+	}
+}
+
+func (c *Classifier) Classify(title, body string) Classification {
+	content := strings.ToLower(title + "\n" + body)
+
+	for _, fp := range c.fingerprints {
+		for _, pattern := range fp.patterns {
+			if strings.Contains(content, pattern) {
+				return Classification{Parked: true, Provider: fp.provider, Evidence: pattern}
+			}
+		}
+	}
+
+	for _, pattern := range c.generic {
+		if strings.Contains(content, pattern) {
+			return Classification{Parked: true, Evidence: pattern}
+		}
+	}
+
+	return Classification{}
+}
@@ -0,0 +1,37 @@
+package passive
+
+import "context"
+
+// ZoneTransferer is implemented by resolvers that can request a zone
+// transfer from a domain's nameservers.
+type ZoneTransferer interface {
+	AttemptAXFR(domain string) ([]string, error)
+}
+
+// AXFRSource lists the names in a zone its nameservers are willing to hand
+// over.
+type AXFRSource struct {
+	resolver ZoneTransferer
+}
+
+func NewAXFRSource(resolver ZoneTransferer) *AXFRSource {
+	return &AXFRSource{resolver: resolver}
+}
+
+func (s *AXFRSource) Name() string {
+	return "axfr"
+}
+
+// Enumerate reports the names from any nameserver that allows the
+// transfer. Refused transfers are the norm rather than a failure of the
+// source, so they end quietly with nothing found.
+func (s *AXFRSource) Enumerate(ctx context.Context, domain string, found func(subdomain string)) error {
+	names, err := s.resolver.AttemptAXFR(domain)
+	if err != nil || ctx.Err() != nil {
+		return nil
+	}
+	for _, name := range names {
+		found(name)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package passive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const crtshURL = "https://crt.sh/"
+
+// CTSource finds subdomains in certificate transparency logs through the
+// crt.sh JSON API.
+type CTSource struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewCTSource returns a crt.sh source. A nil client uses
+// http.DefaultClient; deadlines come from the context passed to Enumerate.
+func NewCTSource(client *http.Client) *CTSource {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &CTSource{client: client, baseURL: crtshURL}
+}
+
+func (s *CTSource) Name() string {
+	return "crt.sh"
+}
+
+type crtshEntry struct {
+	CommonName string `json:"common_name"`
+	NameValue  string `json:"name_value"`
+}
+
+// Enumerate reports every distinct name logged for certificates of domain
+// and its subdomains. Wildcard labels are stripped and entries that are not
+// host names, such as e-mail addresses, are skipped. The response is decoded
+// entry by entry since large domains return tens of megabytes.
+func (s *CTSource) Enumerate(ctx context.Context, domain string, found func(subdomain string)) error {
+	query := url.Values{"q": {"%." + domain}, "output": {"json"}}
+	req, err := http.NewRequestWithContext(ctx, "GET", s.baseURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("crt.sh returned status %d", resp.StatusCode)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("failed to parse crt.sh response: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for decoder.More() {
+		var entry crtshEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return fmt.Errorf("failed to parse crt.sh response: %w", err)
+		}
+
+		names := strings.Split(entry.NameValue, "\n")
+		names = append(names, entry.CommonName)
+		for _, name := range names {
+			name = normalize(name)
+			if name == "" || seen[name] || strings.ContainsAny(name, "@ *") {
+				continue
+			}
+			seen[name] = true
+			found(name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package passive
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"subdomain-finder/internal/logger"
+)
+
+// Source is a passive subdomain data source such as a certificate
+// transparency log or a threat-intelligence API.
+type Source interface {
+	Name() string
+	// Enumerate reports each subdomain through found as soon as it is known
+	// and returns when done or when ctx is cancelled.
+	Enumerate(ctx context.Context, domain string, found func(subdomain string)) error
+}
+
+type Config struct {
+	Concurrency   int
+	SourceTimeout time.Duration
+	Timeout       time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Concurrency:   5,
+		SourceTimeout: 30 * time.Second,
+		Timeout:       2 * time.Minute,
+	}
+}
+
+// Runner queries sources with its own concurrency and deadlines, separate
+// from active scanning, so a slow API cannot hold up the scan.
+type Runner struct {
+	config  Config
+	sources []Source
+	logger  *logger.Logger
+}
+
+func NewRunner(config Config, sources []Source, log *logger.Logger) *Runner {
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	return &Runner{config: config, sources: sources, logger: log}
+}
+
+// Sources returns the names of the sources Run queries.
+func (r *Runner) Sources() []string {
+	names := make([]string, 0, len(r.sources))
+	for _, source := range r.sources {
+		names = append(names, source.Name())
+	}
+	return names
+}
+
+// Run returns the sorted, deduplicated subdomains of domain found by every
+// source. A source that misses its deadline is abandoned with a warning and
+// whatever it reported before then is kept.
+func (r *Runner) Run(ctx context.Context, domain string) []string {
+	if r.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.config.Timeout)
+		defer cancel()
+	}
+
+	domain = normalize(domain)
+	var mu sync.Mutex
+	closed := false
+	found := make(map[string]bool)
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, r.config.Concurrency)
+
+	for _, source := range r.sources {
+		wg.Add(1)
+		go func(source Source) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			count := 0
+			record := func(subdomain string) {
+				subdomain = normalize(subdomain)
+				if !strings.HasSuffix(subdomain, "."+domain) {
+					return
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				if closed {
+					return
+				}
+				found[subdomain] = true
+				count++
+			}
+
+			r.query(ctx, source, domain, record, func() int {
+				mu.Lock()
+				defer mu.Unlock()
+				return count
+			})
+		}(source)
+	}
+
+	wg.Wait()
+
+	mu.Lock()
+	closed = true
+	subdomains := make([]string, 0, len(found))
+	for subdomain := range found {
+		subdomains = append(subdomains, subdomain)
+	}
+	mu.Unlock()
+
+	sort.Strings(subdomains)
+	return subdomains
+}
+
+func (r *Runner) query(ctx context.Context, source Source, domain string, record func(string), count func() int) {
+	if r.config.SourceTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.config.SourceTimeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- source.Enumerate(ctx, domain, record)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && ctx.Err() == nil {
+			r.warnf("Passive source %s failed: %v (kept %d results)", source.Name(), err, count())
+			return
+		}
+		if ctx.Err() != nil {
+			r.warnf("Passive source %s exceeded its deadline, kept %d partial results", source.Name(), count())
+		}
+	case <-ctx.Done():
+		r.warnf("Passive source %s exceeded its deadline and was abandoned, kept %d partial results", source.Name(), count())
+	}
+}
+
+func (r *Runner) warnf(format string, args ...interface{}) {
+	if r.logger != nil {
+		r.logger.Warnf(format, args...)
+	}
+}
+
+func normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimPrefix(name, "*.")
+	return strings.TrimSuffix(name, ".")
+}
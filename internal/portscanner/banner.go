@@ -0,0 +1,143 @@
+package portscanner
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// httpPorts get a request before reading, since HTTP servers wait for the
+// client to speak first. TLS ports are left out.
+var httpPorts = map[int]bool{
+	80: true, 3000: true, 5000: true, 8000: true, 8008: true, 8080: true, 8081: true, 8888: true, 9000: true, 9090: true,
+}
+
+// getBanner reads what the service says first, or its answer to a minimal
+// HTTP request on web ports, and returns it as one line along with the
+// product and version parsed from it.
+func (ps *PortScanner) getBanner(conn net.Conn, host string, port int) (string, string) {
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	if httpPorts[port] {
+		request := fmt.Sprintf("GET / HTTP/1.0\r\nHost: %s\r\nUser-Agent: Mozilla/5.0\r\n\r\n", host)
+		if _, err := conn.Write([]byte(request)); err != nil {
+			return "", ""
+		}
+	}
+
+	raw := readBanner(conn, httpPorts[port])
+	if len(raw) == 0 {
+		return "", ""
+	}
+
+	if isBinary(raw) {
+		return printableBanner(raw), parseVersion(port, raw)
+	}
+
+	banner := string(raw)
+	if strings.HasPrefix(banner, "HTTP/") {
+		banner, _, _ = strings.Cut(banner, "\r\n")
+	}
+	banner = strings.TrimSpace(banner)
+	banner = strings.ReplaceAll(banner, "\r\n", " ")
+	banner = strings.ReplaceAll(banner, "\n", " ")
+
+	if len(banner) > 200 {
+		banner = banner[:200] + "..."
+	}
+
+	return banner, parseVersion(port, raw)
+}
+
+func isBinary(data []byte) bool {
+	for _, c := range data {
+		if c < 0x20 && c != '\r' && c != '\n' && c != '\t' {
+			return true
+		}
+	}
+	return false
+}
+
+// readBanner reads one chunk, or for HTTP keeps reading until the headers
+// are complete.
+func readBanner(conn net.Conn, untilHeaders bool) []byte {
+	buffer := make([]byte, 0, 4096)
+	chunk := make([]byte, 1024)
+	for len(buffer) < cap(buffer) {
+		n, err := conn.Read(chunk)
+		buffer = append(buffer, chunk[:n]...)
+		if err != nil || !untilHeaders || bytes.Contains(buffer, []byte("\r\n\r\n")) {
+			break
+		}
+	}
+	return buffer
+}
+
+// parseVersion extracts the software named in a banner: the SSH software
+// version, the HTTP Server header, the text of an SMTP/FTP 220, POP3 +OK or
+// IMAP OK greeting, or the MySQL server version.
+func parseVersion(port int, raw []byte) string {
+	if port == 3306 && len(raw) > 5 && raw[4] == 0x0a {
+		if end := bytes.IndexByte(raw[5:], 0); end > 0 {
+			return "MySQL " + string(raw[5:5+end])
+		}
+	}
+
+	text := string(raw)
+	line, _, _ := strings.Cut(text, "\n")
+	line = strings.TrimSpace(line)
+
+	switch {
+	case strings.HasPrefix(line, "SSH-"):
+		// SSH-2.0-OpenSSH_8.9p1 Ubuntu-3ubuntu0.6
+		if parts := strings.SplitN(line, "-", 3); len(parts) == 3 {
+			return parts[2]
+		}
+	case strings.HasPrefix(line, "HTTP/"):
+		for _, header := range strings.Split(text, "\n") {
+			name, value, ok := strings.Cut(header, ":")
+			if ok && strings.EqualFold(strings.TrimSpace(name), "server") {
+				return strings.TrimSpace(value)
+			}
+		}
+	case strings.HasPrefix(line, "220"):
+		return greetingVersion(strings.TrimLeft(line[3:], " -"))
+	case strings.HasPrefix(line, "+OK"):
+		return greetingVersion(strings.TrimSpace(line[3:]))
+	case strings.HasPrefix(line, "* OK"):
+		greeting := strings.TrimSpace(line[4:])
+		// Drop the [CAPABILITY ...] list some servers put first
+		if strings.HasPrefix(greeting, "[") {
+			if end := strings.Index(greeting, "]"); end >= 0 {
+				greeting = strings.TrimSpace(greeting[end+1:])
+			}
+		}
+		return greetingVersion(greeting)
+	}
+	return ""
+}
+
+// greetingVersion trims a mail or FTP greeting such as
+// "mail.example.com ESMTP Postfix (Ubuntu)" or "(vsFTPd 3.0.3)" down to
+// the software it names.
+func greetingVersion(greeting string) string {
+	fields := strings.Fields(greeting)
+	if len(fields) > 1 && strings.Contains(fields[0], ".") {
+		fields = fields[1:]
+	}
+	greeting = strings.Join(fields, " ")
+
+	if strings.HasPrefix(greeting, "(") && strings.HasSuffix(greeting, ")") {
+		greeting = greeting[1 : len(greeting)-1]
+	}
+	if i := strings.Index(greeting, " ["); i > 0 {
+		greeting = greeting[:i]
+	}
+	greeting = strings.TrimSuffix(greeting, ".")
+	for _, suffix := range []string{" ready", " Service ready", " server ready"} {
+		greeting = strings.TrimSuffix(greeting, suffix)
+	}
+	return strings.TrimSpace(greeting)
+}
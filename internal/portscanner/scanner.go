@@ -1,26 +1,42 @@
 package portscanner
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"subdomain-finder/internal/limiter"
+	"subdomain-finder/internal/netutil"
 )
 
 type PortScanner struct {
 	timeout     time.Duration
 	threads     int
 	commonPorts []int
+	excluded    map[int]bool
+	budget      *limiter.Budget
 }
 
+// Protocol is the transport a port is scanned over.
+type Protocol string
+
+const (
+	TCP Protocol = "tcp"
+	UDP Protocol = "udp"
+)
+
 type PortResult struct {
 	Port     int
 	Protocol string
 	State    string
 	Service  string
 	Banner   string
+	Version  string
 }
 
 type ScanResult struct {
@@ -40,10 +56,33 @@ func NewPortScanner(timeout time.Duration, threads int) *PortScanner {
 	}
 }
 
-func (ps *PortScanner) ScanHost(host string, ports []int) *ScanResult {
+// SetExcludedPorts lists ports that are never dialed, whatever set a scan
+// asks for.
+func (ps *PortScanner) SetExcludedPorts(ports []int) {
+	ps.excluded = make(map[int]bool, len(ports))
+	for _, port := range ports {
+		ps.excluded[port] = true
+	}
+}
+
+// SetBudget makes every dial also hold a slot of budget, shared with the
+// scan's other checks, on top of the scanner's own threads per host.
+func (ps *PortScanner) SetBudget(budget *limiter.Budget) {
+	ps.budget = budget
+}
+
+func (ps *PortScanner) ScanHost(host string, ports []int, protocol Protocol) *ScanResult {
+	return ps.ScanHostContext(context.Background(), host, ports, protocol)
+}
+
+// ScanHostContext is ScanHost that stops dialing once ctx is done. Ports
+// not dialed by then are left out of the result.
+func (ps *PortScanner) ScanHostContext(ctx context.Context, host string, ports []int, protocol Protocol) *ScanResult {
+	host = netutil.Unbracket(host)
 	if len(ports) == 0 {
 		ports = ps.commonPorts
 	}
+	ports = ps.filterPorts(ports)
 
 	result := &ScanResult{
 		Host:       host,
@@ -55,17 +94,30 @@ func (ps *PortScanner) ScanHost(host string, ports []int) *ScanResult {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
+	// A goroutine starts only once a thread is free, so a full scan does
+	// not park 65535 of them
 	for _, port := range ports {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
 		wg.Add(1)
 		go func(p int) {
 			defer wg.Done()
-			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			portResult := ps.scanPort(host, p)
+			if err := ps.budget.Acquire(ctx); err != nil {
+				return
+			}
+			defer ps.budget.Release()
+			portResult := ps.scanPort(ctx, host, p, protocol)
 
 			mu.Lock()
-			if portResult.State == "open" {
+			if portResult.State == StateOpen {
 				result.OpenPorts++
 			}
 			result.Ports = append(result.Ports, portResult)
@@ -77,53 +129,52 @@ func (ps *PortScanner) ScanHost(host string, ports []int) *ScanResult {
 	return result
 }
 
-func (ps *PortScanner) scanPort(host string, port int) PortResult {
-	address := fmt.Sprintf("%s:%d", host, port)
+func (ps *PortScanner) filterPorts(ports []int) []int {
+	if len(ps.excluded) == 0 {
+		return ports
+	}
+
+	filtered := make([]int, 0, len(ports))
+	for _, port := range ports {
+		if !ps.excluded[port] {
+			filtered = append(filtered, port)
+		}
+	}
+	return filtered
+}
+
+func (ps *PortScanner) scanPort(ctx context.Context, host string, port int, protocol Protocol) PortResult {
+	if protocol == UDP {
+		return ps.scanUDPPort(ctx, host, port)
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
 
-	conn, err := net.DialTimeout("tcp", address, ps.timeout)
+	dialer := net.Dialer{Timeout: ps.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
 	if err != nil {
 		return PortResult{
 			Port:     port,
-			Protocol: "tcp",
-			State:    "closed",
+			Protocol: string(TCP),
+			State:    StateClosed,
 			Service:  ps.getServiceName(port),
 		}
 	}
 	defer conn.Close()
 
-	banner := ps.getBanner(conn, port)
+	banner, version := ps.getBanner(conn, host, port)
 	service := ps.getServiceName(port)
 
 	return PortResult{
 		Port:     port,
-		Protocol: "tcp",
-		State:    "open",
+		Protocol: string(TCP),
+		State:    StateOpen,
 		Service:  service,
 		Banner:   banner,
+		Version:  version,
 	}
 }
 
-func (ps *PortScanner) getBanner(conn net.Conn, port int) string {
-	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
-
-	buffer := make([]byte, 1024)
-	n, err := conn.Read(buffer)
-	if err != nil {
-		return ""
-	}
-
-	banner := string(buffer[:n])
-	banner = strings.TrimSpace(banner)
-	banner = strings.ReplaceAll(banner, "\r\n", " ")
-	banner = strings.ReplaceAll(banner, "\n", " ")
-
-	if len(banner) > 200 {
-		banner = banner[:200] + "..."
-	}
-
-	return banner
-}
-
 func (ps *PortScanner) getServiceName(port int) string {
 	services := map[int]string{
 		21:    "ftp",
@@ -131,12 +182,16 @@ func (ps *PortScanner) getServiceName(port int) string {
 		23:    "telnet",
 		25:    "smtp",
 		53:    "dns",
+		69:    "tftp",
 		80:    "http",
 		110:   "pop3",
 		111:   "rpcbind",
+		123:   "ntp",
 		135:   "msrpc",
+		137:   "netbios-ns",
 		139:   "netbios-ssn",
 		143:   "imap",
+		161:   "snmp",
 		443:   "https",
 		993:   "imaps",
 		995:   "pop3s",
@@ -156,6 +211,8 @@ func (ps *PortScanner) getServiceName(port int) string {
 		27017: "mongodb",
 		6379:  "redis",
 		5984:  "couchdb",
+		1900:  "ssdp",
+		5353:  "mdns",
 	}
 
 	if service, exists := services[port]; exists {
@@ -173,7 +230,7 @@ func (ps *PortScanner) ScanMultipleHosts(hosts []string, ports []int) map[string
 		wg.Add(1)
 		go func(h string) {
 			defer wg.Done()
-			result := ps.ScanHost(h, ports)
+			result := ps.ScanHost(h, ports, TCP)
 
 			mu.Lock()
 			results[h] = result
@@ -185,50 +242,113 @@ func (ps *PortScanner) ScanMultipleHosts(hosts []string, ports []int) map[string
 	return results
 }
 
+var quickPorts = []int{21, 22, 23, 25, 53, 80, 110, 135, 139, 143, 443, 993, 995, 1723, 3306, 3389, 5432, 5900, 8080, 8443, 8888, 9000, 9090}
+
+func (ps *PortScanner) QuickPorts() []int {
+	return ps.filterPorts(quickPorts)
+}
+
 func (ps *PortScanner) QuickScan(host string) *ScanResult {
-	quickPorts := []int{21, 22, 23, 25, 53, 80, 110, 135, 139, 143, 443, 993, 995, 1723, 3306, 3389, 5432, 5900, 8080, 8443, 8888, 9000, 9090}
-	return ps.ScanHost(host, quickPorts)
+	return ps.ScanHost(host, ps.QuickPorts(), TCP)
+}
+
+func (ps *PortScanner) QuickScanContext(ctx context.Context, host string) *ScanResult {
+	return ps.ScanHostContext(ctx, host, ps.QuickPorts(), TCP)
 }
 
 func (ps *PortScanner) FullScan(host string) *ScanResult {
-	fullPorts := make([]int, 0, 65535)
+	return ps.ScanHost(host, allPorts(), TCP)
+}
+
+func allPorts() []int {
+	ports := make([]int, 0, 65535)
 	for i := 1; i <= 65535; i++ {
-		fullPorts = append(fullPorts, i)
+		ports = append(ports, i)
 	}
-	return ps.ScanHost(host, fullPorts)
+	return ports
 }
 
-func (ps *PortScanner) CustomScan(host string, portRange string) *ScanResult {
-	ports := ps.parsePortRange(portRange)
-	return ps.ScanHost(host, ports)
+func (ps *PortScanner) CustomScan(host string, portRange string) (*ScanResult, error) {
+	ports, err := ParsePorts(portRange)
+	if err != nil {
+		return nil, err
+	}
+	return ps.ScanHost(host, ports, TCP), nil
+}
+
+// SelectPorts returns the ports a --ports value stands for: "none" (no
+// port scan), "quick" (the QuickScan set), "full" (1-65535) or a list for
+// ParsePorts. An empty value means none.
+func SelectPorts(spec string) ([]int, error) {
+	switch strings.ToLower(strings.TrimSpace(spec)) {
+	case "", "none":
+		return nil, nil
+	case "quick":
+		return append([]int(nil), quickPorts...), nil
+	case "full":
+		return allPorts(), nil
+	default:
+		return ParsePorts(spec)
+	}
 }
 
-func (ps *PortScanner) parsePortRange(portRange string) []int {
+// ParsePorts parses specs such as "80,443,8000-8100,3306" into a sorted,
+// deduplicated port list. Every token must be a port or a low-high range
+// within 1-65535.
+func ParsePorts(spec string) ([]int, error) {
+	seen := make(map[int]bool)
 	var ports []int
 
-	if strings.Contains(portRange, "-") {
-		parts := strings.Split(portRange, "-")
-		if len(parts) == 2 {
-			start, err1 := strconv.Atoi(parts[0])
-			end, err2 := strconv.Atoi(parts[1])
-			if err1 == nil && err2 == nil {
-				for i := start; i <= end; i++ {
-					ports = append(ports, i)
-				}
-			}
+	for _, token := range strings.Split(spec, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			return nil, fmt.Errorf("invalid port specification %q: empty entry", spec)
+		}
+
+		start, end, err := parsePortToken(token)
+		if err != nil {
+			return nil, err
 		}
-	} else if strings.Contains(portRange, ",") {
-		parts := strings.Split(portRange, ",")
-		for _, part := range parts {
-			if port, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+
+		for port := start; port <= end; port++ {
+			if !seen[port] {
+				seen[port] = true
 				ports = append(ports, port)
 			}
 		}
-	} else {
-		if port, err := strconv.Atoi(portRange); err == nil {
-			ports = append(ports, port)
-		}
 	}
 
-	return ports
+	sort.Ints(ports)
+	return ports, nil
+}
+
+func parsePortToken(token string) (int, int, error) {
+	low, high, isRange := strings.Cut(token, "-")
+	start, err := parsePort(low)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port %q: %w", token, err)
+	}
+	if !isRange {
+		return start, start, nil
+	}
+
+	end, err := parsePort(high)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port range %q: %w", token, err)
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("invalid port range %q: start is greater than end", token)
+	}
+	return start, end, nil
+}
+
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("not a number")
+	}
+	if port < 1 || port > 65535 {
+		return 0, fmt.Errorf("out of range 1-65535")
+	}
+	return port, nil
 }
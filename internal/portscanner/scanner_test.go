@@ -0,0 +1,129 @@
+package portscanner
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParsePorts(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []int
+		wantErr string
+	}{
+		{spec: "443", want: []int{443}},
+		{spec: "80,443,8000-8003,3306", want: []int{80, 443, 3306, 8000, 8001, 8002, 8003}},
+		{spec: " 22 , 80-81 ,22,81", want: []int{22, 80, 81}},
+		{spec: "1-3,2-4", want: []int{1, 2, 3, 4}},
+		{spec: "65535", want: []int{65535}},
+		{spec: "5-5", want: []int{5}},
+		{spec: "0", wantErr: `invalid port "0": out of range`},
+		{spec: "70000", wantErr: `invalid port "70000": out of range`},
+		{spec: "abc", wantErr: `invalid port "abc": not a number`},
+		{spec: "80,abc,443", wantErr: `invalid port "abc"`},
+		{spec: "80-70000", wantErr: `invalid port range "80-70000": out of range`},
+		{spec: "0-80", wantErr: `invalid port "0-80": out of range`},
+		{spec: "90-80", wantErr: "start is greater than end"},
+		{spec: "80-", wantErr: `invalid port range "80-": not a number`},
+		{spec: "80,,443", wantErr: "empty entry"},
+		{spec: "", wantErr: "empty entry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParsePorts(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectPorts(t *testing.T) {
+	tests := []struct {
+		spec  string
+		count int
+	}{
+		{spec: "", count: 0},
+		{spec: "none", count: 0},
+		{spec: "Quick", count: len(quickPorts)},
+		{spec: "full", count: 65535},
+		{spec: "80,8000-8009", count: 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := SelectPorts(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.count {
+				t.Errorf("got %d ports, want %d", len(got), tt.count)
+			}
+		})
+	}
+}
+
+func TestExcludedPortsAreNeverDialed(t *testing.T) {
+	// Each listener counts and closes its connections; the scanner's banner
+	// read ends on the close, so every dial is counted before ScanHost
+	// returns
+	accepted := make([]atomic.Int32, 3)
+	ports := make([]int, len(accepted))
+	for i := range accepted {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer listener.Close()
+		ports[i] = listener.Addr().(*net.TCPAddr).Port
+		go func(i int) {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				accepted[i].Add(1)
+				conn.Close()
+			}
+		}(i)
+	}
+
+	scanner := NewPortScanner(time.Second, 4)
+	scanner.SetExcludedPorts([]int{ports[1]})
+	result := scanner.ScanHost("127.0.0.1", ports, TCP)
+
+	if result.TotalPorts != 2 || result.OpenPorts != 2 {
+		t.Errorf("got %d of %d ports open, want 2 of 2", result.OpenPorts, result.TotalPorts)
+	}
+	for _, port := range result.Ports {
+		if port.Port == ports[1] {
+			t.Errorf("excluded port %d is in the result", port.Port)
+		}
+	}
+	for i := range accepted {
+		if dialed := accepted[i].Load() > 0; dialed != (i != 1) {
+			t.Errorf("port %d dialed = %v, want %v", ports[i], dialed, i != 1)
+		}
+	}
+
+	scanner.SetExcludedPorts([]int{22, 443})
+	for _, port := range scanner.QuickPorts() {
+		if port == 22 || port == 443 {
+			t.Errorf("QuickPorts includes excluded port %d", port)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package portscanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+	// StateOpenFiltered is a UDP port that neither answered nor was
+	// refused, so a firewall may be dropping the probe.
+	StateOpenFiltered = "open|filtered"
+)
+
+// udpProbes are requests that make common UDP services answer. Other ports
+// get an empty datagram.
+var udpProbes = map[int][]byte{
+	// DNS query for the root NS records
+	53: {0x13, 0x37, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x01},
+	// TFTP read request for a file that should not exist
+	69: append([]byte{0x00, 0x01}, "sf-probe\x00octet\x00"...),
+	// NTP version 3 client request
+	123: append([]byte{0x1b}, make([]byte, 47)...),
+	// NetBIOS node status request for *
+	137: append([]byte{0x13, 0x37, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x20},
+		"CKAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA\x00\x00\x21\x00\x01"...),
+	// SNMPv1 get of sysDescr.0 with the "public" community
+	161: {
+		0x30, 0x26, 0x02, 0x01, 0x00, 0x04, 0x06, 'p', 'u', 'b', 'l', 'i', 'c',
+		0xa0, 0x19, 0x02, 0x01, 0x01, 0x02, 0x01, 0x00, 0x02, 0x01, 0x00,
+		0x30, 0x0e, 0x30, 0x0c, 0x06, 0x08, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x01, 0x01, 0x00, 0x05, 0x00,
+	},
+	// SSDP discovery
+	1900: []byte("M-SEARCH * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\nMAN: \"ssdp:discover\"\r\nMX: 1\r\nST: ssdp:all\r\n\r\n"),
+	// mDNS query for the advertised service types
+	5353: append([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		"\x09_services\x07_dns-sd\x04_udp\x05local\x00\x00\x0c\x00\x01"...),
+}
+
+// UDPPorts returns the ports with a built-in UDP probe.
+func UDPPorts() []int {
+	return []int{53, 69, 123, 137, 161, 1900, 5353}
+}
+
+// scanUDPPort sends the port's probe and reads one reply. A reply means
+// open and an ICMP port unreachable means closed. Silence is
+// open|filtered, since UDP services often ignore requests they do not
+// understand.
+func (ps *PortScanner) scanUDPPort(ctx context.Context, host string, port int) PortResult {
+	result := PortResult{
+		Port:     port,
+		Protocol: string(UDP),
+		State:    StateOpenFiltered,
+		Service:  ps.getServiceName(port),
+	}
+
+	dialer := net.Dialer{Timeout: ps.timeout}
+	conn, err := dialer.DialContext(ctx, "udp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		result.State = StateClosed
+		return result
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(ps.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.SetDeadline(deadline)
+
+	// Closing the socket ends the read early when the scan is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if _, err := conn.Write(udpProbes[port]); err != nil {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			result.State = StateClosed
+		}
+		return result
+	}
+
+	buffer := make([]byte, 1024)
+	n, err := conn.Read(buffer)
+	switch {
+	case err == nil:
+		result.State = StateOpen
+		result.Banner = printableBanner(buffer[:n])
+	case errors.Is(err, syscall.ECONNREFUSED):
+		result.State = StateClosed
+	}
+	return result
+}
+
+// printableBanner keeps the readable text of a binary reply, such as the
+// strings in an SNMP or NetBIOS answer.
+func printableBanner(data []byte) string {
+	banner := make([]byte, 0, len(data))
+	space := false
+	for _, c := range data {
+		if c >= 0x21 && c <= 0x7e {
+			banner = append(banner, c)
+			space = false
+		} else if !space && len(banner) > 0 {
+			banner = append(banner, ' ')
+			space = true
+		}
+	}
+	if len(banner) > 200 {
+		return string(banner[:200]) + "..."
+	}
+	return string(bytes.TrimSpace(banner))
+}
@@ -0,0 +1,131 @@
+package progress
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"subdomain-finder/internal/fileutil"
+)
+
+// Snapshot is the JSON document written by FileWriter.
+type Snapshot struct {
+	Target         string    `json:"target"`
+	Total          int       `json:"total"`
+	Completed      int       `json:"completed"`
+	Found          int       `json:"found"`
+	Errors         int       `json:"errors"`
+	Percent        float64   `json:"percent"`
+	Rate           float64   `json:"rate"`
+	ETASeconds     float64   `json:"eta_seconds"`
+	ElapsedSeconds float64   `json:"elapsed_seconds"`
+	Done           bool      `json:"done"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// FileWriter periodically writes the latest Stats to a JSON file that
+// external monitors can poll. Each write replaces the file atomically.
+type FileWriter struct {
+	path     string
+	target   string
+	interval time.Duration
+
+	mu      sync.Mutex
+	stats   Stats
+	dirty   bool
+	lastErr error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewFileWriter(path, target string, interval time.Duration) *FileWriter {
+	return &FileWriter{
+		path:     path,
+		target:   target,
+		interval: interval,
+		dirty:    true,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Update records stats to be written on the next tick.
+func (fw *FileWriter) Update(stats Stats) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	fw.stats = stats
+	fw.dirty = true
+}
+
+// Start writes an initial snapshot and then rewrites the file every
+// interval while the stats keep changing.
+func (fw *FileWriter) Start() {
+	fw.flush(false)
+	go func() {
+		defer close(fw.done)
+		ticker := time.NewTicker(fw.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-fw.stop:
+				return
+			case <-ticker.C:
+				fw.flush(false)
+			}
+		}
+	}()
+}
+
+// Stop writes the final snapshot, marked done, and returns the last write
+// error, if any.
+func (fw *FileWriter) Stop() error {
+	close(fw.stop)
+	<-fw.done
+	fw.mu.Lock()
+	fw.dirty = true
+	fw.mu.Unlock()
+	fw.flush(true)
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	return fw.lastErr
+}
+
+func (fw *FileWriter) flush(done bool) {
+	fw.mu.Lock()
+	if !fw.dirty {
+		fw.mu.Unlock()
+		return
+	}
+	stats := fw.stats
+	fw.dirty = false
+	fw.mu.Unlock()
+
+	snapshot := Snapshot{
+		Target:         fw.target,
+		Total:          stats.Total,
+		Completed:      stats.Completed,
+		Found:          stats.Found,
+		Errors:         stats.Errors,
+		Rate:           stats.Rate,
+		ETASeconds:     stats.ETA.Seconds(),
+		ElapsedSeconds: stats.Elapsed.Seconds(),
+		Done:           done,
+		UpdatedAt:      time.Now().UTC(),
+	}
+	if stats.Total > 0 {
+		snapshot.Percent = float64(stats.Completed) / float64(stats.Total) * 100
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err == nil {
+		err = fileutil.WriteFileAtomic(fw.path, data)
+	}
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if err != nil {
+		fw.lastErr = err
+	}
+}
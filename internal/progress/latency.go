@@ -0,0 +1,95 @@
+package progress
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	histogramBase    = time.Millisecond
+	histogramGrowth  = 1.1
+	histogramBuckets = 160
+)
+
+type Latency struct {
+	Samples int
+	Min     time.Duration
+	Median  time.Duration
+	P95     time.Duration
+	Max     time.Duration
+}
+
+// Histogram records durations in logarithmic buckets from 1ms up to about
+// an hour, so memory stays fixed however large the scan. Percentiles are
+// accurate to one bucket (10%); min and max are exact.
+type Histogram struct {
+	counts [histogramBuckets]uint64
+	count  uint64
+	min    time.Duration
+	max    time.Duration
+}
+
+func (h *Histogram) Observe(d time.Duration) {
+	if h.count == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.counts[bucketFor(d)]++
+	h.count++
+}
+
+// Percentile returns the upper bound of the bucket holding the p-th
+// percentile (0-100), clamped to the observed range.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(p / 100 * float64(h.count)))
+	if rank == 0 {
+		rank = 1
+	}
+
+	var seen uint64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			value := bucketUpperBound(i)
+			if value > h.max {
+				value = h.max
+			}
+			if value < h.min {
+				value = h.min
+			}
+			return value
+		}
+	}
+	return h.max
+}
+
+func (h *Histogram) Summary() Latency {
+	return Latency{
+		Samples: int(h.count),
+		Min:     h.min,
+		Median:  h.Percentile(50),
+		P95:     h.Percentile(95),
+		Max:     h.max,
+	}
+}
+
+func bucketFor(d time.Duration) int {
+	if d <= histogramBase {
+		return 0
+	}
+	i := int(math.Ceil(math.Log(float64(d)/float64(histogramBase)) / math.Log(histogramGrowth)))
+	if i >= histogramBuckets {
+		return histogramBuckets - 1
+	}
+	return i
+}
+
+func bucketUpperBound(i int) time.Duration {
+	return time.Duration(float64(histogramBase) * math.Pow(histogramGrowth, float64(i)))
+}
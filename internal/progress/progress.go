@@ -2,6 +2,8 @@ package progress
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"sync"
 	"time"
 
@@ -24,6 +26,7 @@ type Stats struct {
 	Rate      float64
 	ETA       time.Duration
 	Elapsed   time.Duration
+	Response  Latency
 }
 
 func NewProgress(total int, showStats bool) *Progress {
@@ -79,17 +82,30 @@ func (p *Progress) SetTotal(total int) {
 	p.bar.SetTotal(int64(total))
 }
 
+func (p *Progress) Update(stats Stats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if stats.Total != p.stats.Total {
+		p.bar.SetTotal(int64(stats.Total))
+	}
+	p.bar.SetCurrent(int64(stats.Completed))
+	*p.stats = stats
+}
+
 func (p *Progress) updateStats() {
-	now := time.Now()
-	elapsed := now.Sub(p.startTime)
-	p.stats.Elapsed = elapsed
+	computeStats(p.stats, p.startTime)
+}
+
+func computeStats(stats *Stats, startTime time.Time) {
+	elapsed := time.Since(startTime)
+	stats.Elapsed = elapsed
 
-	if p.stats.Completed > 0 {
-		p.stats.Rate = float64(p.stats.Completed) / elapsed.Seconds()
+	if stats.Completed > 0 {
+		stats.Rate = float64(stats.Completed) / elapsed.Seconds()
 
-		if p.stats.Rate > 0 {
-			remaining := p.stats.Total - p.stats.Completed
-			p.stats.ETA = time.Duration(float64(remaining)/p.stats.Rate) * time.Second
+		if stats.Rate > 0 {
+			remaining := stats.Total - stats.Completed
+			stats.ETA = time.Duration(float64(remaining)/stats.Rate) * time.Second
 		}
 	}
 }
@@ -101,6 +117,10 @@ func (p *Progress) GetStats() Stats {
 }
 
 func (p *Progress) PrintStats() {
+	p.WriteStats(os.Stdout)
+}
+
+func (p *Progress) WriteStats(w io.Writer) {
 	if !p.showStats {
 		return
 	}
@@ -109,17 +129,82 @@ func (p *Progress) PrintStats() {
 	stats := *p.stats
 	p.mu.RUnlock()
 
-	fmt.Printf("\n")
-	fmt.Printf("Statistics:\n")
-	fmt.Printf("===========\n")
-	fmt.Printf("Total:     %d\n", stats.Total)
-	fmt.Printf("Completed: %d\n", stats.Completed)
-	fmt.Printf("Found:     %d\n", stats.Found)
-	fmt.Printf("Errors:    %d\n", stats.Errors)
-	fmt.Printf("Rate:      %.2f req/s\n", stats.Rate)
-	fmt.Printf("Elapsed:   %s\n", stats.Elapsed.Round(time.Second))
-	fmt.Printf("ETA:       %s\n", stats.ETA.Round(time.Second))
-	fmt.Printf("\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "Statistics:\n")
+	fmt.Fprintf(w, "===========\n")
+	fmt.Fprintf(w, "Total:     %d\n", stats.Total)
+	fmt.Fprintf(w, "Completed: %d\n", stats.Completed)
+	fmt.Fprintf(w, "Found:     %d\n", stats.Found)
+	fmt.Fprintf(w, "Errors:    %d\n", stats.Errors)
+	fmt.Fprintf(w, "Rate:      %.2f req/s\n", stats.Rate)
+	fmt.Fprintf(w, "Elapsed:   %s\n", stats.Elapsed.Round(time.Second))
+	fmt.Fprintf(w, "ETA:       %s\n", stats.ETA.Round(time.Second))
+	if stats.Response.Samples > 0 {
+		fmt.Fprintf(w, "Response:  min %s / median %s / p95 %s / max %s (%d hosts)\n",
+			roundLatency(stats.Response.Min), roundLatency(stats.Response.Median),
+			roundLatency(stats.Response.P95), roundLatency(stats.Response.Max), stats.Response.Samples)
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+func roundLatency(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(10 * time.Millisecond)
+	}
+	return d.Round(time.Millisecond)
+}
+
+type Tracker struct {
+	stats     Stats
+	responses Histogram
+	mu        sync.Mutex
+	startTime time.Time
+}
+
+func NewTracker(total int) *Tracker {
+	return &Tracker{
+		stats:     Stats{Total: total},
+		startTime: time.Now(),
+	}
+}
+
+func (t *Tracker) Increment(found bool) Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Completed++
+	if found {
+		t.stats.Found++
+	}
+	computeStats(&t.stats, t.startTime)
+	return t.stats
+}
+
+// ObserveResponseTime records how long one host took to answer its HTTP
+// fetch; the distribution is reported in Stats.Response.
+func (t *Tracker) ObserveResponseTime(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.responses.Observe(d)
+	t.stats.Response = t.responses.Summary()
+}
+
+// AddTotal grows the total when a scan discovers more candidates mid-run.
+func (t *Tracker) AddTotal(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Total += n
+}
+
+func (t *Tracker) AddError() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Errors++
+}
+
+func (t *Tracker) Stats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
 }
 
 type MultiProgress struct {
@@ -196,19 +281,23 @@ func (mp *MultiProgress) UpdateStats() {
 }
 
 func (mp *MultiProgress) PrintStats() {
+	mp.WriteStats(os.Stdout)
+}
+
+func (mp *MultiProgress) WriteStats(w io.Writer) {
 	mp.UpdateStats()
 
-	fmt.Printf("\n")
-	fmt.Printf("Multi-Progress Statistics:\n")
-	fmt.Printf("=========================\n")
-	fmt.Printf("Total Bars:  %d\n", mp.stats.TotalBars)
-	fmt.Printf("Active Bars: %d\n", mp.stats.ActiveBars)
-	fmt.Printf("Total Items: %d\n", mp.stats.TotalItems)
-	fmt.Printf("Completed:   %d\n", mp.stats.Completed)
-	fmt.Printf("Found:       %d\n", mp.stats.Found)
-	fmt.Printf("Errors:      %d\n", mp.stats.Errors)
-	fmt.Printf("Elapsed:     %s\n", mp.stats.Elapsed.Round(time.Second))
-	fmt.Printf("\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "Multi-Progress Statistics:\n")
+	fmt.Fprintf(w, "=========================\n")
+	fmt.Fprintf(w, "Total Bars:  %d\n", mp.stats.TotalBars)
+	fmt.Fprintf(w, "Active Bars: %d\n", mp.stats.ActiveBars)
+	fmt.Fprintf(w, "Total Items: %d\n", mp.stats.TotalItems)
+	fmt.Fprintf(w, "Completed:   %d\n", mp.stats.Completed)
+	fmt.Fprintf(w, "Found:       %d\n", mp.stats.Found)
+	fmt.Fprintf(w, "Errors:      %d\n", mp.stats.Errors)
+	fmt.Fprintf(w, "Elapsed:     %s\n", mp.stats.Elapsed.Round(time.Second))
+	fmt.Fprintf(w, "\n")
 }
 
 func (mp *MultiProgress) StopAll() {
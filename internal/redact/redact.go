@@ -0,0 +1,104 @@
+package redact
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Value stands in for cookie values, credentials and other secrets in
+// results and recordings. Reports only need the names and flags, and the
+// values may be session tokens, including the one from --login-url.
+const Value = "[redacted]"
+
+// sensitiveField matches form and query field names that usually carry a
+// password or token.
+var sensitiveField = regexp.MustCompile(`(?i)pass|pwd|secret|token|auth|credential|api[-_]?key|otp`)
+
+// SetCookie replaces the value of a Set-Cookie header, keeping the name and
+// attributes. Empty values, as sent to delete a cookie, are kept.
+func SetCookie(header string) string {
+	pair, attributes, hasAttributes := strings.Cut(header, ";")
+	name, value, ok := strings.Cut(pair, "=")
+	if !ok || strings.TrimSpace(value) == "" {
+		return header
+	}
+	header = name + "=" + Value
+	if hasAttributes {
+		header += ";" + attributes
+	}
+	return header
+}
+
+// Cookie replaces every value of a Cookie request header.
+func Cookie(header string) string {
+	pairs := strings.Split(header, ";")
+	for i, pair := range pairs {
+		if name, value, ok := strings.Cut(pair, "="); ok && value != "" {
+			pairs[i] = name + "=" + Value
+		}
+	}
+	return strings.Join(pairs, ";")
+}
+
+// Header redacts the value of a header that carries credentials: cookies
+// and authorization. Other headers are returned unchanged.
+func Header(name, value string) string {
+	switch strings.ToLower(name) {
+	case "cookie":
+		return Cookie(value)
+	case "set-cookie":
+		return SetCookie(value)
+	case "authorization", "proxy-authorization":
+		scheme, _, ok := strings.Cut(value, " ")
+		if !ok {
+			return Value
+		}
+		return scheme + " " + Value
+	}
+	return value
+}
+
+// SensitiveField reports whether a form or query field name looks like it
+// holds a password or token.
+func SensitiveField(name string) bool {
+	return sensitiveField.MatchString(name)
+}
+
+// Form masks the values of sensitive fields in URL-encoded form data or a
+// query string. Data that does not parse is returned unchanged.
+func Form(encoded string) string {
+	values, err := url.ParseQuery(encoded)
+	if err != nil {
+		return encoded
+	}
+	masked := false
+	for name, list := range values {
+		if !SensitiveField(name) {
+			continue
+		}
+		for i := range list {
+			list[i] = Value
+		}
+		masked = true
+	}
+	if !masked {
+		return encoded
+	}
+	return values.Encode()
+}
+
+// URL masks sensitive query fields and any user info password in raw.
+func URL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	if _, ok := parsed.User.Password(); ok {
+		parsed.User = url.UserPassword(parsed.User.Username(), Value)
+	}
+	if parsed.RawQuery != "" {
+		parsed.RawQuery = Form(parsed.RawQuery)
+	}
+	return parsed.String()
+}
@@ -1,9 +1,10 @@
 package reporter
 
 import (
+	"bufio"
 	"html/template"
-	"os"
 	"path/filepath"
+	"subdomain-finder/internal/fileutil"
 	"subdomain-finder/internal/types"
 	"time"
 )
@@ -21,27 +22,15 @@ func NewHTMLReporter(templateDir, outputDir string) *HTMLReporter {
 }
 
 func (hr *HTMLReporter) GenerateReport(summary *types.ScanSummary, results []types.Result, filename string) error {
-	if err := os.MkdirAll(hr.outputDir, 0755); err != nil {
-		return err
-	}
-
-	filePath := filepath.Join(hr.outputDir, filename)
-	file, err := os.Create(filePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
 	tmpl := hr.getReportTemplate()
-	if err := tmpl.Execute(file, map[string]interface{}{
-		"Summary":     summary,
-		"Results":     results,
-		"GeneratedAt": time.Now(),
-	}); err != nil {
-		return err
-	}
-
-	return nil
+	return fileutil.WriteBuffered(filepath.Join(hr.outputDir, filename), func(w *bufio.Writer) error {
+		return tmpl.Execute(w, map[string]interface{}{
+			"Summary":     summary,
+			"Results":     results,
+			"Surface":     AttackSurfaceSummary(results),
+			"GeneratedAt": time.Now(),
+		})
+	})
 }
 
 func (hr *HTMLReporter) getReportTemplate() *template.Template {
@@ -257,6 +246,27 @@ func (hr *HTMLReporter) getReportTemplate() *template.Template {
             color: #e74c3c;
         }
         
+        .new-badge {
+            display: inline-block;
+            background: #27ae60;
+            color: white;
+            padding: 2px 8px;
+            border-radius: 10px;
+            font-size: 0.7em;
+            font-weight: bold;
+            margin-left: 8px;
+        }
+        
+        .result-tag {
+            display: inline-block;
+            background: #ecf0f1;
+            color: #2c3e50;
+            padding: 2px 8px;
+            border-radius: 10px;
+            font-size: 0.7em;
+            margin-left: 6px;
+        }
+        
         .footer {
             text-align: center;
             color: #666;
@@ -325,6 +335,13 @@ func (hr *HTMLReporter) getReportTemplate() *template.Template {
                 <div class="number risk-high">{{.Summary.HighRiskItems}}</div>
                 <div class="label">Critical</div>
             </div>
+            {{if or .Summary.NewSubdomains .Summary.Disappeared}}
+            <div class="card">
+                <h3>New Since Last Scan</h3>
+                <div class="number">{{.Summary.NewSubdomains}}</div>
+                <div class="label">{{len .Summary.Disappeared}} disappeared</div>
+            </div>
+            {{end}}
             <div class="card">
                 <h3>Scan Duration</h3>
                 <div class="number">{{.Summary.ScanDuration}}</div>
@@ -332,12 +349,52 @@ func (hr *HTMLReporter) getReportTemplate() *template.Template {
             </div>
         </div>
         
+        <div class="results-section">
+            <h2>🎯 Attack Surface</h2>
+            {{if .Surface.TopRisks}}
+            <h3>Highest-Risk Subdomains</h3>
+            {{range .Surface.TopRisks}}
+            <div class="vuln-item">
+                <span class="vuln-severity risk-{{.RiskLevel}}">{{.Score}}</span> - <strong>{{.Subdomain}}</strong> ({{.IP}})
+                {{range .KeyFindings}}<br><small>{{.}}</small>{{end}}
+            </div>
+            {{end}}
+            {{else}}
+            <p>No risky subdomains identified.</p>
+            {{end}}
+            {{if .Surface.ExposedServices}}
+            <h3>Most Exposed Services</h3>
+            <div class="technologies">
+                {{range .Surface.ExposedServices}}
+                <span class="tech-tag">{{.Port}}/{{.Service}} on {{.Hosts}} host(s){{if .Sensitive}} ⚠{{end}}</span>
+                {{end}}
+            </div>
+            {{end}}
+            {{if .Surface.ExpiringCerts}}
+            <h3>Expiring Certificates</h3>
+            {{range .Surface.ExpiringCerts}}
+            <div class="vuln-item">
+                <strong>{{.Subdomain}}</strong> - {{if .Expired}}expired{{else}}expires in {{.DaysUntilExpiry}} days{{end}} ({{.Issuer}})
+            </div>
+            {{end}}
+            {{end}}
+        </div>
+        
+        {{if .Summary.Disappeared}}
+        <div class="results-section">
+            <h2>👻 Disappeared Since Last Scan</h2>
+            {{range .Summary.Disappeared}}
+            <div class="vuln-item">{{.}}</div>
+            {{end}}
+        </div>
+        {{end}}
+        
         <div class="results-section">
             <h2>📊 Detailed Results</h2>
             {{range .Results}}
             <div class="subdomain-item">
                 <div class="subdomain-header" onclick="toggleDetails(this)">
-                    <div class="subdomain-name">{{.Subdomain}}</div>
+                    <div class="subdomain-name">{{.Subdomain}}{{if .New}}<span class="new-badge">NEW</span>{{end}}{{range .Tags}}<span class="result-tag">{{.}}</span>{{end}}</div>
                     <div class="subdomain-status status-{{.Status}}">{{.Status}}</div>
                     <span class="toggle-icon">▼</span>
                 </div>
@@ -367,6 +424,22 @@ func (hr *HTMLReporter) getReportTemplate() *template.Template {
                             <div class="detail-label">Risk Level</div>
                             <div class="detail-value risk-{{.RiskLevel}}">{{.RiskLevel}}</div>
                         </div>
+                        {{with .GeoLocation}}
+                        <div class="detail-item">
+                            <div class="detail-label">Location</div>
+                            <div class="detail-value">{{.City}}{{if and .City .Country}}, {{end}}{{.Country}}</div>
+                        </div>
+                        <div class="detail-item">
+                            <div class="detail-label">Network</div>
+                            <div class="detail-value">{{.ASN}} {{.Organization}}</div>
+                        </div>
+                        {{end}}
+                        {{if .Source}}
+                        <div class="detail-item">
+                            <div class="detail-label">Discovered Via</div>
+                            <div class="detail-value">{{.Source}}</div>
+                        </div>
+                        {{end}}
                     </div>
                     
                     {{if .Technologies}}
@@ -378,6 +451,33 @@ func (hr *HTMLReporter) getReportTemplate() *template.Template {
                     </div>
                     {{end}}
                     
+                    {{if .WebServices}}
+                    <div class="technologies">
+                        <strong>Web Services:</strong>
+                        {{range .WebServices}}
+                        <div class="detail-value">:{{.Port}} <a href="{{.URL}}">{{.URL}}</a> [{{.Status}}] {{.Title}}{{range .Technologies}} <span class="tech-tag">{{.Name}} {{.Version}}</span>{{end}}</div>
+                        {{end}}
+                    </div>
+                    {{end}}
+                    
+                    {{if .Cookies}}
+                    <div class="technologies">
+                        <strong>Cookies:</strong>
+                        {{range .Cookies}}
+                        <div class="detail-value">{{.Name}}{{if .Domain}} ({{.Domain}}{{.Path}}){{end}}{{if .Secure}} <span class="tech-tag">Secure</span>{{end}}{{if .HttpOnly}} <span class="tech-tag">HttpOnly</span>{{end}}{{if .SameSite}} <span class="tech-tag">SameSite={{.SameSite}}</span>{{end}}</div>
+                        {{end}}
+                    </div>
+                    {{end}}
+                    
+                    {{if .Headers}}
+                    <div class="technologies">
+                        <strong>Response Headers:</strong>
+                        {{range $name, $value := .Headers}}
+                        <div class="detail-value"><strong>{{$name}}:</strong> {{$value}}</div>
+                        {{end}}
+                    </div>
+                    {{end}}
+                    
                     {{if .Vulnerabilities}}
                     <div class="vulnerabilities">
                         <strong>Vulnerabilities:</strong>
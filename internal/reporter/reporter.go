@@ -1,10 +1,13 @@
 package reporter
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"subdomain-finder/internal/fileutil"
+	"subdomain-finder/internal/history"
 	"subdomain-finder/internal/types"
 	"time"
 )
@@ -26,6 +29,7 @@ func (r *Reporter) GenerateSummaryReport(results []types.Result) *types.ScanSumm
 		OpenPorts:        0,
 		Vulnerabilities:  0,
 		HighRiskItems:    0,
+		Disappeared:      make([]string, 0),
 		Technologies:     make([]types.Technology, 0),
 		TopPorts:         make([]types.PortInfo, 0),
 		RiskDistribution: make(map[string]int),
@@ -44,9 +48,11 @@ func (r *Reporter) GenerateSummaryReport(results []types.Result) *types.ScanSumm
 		}
 
 		// Count open ports
-		summary.OpenPorts += len(result.Ports)
 		for _, port := range result.Ports {
-			portMap[port.Port]++
+			if port.Open() {
+				summary.OpenPorts++
+				portMap[port.Port]++
+			}
 		}
 
 		// Count vulnerabilities
@@ -62,6 +68,13 @@ func (r *Reporter) GenerateSummaryReport(results []types.Result) *types.ScanSumm
 			techMap[tech.Name]++
 		}
 
+		if result.New {
+			summary.NewSubdomains++
+		}
+		if result.Change == history.ChangeStale {
+			summary.Disappeared = append(summary.Disappeared, result.Subdomain)
+		}
+
 		// Count risk levels
 		summary.RiskDistribution[result.RiskLevel]++
 	}
@@ -114,153 +127,160 @@ func (r *Reporter) getServiceName(port int) string {
 }
 
 func (r *Reporter) SaveAsJSON(results []types.Result, filename string) error {
-	if err := os.MkdirAll(r.outputDir, 0755); err != nil {
-		return err
-	}
-
-	filePath := filepath.Join(r.outputDir, filename)
-	file, err := os.Create(filePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+	return fileutil.WriteBuffered(filepath.Join(r.outputDir, filename), func(w *bufio.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	})
+}
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(results)
+// SaveAsJSONL writes one compact JSON object per line, so results can be
+// processed line by line without reading the whole file.
+func (r *Reporter) SaveAsJSONL(results []types.Result, filename string) error {
+	return fileutil.WriteBuffered(filepath.Join(r.outputDir, filename), func(w *bufio.Writer) error {
+		encoder := json.NewEncoder(w)
+		for _, result := range results {
+			if err := encoder.Encode(result); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-func (r *Reporter) SaveAsXML(results []types.Result, filename string) error {
-	if err := os.MkdirAll(r.outputDir, 0755); err != nil {
-		return err
+// LoadJSON reads results saved by SaveAsJSON or output.WriteReport. The
+// summary is nil when the file holds only results.
+func LoadJSON(path string) ([]types.Result, *types.ScanSummary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	filePath := filepath.Join(r.outputDir, filename)
-	file, err := os.Create(filePath)
+	results, summary, err := types.DecodeResults(data)
 	if err != nil {
-		return err
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", path, err)
 	}
-	defer file.Close()
+	return results, summary, nil
+}
 
-	_, _ = file.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
-	_, _ = file.WriteString("<subdomain-scan-results>\n")
-	_, _ = file.WriteString(fmt.Sprintf("  <scan-info>\n"))
-	_, _ = file.WriteString(fmt.Sprintf("    <total-subdomains>%d</total-subdomains>\n", len(results)))
-	_, _ = file.WriteString(fmt.Sprintf("    <scan-date>%s</scan-date>\n", time.Now().Format(time.RFC3339)))
-	_, _ = file.WriteString(fmt.Sprintf("  </scan-info>\n"))
+func (r *Reporter) SaveAsXML(results []types.Result, filename string) error {
+	return fileutil.WriteBuffered(filepath.Join(r.outputDir, filename), func(w *bufio.Writer) error {
+		w.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+		w.WriteString("<subdomain-scan-results>\n")
+		w.WriteString("  <scan-info>\n")
+		fmt.Fprintf(w, "    <total-subdomains>%d</total-subdomains>\n", len(results))
+		fmt.Fprintf(w, "    <scan-date>%s</scan-date>\n", time.Now().Format(time.RFC3339))
+		w.WriteString("  </scan-info>\n")
+
+		for _, result := range results {
+			w.WriteString("  <subdomain>\n")
+			fmt.Fprintf(w, "    <name>%s</name>\n", result.Subdomain)
+			fmt.Fprintf(w, "    <ip>%s</ip>\n", result.IP)
+			fmt.Fprintf(w, "    <status>%s</status>\n", result.Status)
+			fmt.Fprintf(w, "    <server>%s</server>\n", result.Server)
+			fmt.Fprintf(w, "    <title>%s</title>\n", result.Title)
+			fmt.Fprintf(w, "    <risk-level>%s</risk-level>\n", result.RiskLevel)
+			fmt.Fprintf(w, "    <confidence>%d</confidence>\n", result.Confidence)
+			fmt.Fprintf(w, "    <response-time>%s</response-time>\n", result.ResponseTime)
+			if result.Source != "" {
+				fmt.Fprintf(w, "    <source>%s</source>\n", result.Source)
+			}
 
-	for _, result := range results {
-		_, _ = file.WriteString("  <subdomain>\n")
-		_, _ = file.WriteString(fmt.Sprintf("    <name>%s</name>\n", result.Subdomain))
-		_, _ = file.WriteString(fmt.Sprintf("    <ip>%s</ip>\n", result.IP))
-		file.WriteString(fmt.Sprintf("    <status>%s</status>\n", result.Status))
-		file.WriteString(fmt.Sprintf("    <server>%s</server>\n", result.Server))
-		file.WriteString(fmt.Sprintf("    <title>%s</title>\n", result.Title))
-		file.WriteString(fmt.Sprintf("    <risk-level>%s</risk-level>\n", result.RiskLevel))
-		file.WriteString(fmt.Sprintf("    <confidence>%d</confidence>\n", result.Confidence))
-		file.WriteString(fmt.Sprintf("    <response-time>%s</response-time>\n", result.ResponseTime))
-
-		if len(result.Ports) > 0 {
-			file.WriteString("    <ports>\n")
-			for _, port := range result.Ports {
-				file.WriteString("      <port>\n")
-				file.WriteString(fmt.Sprintf("        <number>%d</number>\n", port.Port))
-				file.WriteString(fmt.Sprintf("        <protocol>%s</protocol>\n", port.Protocol))
-				file.WriteString(fmt.Sprintf("        <state>%s</state>\n", port.State))
-				file.WriteString(fmt.Sprintf("        <service>%s</service>\n", port.Service))
-				file.WriteString("      </port>\n")
+			if len(result.Ports) > 0 {
+				w.WriteString("    <ports>\n")
+				for _, port := range result.Ports {
+					w.WriteString("      <port>\n")
+					fmt.Fprintf(w, "        <number>%d</number>\n", port.Port)
+					fmt.Fprintf(w, "        <protocol>%s</protocol>\n", port.Protocol)
+					fmt.Fprintf(w, "        <state>%s</state>\n", port.State)
+					fmt.Fprintf(w, "        <service>%s</service>\n", port.Service)
+					w.WriteString("      </port>\n")
+				}
+				w.WriteString("    </ports>\n")
 			}
-			file.WriteString("    </ports>\n")
-		}
 
-		if len(result.Technologies) > 0 {
-			file.WriteString("    <technologies>\n")
-			for _, tech := range result.Technologies {
-				file.WriteString("      <technology>\n")
-				file.WriteString(fmt.Sprintf("        <name>%s</name>\n", tech.Name))
-				file.WriteString(fmt.Sprintf("        <version>%s</version>\n", tech.Version))
-				file.WriteString(fmt.Sprintf("        <category>%s</category>\n", tech.Category))
-				file.WriteString(fmt.Sprintf("        <confidence>%d</confidence>\n", tech.Confidence))
-				file.WriteString("      </technology>\n")
+			if len(result.Technologies) > 0 {
+				w.WriteString("    <technologies>\n")
+				for _, tech := range result.Technologies {
+					w.WriteString("      <technology>\n")
+					fmt.Fprintf(w, "        <name>%s</name>\n", tech.Name)
+					fmt.Fprintf(w, "        <version>%s</version>\n", tech.Version)
+					fmt.Fprintf(w, "        <category>%s</category>\n", tech.Category)
+					fmt.Fprintf(w, "        <confidence>%d</confidence>\n", tech.Confidence)
+					w.WriteString("      </technology>\n")
+				}
+				w.WriteString("    </technologies>\n")
 			}
-			file.WriteString("    </technologies>\n")
-		}
 
-		if len(result.Vulnerabilities) > 0 {
-			file.WriteString("    <vulnerabilities>\n")
-			for _, vuln := range result.Vulnerabilities {
-				file.WriteString("      <vulnerability>\n")
-				file.WriteString(fmt.Sprintf("        <name>%s</name>\n", vuln.Name))
-				file.WriteString(fmt.Sprintf("        <severity>%s</severity>\n", vuln.Severity))
-				file.WriteString(fmt.Sprintf("        <description>%s</description>\n", vuln.Description))
-				file.WriteString(fmt.Sprintf("        <solution>%s</solution>\n", vuln.Solution))
-				file.WriteString("      </vulnerability>\n")
+			if len(result.Vulnerabilities) > 0 {
+				w.WriteString("    <vulnerabilities>\n")
+				for _, vuln := range result.Vulnerabilities {
+					w.WriteString("      <vulnerability>\n")
+					fmt.Fprintf(w, "        <name>%s</name>\n", vuln.Name)
+					fmt.Fprintf(w, "        <severity>%s</severity>\n", vuln.Severity)
+					fmt.Fprintf(w, "        <description>%s</description>\n", vuln.Description)
+					fmt.Fprintf(w, "        <solution>%s</solution>\n", vuln.Solution)
+					w.WriteString("      </vulnerability>\n")
+				}
+				w.WriteString("    </vulnerabilities>\n")
 			}
-			file.WriteString("    </vulnerabilities>\n")
-		}
 
-		file.WriteString("  </subdomain>\n")
-	}
+			w.WriteString("  </subdomain>\n")
+		}
 
-	file.WriteString("</subdomain-scan-results>\n")
-	return nil
+		w.WriteString("</subdomain-scan-results>\n")
+		return nil
+	})
 }
 
 func (r *Reporter) SaveAsCSV(results []types.Result, filename string) error {
-	if err := os.MkdirAll(r.outputDir, 0755); err != nil {
-		return err
-	}
-
-	filePath := filepath.Join(r.outputDir, filename)
-	file, err := os.Create(filePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Write header
-	file.WriteString("Subdomain,IP,Status,Server,Title,Risk Level,Confidence,Response Time,Open Ports,Technologies,Vulnerabilities\n")
+	return fileutil.WriteBuffered(filepath.Join(r.outputDir, filename), func(w *bufio.Writer) error {
+		// Write header
+		w.WriteString("Subdomain,IP,Status,Server,Title,Risk Level,Confidence,Response Time,Open Ports,Technologies,Vulnerabilities\n")
 
-	for _, result := range results {
-		ports := ""
-		for i, port := range result.Ports {
-			if i > 0 {
-				ports += ";"
+		for _, result := range results {
+			ports := ""
+			for _, port := range result.Ports {
+				if !port.Open() {
+					continue
+				}
+				if ports != "" {
+					ports += ";"
+				}
+				ports += fmt.Sprintf("%d:%s", port.Port, port.Service)
 			}
-			ports += fmt.Sprintf("%d:%s", port.Port, port.Service)
-		}
 
-		technologies := ""
-		for i, tech := range result.Technologies {
-			if i > 0 {
-				technologies += ";"
+			technologies := ""
+			for i, tech := range result.Technologies {
+				if i > 0 {
+					technologies += ";"
+				}
+				technologies += tech.Name
 			}
-			technologies += tech.Name
-		}
 
-		vulnerabilities := ""
-		for i, vuln := range result.Vulnerabilities {
-			if i > 0 {
-				vulnerabilities += ";"
+			vulnerabilities := ""
+			for i, vuln := range result.Vulnerabilities {
+				if i > 0 {
+					vulnerabilities += ";"
+				}
+				vulnerabilities += vuln.Name
 			}
-			vulnerabilities += vuln.Name
-		}
 
-		line := fmt.Sprintf("%s,%s,%s,%s,%s,%s,%d,%s,%s,%s,%s\n",
-			result.Subdomain,
-			result.IP,
-			result.Status,
-			result.Server,
-			result.Title,
-			result.RiskLevel,
-			result.Confidence,
-			result.ResponseTime,
-			ports,
-			technologies,
-			vulnerabilities,
-		)
-		file.WriteString(line)
-	}
+			line := fmt.Sprintf("%s,%s,%s,%s,%s,%s,%d,%s,%s,%s,%s\n",
+				result.Subdomain,
+				result.IP,
+				result.Status,
+				result.Server,
+				result.Title,
+				result.RiskLevel,
+				result.Confidence,
+				result.ResponseTime,
+				ports,
+				technologies,
+				vulnerabilities,
+			)
+			w.WriteString(line)
+		}
 
-	return nil
+		return nil
+	})
 }
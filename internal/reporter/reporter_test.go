@@ -0,0 +1,48 @@
+package reporter
+
+import (
+	"reflect"
+	"testing"
+
+	"subdomain-finder/internal/history"
+	"subdomain-finder/internal/types"
+)
+
+func TestGenerateSummaryReportDisappeared(t *testing.T) {
+	results := []types.Result{
+		{Subdomain: "www.example.com", IP: "192.0.2.1", Change: history.ChangeUnchanged},
+		{Subdomain: "old.example.com", IP: "192.0.2.2", Change: history.ChangeStale},
+		{Subdomain: "new.example.com", IP: "192.0.2.3", Change: history.ChangeAdded, New: true},
+		{Subdomain: "gone.example.com", Change: history.ChangeStale},
+	}
+
+	summary := NewReporter("").GenerateSummaryReport(results)
+	if want := []string{"old.example.com", "gone.example.com"}; !reflect.DeepEqual(summary.Disappeared, want) {
+		t.Errorf("disappeared = %q, want %q", summary.Disappeared, want)
+	}
+	if summary.NewSubdomains != 1 {
+		t.Errorf("new subdomains = %d, want 1", summary.NewSubdomains)
+	}
+}
+
+func TestGenerateSummaryReportOpenPorts(t *testing.T) {
+	results := []types.Result{
+		{Subdomain: "www.example.com", IP: "192.0.2.1", Ports: []types.PortInfo{
+			{Port: 443, Protocol: "tcp", State: "open"},
+			{Port: 53, Protocol: "udp", State: "open"},
+			{Port: 161, Protocol: "udp", State: "open|filtered"},
+		}},
+		// Results saved before states were kept count as open
+		{Subdomain: "old.example.com", IP: "192.0.2.2", Ports: []types.PortInfo{{Port: 22}}},
+	}
+
+	summary := NewReporter("").GenerateSummaryReport(results)
+	if summary.OpenPorts != 3 {
+		t.Errorf("open ports = %d, want 3", summary.OpenPorts)
+	}
+	for _, port := range summary.TopPorts {
+		if port.Port == 161 {
+			t.Errorf("open|filtered port 161 counted in top ports: %+v", summary.TopPorts)
+		}
+	}
+}
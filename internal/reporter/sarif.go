@@ -0,0 +1,256 @@
+package reporter
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"subdomain-finder/internal/fileutil"
+	"subdomain-finder/internal/types"
+)
+
+const (
+	sarifVersion  = "2.1.0"
+	sarifSchema   = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolName = "subdomain-finder"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations,omitempty"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string              `json:"id"`
+	Name             string              `json:"name"`
+	ShortDescription sarifMessage        `json:"shortDescription"`
+	Help             *sarifMessage       `json:"help,omitempty"`
+	HelpURI          string              `json:"helpUri,omitempty"`
+	DefaultConfig    sarifConfiguration  `json:"defaultConfiguration"`
+	Properties       sarifRuleProperties `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProperties struct {
+	Tags             []string `json:"tags"`
+	SecuritySeverity string   `json:"security-severity"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool   `json:"executionSuccessful"`
+	StartTimeUTC        string `json:"startTimeUtc,omitempty"`
+	EndTimeUTC          string `json:"endTimeUtc,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// SaveAsSARIF writes every vulnerability as a SARIF 2.1.0 result located at
+// its subdomain, for upload to code scanning tools. Each distinct finding
+// name becomes one rule. summary may be nil; when set, its start and end
+// times describe the run.
+func (r *Reporter) SaveAsSARIF(summary *types.ScanSummary, results []types.Result, filename string) error {
+	return fileutil.WriteBuffered(filepath.Join(r.outputDir, filename), func(w *bufio.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(buildSARIF(summary, results))
+	})
+}
+
+func buildSARIF(summary *types.ScanSummary, results []types.Result) sarifLog {
+	// Rules are sorted by id so the same findings always produce the same
+	// rule indexes. A rule takes the highest severity it was reported with.
+	rules := make(map[string]types.Vulnerability)
+	for _, result := range results {
+		for _, vuln := range result.Vulnerabilities {
+			id := sarifRuleID(vuln.Name)
+			if existing, ok := rules[id]; !ok || types.SeverityRank(vuln.Severity) > types.SeverityRank(existing.Severity) {
+				rules[id] = vuln
+			}
+		}
+	}
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	driver := sarifDriver{Name: sarifToolName, Rules: make([]sarifRule, 0, len(ids))}
+	ruleIndex := make(map[string]int, len(ids))
+	for i, id := range ids {
+		ruleIndex[id] = i
+		driver.Rules = append(driver.Rules, newSARIFRule(id, rules[id]))
+	}
+
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: make([]sarifResult, 0)}
+	for _, result := range results {
+		for _, vuln := range result.Vulnerabilities {
+			id := sarifRuleID(vuln.Name)
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    id,
+				RuleIndex: ruleIndex[id],
+				Level:     sarifLevel(vuln.Severity),
+				Message:   sarifMessage{Text: sarifText(result.Subdomain, vuln)},
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: result.Subdomain},
+						Region:           sarifRegion{StartLine: 1},
+					},
+				}},
+				PartialFingerprints: map[string]string{
+					"subdomainFinding/v1": sarifFingerprint(result.Subdomain, id, vuln.Evidence),
+				},
+			})
+		}
+	}
+
+	if summary != nil {
+		invocation := sarifInvocation{ExecutionSuccessful: true}
+		if !summary.StartTime.IsZero() {
+			invocation.StartTimeUTC = summary.StartTime.UTC().Format(time.RFC3339)
+		}
+		if !summary.EndTime.IsZero() {
+			invocation.EndTimeUTC = summary.EndTime.UTC().Format(time.RFC3339)
+		}
+		run.Invocations = []sarifInvocation{invocation}
+	}
+
+	return sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+}
+
+func newSARIFRule(id string, vuln types.Vulnerability) sarifRule {
+	rule := sarifRule{
+		ID:               id,
+		Name:             vuln.Name,
+		ShortDescription: sarifMessage{Text: vuln.Name},
+		DefaultConfig:    sarifConfiguration{Level: sarifLevel(vuln.Severity)},
+		Properties: sarifRuleProperties{
+			Tags:             []string{"security"},
+			SecuritySeverity: securitySeverity(vuln.Severity),
+		},
+	}
+	if vuln.Solution != "" {
+		rule.Help = &sarifMessage{Text: vuln.Solution}
+	}
+	if len(vuln.References) > 0 {
+		rule.HelpURI = vuln.References[0]
+	}
+	return rule
+}
+
+// sarifLevel maps Critical and High to error, Medium to warning and
+// everything else to note.
+func sarifLevel(severity string) string {
+	switch rank := types.SeverityRank(severity); {
+	case rank >= types.SeverityRank("high"):
+		return "error"
+	case rank == types.SeverityRank("medium"):
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// securitySeverity is the score GitHub uses to rank code scanning alerts.
+func securitySeverity(severity string) string {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "critical":
+		return "9.5"
+	case "high":
+		return "8.0"
+	case "medium":
+		return "5.5"
+	case "low":
+		return "3.0"
+	default:
+		return "0.0"
+	}
+}
+
+// sarifRuleID turns a finding name into a stable id such as
+// "missing-security-header".
+func sarifRuleID(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	id := strings.TrimSuffix(b.String(), "-")
+	if id == "" {
+		return "finding"
+	}
+	return id
+}
+
+func sarifText(subdomain string, vuln types.Vulnerability) string {
+	text := vuln.Name + " on " + subdomain
+	if vuln.Description != "" {
+		text += ": " + vuln.Description
+	}
+	if vuln.Evidence != "" {
+		text += " (" + vuln.Evidence + ")"
+	}
+	return text
+}
+
+func sarifFingerprint(subdomain, ruleID, evidence string) string {
+	sum := sha256.Sum256([]byte(subdomain + "\x00" + ruleID + "\x00" + evidence))
+	return hex.EncodeToString(sum[:16])
+}
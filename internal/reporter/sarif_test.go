@@ -0,0 +1,162 @@
+package reporter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"subdomain-finder/internal/types"
+)
+
+// TestSaveAsSARIF checks the written log against the properties SARIF 2.1.0
+// requires and the values it constrains.
+func TestSaveAsSARIF(t *testing.T) {
+	dir := t.TempDir()
+	summary := &types.ScanSummary{
+		StartTime: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2024, 5, 1, 10, 5, 0, 0, time.UTC),
+	}
+	results := []types.Result{
+		{Subdomain: "www.example.com", Vulnerabilities: []types.Vulnerability{
+			{Name: "Missing Security Header", Severity: "Low", Evidence: "X-Frame-Options"},
+			{Name: "SQL Injection", Severity: "Critical", Solution: "Use parameterized queries", References: []string{"https://owasp.org/www-community/attacks/SQL_Injection"}},
+		}},
+		{Subdomain: "api.example.com", Vulnerabilities: []types.Vulnerability{
+			{Name: "Missing Security Header", Severity: "Medium", Evidence: "Content-Security-Policy"},
+			{Name: "???", Severity: "info"},
+		}},
+		{Subdomain: "static.example.com"},
+	}
+
+	if err := NewReporter(dir).SaveAsSARIF(summary, results, "results.sarif"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "results.sarif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log map[string]any
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log["version"] != "2.1.0" {
+		t.Errorf("version = %v, want 2.1.0", log["version"])
+	}
+	if _, ok := log["$schema"].(string); !ok {
+		t.Error("$schema missing")
+	}
+
+	runs := array(t, log, "runs")
+	if len(runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(runs))
+	}
+	run := runs[0].(map[string]any)
+	driver := object(t, object(t, run, "tool"), "driver")
+	if driver["name"] != "subdomain-finder" {
+		t.Errorf("driver name = %v", driver["name"])
+	}
+
+	levels := map[string]bool{"none": true, "note": true, "warning": true, "error": true}
+	rules := array(t, driver, "rules")
+	ruleIDs := make([]string, len(rules))
+	seen := make(map[string]bool)
+	for i, r := range rules {
+		rule := r.(map[string]any)
+		id, _ := rule["id"].(string)
+		if id == "" || seen[id] {
+			t.Errorf("rule %d has a missing or repeated id %q", i, id)
+		}
+		seen[id] = true
+		ruleIDs[i] = id
+		if text := object(t, rule, "shortDescription")["text"]; text == "" || text == nil {
+			t.Errorf("rule %s has no shortDescription text", id)
+		}
+		if level := object(t, rule, "defaultConfiguration")["level"]; !levels[level.(string)] {
+			t.Errorf("rule %s has level %v", id, level)
+		}
+	}
+	if len(rules) != 3 {
+		t.Errorf("got %d rules, want 3: %v", len(rules), ruleIDs)
+	}
+
+	sarifResults := array(t, run, "results")
+	if len(sarifResults) != 4 {
+		t.Fatalf("got %d results, want 4", len(sarifResults))
+	}
+	for i, r := range sarifResults {
+		result := r.(map[string]any)
+		if text, _ := object(t, result, "message")["text"].(string); text == "" {
+			t.Errorf("result %d has no message text", i)
+		}
+		if !levels[result["level"].(string)] {
+			t.Errorf("result %d has level %v", i, result["level"])
+		}
+		index := int(result["ruleIndex"].(float64))
+		if index < 0 || index >= len(ruleIDs) || ruleIDs[index] != result["ruleId"] {
+			t.Errorf("result %d: ruleIndex %d does not point at rule %v", i, index, result["ruleId"])
+		}
+		for _, l := range array(t, result, "locations") {
+			physical := object(t, l.(map[string]any), "physicalLocation")
+			if uri, _ := object(t, physical, "artifactLocation")["uri"].(string); uri == "" {
+				t.Errorf("result %d has an empty artifact uri", i)
+			}
+			if line := object(t, physical, "region")["startLine"].(float64); line < 1 {
+				t.Errorf("result %d has startLine %v", i, line)
+			}
+		}
+	}
+
+	invocations := array(t, run, "invocations")
+	if len(invocations) != 1 {
+		t.Fatalf("got %d invocations, want 1", len(invocations))
+	}
+	invocation := invocations[0].(map[string]any)
+	if _, ok := invocation["executionSuccessful"].(bool); !ok {
+		t.Error("invocation has no executionSuccessful")
+	}
+	if invocation["startTimeUtc"] != "2024-05-01T10:00:00Z" {
+		t.Errorf("startTimeUtc = %v", invocation["startTimeUtc"])
+	}
+}
+
+func TestSARIFRuleSeverity(t *testing.T) {
+	log := buildSARIF(nil, []types.Result{
+		{Subdomain: "a.example.com", Vulnerabilities: []types.Vulnerability{{Name: "Open Redirect", Severity: "Low"}}},
+		{Subdomain: "b.example.com", Vulnerabilities: []types.Vulnerability{{Name: "Open Redirect", Severity: "High"}}},
+	})
+
+	rules := log.Runs[0].Tool.Driver.Rules
+	if len(rules) != 1 {
+		t.Fatalf("got %d rules, want 1", len(rules))
+	}
+	if rules[0].ID != "open-redirect" || rules[0].DefaultConfig.Level != "error" {
+		t.Errorf("rule = %s with level %s, want open-redirect with level error", rules[0].ID, rules[0].DefaultConfig.Level)
+	}
+	if levels := []string{log.Runs[0].Results[0].Level, log.Runs[0].Results[1].Level}; levels[0] != "note" || levels[1] != "error" {
+		t.Errorf("result levels = %v, want [note error]", levels)
+	}
+	if log.Runs[0].Invocations != nil {
+		t.Error("invocations written without a summary")
+	}
+}
+
+func object(t *testing.T, parent map[string]any, key string) map[string]any {
+	t.Helper()
+	value, ok := parent[key].(map[string]any)
+	if !ok {
+		t.Fatalf("%q is missing or not an object", key)
+	}
+	return value
+}
+
+func array(t *testing.T, parent map[string]any, key string) []any {
+	t.Helper()
+	value, ok := parent[key].([]any)
+	if !ok {
+		t.Fatalf("%q is missing or not an array", key)
+	}
+	return value
+}
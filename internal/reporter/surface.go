@@ -0,0 +1,176 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"subdomain-finder/internal/types"
+)
+
+const attackSurfaceTopN = 10
+
+var sensitivePorts = map[int]bool{
+	21: true, 22: true, 23: true, 445: true, 1433: true, 2375: true, 3306: true,
+	3389: true, 5432: true, 5900: true, 6379: true, 9200: true, 11211: true, 27017: true,
+}
+
+var severityWeights = map[string]int{
+	"critical": 10,
+	"high":     7,
+	"medium":   4,
+	"low":      1,
+}
+
+type RankedSubdomain struct {
+	Subdomain      string   `json:"subdomain"`
+	IP             string   `json:"ip"`
+	RiskLevel      string   `json:"risk_level"`
+	Score          int      `json:"score"`
+	KeyFindings    []string `json:"key_findings"`
+	SensitivePorts []int    `json:"sensitive_ports"`
+}
+
+type ExposedService struct {
+	Port      int    `json:"port"`
+	Service   string `json:"service"`
+	Hosts     int    `json:"hosts"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+type ExpiringCert struct {
+	Subdomain       string `json:"subdomain"`
+	DaysUntilExpiry int    `json:"days_until_expiry"`
+	Expired         bool   `json:"expired"`
+	Issuer          string `json:"issuer"`
+}
+
+type AttackSurface struct {
+	TopRisks        []RankedSubdomain `json:"top_risks"`
+	ExposedServices []ExposedService  `json:"exposed_services"`
+	ExpiringCerts   []ExpiringCert    `json:"expiring_certs"`
+}
+
+// AttackSurfaceSummary ranks subdomains by a combined score of risk level,
+// vulnerability severity and sensitive-port exposure, and lists the most
+// exposed services and certificates that are expired or about to expire.
+func AttackSurfaceSummary(results []types.Result) *AttackSurface {
+	surface := &AttackSurface{
+		TopRisks:        make([]RankedSubdomain, 0),
+		ExposedServices: make([]ExposedService, 0),
+		ExpiringCerts:   make([]ExpiringCert, 0),
+	}
+
+	services := make(map[int]*ExposedService)
+
+	for _, result := range results {
+		if result.Subdomain == "" {
+			continue
+		}
+
+		ranked := rankSubdomain(result)
+		if ranked.Score > 0 {
+			surface.TopRisks = append(surface.TopRisks, ranked)
+		}
+
+		for _, port := range result.Ports {
+			if !port.Open() {
+				continue
+			}
+			service, exists := services[port.Port]
+			if !exists {
+				name := port.Service
+				if name == "" {
+					name = "Unknown"
+				}
+				service = &ExposedService{Port: port.Port, Service: name, Sensitive: sensitivePorts[port.Port]}
+				services[port.Port] = service
+			}
+			service.Hosts++
+		}
+
+		if result.SSL != nil && (result.SSL.Expired || result.SSL.ExpiresSoon) {
+			surface.ExpiringCerts = append(surface.ExpiringCerts, ExpiringCert{
+				Subdomain:       result.Subdomain,
+				DaysUntilExpiry: result.SSL.DaysUntilExpiry,
+				Expired:         result.SSL.Expired,
+				Issuer:          result.SSL.Issuer,
+			})
+		}
+	}
+
+	sort.SliceStable(surface.TopRisks, func(i, j int) bool {
+		if surface.TopRisks[i].Score != surface.TopRisks[j].Score {
+			return surface.TopRisks[i].Score > surface.TopRisks[j].Score
+		}
+		return surface.TopRisks[i].Subdomain < surface.TopRisks[j].Subdomain
+	})
+	if len(surface.TopRisks) > attackSurfaceTopN {
+		surface.TopRisks = surface.TopRisks[:attackSurfaceTopN]
+	}
+
+	for _, service := range services {
+		surface.ExposedServices = append(surface.ExposedServices, *service)
+	}
+	sort.Slice(surface.ExposedServices, func(i, j int) bool {
+		a, b := surface.ExposedServices[i], surface.ExposedServices[j]
+		if a.Sensitive != b.Sensitive {
+			return a.Sensitive
+		}
+		if a.Hosts != b.Hosts {
+			return a.Hosts > b.Hosts
+		}
+		return a.Port < b.Port
+	})
+	if len(surface.ExposedServices) > attackSurfaceTopN {
+		surface.ExposedServices = surface.ExposedServices[:attackSurfaceTopN]
+	}
+
+	sort.SliceStable(surface.ExpiringCerts, func(i, j int) bool {
+		return surface.ExpiringCerts[i].DaysUntilExpiry < surface.ExpiringCerts[j].DaysUntilExpiry
+	})
+
+	return surface
+}
+
+func rankSubdomain(result types.Result) RankedSubdomain {
+	ranked := RankedSubdomain{
+		Subdomain:      result.Subdomain,
+		IP:             result.IP,
+		RiskLevel:      result.RiskLevel,
+		KeyFindings:    make([]string, 0),
+		SensitivePorts: make([]int, 0),
+	}
+
+	if rank := types.SeverityRank(result.RiskLevel); rank > 0 {
+		ranked.Score += rank * 10
+	}
+
+	vulns := make([]types.Vulnerability, len(result.Vulnerabilities))
+	copy(vulns, result.Vulnerabilities)
+	sort.SliceStable(vulns, func(i, j int) bool {
+		return types.SeverityRank(vulns[i].Severity) > types.SeverityRank(vulns[j].Severity)
+	})
+	for i, vuln := range vulns {
+		ranked.Score += severityWeights[strings.ToLower(vuln.Severity)]
+		if i < 3 && types.SeverityRank(vuln.Severity) >= types.SeverityRank("medium") {
+			ranked.KeyFindings = append(ranked.KeyFindings, fmt.Sprintf("[%s] %s", vuln.Severity, vuln.Name))
+		}
+	}
+
+	for _, port := range result.Ports {
+		if port.Open() && sensitivePorts[port.Port] {
+			ranked.Score += 5
+			ranked.SensitivePorts = append(ranked.SensitivePorts, port.Port)
+		}
+	}
+	if len(ranked.SensitivePorts) > 0 {
+		ranked.KeyFindings = append(ranked.KeyFindings, fmt.Sprintf("Sensitive ports open: %v", ranked.SensitivePorts))
+	}
+
+	if result.SSL != nil && result.SSL.Expired {
+		ranked.KeyFindings = append(ranked.KeyFindings, "Expired TLS certificate")
+	}
+
+	return ranked
+}
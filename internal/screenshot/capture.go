@@ -3,10 +3,12 @@ package screenshot
 import (
 	"context"
 	"fmt"
-	"os"
 	"path/filepath"
 	"time"
 
+	"subdomain-finder/internal/fileutil"
+	"subdomain-finder/internal/limiter"
+
 	"github.com/chromedp/chromedp"
 )
 
@@ -17,6 +19,10 @@ type ScreenshotConfig struct {
 	FullPage  bool
 	Timeout   time.Duration
 	UserAgent string
+	// Retries are extra attempts after a failure, spaced by exponential
+	// backoff starting at RetryDelay (default 1s)
+	Retries    int
+	RetryDelay time.Duration
 }
 
 type ScreenshotResult struct {
@@ -28,6 +34,8 @@ type ScreenshotResult struct {
 	Timestamp time.Time
 	Success   bool
 	Error     string
+	Reason    FailureReason
+	Attempts  int
 }
 
 type ScreenshotCapture struct {
@@ -41,44 +49,58 @@ func NewScreenshotCapture(config ScreenshotConfig) *ScreenshotCapture {
 }
 
 func (sc *ScreenshotCapture) Capture(url string) (*ScreenshotResult, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), sc.config.Timeout)
-	defer cancel()
-
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-setuid-sandbox", true),
-		chromedp.UserAgent(sc.config.UserAgent),
-	)
-
-	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
-	defer cancel()
+	return sc.captureWithRetries(url, func(ctx context.Context) ([]byte, int, int, error) {
+		var buf []byte
+		var width, height int
+
+		err := chromedp.Run(ctx,
+			chromedp.Navigate(url),
+			chromedp.WaitVisible("body"),
+			chromedp.Sleep(2*time.Second),
+			chromedp.ActionFunc(func(ctx context.Context) error {
+				var err error
+				width, height, err = sc.getViewportSize(ctx)
+				return err
+			}),
+			chromedp.FullScreenshot(&buf, sc.config.Quality),
+		)
+		return buf, width, height, err
+	})
+}
 
-	ctx, cancel = chromedp.NewContext(allocCtx)
-	defer cancel()
+// captureWithRetries runs capture in a fresh browser per attempt, each with
+// the full timeout, and retries failures that may be transient. A failed
+// result's Error is prefixed with its Reason.
+func (sc *ScreenshotCapture) captureWithRetries(url string, capture func(ctx context.Context) ([]byte, int, int, error)) (*ScreenshotResult, error) {
+	retryDelay := sc.config.RetryDelay
+	if retryDelay <= 0 {
+		retryDelay = time.Second
+	}
+	retryer := limiter.NewRetryer(limiter.RetryConfig{
+		MaxRetries: sc.config.Retries,
+		Backoff:    &limiter.ExponentialBackoff{BaseDelay: retryDelay, MaxDelay: 30 * time.Second},
+	})
 
 	var buf []byte
-	var width, height int
-
-	err := chromedp.Run(ctx,
-		chromedp.Navigate(url),
-		chromedp.WaitVisible("body"),
-		chromedp.Sleep(2*time.Second),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			var err error
-			width, height, err = sc.getViewportSize(ctx)
-			return err
-		}),
-		chromedp.FullScreenshot(&buf, sc.config.Quality),
-	)
+	var width, height, attempts int
+	err := retryer.Execute(context.Background(), func() error {
+		attempts++
+		var err error
+		buf, width, height, err = sc.run(capture)
+		if err != nil && !classifyFailure(err).retryable() {
+			return limiter.Permanent(err)
+		}
+		return err
+	})
 
 	if err != nil {
+		reason := classifyFailure(err)
 		return &ScreenshotResult{
 			URL:       url,
 			Success:   false,
-			Error:     err.Error(),
+			Error:     fmt.Sprintf("%s: %v", reason, err),
+			Reason:    reason,
+			Attempts:  attempts,
 			Timestamp: time.Now(),
 		}, err
 	}
@@ -93,9 +115,35 @@ func (sc *ScreenshotCapture) Capture(url string) (*ScreenshotResult, error) {
 		Size:      int64(len(buf)),
 		Timestamp: time.Now(),
 		Success:   true,
+		Attempts:  attempts,
 	}, nil
 }
 
+func (sc *ScreenshotCapture) run(capture func(ctx context.Context) ([]byte, int, int, error)) ([]byte, int, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sc.config.Timeout)
+	defer cancel()
+
+	opts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", true),
+		chromedp.Flag("disable-gpu", true),
+		chromedp.Flag("disable-dev-shm-usage", true),
+		chromedp.Flag("no-sandbox", true),
+		chromedp.Flag("disable-setuid-sandbox", true),
+		chromedp.UserAgent(sc.config.UserAgent),
+	)
+	if sc.config.Width > 0 && sc.config.Height > 0 {
+		opts = append(opts, chromedp.WindowSize(sc.config.Width, sc.config.Height))
+	}
+
+	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
+	defer cancel()
+
+	ctx, cancel = chromedp.NewContext(allocCtx)
+	defer cancel()
+
+	return capture(ctx)
+}
+
 func (sc *ScreenshotCapture) getViewportSize(ctx context.Context) (int, int, error) {
 	var width, height int
 
@@ -136,17 +184,13 @@ func (sc *ScreenshotCapture) getViewportSize(ctx context.Context) (int, int, err
 
 func (sc *ScreenshotCapture) saveScreenshot(url string, data []byte) string {
 	dir := "screenshots"
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return ""
-	}
-
 	filename := fmt.Sprintf("%s_%d.png",
 		filepath.Base(url),
 		time.Now().Unix())
 
 	filePath := filepath.Join(dir, filename)
 
-	file, err := os.Create(filePath)
+	file, err := fileutil.Create(filePath)
 	if err != nil {
 		return ""
 	}
@@ -165,7 +209,7 @@ func (sc *ScreenshotCapture) CaptureMultiple(urls []string) map[string]*Screensh
 
 	for _, url := range urls {
 		result, err := sc.Capture(url)
-		if err != nil {
+		if err != nil && result == nil {
 			result = &ScreenshotResult{
 				URL:       url,
 				Success:   false,
@@ -195,59 +239,23 @@ func (sc *ScreenshotCapture) CaptureWithCustomSize(url string, width, height int
 }
 
 func (sc *ScreenshotCapture) CaptureElement(url, selector string) (*ScreenshotResult, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), sc.config.Timeout)
-	defer cancel()
-
-	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", true),
-		chromedp.Flag("disable-gpu", true),
-		chromedp.Flag("disable-dev-shm-usage", true),
-		chromedp.Flag("no-sandbox", true),
-		chromedp.Flag("disable-setuid-sandbox", true),
-		chromedp.UserAgent(sc.config.UserAgent),
-	)
-
-	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
-	defer cancel()
-
-	ctx, cancel = chromedp.NewContext(allocCtx)
-	defer cancel()
-
-	var buf []byte
-	var width, height int
-
-	err := chromedp.Run(ctx,
-		chromedp.Navigate(url),
-		chromedp.WaitVisible(selector),
-		chromedp.Sleep(2*time.Second),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			var err error
-			width, height, err = sc.getElementSize(ctx, selector)
-			return err
-		}),
-		chromedp.Screenshot(selector, &buf, chromedp.NodeVisible),
-	)
-
-	if err != nil {
-		return &ScreenshotResult{
-			URL:       url,
-			Success:   false,
-			Error:     err.Error(),
-			Timestamp: time.Now(),
-		}, err
-	}
-
-	filePath := sc.saveScreenshot(url, buf)
-
-	return &ScreenshotResult{
-		URL:       url,
-		FilePath:  filePath,
-		Width:     width,
-		Height:    height,
-		Size:      int64(len(buf)),
-		Timestamp: time.Now(),
-		Success:   true,
-	}, nil
+	return sc.captureWithRetries(url, func(ctx context.Context) ([]byte, int, int, error) {
+		var buf []byte
+		var width, height int
+
+		err := chromedp.Run(ctx,
+			chromedp.Navigate(url),
+			chromedp.WaitVisible(selector),
+			chromedp.Sleep(2*time.Second),
+			chromedp.ActionFunc(func(ctx context.Context) error {
+				var err error
+				width, height, err = sc.getElementSize(ctx, selector)
+				return err
+			}),
+			chromedp.Screenshot(selector, &buf, chromedp.NodeVisible),
+		)
+		return buf, width, height, err
+	})
 }
 
 func (sc *ScreenshotCapture) getElementSize(ctx context.Context, selector string) (int, int, error) {
@@ -0,0 +1,53 @@
+package screenshot
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// FailureReason says why a screenshot could not be taken.
+type FailureReason string
+
+const (
+	FailureTimeout    FailureReason = "timeout"
+	FailureDNS        FailureReason = "dns"
+	FailureTLS        FailureReason = "tls"
+	FailureNavigation FailureReason = "navigation"
+	FailureCapture    FailureReason = "capture"
+	FailureBrowser    FailureReason = "browser"
+)
+
+// classifyFailure maps a chromedp error to a FailureReason. Chrome reports
+// page load failures as net::ERR_* codes in the error text.
+func classifyFailure(err error) FailureReason {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return FailureTimeout
+	}
+	var execErr *exec.Error
+	if errors.As(err, &execErr) {
+		return FailureBrowser
+	}
+
+	text := err.Error()
+	switch {
+	case strings.Contains(text, "ERR_NAME_NOT_RESOLVED"), strings.Contains(text, "ERR_NAME_RESOLUTION_FAILED"):
+		return FailureDNS
+	case strings.Contains(text, "ERR_CERT_"), strings.Contains(text, "ERR_SSL_"), strings.Contains(text, "ERR_BAD_SSL"):
+		return FailureTLS
+	case strings.Contains(text, "ERR_TIMED_OUT"), strings.Contains(text, "ERR_CONNECTION_TIMED_OUT"):
+		return FailureTimeout
+	case strings.Contains(text, "net::ERR_"), strings.Contains(text, "page load error"):
+		return FailureNavigation
+	default:
+		return FailureCapture
+	}
+}
+
+// retryable reports whether another attempt may succeed. A name that does
+// not resolve, a certificate Chrome rejects or a missing browser fails the
+// same way again.
+func (r FailureReason) retryable() bool {
+	return r != FailureDNS && r != FailureTLS && r != FailureBrowser
+}
@@ -0,0 +1,59 @@
+package securitytxt
+
+import (
+	"bufio"
+	"strings"
+	"time"
+)
+
+// Paths lists the RFC 9116 location first, then the legacy root location.
+var Paths = []string{"/.well-known/security.txt", "/security.txt"}
+
+type File struct {
+	Contacts   []string
+	Policies   []string
+	Expires    time.Time
+	ExpiresRaw string
+	Signed     bool
+}
+
+// Parse reads the fields of a security.txt file. It returns false when body
+// has no Contact field, which RFC 9116 requires, so HTML error pages served
+// with a 200 are not mistaken for one.
+func Parse(body string) (*File, bool) {
+	file := &File{Signed: strings.Contains(body, "-----BEGIN PGP SIGNED MESSAGE-----")}
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-----") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "contact":
+			file.Contacts = append(file.Contacts, value)
+		case "policy":
+			file.Policies = append(file.Policies, value)
+		case "expires":
+			file.ExpiresRaw = value
+			if expires, err := time.Parse(time.RFC3339, value); err == nil {
+				file.Expires = expires
+			}
+		}
+	}
+
+	return file, len(file.Contacts) > 0
+}
+
+// Expired reports whether the Expires date has passed. Files without a
+// parseable Expires field are not considered expired.
+func (f *File) Expired(now time.Time) bool {
+	return !f.Expires.IsZero() && now.After(f.Expires)
+}
@@ -1,12 +1,20 @@
 package ssl
 
 import (
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
+
+	"subdomain-finder/internal/netutil"
 )
 
 type CertificateInfo struct {
@@ -29,45 +37,97 @@ type CertificateInfo struct {
 	IsExpiringSoon     bool
 	Strength           string
 	Vulnerabilities    []string
+	RevocationChecked  bool
+	Revoked            bool
+	RevocationStatus   string
+	ChainValid         bool
+	ChainError         string
+	UntrustedRoot      bool
+	HostnameMismatch   bool
 }
 
 type SSLResult struct {
 	Host               string
 	Port               int
+	ServerName         string
 	Protocol           string
 	Certificate        *CertificateInfo
 	SupportedCiphers   []string
 	SupportedProtocols []string
 	IsSecure           bool
+	Resumption         *Resumption
 	Grade              string
 	Recommendations    []string
 }
 
+type SNIResult struct {
+	ServerName   string
+	Subject      string
+	Issuer       string
+	SerialNumber string
+	DNSNames     []string
+	Fingerprint  string
+	Error        string
+}
+
 type SSLAnalyzer struct {
-	timeout time.Duration
+	timeout     time.Duration
+	serverName  string
+	disableSNI  bool
+	startTLS    string
+	deep        bool
+	client      *http.Client
+	revocations revocationCache
+	postures    postureCache
 }
 
 func NewSSLAnalyzer(timeout time.Duration) *SSLAnalyzer {
+	return NewSSLAnalyzerWithClient(timeout, &http.Client{Timeout: timeout})
+}
+
+// NewSSLAnalyzerWithClient returns an analyzer that fetches OCSP responses
+// and CRLs through client, sharing its transport and limits. Handshakes dial
+// the host directly and are bounded by timeout.
+func NewSSLAnalyzerWithClient(timeout time.Duration, client *http.Client) *SSLAnalyzer {
 	return &SSLAnalyzer{
 		timeout: timeout,
+		client:  client,
 	}
 }
 
+func (sa *SSLAnalyzer) SetServerName(serverName string) {
+	sa.serverName = serverName
+}
+
+func (sa *SSLAnalyzer) SetDisableSNI(disable bool) {
+	sa.disableSNI = disable
+}
+
 func (sa *SSLAnalyzer) Analyze(host string, port int) (*SSLResult, error) {
-	address := fmt.Sprintf("%s:%d", host, port)
+	return sa.AnalyzeContext(context.Background(), host, port)
+}
 
-	conn, err := net.DialTimeout("tcp", address, sa.timeout)
-	if err != nil {
-		return nil, err
+// AnalyzeContext is Analyze with every connection it makes bound to ctx.
+func (sa *SSLAnalyzer) AnalyzeContext(ctx context.Context, host string, port int) (*SSLResult, error) {
+	host = netutil.Unbracket(host)
+	serverName := host
+	if sa.serverName != "" {
+		serverName = sa.serverName
+	}
+	if sa.disableSNI {
+		serverName = ""
 	}
-	defer conn.Close()
 
-	tlsConn := tls.Client(conn, &tls.Config{
-		ServerName:         host,
-		InsecureSkipVerify: true,
-	})
+	return sa.analyze(ctx, host, port, serverName)
+}
+
+func (sa *SSLAnalyzer) AnalyzeWithSNI(host string, port int, serverName string) (*SSLResult, error) {
+	return sa.analyze(context.Background(), netutil.Unbracket(host), port, serverName)
+}
 
-	if err := tlsConn.Handshake(); err != nil {
+func (sa *SSLAnalyzer) analyze(ctx context.Context, host string, port int, serverName string) (*SSLResult, error) {
+	tlsConn, err := sa.handshake(ctx, host, port, serverName)
+	if err != nil {
 		return nil, err
 	}
 
@@ -75,26 +135,106 @@ func (sa *SSLAnalyzer) Analyze(host string, port int) (*SSLResult, error) {
 	cert := state.PeerCertificates[0]
 
 	certInfo := sa.analyzeCertificate(cert)
-	supportedCiphers := sa.getSupportedCiphers(tlsConn)
-	supportedProtocols := sa.getSupportedProtocols(tlsConn)
+	sa.verifyChain(state.PeerCertificates, host, serverName, certInfo)
+	sa.checkRevocation(ctx, state, certInfo)
+	address := tlsConn.RemoteAddr().String()
+	tlsConn.Close()
+
+	// Close first so servers handling one connection at a time can answer
+	supportedProtocols, supportedCiphers, resumption := sa.posture(ctx, address, host, port, serverName, state)
 
 	isSecure := sa.isSecure(certInfo, supportedCiphers, supportedProtocols)
 	grade := sa.calculateGrade(certInfo, supportedCiphers, supportedProtocols)
-	recommendations := sa.getRecommendations(certInfo, supportedCiphers, supportedProtocols)
+	recommendations := sa.getRecommendations(certInfo, supportedCiphers, supportedProtocols, resumption)
 
 	return &SSLResult{
 		Host:               host,
 		Port:               port,
+		ServerName:         serverName,
 		Protocol:           "TLS",
 		Certificate:        certInfo,
 		SupportedCiphers:   supportedCiphers,
 		SupportedProtocols: supportedProtocols,
+		Resumption:         resumption,
 		IsSecure:           isSecure,
 		Grade:              grade,
 		Recommendations:    recommendations,
 	}, nil
 }
 
+func (sa *SSLAnalyzer) handshake(ctx context.Context, host string, port int, serverName string) (*tls.Conn, error) {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	conn, err := sa.dial(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(sa.timeout))
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+	})
+
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+// dial connects to address and, on STARTTLS ports, upgrades the connection
+// so the caller can start the handshake.
+func (sa *SSLAnalyzer) dial(ctx context.Context, address string) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: sa.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	_, portText, _ := net.SplitHostPort(address)
+	port, _ := strconv.Atoi(portText)
+	if protocol := sa.startTLSProtocol(port); protocol != "" {
+		_ = conn.SetDeadline(time.Now().Add(sa.timeout))
+		if err := upgrade(conn, protocol); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s STARTTLS: %w", protocol, err)
+		}
+	}
+	return conn, nil
+}
+
+func (sa *SSLAnalyzer) TestSNI(host string, port int, serverNames []string) []SNIResult {
+	host = netutil.Unbracket(host)
+	results := make([]SNIResult, 0, len(serverNames))
+
+	for _, serverName := range serverNames {
+		result := SNIResult{ServerName: serverName}
+
+		tlsConn, err := sa.handshake(context.Background(), host, port, serverName)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		cert := tlsConn.ConnectionState().PeerCertificates[0]
+		fingerprint := sha256.Sum256(cert.Raw)
+
+		result.Subject = cert.Subject.String()
+		result.Issuer = cert.Issuer.String()
+		result.SerialNumber = cert.SerialNumber.String()
+		result.DNSNames = cert.DNSNames
+		result.Fingerprint = hex.EncodeToString(fingerprint[:])
+		tlsConn.Close()
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
 func (sa *SSLAnalyzer) analyzeCertificate(cert *x509.Certificate) *CertificateInfo {
 	now := time.Now()
 	daysUntilExpiry := int(cert.NotAfter.Sub(now).Hours() / 24)
@@ -124,6 +264,30 @@ func (sa *SSLAnalyzer) analyzeCertificate(cert *x509.Certificate) *CertificateIn
 	return info
 }
 
+// verifyChain checks the presented chain against the system roots and the
+// leaf against the name that was asked for. The handshake itself skips
+// verification so that untrusted hosts can still be inspected.
+func (sa *SSLAnalyzer) verifyChain(certs []*x509.Certificate, host, serverName string, info *CertificateInfo) {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(x509.VerifyOptions{Intermediates: intermediates})
+	info.ChainValid = err == nil
+	if err != nil {
+		info.ChainError = err.Error()
+		var unknownAuthority x509.UnknownAuthorityError
+		info.UntrustedRoot = errors.As(err, &unknownAuthority)
+	}
+
+	name := serverName
+	if name == "" {
+		name = host
+	}
+	info.HostnameMismatch = certs[0].VerifyHostname(name) != nil
+}
+
 func (sa *SSLAnalyzer) getKeyUsage(keyUsage x509.KeyUsage) []string {
 	var usage []string
 
@@ -206,41 +370,16 @@ func (sa *SSLAnalyzer) checkVulnerabilities(cert *x509.Certificate) []string {
 	return vulnerabilities
 }
 
-func (sa *SSLAnalyzer) getSupportedCiphers(conn *tls.Conn) []string {
-	state := conn.ConnectionState()
-	var ciphers []string
-
-	if state.CipherSuite != 0 {
-		ciphers = append(ciphers, tls.CipherSuiteName(state.CipherSuite))
-	}
-
-	return ciphers
-}
-
-func (sa *SSLAnalyzer) getSupportedProtocols(conn *tls.Conn) []string {
-	state := conn.ConnectionState()
-	var protocols []string
-
-	switch state.Version {
-	case tls.VersionTLS10:
-		protocols = append(protocols, "TLS 1.0")
-	case tls.VersionTLS11:
-		protocols = append(protocols, "TLS 1.1")
-	case tls.VersionTLS12:
-		protocols = append(protocols, "TLS 1.2")
-	case tls.VersionTLS13:
-		protocols = append(protocols, "TLS 1.3")
-	}
-
-	return protocols
-}
-
 func (sa *SSLAnalyzer) isSecure(certInfo *CertificateInfo, ciphers, protocols []string) bool {
 	if !certInfo.IsValid || certInfo.IsExpired {
 		return false
 	}
 
-	if certInfo.IsSelfSigned {
+	if certInfo.IsSelfSigned || certInfo.Revoked {
+		return false
+	}
+
+	if !certInfo.ChainValid || certInfo.HostnameMismatch {
 		return false
 	}
 
@@ -250,10 +389,18 @@ func (sa *SSLAnalyzer) isSecure(certInfo *CertificateInfo, ciphers, protocols []
 		}
 	}
 
+	if len(weakCiphers(ciphers)) > 0 {
+		return false
+	}
+
 	return true
 }
 
 func (sa *SSLAnalyzer) calculateGrade(certInfo *CertificateInfo, ciphers, protocols []string) string {
+	if certInfo.Revoked {
+		return "F"
+	}
+
 	score := 100
 
 	if certInfo.IsExpired {
@@ -268,6 +415,13 @@ func (sa *SSLAnalyzer) calculateGrade(certInfo *CertificateInfo, ciphers, protoc
 	if certInfo.IsWildcard {
 		score -= 10
 	}
+	// Self-signed and expired certificates are already penalized above
+	if !certInfo.ChainValid && !certInfo.IsSelfSigned && !certInfo.IsExpired {
+		score -= 30
+	}
+	if certInfo.HostnameMismatch {
+		score -= 30
+	}
 
 	for _, vuln := range certInfo.Vulnerabilities {
 		if strings.Contains(vuln, "weak") {
@@ -281,6 +435,12 @@ func (sa *SSLAnalyzer) calculateGrade(certInfo *CertificateInfo, ciphers, protoc
 	if len(protocols) == 0 || !sa.hasModernProtocol(protocols) {
 		score -= 25
 	}
+	if hasLegacyProtocol(protocols) {
+		score -= 20
+	}
+	if len(weakCiphers(ciphers)) > 0 {
+		score -= 20
+	}
 
 	if score >= 90 {
 		return "A+"
@@ -306,9 +466,12 @@ func (sa *SSLAnalyzer) hasModernProtocol(protocols []string) bool {
 	return false
 }
 
-func (sa *SSLAnalyzer) getRecommendations(certInfo *CertificateInfo, ciphers, protocols []string) []string {
+func (sa *SSLAnalyzer) getRecommendations(certInfo *CertificateInfo, ciphers, protocols []string, resumption *Resumption) []string {
 	var recommendations []string
 
+	if certInfo.Revoked {
+		recommendations = append(recommendations, "Certificate is revoked - replace it immediately")
+	}
 	if certInfo.IsExpired {
 		recommendations = append(recommendations, "Certificate is expired - renew immediately")
 	}
@@ -317,16 +480,33 @@ func (sa *SSLAnalyzer) getRecommendations(certInfo *CertificateInfo, ciphers, pr
 	}
 	if certInfo.IsSelfSigned {
 		recommendations = append(recommendations, "Use a trusted CA certificate instead of self-signed")
+	} else if certInfo.UntrustedRoot {
+		recommendations = append(recommendations, "Serve the full intermediate chain, or use a certificate from a publicly trusted CA")
+	}
+	if certInfo.HostnameMismatch {
+		recommendations = append(recommendations, "Use a certificate that covers the host name")
 	}
 	if !sa.hasModernProtocol(protocols) {
 		recommendations = append(recommendations, "Upgrade to TLS 1.2 or 1.3")
 	}
+	if hasLegacyProtocol(protocols) {
+		recommendations = append(recommendations, "Disable TLS 1.0 and 1.1")
+	}
+	if weak := weakCiphers(ciphers); len(weak) > 0 {
+		recommendations = append(recommendations, "Disable weak cipher suites: "+strings.Join(weak, ", "))
+	}
 	if len(certInfo.Vulnerabilities) > 0 {
 		recommendations = append(recommendations, "Fix certificate vulnerabilities")
 	}
 	if certInfo.IsWildcard {
 		recommendations = append(recommendations, "Consider using specific certificates for better security")
 	}
+	if resumption != nil && resumption.EarlyData {
+		recommendations = append(recommendations, "Disable TLS 1.3 0-RTT or accept early data only for idempotent requests, since it can be replayed")
+	}
+	if resumption != nil && !resumption.SessionIDs && !resumption.SessionTickets {
+		recommendations = append(recommendations, "Enable session resumption to avoid a full handshake on every connection")
+	}
 
 	return recommendations
 }
@@ -0,0 +1,129 @@
+package ssl
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strconv"
+)
+
+// tlsVersions are the protocol versions enumerated, oldest first.
+var tlsVersions = []struct {
+	version uint16
+	name    string
+}{
+	{tls.VersionTLS10, "TLS 1.0"},
+	{tls.VersionTLS11, "TLS 1.1"},
+	{tls.VersionTLS12, "TLS 1.2"},
+	{tls.VersionTLS13, "TLS 1.3"},
+}
+
+// enumerate handshakes once per protocol version to find the versions the
+// server accepts. For TLS 1.0 to 1.2 the accepted cipher suites are found by
+// offering every suite Go implements and dropping the one the server picks
+// until it refuses the rest. Go does not let TLS 1.3 suites be chosen, so
+// only the one negotiated is recorded for it.
+func (sa *SSLAnalyzer) enumerate(ctx context.Context, host string, port int, serverName string) (protocols, ciphers []string) {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	seen := make(map[uint16]bool)
+	accept := func(id uint16) {
+		if !seen[id] {
+			seen[id] = true
+			ciphers = append(ciphers, tls.CipherSuiteName(id))
+		}
+	}
+
+	for _, v := range tlsVersions {
+		config := &tls.Config{
+			ServerName:         serverName,
+			InsecureSkipVerify: true,
+			MinVersion:         v.version,
+			MaxVersion:         v.version,
+		}
+
+		if v.version == tls.VersionTLS13 {
+			conn, err := sa.dialTLS(ctx, address, config)
+			if err != nil {
+				continue
+			}
+			protocols = append(protocols, v.name)
+			accept(conn.ConnectionState().CipherSuite)
+			conn.Close()
+			continue
+		}
+
+		offered := cipherSuitesFor(v.version)
+		accepted := false
+		for len(offered) > 0 && ctx.Err() == nil {
+			config.CipherSuites = offered
+			conn, err := sa.dialTLS(ctx, address, config)
+			if err != nil {
+				break
+			}
+			chosen := conn.ConnectionState().CipherSuite
+			conn.Close()
+
+			accepted = true
+			accept(chosen)
+
+			remaining := make([]uint16, 0, len(offered))
+			for _, id := range offered {
+				if id != chosen {
+					remaining = append(remaining, id)
+				}
+			}
+			if len(remaining) == len(offered) {
+				break
+			}
+			offered = remaining
+		}
+		if accepted {
+			protocols = append(protocols, v.name)
+		}
+	}
+	return protocols, ciphers
+}
+
+// cipherSuitesFor lists the suites, secure and insecure, that Go can offer
+// for a TLS 1.2 or older version.
+func cipherSuitesFor(version uint16) []uint16 {
+	var ids []uint16
+	for _, suites := range [][]*tls.CipherSuite{tls.CipherSuites(), tls.InsecureCipherSuites()} {
+		for _, suite := range suites {
+			for _, supported := range suite.SupportedVersions {
+				if supported == version {
+					ids = append(ids, suite.ID)
+					break
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// weakCiphers returns the accepted suites Go considers insecure, such as
+// RC4, 3DES and CBC with SHA-256.
+func weakCiphers(ciphers []string) []string {
+	insecure := make(map[string]bool)
+	for _, suite := range tls.InsecureCipherSuites() {
+		insecure[suite.Name] = true
+	}
+
+	var weak []string
+	for _, cipher := range ciphers {
+		if insecure[cipher] {
+			weak = append(weak, cipher)
+		}
+	}
+	return weak
+}
+
+// hasLegacyProtocol reports whether TLS 1.0 or 1.1 is accepted.
+func hasLegacyProtocol(protocols []string) bool {
+	for _, protocol := range protocols {
+		if protocol == "TLS 1.0" || protocol == "TLS 1.1" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package ssl
+
+import (
+	"context"
+	"crypto/tls"
+	"sync"
+)
+
+// SetDeepChecks enables the checks that cost many extra connections:
+// protocol and cipher suite enumeration, session resumption and 0-RTT
+// probing, and fetching OCSP responses and CRLs. Without them the negotiated
+// protocol and suite are reported and only a stapled OCSP response is used.
+func (sa *SSLAnalyzer) SetDeepChecks(enabled bool) {
+	sa.deep = enabled
+}
+
+// posture is what the deep checks learn about a TLS endpoint. It belongs to
+// the server rather than the name, so it is shared by every name served from
+// the same address and port.
+type posture struct {
+	once       sync.Once
+	protocols  []string
+	ciphers    []string
+	resumption *Resumption
+}
+
+type postureCache struct {
+	mu      sync.Mutex
+	entries map[string]*posture
+}
+
+func (c *postureCache) entry(address string) *posture {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]*posture)
+	}
+	entry, ok := c.entries[address]
+	if !ok {
+		entry = &posture{}
+		c.entries[address] = entry
+	}
+	return entry
+}
+
+// posture returns the protocols, cipher suites and resumption support of the
+// endpoint at address, the IP and port the handshake reached. Without deep
+// checks it is read from the handshake state alone.
+func (sa *SSLAnalyzer) posture(ctx context.Context, address, host string, port int, serverName string, state tls.ConnectionState) ([]string, []string, *Resumption) {
+	if !sa.deep {
+		return []string{tls.VersionName(state.Version)}, []string{tls.CipherSuiteName(state.CipherSuite)}, nil
+	}
+
+	entry := sa.postures.entry(address)
+	entry.once.Do(func() {
+		entry.protocols, entry.ciphers = sa.enumerate(ctx, host, port, serverName)
+		entry.resumption = sa.checkResumption(ctx, host, port, serverName)
+	})
+	return entry.protocols, entry.ciphers, entry.resumption
+}
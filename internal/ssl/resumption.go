@@ -0,0 +1,528 @@
+package ssl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Resumption records how the server lets a returning client skip the full
+// handshake.
+type Resumption struct {
+	SessionIDs     bool
+	SessionTickets bool
+	EarlyData      bool
+	MaxEarlyData   uint32
+}
+
+// ticketWait bounds how long to wait for TLS 1.3 session tickets, which
+// arrive after the handshake completes.
+const ticketWait = 2 * time.Second
+
+const (
+	extensionEarlyData     = 42
+	handshakeServerHello   = 2
+	handshakeSessionTicket = 4
+	recordHandshake        = 22
+	recordApplicationData  = 23
+	recordAlert            = 21
+)
+
+func (sa *SSLAnalyzer) checkResumption(ctx context.Context, host string, port int, serverName string) *Resumption {
+	resumption := sa.checkSessionTickets(ctx, host, port, serverName)
+	resumption.SessionIDs = sa.checkSessionIDs(ctx, host, port, serverName)
+	return resumption
+}
+
+// checkSessionTickets connects once to collect a ticket and again to see
+// whether the server accepts it. crypto/tls cannot send early data and only
+// reports max_early_data for QUIC, so 0-RTT support is read from the
+// NewSessionTicket messages by decrypting them with the logged traffic
+// secret.
+func (sa *SSLAnalyzer) checkSessionTickets(ctx context.Context, host string, port int, serverName string) *Resumption {
+	resumption := &Resumption{}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	conn, err := sa.dial(ctx, address)
+	if err != nil {
+		return resumption
+	}
+	_ = conn.SetDeadline(time.Now().Add(sa.timeout))
+
+	recorder := &recordingConn{Conn: conn}
+	cache := &ticketCache{ClientSessionCache: tls.NewLRUClientSessionCache(1), conn: conn}
+	var keyLog bytes.Buffer
+	config := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+		ClientSessionCache: cache,
+		KeyLogWriter:       &keyLog,
+	}
+
+	tlsConn := tls.Client(recorder, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return resumption
+	}
+
+	state := tlsConn.ConnectionState()
+	if state.Version == tls.VersionTLS13 {
+		// Tickets are only processed on Read; the cache cuts the wait short
+		// once one arrives
+		wait := ticketWait
+		if sa.timeout < wait {
+			wait = sa.timeout
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(wait))
+		_, _ = tlsConn.Read(make([]byte, 1))
+
+		if secret, ok := trafficSecret(keyLog.String(), "SERVER_TRAFFIC_SECRET_0"); ok {
+			resumption.MaxEarlyData, resumption.EarlyData = earlyDataLimit(recorder.Bytes(), state.CipherSuite, secret)
+		}
+	}
+	tlsConn.Close()
+
+	if !cache.Stored() {
+		return resumption
+	}
+
+	config.KeyLogWriter = nil
+	resumed, err := sa.dialTLS(ctx, address, config)
+	if err != nil {
+		return resumption
+	}
+	resumption.SessionTickets = resumed.ConnectionState().DidResume
+	resumed.Close()
+
+	return resumption
+}
+
+func (sa *SSLAnalyzer) dialTLS(ctx context.Context, address string, config *tls.Config) (*tls.Conn, error) {
+	conn, err := sa.dial(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(sa.timeout))
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// checkSessionIDs completes a TLS 1.2 handshake with tickets disabled so the
+// server caches the session, then offers the session ID it assigned in a
+// fresh ClientHello. The server resumed when it echoes the ID back.
+// crypto/tls only resumes with tickets, so the second hello is built by hand
+// and abandoned after the ServerHello.
+func (sa *SSLAnalyzer) checkSessionIDs(ctx context.Context, host string, port int, serverName string) bool {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	conn, err := sa.dial(ctx, address)
+	if err != nil {
+		return false
+	}
+	_ = conn.SetDeadline(time.Now().Add(sa.timeout))
+
+	recorder := &recordingConn{Conn: conn}
+	tlsConn := tls.Client(recorder, &tls.Config{
+		ServerName:             serverName,
+		InsecureSkipVerify:     true,
+		MaxVersion:             tls.VersionTLS12,
+		SessionTicketsDisabled: true,
+	})
+	err = tlsConn.HandshakeContext(ctx)
+	tlsConn.Close()
+	if err != nil {
+		return false
+	}
+
+	first, err := serverHelloSessionID(bytes.NewReader(recorder.Bytes()))
+	if err != nil || len(first) == 0 {
+		return false
+	}
+	second, err := sa.helloSessionID(ctx, address, serverName, first)
+	return err == nil && bytes.Equal(first, second)
+}
+
+func (sa *SSLAnalyzer) helloSessionID(ctx context.Context, address, serverName string, sessionID []byte) ([]byte, error) {
+	conn, err := sa.dial(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(sa.timeout))
+
+	hello, err := clientHello(serverName, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(hello); err != nil {
+		return nil, err
+	}
+
+	return serverHelloSessionID(bufio.NewReader(conn))
+}
+
+func serverHelloSessionID(r io.Reader) ([]byte, error) {
+	message, err := readHandshakeMessage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgType uint8
+	var version uint16
+	var body, id cryptobyte.String
+	if !message.ReadUint8(&msgType) || !message.ReadUint24LengthPrefixed(&body) || msgType != handshakeServerHello {
+		return nil, fmt.Errorf("expected ServerHello")
+	}
+	if !body.ReadUint16(&version) || !body.Skip(32) || !body.ReadUint8LengthPrefixed(&id) {
+		return nil, fmt.Errorf("malformed ServerHello")
+	}
+	return bytes.Clone(id), nil
+}
+
+func clientHello(serverName string, sessionID []byte) ([]byte, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+
+	var b cryptobyte.Builder
+	b.AddUint8(recordHandshake)
+	b.AddUint16(tls.VersionTLS10)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint8(1)
+		b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddUint16(tls.VersionTLS12)
+			b.AddBytes(random)
+			b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+				b.AddBytes(sessionID)
+			})
+			b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+				for _, suite := range tls12CipherSuites() {
+					b.AddUint16(suite)
+				}
+			})
+			// Null compression only
+			b.AddUint8(1)
+			b.AddUint8(0)
+			b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+				addHelloExtensions(b, serverName)
+			})
+		})
+	})
+	return b.Bytes()
+}
+
+func addHelloExtensions(b *cryptobyte.Builder, serverName string) {
+	if serverName != "" && net.ParseIP(serverName) == nil {
+		b.AddUint16(0) // server_name
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+				b.AddUint8(0) // host_name
+				b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+					b.AddBytes([]byte(serverName))
+				})
+			})
+		})
+	}
+
+	b.AddUint16(10) // supported_groups
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			for _, group := range []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384} {
+				b.AddUint16(uint16(group))
+			}
+		})
+	})
+
+	b.AddUint16(11) // ec_point_formats
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddUint8(0) // uncompressed
+		})
+	})
+
+	b.AddUint16(13) // signature_algorithms
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			for _, scheme := range []tls.SignatureScheme{
+				tls.PSSWithSHA256, tls.PSSWithSHA384, tls.PSSWithSHA512,
+				tls.ECDSAWithP256AndSHA256, tls.ECDSAWithP384AndSHA384, tls.ECDSAWithP521AndSHA512,
+				tls.PKCS1WithSHA256, tls.PKCS1WithSHA384, tls.PKCS1WithSHA512,
+				tls.PKCS1WithSHA1, tls.ECDSAWithSHA1,
+			} {
+				b.AddUint16(uint16(scheme))
+			}
+		})
+	})
+
+	b.AddUint16(23) // extended_master_secret
+	b.AddUint16(0)
+
+	b.AddUint16(0xff01) // renegotiation_info
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint8(0)
+	})
+}
+
+// tls12CipherSuites returns every suite crypto/tls knows for TLS 1.2,
+// insecure ones included, so older servers still answer the hello.
+func tls12CipherSuites() []uint16 {
+	var suites []uint16
+	for _, list := range [][]*tls.CipherSuite{tls.CipherSuites(), tls.InsecureCipherSuites()} {
+		for _, suite := range list {
+			for _, version := range suite.SupportedVersions {
+				if version == tls.VersionTLS12 {
+					suites = append(suites, suite.ID)
+					break
+				}
+			}
+		}
+	}
+	return suites
+}
+
+// readHandshakeMessage reads plaintext handshake records until they hold one
+// complete handshake message.
+func readHandshakeMessage(r io.Reader) (cryptobyte.String, error) {
+	var data []byte
+	header := make([]byte, 5)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		length := int(header[3])<<8 | int(header[4])
+		fragment := make([]byte, length)
+		if _, err := io.ReadFull(r, fragment); err != nil {
+			return nil, err
+		}
+
+		switch header[0] {
+		case recordHandshake:
+			data = append(data, fragment...)
+		case recordAlert:
+			return nil, fmt.Errorf("server sent alert")
+		default:
+			return nil, fmt.Errorf("unexpected record type %d", header[0])
+		}
+
+		if len(data) >= 4 && len(data) >= 4+(int(data[1])<<16|int(data[2])<<8|int(data[3])) {
+			return cryptobyte.String(data), nil
+		}
+	}
+}
+
+// trafficSecret returns the secret logged under label in NSS key log format.
+func trafficSecret(keyLog, label string) ([]byte, bool) {
+	for _, line := range strings.Split(keyLog, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != label {
+			continue
+		}
+		secret, err := hex.DecodeString(fields[2])
+		return secret, err == nil
+	}
+	return nil, false
+}
+
+// earlyDataLimit decrypts the application data records the server sent after
+// the handshake and returns the max_early_data_size of the first session
+// ticket that allows early data. Records still under the handshake keys fail
+// to open and are skipped.
+func earlyDataLimit(records []byte, suite uint16, secret []byte) (uint32, bool) {
+	aead, iv, err := trafficAEAD(suite, secret)
+	if err != nil {
+		return 0, false
+	}
+
+	var messages []byte
+	var seq uint64
+	s := cryptobyte.String(records)
+	for !s.Empty() {
+		var contentType uint8
+		var version uint16
+		var fragment cryptobyte.String
+		if !s.ReadUint8(&contentType) || !s.ReadUint16(&version) || !s.ReadUint16LengthPrefixed(&fragment) {
+			break
+		}
+		if contentType != recordApplicationData {
+			continue
+		}
+
+		header := []byte{contentType, byte(version >> 8), byte(version), byte(len(fragment) >> 8), byte(len(fragment))}
+		nonce := bytes.Clone(iv)
+		for i := 0; i < 8; i++ {
+			nonce[len(nonce)-1-i] ^= byte(seq >> (8 * i))
+		}
+		plaintext, err := aead.Open(nil, nonce, fragment, header)
+		if err != nil {
+			continue
+		}
+		seq++
+
+		// The inner content type follows the content, then zero padding
+		plaintext = bytes.TrimRight(plaintext, "\x00")
+		if len(plaintext) > 0 && plaintext[len(plaintext)-1] == recordHandshake {
+			messages = append(messages, plaintext[:len(plaintext)-1]...)
+		}
+	}
+
+	return ticketEarlyData(messages)
+}
+
+func ticketEarlyData(messages []byte) (uint32, bool) {
+	s := cryptobyte.String(messages)
+	for !s.Empty() {
+		var msgType uint8
+		var body cryptobyte.String
+		if !s.ReadUint8(&msgType) || !s.ReadUint24LengthPrefixed(&body) {
+			break
+		}
+		if msgType != handshakeSessionTicket {
+			continue
+		}
+
+		var lifetime, ageAdd uint32
+		var nonce, ticket, extensions cryptobyte.String
+		if !body.ReadUint32(&lifetime) || !body.ReadUint32(&ageAdd) || !body.ReadUint8LengthPrefixed(&nonce) ||
+			!body.ReadUint16LengthPrefixed(&ticket) || !body.ReadUint16LengthPrefixed(&extensions) {
+			continue
+		}
+		for !extensions.Empty() {
+			var extType uint16
+			var data cryptobyte.String
+			if !extensions.ReadUint16(&extType) || !extensions.ReadUint16LengthPrefixed(&data) {
+				break
+			}
+			var maxEarlyData uint32
+			if extType == extensionEarlyData && data.ReadUint32(&maxEarlyData) && maxEarlyData > 0 {
+				return maxEarlyData, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// trafficAEAD derives the record protection key and IV for a TLS 1.3
+// traffic secret (RFC 8446 section 7.3).
+func trafficAEAD(suite uint16, secret []byte) (cipher.AEAD, []byte, error) {
+	var newHash func() hash.Hash
+	var keyLen int
+	switch suite {
+	case tls.TLS_AES_128_GCM_SHA256:
+		newHash, keyLen = sha256.New, 16
+	case tls.TLS_AES_256_GCM_SHA384:
+		newHash, keyLen = sha512.New384, 32
+	case tls.TLS_CHACHA20_POLY1305_SHA256:
+		newHash, keyLen = sha256.New, 32
+	default:
+		return nil, nil, fmt.Errorf("unsupported cipher suite %s", tls.CipherSuiteName(suite))
+	}
+
+	key, err := expandLabel(newHash, secret, "key", keyLen)
+	if err != nil {
+		return nil, nil, err
+	}
+	iv, err := expandLabel(newHash, secret, "iv", 12)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if suite == tls.TLS_CHACHA20_POLY1305_SHA256 {
+		aead, err := chacha20poly1305.New(key)
+		return aead, iv, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	return aead, iv, err
+}
+
+func expandLabel(newHash func() hash.Hash, secret []byte, label string, length int) ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddUint16(uint16(length))
+	b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes([]byte("tls13 " + label))
+	})
+	b.AddUint8(0) // empty context
+	info, err := b.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.Expand(newHash, secret, info), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// recordingConn keeps a copy of everything read so the records can be
+// inspected after crypto/tls has consumed them.
+type recordingConn struct {
+	net.Conn
+	read bytes.Buffer
+}
+
+func (c *recordingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.read.Write(p[:n])
+	return n, err
+}
+
+func (c *recordingConn) Bytes() []byte {
+	return c.read.Bytes()
+}
+
+// ticketCache notes when a session is stored and ends the pending read so
+// the scan does not wait out the deadline once a ticket has arrived.
+type ticketCache struct {
+	tls.ClientSessionCache
+	conn   net.Conn
+	mu     sync.Mutex
+	stored bool
+}
+
+func (c *ticketCache) Put(sessionKey string, cs *tls.ClientSessionState) {
+	c.ClientSessionCache.Put(sessionKey, cs)
+	if cs == nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.stored = true
+	c.mu.Unlock()
+	// Let any further tickets in the same segment be read first
+	_ = c.conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+}
+
+func (c *ticketCache) Stored() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stored
+}
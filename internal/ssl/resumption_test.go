@@ -0,0 +1,371 @@
+package ssl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/cryptobyte"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// The secrets, keys and IVs are from the simple 1-RTT handshake of RFC 8448
+// section 3, which uses TLS_AES_128_GCM_SHA256.
+func TestTrafficKeysRFC8448(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		key    string
+		iv     string
+	}{
+		{
+			name:   "server handshake",
+			secret: "b6 7b 7d 69 0c c1 6c 4e 75 e5 42 13 cb 2d 37 b4 e9 c9 12 bc de d9 10 5d 42 be fd 59 d3 91 ad 38",
+			key:    "3f ce 51 60 09 c2 17 27 d0 f2 e4 e8 6e e4 03 bc",
+			iv:     "5d 31 3e b2 67 12 76 ee 13 00 0b 30",
+		},
+		{
+			name:   "server application",
+			secret: "a1 1a f9 f0 55 31 f8 56 ad 47 11 6b 45 a9 50 32 82 04 b4 f4 4b fb 6b 3a 4b 4f 1f 3f cb 63 16 43",
+			key:    "9f 02 28 3b 6c 9c 07 ef c2 6b b9 f2 ac 92 e3 56",
+			iv:     "cf 78 2b 88 dd 83 54 9a ad f1 e9 84",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := unhex(t, tt.secret)
+			key, err := expandLabel(sha256.New, secret, "key", 16)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(key, unhex(t, tt.key)) {
+				t.Errorf("key = %x, want %s", key, tt.key)
+			}
+			iv, err := expandLabel(sha256.New, secret, "iv", 12)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(iv, unhex(t, tt.iv)) {
+				t.Errorf("iv = %x, want %s", iv, tt.iv)
+			}
+
+			_, aeadIV, err := trafficAEAD(tls.TLS_AES_128_GCM_SHA256, secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(aeadIV, iv) {
+				t.Errorf("trafficAEAD iv = %x, want %x", aeadIV, iv)
+			}
+		})
+	}
+}
+
+func TestTrafficAEADSuites(t *testing.T) {
+	secret := bytes.Repeat([]byte{1}, 48)
+	tests := []struct {
+		suite   uint16
+		wantErr bool
+	}{
+		{tls.TLS_AES_128_GCM_SHA256, false},
+		{tls.TLS_AES_256_GCM_SHA384, false},
+		{tls.TLS_CHACHA20_POLY1305_SHA256, false},
+		{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tls.CipherSuiteName(tt.suite), func(t *testing.T) {
+			aead, iv, err := trafficAEAD(tt.suite, secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("trafficAEAD error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && (aead.NonceSize() != 12 || len(iv) != 12) {
+				t.Errorf("nonce size %d, iv length %d, want 12", aead.NonceSize(), len(iv))
+			}
+		})
+	}
+}
+
+// sessionTicket builds a NewSessionTicket handshake message, with an
+// early_data extension when maxEarlyData is not zero.
+func sessionTicket(t *testing.T, maxEarlyData uint32) []byte {
+	t.Helper()
+	var b cryptobyte.Builder
+	b.AddUint8(handshakeSessionTicket)
+	b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint32(7200)       // lifetime
+		b.AddUint32(0x12345678) // age_add
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes([]byte{0, 0}) })
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(bytes.Repeat([]byte{0xaa}, 32)) })
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddUint16(0xfafa) // a GREASE extension first
+			b.AddUint16(0)
+			if maxEarlyData > 0 {
+				b.AddUint16(extensionEarlyData)
+				b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) { b.AddUint32(maxEarlyData) })
+			}
+		})
+	})
+	return b.BytesOrPanic()
+}
+
+func TestTicketEarlyData(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []byte
+		want     uint32
+		wantOK   bool
+	}{
+		{"with early_data", sessionTicket(t, 0x400), 0x400, true},
+		{"without early_data", sessionTicket(t, 0), 0, false},
+		{"second ticket allows it", append(sessionTicket(t, 0), sessionTicket(t, 16384)...), 16384, true},
+		{"other message", []byte{20, 0, 0, 2, 0, 0}, 0, false},
+		{"truncated", sessionTicket(t, 0x400)[:20], 0, false},
+		{"empty", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ticketEarlyData(tt.messages)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ticketEarlyData = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// sealRecords protects each handshake message as a TLS 1.3 record under
+// secret, numbering them from zero.
+func sealRecords(t *testing.T, secret []byte, messages ...[]byte) []byte {
+	t.Helper()
+	aead, iv, err := trafficAEAD(tls.TLS_AES_128_GCM_SHA256, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []byte
+	for seq, message := range messages {
+		inner := append(append(bytes.Clone(message), recordHandshake), 0, 0)
+		length := len(inner) + aead.Overhead()
+		header := []byte{recordApplicationData, 3, 3, byte(length >> 8), byte(length)}
+		nonce := bytes.Clone(iv)
+		nonce[len(nonce)-1] ^= byte(seq)
+		records = append(records, aead.Seal(header, nonce, inner, header)...)
+	}
+	return records
+}
+
+func TestEarlyDataLimit(t *testing.T) {
+	secret := unhex(t, "a1 1a f9 f0 55 31 f8 56 ad 47 11 6b 45 a9 50 32 82 04 b4 f4 4b fb 6b 3a 4b 4f 1f 3f cb 63 16 43")
+	handshakeSecret := unhex(t, "b6 7b 7d 69 0c c1 6c 4e 75 e5 42 13 cb 2d 37 b4 e9 c9 12 bc de d9 10 5d 42 be fd 59 d3 91 ad 38")
+	changeCipherSpec := []byte{20, 3, 3, 0, 1, 1}
+
+	tests := []struct {
+		name    string
+		records []byte
+		want    uint32
+		wantOK  bool
+	}{
+		{"ticket with early_data", sealRecords(t, secret, sessionTicket(t, 0x400)), 0x400, true},
+		{"ticket without early_data", sealRecords(t, secret, sessionTicket(t, 0)), 0, false},
+		{"second record", sealRecords(t, secret, sessionTicket(t, 0), sessionTicket(t, 1024)), 1024, true},
+		{
+			name:    "handshake records skipped",
+			records: append(append(changeCipherSpec, sealRecords(t, handshakeSecret, []byte{8, 0, 0, 2, 0, 0})...), sealRecords(t, secret, sessionTicket(t, 0x400))...),
+			want:    0x400,
+			wantOK:  true,
+		},
+		{"wrong key", sealRecords(t, handshakeSecret, sessionTicket(t, 0x400)), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := earlyDataLimit(tt.records, tls.TLS_AES_128_GCM_SHA256, secret)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("earlyDataLimit = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// serverHello builds a ServerHello handshake message carrying sessionID.
+func serverHello(sessionID []byte) []byte {
+	var b cryptobyte.Builder
+	b.AddUint8(handshakeServerHello)
+	b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint16(tls.VersionTLS12)
+		b.AddBytes(make([]byte, 32))
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(sessionID) })
+		b.AddUint16(tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256)
+		b.AddUint8(0)
+	})
+	return b.BytesOrPanic()
+}
+
+func record(contentType uint8, fragment []byte) []byte {
+	return append([]byte{contentType, 3, 3, byte(len(fragment) >> 8), byte(len(fragment))}, fragment...)
+}
+
+func TestReadHandshakeMessage(t *testing.T) {
+	hello := serverHello(bytes.Repeat([]byte{0x42}, 32))
+
+	tests := []struct {
+		name    string
+		input   []byte
+		want    []byte
+		wantErr string
+	}{
+		{"one record", record(recordHandshake, hello), hello, ""},
+		{"fragmented", append(record(recordHandshake, hello[:3]), record(recordHandshake, hello[3:])...), hello, ""},
+		{"alert", record(recordAlert, []byte{2, 40}), nil, "alert"},
+		{"unexpected record", record(recordApplicationData, []byte{1}), nil, "unexpected record type 23"},
+		{"truncated", record(recordHandshake, hello)[:20], nil, "EOF"},
+		{"incomplete message", record(recordHandshake, hello[:10]), nil, "EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readHandshakeMessage(bytes.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("message = %x, want %x", []byte(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestServerHelloSessionID(t *testing.T) {
+	id := bytes.Repeat([]byte{0x42}, 32)
+	tests := []struct {
+		name    string
+		input   []byte
+		want    []byte
+		wantErr bool
+	}{
+		{"session id", record(recordHandshake, serverHello(id)), id, false},
+		{"no session id", record(recordHandshake, serverHello(nil)), []byte{}, false},
+		{"not a server hello", record(recordHandshake, sessionTicket(t, 0)), nil, true},
+		{"malformed", record(recordHandshake, []byte{handshakeServerHello, 0, 0, 3, 3, 3, 0}), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := serverHelloSessionID(bytes.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Equal(got, tt.want) {
+				t.Errorf("session id = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientHello(t *testing.T) {
+	id := bytes.Repeat([]byte{7}, 32)
+	tests := []struct {
+		name       string
+		serverName string
+		wantSNI    bool
+	}{
+		{"host name", "www.example.com", true},
+		{"ip address", "192.0.2.1", false},
+		{"no name", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hello, err := clientHello(tt.serverName, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			message, err := readHandshakeMessage(bytes.NewReader(hello))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var msgType, compressionCount uint8
+			var version uint16
+			var body, sessionID, suites, compression, extensions cryptobyte.String
+			if !message.ReadUint8(&msgType) || !message.ReadUint24LengthPrefixed(&body) || !message.Empty() ||
+				!body.ReadUint16(&version) || !body.Skip(32) || !body.ReadUint8LengthPrefixed(&sessionID) ||
+				!body.ReadUint16LengthPrefixed(&suites) || !body.ReadUint8(&compressionCount) ||
+				!body.ReadBytes((*[]byte)(&compression), int(compressionCount)) ||
+				!body.ReadUint16LengthPrefixed(&extensions) || !body.Empty() {
+				t.Fatalf("malformed ClientHello %x", hello)
+			}
+			if msgType != 1 || version != tls.VersionTLS12 {
+				t.Errorf("type %d version %x, want ClientHello for TLS 1.2", msgType, version)
+			}
+			if !bytes.Equal(sessionID, id) {
+				t.Errorf("session id = %x, want %x", []byte(sessionID), id)
+			}
+			if len(suites) == 0 || len(suites)%2 != 0 {
+				t.Errorf("cipher suites length %d", len(suites))
+			}
+
+			var sni string
+			for !extensions.Empty() {
+				var extType uint16
+				var data cryptobyte.String
+				if !extensions.ReadUint16(&extType) || !extensions.ReadUint16LengthPrefixed(&data) {
+					t.Fatal("malformed extensions")
+				}
+				var list, name cryptobyte.String
+				var nameType uint8
+				if extType == 0 && data.ReadUint16LengthPrefixed(&list) && list.ReadUint8(&nameType) && list.ReadUint16LengthPrefixed(&name) {
+					sni = string(name)
+				}
+			}
+			if tt.wantSNI && sni != tt.serverName {
+				t.Errorf("server_name = %q, want %q", sni, tt.serverName)
+			}
+			if !tt.wantSNI && sni != "" {
+				t.Errorf("server_name = %q, want none", sni)
+			}
+		})
+	}
+}
+
+func TestTrafficSecret(t *testing.T) {
+	keyLog := "CLIENT_HANDSHAKE_TRAFFIC_SECRET 00 0102\n" +
+		"SERVER_TRAFFIC_SECRET_0 0011 a1b2\n" +
+		"SERVER_TRAFFIC_SECRET_1 0011 zz\n"
+
+	tests := []struct {
+		label  string
+		want   []byte
+		wantOK bool
+	}{
+		{"SERVER_TRAFFIC_SECRET_0", []byte{0xa1, 0xb2}, true},
+		{"SERVER_TRAFFIC_SECRET_1", nil, false},
+		{"CLIENT_TRAFFIC_SECRET_0", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			got, ok := trafficSecret(keyLog, tt.label)
+			if ok != tt.wantOK || (ok && !bytes.Equal(got, tt.want)) {
+				t.Errorf("trafficSecret(%q) = %x, %v, want %x, %v", tt.label, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
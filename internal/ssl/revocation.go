@@ -0,0 +1,241 @@
+package ssl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// Revocation statuses reported in CertificateInfo.RevocationStatus.
+const (
+	RevocationGood    = "good"
+	RevocationRevoked = "revoked"
+	RevocationUnknown = "unknown"
+)
+
+// maxCRLSize bounds how much of a certificate revocation list is downloaded.
+const maxCRLSize = 20 << 20
+
+// revocationCache remembers the status of certificates already checked, so
+// subdomains sharing a certificate ask the responder only once. It also
+// remembers each OCSP responder that did not answer and each CRL, so an
+// unreachable source is waited on once per scan and a CRL is downloaded once.
+type revocationCache struct {
+	mu          sync.Mutex
+	statuses    map[string]string
+	unreachable map[string]bool
+	crls        map[string]*crlEntry
+}
+
+type crlEntry struct {
+	once sync.Once
+	list *x509.RevocationList
+	err  error
+}
+
+func (c *revocationCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status, ok := c.statuses[key]
+	return status, ok
+}
+
+func (c *revocationCache) set(key, status string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.statuses == nil {
+		c.statuses = make(map[string]string)
+	}
+	c.statuses[key] = status
+}
+
+func (c *revocationCache) isUnreachable(url string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.unreachable[url]
+}
+
+func (c *revocationCache) markUnreachable(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unreachable == nil {
+		c.unreachable = make(map[string]bool)
+	}
+	c.unreachable[url] = true
+}
+
+func (c *revocationCache) crl(url string) *crlEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.crls == nil {
+		c.crls = make(map[string]*crlEntry)
+	}
+	entry, ok := c.crls[url]
+	if !ok {
+		entry = &crlEntry{}
+		c.crls[url] = entry
+	}
+	return entry
+}
+
+// checkRevocation fills in the revocation status of the leaf certificate.
+// A response stapled to the handshake is used first, then, with deep checks,
+// the certificate's OCSP responders and finally its CRL distribution points.
+// When none of them gives a current answer the status is unknown and
+// RevocationChecked stays false.
+func (sa *SSLAnalyzer) checkRevocation(ctx context.Context, state tls.ConnectionState, info *CertificateInfo) {
+	info.RevocationStatus = RevocationUnknown
+	if len(state.PeerCertificates) < 2 {
+		return
+	}
+	cert, issuer := state.PeerCertificates[0], state.PeerCertificates[1]
+
+	key := string(issuer.RawSubject) + "\x00" + cert.SerialNumber.String()
+	status, ok := sa.revocations.get(key)
+	if !ok {
+		status = sa.revocationStatus(ctx, state.OCSPResponse, cert, issuer)
+		// Unreachable responders are tried again for the next host
+		if status != RevocationUnknown {
+			sa.revocations.set(key, status)
+		}
+	}
+
+	info.RevocationStatus = status
+	info.RevocationChecked = status != RevocationUnknown
+	info.Revoked = status == RevocationRevoked
+}
+
+func (sa *SSLAnalyzer) revocationStatus(ctx context.Context, stapled []byte, cert, issuer *x509.Certificate) string {
+	if len(stapled) > 0 {
+		if response, err := ocsp.ParseResponseForCert(stapled, cert, issuer); err == nil {
+			if status := ocspStatus(response); status != RevocationUnknown {
+				return status
+			}
+		}
+	}
+
+	// Asking the responders and downloading CRLs is a deep check
+	if !sa.deep {
+		return RevocationUnknown
+	}
+
+	for _, server := range cert.OCSPServer {
+		if sa.revocations.isUnreachable(server) {
+			continue
+		}
+		response, err := sa.queryOCSP(ctx, server, cert, issuer)
+		if err != nil {
+			continue
+		}
+		if status := ocspStatus(response); status != RevocationUnknown {
+			return status
+		}
+	}
+
+	for _, point := range cert.CRLDistributionPoints {
+		list, err := sa.cachedCRL(ctx, point)
+		if err != nil || list.CheckSignatureFrom(issuer) != nil || !current(list.ThisUpdate, list.NextUpdate) {
+			continue
+		}
+		for _, entry := range list.RevokedCertificateEntries {
+			if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return RevocationRevoked
+			}
+		}
+		return RevocationGood
+	}
+
+	return RevocationUnknown
+}
+
+func (sa *SSLAnalyzer) queryOCSP(ctx context.Context, server string, cert, issuer *x509.Certificate) (*ocsp.Response, error) {
+	request, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server, bytes.NewReader(request))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+	req.Header.Set("Accept", "application/ocsp-response")
+
+	body, err := sa.fetch(req, 64<<10)
+	if err != nil {
+		sa.revocations.markUnreachable(server)
+		return nil, err
+	}
+	return ocsp.ParseResponseForCert(body, cert, issuer)
+}
+
+// cachedCRL downloads and parses the CRL at url once per scan; a failed
+// download is remembered too. Its signature and validity are checked by the
+// caller for each certificate.
+func (sa *SSLAnalyzer) cachedCRL(ctx context.Context, url string) (*x509.RevocationList, error) {
+	entry := sa.revocations.crl(url)
+	entry.once.Do(func() {
+		entry.list, entry.err = sa.fetchCRL(ctx, url)
+	})
+	return entry.list, entry.err
+}
+
+func (sa *SSLAnalyzer) fetchCRL(ctx context.Context, url string) (*x509.RevocationList, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := sa.fetch(req, maxCRLSize)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseRevocationList(body)
+}
+
+func (sa *SSLAnalyzer) fetch(req *http.Request, limit int64) ([]byte, error) {
+	resp, err := sa.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", req.URL, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, limit))
+}
+
+// clockSkew is how far ahead of the local clock a thisUpdate time may be.
+const clockSkew = 5 * time.Minute
+
+// current reports whether a response or CRL is in its validity window. A
+// zero nextUpdate means newer information is always available, which is
+// accepted.
+func current(thisUpdate, nextUpdate time.Time) bool {
+	now := time.Now()
+	return !now.Add(clockSkew).Before(thisUpdate) && (nextUpdate.IsZero() || now.Before(nextUpdate))
+}
+
+// ocspStatus reads a response, treating one that is out of date as unknown.
+func ocspStatus(response *ocsp.Response) string {
+	if !current(response.ThisUpdate, response.NextUpdate) {
+		return RevocationUnknown
+	}
+	switch response.Status {
+	case ocsp.Good:
+		return RevocationGood
+	case ocsp.Revoked:
+		return RevocationRevoked
+	default:
+		return RevocationUnknown
+	}
+}
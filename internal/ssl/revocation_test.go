@@ -0,0 +1,87 @@
+package ssl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestCurrent(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		thisUpdate time.Time
+		nextUpdate time.Time
+		want       bool
+	}{
+		{"in window", now.Add(-time.Hour), now.Add(time.Hour), true},
+		{"no next update", now.Add(-time.Hour), time.Time{}, true},
+		{"stale next update", now.Add(-2 * time.Hour), now.Add(-time.Hour), false},
+		{"this update within skew", now.Add(clockSkew / 2), now.Add(time.Hour), true},
+		{"this update beyond skew", now.Add(clockSkew + time.Minute), now.Add(time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := current(tt.thisUpdate, tt.nextUpdate); got != tt.want {
+				t.Errorf("current(%v, %v) = %v, want %v", tt.thisUpdate, tt.nextUpdate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOCSPStatus(t *testing.T) {
+	now := time.Now()
+	fresh := func(status int) *ocsp.Response {
+		return &ocsp.Response{Status: status, ThisUpdate: now.Add(-time.Hour), NextUpdate: now.Add(time.Hour)}
+	}
+	tests := []struct {
+		name     string
+		response *ocsp.Response
+		want     string
+	}{
+		{"good", fresh(ocsp.Good), RevocationGood},
+		{"revoked", fresh(ocsp.Revoked), RevocationRevoked},
+		{"unknown", fresh(ocsp.Unknown), RevocationUnknown},
+		{"stale good", &ocsp.Response{Status: ocsp.Good, ThisUpdate: now.Add(-48 * time.Hour), NextUpdate: now.Add(-24 * time.Hour)}, RevocationUnknown},
+		{"stale revoked", &ocsp.Response{Status: ocsp.Revoked, ThisUpdate: now.Add(-48 * time.Hour), NextUpdate: now.Add(-time.Second)}, RevocationUnknown},
+		{"future this update", &ocsp.Response{Status: ocsp.Good, ThisUpdate: now.Add(time.Hour), NextUpdate: now.Add(2 * time.Hour)}, RevocationUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ocspStatus(tt.response); got != tt.want {
+				t.Errorf("ocspStatus = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestFetchUsesInjectedClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not a crl"))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	sa := NewSSLAnalyzerWithClient(time.Second, &http.Client{Transport: transport, Timeout: time.Second})
+	if _, err := sa.fetchCRL(context.Background(), server.URL); err == nil {
+		t.Error("fetchCRL parsed an invalid CRL")
+	}
+	if transport.requests != 1 {
+		t.Errorf("injected client sent %d requests, want 1", transport.requests)
+	}
+}
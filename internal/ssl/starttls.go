@@ -0,0 +1,139 @@
+package ssl
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// STARTTLS protocols accepted by SetStartTLS.
+const (
+	StartTLSSMTP = "smtp"
+	StartTLSIMAP = "imap"
+	StartTLSPOP3 = "pop3"
+	StartTLSNone = "none"
+)
+
+// startTLSPorts are the plaintext ports that upgrade to TLS with STARTTLS.
+var startTLSPorts = map[int]string{
+	25:  StartTLSSMTP,
+	587: StartTLSSMTP,
+	110: StartTLSPOP3,
+	143: StartTLSIMAP,
+}
+
+// StartTLSProtocol returns the STARTTLS protocol spoken on a well-known port,
+// or "" when TLS starts right away.
+func StartTLSProtocol(port int) string {
+	return startTLSPorts[port]
+}
+
+// SetStartTLS upgrades every connection with the given STARTTLS protocol
+// before the handshake. "" picks it from the port and "none" never upgrades.
+func (sa *SSLAnalyzer) SetStartTLS(protocol string) {
+	sa.startTLS = protocol
+}
+
+func (sa *SSLAnalyzer) startTLSProtocol(port int) string {
+	switch sa.startTLS {
+	case "":
+		return StartTLSProtocol(port)
+	case StartTLSNone:
+		return ""
+	default:
+		return sa.startTLS
+	}
+}
+
+// upgrade runs the plaintext exchange that makes a mail server expect a TLS
+// handshake next. The connection's deadline must already be set.
+func upgrade(conn net.Conn, protocol string) error {
+	reader := bufio.NewReader(conn)
+	send := func(command string) error {
+		_, err := fmt.Fprintf(conn, "%s\r\n", command)
+		return err
+	}
+
+	switch protocol {
+	case StartTLSSMTP:
+		if _, err := smtpReply(reader, "220"); err != nil {
+			return err
+		}
+		if err := send("EHLO subdomain-finder"); err != nil {
+			return err
+		}
+		extensions, err := smtpReply(reader, "250")
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(strings.ToUpper(extensions), "STARTTLS") {
+			return fmt.Errorf("smtp server does not offer STARTTLS")
+		}
+		if err := send("STARTTLS"); err != nil {
+			return err
+		}
+		_, err = smtpReply(reader, "220")
+		return err
+	case StartTLSIMAP:
+		if err := expectLine(reader, "* OK"); err != nil {
+			return err
+		}
+		if err := send("a1 STARTTLS"); err != nil {
+			return err
+		}
+		// Untagged lines may come before the tagged answer
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(line, "a1 ") {
+				if !strings.HasPrefix(line, "a1 OK") {
+					return fmt.Errorf("imap STARTTLS refused: %s", strings.TrimSpace(line))
+				}
+				return nil
+			}
+		}
+	case StartTLSPOP3:
+		if err := expectLine(reader, "+OK"); err != nil {
+			return err
+		}
+		if err := send("STLS"); err != nil {
+			return err
+		}
+		return expectLine(reader, "+OK")
+	default:
+		return fmt.Errorf("unknown STARTTLS protocol %q", protocol)
+	}
+}
+
+// smtpReply reads a possibly multi-line SMTP reply and checks its code.
+func smtpReply(reader *bufio.Reader, code string) (string, error) {
+	var reply strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		if !strings.HasPrefix(line, code) {
+			return "", fmt.Errorf("unexpected smtp reply: %s", strings.TrimSpace(line))
+		}
+		reply.WriteString(line)
+		// "250-" continues the reply, "250 " ends it
+		if len(line) < 4 || line[3] != '-' {
+			return reply.String(), nil
+		}
+	}
+}
+
+func expectLine(reader *bufio.Reader, prefix string) error {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, prefix) {
+		return fmt.Errorf("unexpected reply: %s", strings.TrimSpace(line))
+	}
+	return nil
+}
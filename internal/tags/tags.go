@@ -0,0 +1,146 @@
+package tags
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"subdomain-finder/internal/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule adds Tag to results matching every pattern it sets. Patterns are
+// case-insensitive regular expressions; Technology matches any detected
+// technology name.
+type Rule struct {
+	Tag        string `yaml:"tag"`
+	Subdomain  string `yaml:"subdomain"`
+	IP         string `yaml:"ip"`
+	Title      string `yaml:"title"`
+	Server     string `yaml:"server"`
+	Technology string `yaml:"technology"`
+
+	matchers []fieldMatcher
+}
+
+type fieldMatcher struct {
+	pattern *regexp.Regexp
+	values  func(types.Result) []string
+}
+
+type RuleSet struct {
+	rules []Rule
+}
+
+// ParseRules reads a YAML (or JSON) list of rules. A rule needs a tag and
+// at least one pattern.
+func ParseRules(data []byte) (*RuleSet, error) {
+	var rules []Rule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse tag rules: %w", err)
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		rule.Tag = Normalize(rule.Tag)
+		if rule.Tag == "" {
+			return nil, fmt.Errorf("tag rule %d has no tag", i+1)
+		}
+
+		fields := []struct {
+			pattern string
+			values  func(types.Result) []string
+		}{
+			{rule.Subdomain, func(r types.Result) []string { return []string{r.Subdomain} }},
+			{rule.IP, func(r types.Result) []string { return []string{r.IP} }},
+			{rule.Title, func(r types.Result) []string { return []string{r.Title} }},
+			{rule.Server, func(r types.Result) []string { return []string{r.Server} }},
+			{rule.Technology, technologyNames},
+		}
+		for _, field := range fields {
+			if field.pattern == "" {
+				continue
+			}
+			pattern, err := regexp.Compile("(?i)" + field.pattern)
+			if err != nil {
+				return nil, fmt.Errorf("tag rule %q: %w", rule.Tag, err)
+			}
+			rule.matchers = append(rule.matchers, fieldMatcher{pattern: pattern, values: field.values})
+		}
+		if len(rule.matchers) == 0 {
+			return nil, fmt.Errorf("tag rule %q has no patterns", rule.Tag)
+		}
+	}
+
+	return &RuleSet{rules: rules}, nil
+}
+
+func LoadRules(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag rules: %w", err)
+	}
+	return ParseRules(data)
+}
+
+// Apply returns the tags of every rule that matches result.
+func (rs *RuleSet) Apply(result types.Result) []string {
+	if rs == nil {
+		return nil
+	}
+
+	var matched []string
+	for _, rule := range rs.rules {
+		if rule.matches(result) {
+			matched = append(matched, rule.Tag)
+		}
+	}
+	return matched
+}
+
+func (r Rule) matches(result types.Result) bool {
+	for _, matcher := range r.matchers {
+		found := false
+		for _, value := range matcher.values(result) {
+			if matcher.pattern.MatchString(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func technologyNames(result types.Result) []string {
+	names := make([]string, 0, len(result.Technologies))
+	for _, tech := range result.Technologies {
+		names = append(names, tech.Name)
+	}
+	return names
+}
+
+// Normalize lowercases a tag and replaces spaces with dashes.
+func Normalize(tag string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(tag)), " ", "-")
+}
+
+// Merge returns the sorted union of existing and added tags.
+func Merge(existing []string, added ...string) []string {
+	seen := make(map[string]bool, len(existing)+len(added))
+	var merged []string
+	for _, tag := range append(append([]string{}, existing...), added...) {
+		tag = Normalize(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			merged = append(merged, tag)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
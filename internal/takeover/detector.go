@@ -0,0 +1,85 @@
+package takeover
+
+import (
+	"fmt"
+	"strings"
+
+	"subdomain-finder/internal/types"
+)
+
+type fingerprint struct {
+	service  string
+	suffixes []string
+	patterns []string
+	// dangling services can be claimed as soon as the CNAME target stops
+	// resolving, so no response is needed to confirm them
+	dangling bool
+}
+
+// Detector recognises subdomains whose CNAME points at a third-party
+// service that no longer hosts anything for them, so anyone could claim
+// the name there.
+type Detector struct {
+	fingerprints []fingerprint
+}
+
+func NewDetector() *Detector {
+	return &Detector{
+		fingerprints: []fingerprint{
+			{service: "GitHub Pages", suffixes: []string{"github.io"}, patterns: []string{"there isn't a github pages site here"}},
+			{service: "Amazon S3", suffixes: []string{"amazonaws.com"}, patterns: []string{"nosuchbucket", "the specified bucket does not exist"}},
+			{service: "Heroku", suffixes: []string{"herokuapp.com", "herokudns.com", "herokussl.com"}, patterns: []string{"no such app", "herokucdn.com/error-pages/no-such-app.html"}},
+			{service: "Microsoft Azure", suffixes: []string{"azurewebsites.net", "cloudapp.net", "cloudapp.azure.com", "trafficmanager.net", "blob.core.windows.net", "azureedge.net"}, patterns: []string{"404 web site not found"}, dangling: true},
+			{service: "Shopify", suffixes: []string{"myshopify.com"}, patterns: []string{"sorry, this shop is currently unavailable", "only one step left"}},
+			{service: "Fastly", suffixes: []string{"fastly.net", "fastlylb.net"}, patterns: []string{"fastly error: unknown domain"}},
+		},
+	}
+}
+
+// Check matches a subdomain's CNAME target against the known services. A
+// match needs the target to be one of the service's hosts and either its
+// "nothing here" page in body or, for services claimable by name alone, a
+// target that no longer resolves. It returns nil when nothing matches.
+func (d *Detector) Check(subdomain, cname string, resolves bool, body string) *types.Vulnerability {
+	cname = strings.ToLower(strings.TrimSuffix(cname, "."))
+	content := strings.ToLower(body)
+
+	for _, fp := range d.fingerprints {
+		if !hasSuffix(cname, fp.suffixes) {
+			continue
+		}
+
+		evidence := ""
+		for _, pattern := range fp.patterns {
+			if strings.Contains(content, pattern) {
+				evidence = fmt.Sprintf("CNAME %s, response contains %q", cname, pattern)
+				break
+			}
+		}
+		if evidence == "" && fp.dangling && !resolves {
+			evidence = fmt.Sprintf("CNAME %s does not resolve", cname)
+		}
+		if evidence == "" {
+			continue
+		}
+
+		return &types.Vulnerability{
+			Name:        "Subdomain Takeover",
+			Severity:    "High",
+			Description: fmt.Sprintf("%s points to an unclaimed %s resource, so anyone who registers it there can serve content on this subdomain", subdomain, fp.service),
+			Solution:    fmt.Sprintf("Remove the CNAME record or claim the resource on %s", fp.service),
+			Evidence:    evidence,
+		}
+	}
+
+	return nil
+}
+
+func hasSuffix(host string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if host == suffix || strings.HasSuffix(host, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,8 +5,11 @@ import (
 	"io"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
+
+	httpclient "subdomain-finder/internal/http"
 )
 
 type Technology struct {
@@ -37,17 +40,40 @@ type TechDetector struct {
 }
 
 func NewTechDetector(timeout time.Duration) *TechDetector {
+	return NewTechDetectorWithClient(&http.Client{
+		Timeout:   timeout,
+		Transport: httpclient.NewDecodingTransport(nil),
+	})
+}
+
+// NewTechDetectorWithClient returns a detector that sends every request
+// through client, sharing its transport, cookie jar and timeout.
+func NewTechDetectorWithClient(client *http.Client) *TechDetector {
 	return &TechDetector{
-		client: &http.Client{
-			Timeout: timeout,
-		},
-		timeout: timeout,
+		client:  client,
+		timeout: client.Timeout,
 	}
 }
 
+func (td *TechDetector) SetTransport(transport http.RoundTripper) {
+	td.client.Transport = transport
+}
+
+func (td *TechDetector) SetCookieJar(jar http.CookieJar) {
+	td.client.Jar = jar
+}
+
 func (td *TechDetector) Detect(url string) (*TechResult, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), td.timeout)
-	defer cancel()
+	return td.DetectContext(context.Background(), url)
+}
+
+// DetectContext is Detect with the request bound to ctx.
+func (td *TechDetector) DetectContext(ctx context.Context, url string) (*TechResult, error) {
+	if td.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, td.timeout)
+		defer cancel()
+	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -79,6 +105,8 @@ func (td *TechDetector) Detect(url string) (*TechResult, error) {
 	td.detectFromBody(string(body), result)
 	td.detectFromURL(url, result)
 
+	result.Technologies = MergeTechnologies(result.Technologies)
+
 	return result, nil
 }
 
@@ -228,6 +256,11 @@ func (td *TechDetector) detectFromBody(body string, result *TechResult) {
 		matches := re.FindStringSubmatch(body)
 		if len(matches) > 0 {
 			tech.Version = td.extractVersion(matches[0])
+			if tech.Name == "Generator" && len(matches) > 1 {
+				if name := generatorProduct(matches[1]); name != "" {
+					tech.Name = name
+				}
+			}
 			result.Technologies = append(result.Technologies, tech)
 		}
 	}
@@ -308,6 +341,62 @@ func (td *TechDetector) detectFrameworks(body string, result *TechResult) {
 	}
 }
 
+func generatorProduct(content string) string {
+	name := strings.TrimSpace(content)
+	if idx := strings.Index(name, " - "); idx >= 0 {
+		name = name[:idx]
+	}
+	if idx := strings.IndexFunc(name, func(r rune) bool { return r >= '0' && r <= '9' }); idx >= 0 {
+		name = name[:idx]
+	}
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(name), "/v"))
+}
+
+// MergeTechnologies collapses detections of the same technology (compared
+// case-insensitively) into one entry with the highest confidence, filling in
+// version and metadata from the other detections when missing.
+func MergeTechnologies(technologies []Technology) []Technology {
+	merged := make([]Technology, 0, len(technologies))
+	index := make(map[string]int)
+
+	for _, tech := range technologies {
+		key := strings.ToLower(strings.TrimSpace(tech.Name))
+		i, exists := index[key]
+		if !exists {
+			index[key] = len(merged)
+			merged = append(merged, tech)
+			continue
+		}
+
+		existing := merged[i]
+		if tech.Confidence > existing.Confidence {
+			existing, tech = tech, existing
+		}
+		if existing.Version == "" {
+			existing.Version = tech.Version
+		}
+		if existing.Category == "" {
+			existing.Category = tech.Category
+		}
+		if existing.Description == "" {
+			existing.Description = tech.Description
+		}
+		if existing.Website == "" {
+			existing.Website = tech.Website
+		}
+		merged[i] = existing
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Confidence != merged[j].Confidence {
+			return merged[i].Confidence > merged[j].Confidence
+		}
+		return merged[i].Name < merged[j].Name
+	})
+
+	return merged
+}
+
 func (td *TechDetector) extractVersion(text string) string {
 	re := regexp.MustCompile(`(\d+\.\d+(?:\.\d+)?)`)
 	matches := re.FindStringSubmatch(text)
@@ -0,0 +1,86 @@
+package techdetect
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeTechnologies(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []Technology
+		want []Technology
+	}{
+		{
+			name: "overlapping detections collapse to one",
+			in: []Technology{
+				{Name: "WordPress", Category: "CMS", Confidence: 80},
+				{Name: "wordpress", Version: "6.4.2", Confidence: 95},
+				{Name: "WordPress ", Confidence: 90, Website: "https://wordpress.org"},
+			},
+			want: []Technology{
+				{Name: "wordpress", Version: "6.4.2", Category: "CMS", Confidence: 95, Website: "https://wordpress.org"},
+			},
+		},
+		{
+			name: "higher confidence version wins",
+			in: []Technology{
+				{Name: "nginx", Version: "1.18", Confidence: 60},
+				{Name: "nginx", Version: "1.24", Confidence: 100},
+			},
+			want: []Technology{{Name: "nginx", Version: "1.24", Confidence: 100}},
+		},
+		{
+			name: "distinct names sorted by confidence then name",
+			in: []Technology{
+				{Name: "jQuery", Confidence: 90},
+				{Name: "PHP", Confidence: 95},
+				{Name: "Bootstrap", Confidence: 90},
+			},
+			want: []Technology{
+				{Name: "PHP", Confidence: 95},
+				{Name: "Bootstrap", Confidence: 90},
+				{Name: "jQuery", Confidence: 90},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeTechnologies(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectMergesDuplicates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head>
+<meta name="generator" content="WordPress 6.4.2">
+<script src="/wp-includes/js/wordpress-embed.min.js"></script>
+</head></html>`))
+	}))
+	defer server.Close()
+
+	result, err := NewTechDetector(5 * time.Second).Detect(server.URL + "/wordpress/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found []Technology
+	for _, tech := range result.Technologies {
+		if tech.Name == "WordPress" {
+			found = append(found, tech)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("got %d WordPress entries, want 1: %+v", len(found), result.Technologies)
+	}
+	if found[0].Version != "6.4.2" || found[0].Confidence != 95 {
+		t.Errorf("got %+v, want version 6.4.2 with confidence 95", found[0])
+	}
+}
@@ -0,0 +1,63 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+)
+
+func TestProtocol(t *testing.T) {
+	tests := []struct {
+		name     string
+		traces   string
+		general  string
+		expected string
+	}{
+		{"default", "", "", "http/protobuf"},
+		{"general", "", "grpc", "grpc"},
+		{"traces wins", "http/protobuf", "grpc", "http/protobuf"},
+		{"case and space", " GRPC ", "", "grpc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", tt.traces)
+			t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", tt.general)
+			if got := protocol(); got != tt.expected {
+				t.Errorf("protocol() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		protocol string
+		wantErr  bool
+	}{
+		{"no endpoint is a no-op", "", "http/json", false},
+		{"http/protobuf", "http://127.0.0.1:4318", "http/protobuf", false},
+		{"grpc", "http://127.0.0.1:4317", "grpc", false},
+		{"unsupported protocol", "http://127.0.0.1:4318", "http/json", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+			t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", tt.endpoint)
+			t.Setenv("OTEL_EXPORTER_OTLP_PROTOCOL", tt.protocol)
+
+			shutdown, err := Setup(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Setup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if shutdown == nil {
+				t.Fatal("Setup() returned a nil shutdown function")
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_ = shutdown(ctx)
+		})
+	}
+}
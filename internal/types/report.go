@@ -0,0 +1,31 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// ScanReport is the JSON document that keeps a scan's summary with its
+// results.
+type ScanReport struct {
+	Summary *ScanSummary `json:"summary"`
+	Results []Result     `json:"results"`
+}
+
+// DecodeResults reads either a bare results array or a ScanReport. The
+// summary is nil for a bare array.
+func DecodeResults(data []byte) ([]Result, *ScanSummary, error) {
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '{' {
+		var report ScanReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, nil, err
+		}
+		return report.Results, report.Summary, nil
+	}
+
+	var results []Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, nil, err
+	}
+	return results, nil, nil
+}
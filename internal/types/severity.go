@@ -0,0 +1,33 @@
+package types
+
+import "strings"
+
+var severityRanks = map[string]int{
+	"info":     0,
+	"low":      1,
+	"medium":   2,
+	"high":     3,
+	"critical": 4,
+}
+
+// SeverityRank orders severities from info (0) to critical (4). Unknown
+// values return -1.
+func SeverityRank(severity string) int {
+	rank, ok := severityRanks[strings.ToLower(strings.TrimSpace(severity))]
+	if !ok {
+		return -1
+	}
+	return rank
+}
+
+// HighestSeverity returns the rank of the most severe signal on a result,
+// considering both its risk level and its vulnerabilities.
+func (r Result) HighestSeverity() int {
+	highest := SeverityRank(r.RiskLevel)
+	for _, vuln := range r.Vulnerabilities {
+		if rank := SeverityRank(vuln.Severity); rank > highest {
+			highest = rank
+		}
+	}
+	return highest
+}
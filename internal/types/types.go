@@ -13,7 +13,9 @@ type Result struct {
 	ResponseTime    time.Duration          `json:"response_time"`
 	Technologies    []Technology           `json:"technologies"`
 	Ports           []PortInfo             `json:"ports"`
+	WebServices     []WebService           `json:"web_services,omitempty"`
 	SSL             *SSLInfo               `json:"ssl"`
+	SSLServices     []SSLInfo              `json:"ssl_services,omitempty"`
 	Vulnerabilities []Vulnerability        `json:"vulnerabilities"`
 	Headers         map[string]string      `json:"headers"`
 	Cookies         []Cookie               `json:"cookies"`
@@ -22,6 +24,20 @@ type Result struct {
 	GeoLocation     *GeoLocation           `json:"geo_location"`
 	RiskLevel       string                 `json:"risk_level"`
 	Confidence      int                    `json:"confidence"`
+	Parked          bool                   `json:"parked"`
+	ParkingProvider string                 `json:"parking_provider"`
+	DefaultPage     string                 `json:"default_page,omitempty"`
+	Source          string                 `json:"source,omitempty"`
+	Depth           int                    `json:"depth,omitempty"`
+	Hosting         string                 `json:"hosting,omitempty"`
+	WildcardDNS     bool                   `json:"wildcard_dns,omitempty"`
+	Endpoints       []string               `json:"endpoints,omitempty"`
+	Tags            []string               `json:"tags,omitempty"`
+	FirstSeen       time.Time              `json:"first_seen"`
+	LastSeen        time.Time              `json:"last_seen"`
+	New             bool                   `json:"new"`
+	Change          string                 `json:"change,omitempty"`
+	ChangedFields   []string               `json:"changed_fields,omitempty"`
 	Timestamp       time.Time              `json:"timestamp"`
 	Metadata        map[string]interface{} `json:"metadata"`
 }
@@ -45,7 +61,24 @@ type PortInfo struct {
 	Version  string `json:"version"`
 }
 
+// Open reports whether the port answered. Silent UDP ports are kept with
+// the state "open|filtered"; results saved without a state were open.
+func (p PortInfo) Open() bool {
+	return p.State == "" || p.State == "open"
+}
+
+type WebService struct {
+	Port         int          `json:"port"`
+	URL          string       `json:"url"`
+	Status       int          `json:"status"`
+	Title        string       `json:"title"`
+	Server       string       `json:"server"`
+	Technologies []Technology `json:"technologies,omitempty"`
+}
+
 type SSLInfo struct {
+	Port               int       `json:"port,omitempty"`
+	StartTLS           string    `json:"starttls,omitempty"`
 	Valid              bool      `json:"valid"`
 	Expired            bool      `json:"expired"`
 	ExpiresSoon        bool      `json:"expires_soon"`
@@ -57,6 +90,18 @@ type SSLInfo struct {
 	PublicKeyAlgorithm string    `json:"public_key_algorithm"`
 	KeySize            int       `json:"key_size"`
 	Grade              string    `json:"grade"`
+	ServerName         string    `json:"server_name"`
+	SANs               []string  `json:"sans,omitempty"`
+	Protocols          []string  `json:"protocols,omitempty"`
+	Ciphers            []string  `json:"ciphers,omitempty"`
+	SessionIDs         bool      `json:"session_ids"`
+	SessionTickets     bool      `json:"session_tickets"`
+	EarlyData          bool      `json:"early_data"`
+	Revoked            bool      `json:"revoked"`
+	RevocationStatus   string    `json:"revocation_status,omitempty"`
+	ChainValid         bool      `json:"chain_valid"`
+	UntrustedRoot      bool      `json:"untrusted_root"`
+	HostnameMismatch   bool      `json:"hostname_mismatch"`
 	Vulnerabilities    []string  `json:"vulnerabilities"`
 	NotBefore          time.Time `json:"not_before"`
 	NotAfter           time.Time `json:"not_after"`
@@ -70,6 +115,8 @@ type Vulnerability struct {
 	CVE         string   `json:"cve"`
 	Solution    string   `json:"solution"`
 	References  []string `json:"references"`
+	Evidence    string   `json:"evidence"`
+	Indicators  []string `json:"indicators"`
 }
 
 type Cookie struct {
@@ -118,6 +165,10 @@ type ScanSummary struct {
 	OpenPorts        int                    `json:"open_ports"`
 	Vulnerabilities  int                    `json:"vulnerabilities"`
 	HighRiskItems    int                    `json:"high_risk_items"`
+	NewSubdomains    int                    `json:"new_subdomains"`
+	WildcardDetected bool                   `json:"wildcard_detected"`
+	WildcardIPs      []string               `json:"wildcard_ips,omitempty"`
+	Disappeared      []string               `json:"disappeared"`
 	Technologies     []Technology           `json:"technologies"`
 	TopPorts         []PortInfo             `json:"top_ports"`
 	RiskDistribution map[string]int         `json:"risk_distribution"`
@@ -0,0 +1,119 @@
+package vulnscanner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const maxAPIDocSize = 1024 * 1024
+
+// API documentation paths probed when content discovery is enabled
+var apiDocPaths = []string{
+	"/swagger.json",
+	"/openapi.json",
+	"/swagger-ui",
+	"/api-docs",
+	"/v2/api-docs",
+}
+
+type apiSpec struct {
+	Swagger string `json:"swagger"`
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+}
+
+func (vs *VulnScanner) checkAPIDocumentation(ctx context.Context, url string) []Vulnerability {
+	var vulns []Vulnerability
+	baseURL := strings.TrimSuffix(url, "/")
+
+	for _, path := range apiDocPaths {
+		body, ok := vs.fetchProbe(ctx, baseURL+path)
+		if !ok {
+			continue
+		}
+
+		if evidence := describeAPISpec(body); evidence != "" {
+			vulns = append(vulns, Vulnerability{
+				Name:        "Exposed API Documentation",
+				Severity:    "Medium",
+				Description: "API specification is publicly accessible and maps the API attack surface",
+				Solution:    "Restrict API documentation to authenticated users or internal networks",
+				Evidence:    fmt.Sprintf("%s at %s", evidence, baseURL+path),
+				Confidence:  95,
+			})
+			continue
+		}
+
+		if strings.Contains(strings.ToLower(body), "swagger-ui") {
+			vulns = append(vulns, Vulnerability{
+				Name:        "Exposed API Documentation",
+				Severity:    "Medium",
+				Description: "Swagger UI is publicly accessible and maps the API attack surface",
+				Solution:    "Restrict API documentation to authenticated users or internal networks",
+				Evidence:    fmt.Sprintf("Swagger UI at %s", baseURL+path),
+				Confidence:  85,
+			})
+		}
+	}
+
+	return vulns
+}
+
+func (vs *VulnScanner) fetchProbe(ctx context.Context, url string) (string, bool) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", false
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+
+	resp, err := vs.client.Do(req)
+	if err != nil {
+		return "", false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxAPIDocSize))
+	if err != nil {
+		return "", false
+	}
+	return string(body), true
+}
+
+// describeAPISpec returns "Title vX (OpenAPI 3.0.1)" for a Swagger/OpenAPI
+// document, or "" when body is not one.
+func describeAPISpec(body string) string {
+	var spec apiSpec
+	if err := json.Unmarshal([]byte(body), &spec); err != nil {
+		return ""
+	}
+
+	format := ""
+	switch {
+	case spec.OpenAPI != "":
+		format = "OpenAPI " + spec.OpenAPI
+	case spec.Swagger != "":
+		format = "Swagger " + spec.Swagger
+	default:
+		return ""
+	}
+
+	title := spec.Info.Title
+	if title == "" {
+		title = "Untitled API"
+	}
+	if spec.Info.Version != "" {
+		title += " v" + spec.Info.Version
+	}
+	return fmt.Sprintf("%s (%s)", title, format)
+}
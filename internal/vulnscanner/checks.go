@@ -0,0 +1,72 @@
+package vulnscanner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Check is a category of ScanURL checks that can be enabled on its own.
+// Content discovery and active checks have their own setters.
+type Check string
+
+const (
+	CheckHeaders     Check = "headers"
+	CheckDisclosure  Check = "disclosure"
+	CheckSSL         Check = "ssl"
+	CheckForms       Check = "forms"
+	CheckCORS        Check = "cors"
+	CheckSecurityTxt Check = "securitytxt"
+	CheckJSONP       Check = "jsonp"
+	CheckTraversal   Check = "traversal"
+	CheckSQLi        Check = "sqli"
+	CheckXSS         Check = "xss"
+)
+
+// AllChecks lists every category, all of which run by default.
+var AllChecks = []Check{
+	CheckHeaders, CheckDisclosure, CheckSSL, CheckForms, CheckCORS,
+	CheckSecurityTxt, CheckJSONP, CheckTraversal, CheckSQLi, CheckXSS,
+}
+
+// AttackChecks send attack payloads, so they are skipped in safe mode.
+var AttackChecks = []Check{CheckTraversal, CheckSQLi, CheckXSS}
+
+// ParseChecks parses a comma-separated list such as "headers,ssl,disclosure".
+func ParseChecks(spec string) ([]Check, error) {
+	var checks []Check
+	seen := make(map[Check]bool)
+	for _, part := range strings.Split(spec, ",") {
+		check := Check(strings.ToLower(strings.TrimSpace(part)))
+		if check == "" {
+			continue
+		}
+		if !isCheck(check) {
+			return nil, fmt.Errorf("unknown check %q (use %s)", part, joinChecks(AllChecks))
+		}
+		if !seen[check] {
+			seen[check] = true
+			checks = append(checks, check)
+		}
+	}
+	if len(checks) == 0 {
+		return nil, fmt.Errorf("no checks given")
+	}
+	return checks, nil
+}
+
+func isCheck(check Check) bool {
+	for _, known := range AllChecks {
+		if check == known {
+			return true
+		}
+	}
+	return false
+}
+
+func joinChecks(checks []Check) string {
+	names := make([]string, 0, len(checks))
+	for _, check := range checks {
+		names = append(names, string(check))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,144 @@
+package vulnscanner
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strings"
+)
+
+// crlfCanaryHeader is the harmless header a successful injection adds.
+const crlfCanaryHeader = "X-Sf-Canary"
+
+// crlfSequences are percent-encoded line breaks: CRLF, a bare LF, and the
+// UTF-8 characters U+560A U+560D that servers truncating to one byte turn
+// into LF CR.
+var crlfSequences = []string{"%0d%0a", "%0a", "%e5%98%8a%e5%98%8d"}
+
+// crlfParams are query parameters commonly copied into Location or
+// Set-Cookie headers.
+var crlfParams = []string{"q", "url", "redirect", "next", "lang"}
+
+// crlfHeaders are request headers some applications echo into responses.
+var crlfHeaders = []string{"Referer", "X-Forwarded-Host"}
+
+// crlfInjectionPoints is how many requests each sequence costs.
+const crlfInjectionPoints = 3
+
+// checkCRLFInjection sends encoded line breaks followed by a canary header
+// in query parameters, the path and request headers. The target is
+// vulnerable when the canary comes back as a response header of its own.
+// Redirects are not followed since Location is the usual sink.
+func (vs *VulnScanner) checkCRLFInjection(ctx context.Context, url string) []Vulnerability {
+	base, err := neturl.Parse(strings.TrimSuffix(url, "/"))
+	if err != nil || base.Host == "" {
+		return nil
+	}
+
+	token, err := crlfToken()
+	if err != nil {
+		return nil
+	}
+	marker := "sf" + token
+
+	client := *vs.client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	for _, sequence := range crlfSequences {
+		payload := marker + sequence + crlfCanaryHeader + ":%20" + token
+
+		var query []string
+		for _, param := range crlfParams {
+			query = append(query, param+"="+payload)
+		}
+
+		probes := []struct {
+			point   string
+			url     string
+			headers map[string]string
+		}{
+			{"query parameters " + strings.Join(crlfParams, ", "), base.String() + "/?" + strings.Join(query, "&"), nil},
+			{"path", base.String() + "/" + payload, nil},
+			{"headers " + strings.Join(crlfHeaders, ", "), base.String() + "/", crlfHeaderValues(payload)},
+		}
+
+		for _, probe := range probes {
+			resp, ok := sendCRLFProbe(ctx, &client, probe.url, probe.headers)
+			if !ok || resp.Header.Get(crlfCanaryHeader) != token {
+				continue
+			}
+
+			return []Vulnerability{{
+				Name:        "HTTP Response Splitting",
+				Severity:    "High",
+				Description: fmt.Sprintf("Line breaks sent in the %s are written into the response headers, so an attacker can inject headers or split the response", probe.point),
+				Solution:    "Reject or encode CR and LF characters before copying input into response headers",
+				Evidence:    fmt.Sprintf("Injected %q via %s; reflected in %s, adding %s: %s", payload, probe.point, reflectingHeaders(resp.Header, marker), crlfCanaryHeader, token),
+				Confidence:  95,
+			}}
+		}
+	}
+
+	return nil
+}
+
+func crlfHeaderValues(payload string) map[string]string {
+	headers := make(map[string]string, len(crlfHeaders))
+	for _, name := range crlfHeaders {
+		headers[name] = payload
+	}
+	return headers
+}
+
+func sendCRLFProbe(ctx context.Context, client *http.Client, url string, headers map[string]string) (*http.Response, bool) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, false
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	return resp, true
+}
+
+// reflectingHeaders names the headers that carried the input up to the
+// injected line break.
+func reflectingHeaders(header http.Header, marker string) string {
+	var names []string
+	for name, values := range header {
+		for _, value := range values {
+			if strings.Contains(value, marker) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	if len(names) == 0 {
+		return "an unidentified header"
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func crlfToken() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
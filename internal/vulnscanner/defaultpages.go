@@ -0,0 +1,105 @@
+package vulnscanner
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+//go:embed defaultpages.json
+var defaultPageData []byte
+
+type DefaultPageSignature struct {
+	Type     string   `json:"type"`
+	Product  string   `json:"product"`
+	Titles   []string `json:"titles"`
+	Patterns []string `json:"patterns"`
+	// WeakPatterns, such as links any deployment may carry, only match a
+	// body that also contains one of Markers
+	WeakPatterns []string `json:"weak_patterns,omitempty"`
+	Markers      []string `json:"markers,omitempty"`
+}
+
+type DefaultPageDatabase struct {
+	signatures []DefaultPageSignature
+}
+
+func ParseDefaultPageDatabase(data []byte) (*DefaultPageDatabase, error) {
+	var signatures []DefaultPageSignature
+	if err := json.Unmarshal(data, &signatures); err != nil {
+		return nil, fmt.Errorf("failed to parse default page database: %w", err)
+	}
+
+	return &DefaultPageDatabase{signatures: signatures}, nil
+}
+
+func LoadDefaultPageDatabase(path string) (*DefaultPageDatabase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read default page database: %w", err)
+	}
+
+	return ParseDefaultPageDatabase(data)
+}
+
+func BuiltinDefaultPageDatabase() *DefaultPageDatabase {
+	db, err := ParseDefaultPageDatabase(defaultPageData)
+	if err != nil {
+		return &DefaultPageDatabase{}
+	}
+	return db
+}
+
+// Match returns the signature whose title (exact, case-insensitive) or body
+// pattern matches the page along with the evidence, or nil.
+func (db *DefaultPageDatabase) Match(title, body string) (*DefaultPageSignature, string) {
+	title = strings.ToLower(strings.TrimSpace(title))
+	body = strings.ToLower(body)
+
+	for i := range db.signatures {
+		signature := &db.signatures[i]
+		for _, candidate := range signature.Titles {
+			if title == candidate {
+				return signature, fmt.Sprintf("title %q", title)
+			}
+		}
+		for _, pattern := range signature.Patterns {
+			if strings.Contains(body, pattern) {
+				return signature, fmt.Sprintf("body contains %q", pattern)
+			}
+		}
+		if marker := firstContained(body, signature.Markers); marker != "" {
+			if pattern := firstContained(body, signature.WeakPatterns); pattern != "" {
+				return signature, fmt.Sprintf("body contains %q and %q", pattern, marker)
+			}
+		}
+	}
+	return nil, ""
+}
+
+func firstContained(body string, patterns []string) string {
+	for _, pattern := range patterns {
+		if strings.Contains(body, pattern) {
+			return pattern
+		}
+	}
+	return ""
+}
+
+func (db *DefaultPageDatabase) Check(title, body string) (string, *Vulnerability) {
+	signature, evidence := db.Match(title, body)
+	if signature == nil {
+		return "", nil
+	}
+
+	return signature.Type, &Vulnerability{
+		Name:        fmt.Sprintf("Default Page: %s", signature.Product),
+		Severity:    "Info",
+		Description: fmt.Sprintf("The server shows the %s default installation page, which suggests an unfinished or forgotten deployment", signature.Product),
+		Solution:    "Deploy the intended application, or decommission the server if it is no longer needed",
+		Evidence:    evidence,
+		Confidence:  90,
+	}
+}
@@ -0,0 +1,36 @@
+package vulnscanner
+
+import "testing"
+
+func TestDefaultPageMatch(t *testing.T) {
+	db := BuiltinDefaultPageDatabase()
+
+	tests := []struct {
+		name     string
+		title    string
+		body     string
+		expected string
+	}{
+		{"tomcat title", "Apache Tomcat", "", "tomcat-default"},
+		{"tomcat welcome text", "Home", "<p>If you're seeing this, you've successfully installed Tomcat.</p>", "tomcat-default"},
+		{"tomcat links with logo", "Home", `<img src="tomcat.svg"><a href="/docs/setup.html">Setup</a>`, "tomcat-default"},
+		{"tomcat links with name", "Home", `<a href="/examples/servlets/">Apache Tomcat examples</a>`, "tomcat-default"},
+		{"servlet examples link alone", "Shop", `<a href="/examples/servlets/">Servlet demos</a>`, ""},
+		{"setup docs link alone", "Wiki", `<a href="/docs/setup.html">Setting up the wiki</a>`, ""},
+		{"nginx", "Welcome to nginx!", "", "nginx-welcome"},
+		{"application", "Dashboard", "<h1>Dashboard</h1>", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature, _ := db.Match(tt.title, tt.body)
+			got := ""
+			if signature != nil {
+				got = signature.Type
+			}
+			if got != tt.expected {
+				t.Errorf("Match(%q) = %q, want %q", tt.title, got, tt.expected)
+			}
+		})
+	}
+}
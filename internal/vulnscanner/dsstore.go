@@ -0,0 +1,76 @@
+package vulnscanner
+
+import (
+	"encoding/binary"
+	"sort"
+	"unicode/utf16"
+)
+
+// dsStoreMagic opens every .DS_Store: a version of 1 followed by "Bud1".
+var dsStoreMagic = []byte{0, 0, 0, 1, 'B', 'u', 'd', '1'}
+
+// dsStoreDataTypes are the record value types defined by the format.
+var dsStoreDataTypes = map[string]bool{
+	"long": true, "shor": true, "bool": true, "blob": true,
+	"type": true, "ustr": true, "comp": true, "dutc": true,
+}
+
+func isDSStore(data []byte) bool {
+	return len(data) >= len(dsStoreMagic) && string(data[:len(dsStoreMagic)]) == string(dsStoreMagic)
+}
+
+// parseDSStore returns the sorted file names recorded in a .DS_Store.
+// Records are a UTF-16BE name prefixed by its length and followed by a
+// four-letter property code and data type; rather than walk the B-tree it
+// scans for that shape, which is enough to list the names Finder recorded.
+func parseDSStore(data []byte) []string {
+	if !isDSStore(data) {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for i := len(dsStoreMagic); i+4 <= len(data); i++ {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		if length == 0 || length > 1024 {
+			continue
+		}
+		end := i + 4 + length*2
+		if end+8 > len(data) || !isPropertyCode(data[end:end+4]) || !dsStoreDataTypes[string(data[end+4:end+8])] {
+			continue
+		}
+
+		units := make([]uint16, length)
+		for j := range units {
+			units[j] = binary.BigEndian.Uint16(data[i+4+j*2:])
+		}
+		if name := string(utf16.Decode(units)); name != "." && validFileName(name) {
+			seen[name] = true
+		}
+		i = end + 7
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isPropertyCode(code []byte) bool {
+	for _, c := range code {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func validFileName(name string) bool {
+	for _, r := range name {
+		if r < 0x20 || r == '/' || r == 0xfffd {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,219 @@
+package vulnscanner
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed eol.json
+var defaultEOLData []byte
+
+type EOLCycle struct {
+	Version string `json:"version"`
+	EOL     string `json:"eol"`
+}
+
+type EOLEntry struct {
+	Product         string               `json:"product"`
+	Names           []string             `json:"names"`
+	Severity        string               `json:"severity"`
+	MinVersion      string               `json:"min_version"`
+	Cycles          []EOLCycle           `json:"cycles"`
+	Vulnerabilities []KnownVulnerability `json:"vulnerabilities,omitempty"`
+}
+
+type EOLDatabase struct {
+	entries []EOLEntry
+}
+
+var (
+	productTokenRegex = regexp.MustCompile(`([A-Za-z][A-Za-z0-9!._-]*)/v?(\d+(?:\.\d+)*[a-z]?)`)
+	generatorRegex    = regexp.MustCompile(`(?i)<meta name="generator" content="([^"]+)"`)
+	productNameRegex  = regexp.MustCompile(`^\s*(.*?)[\s/]+v?(\d+(?:\.\d+)*)`)
+	distroTokenRegex  = regexp.MustCompile(`(?i)ubuntu|debian|\+deb\d|red ?hat|rhel|centos|fedora|suse|amazon|alpine|almalinux|rocky|\.el\d`)
+)
+
+func ParseEOLDatabase(data []byte) (*EOLDatabase, error) {
+	var entries []EOLEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse EOL database: %w", err)
+	}
+
+	return &EOLDatabase{entries: entries}, nil
+}
+
+func LoadEOLDatabase(path string) (*EOLDatabase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EOL database: %w", err)
+	}
+
+	return ParseEOLDatabase(data)
+}
+
+func DefaultEOLDatabase() *EOLDatabase {
+	db, err := ParseEOLDatabase(defaultEOLData)
+	if err != nil {
+		return &EOLDatabase{}
+	}
+	return db
+}
+
+// Check reports a release that is end-of-life, older than the entry's
+// minimum version or affected by known CVEs, with the CVEs, the highest
+// CVSS score and NVD references filled in.
+func (db *EOLDatabase) Check(product, version string, now time.Time) *Vulnerability {
+	return db.CheckBanner(product, version, "", now)
+}
+
+// CheckBanner is Check for a version read from banner, such as a Server
+// header. A banner naming a distribution package, like
+// "Apache/2.4.41 (Ubuntu)", may carry backported fixes, so its CVEs are
+// reported with low confidence and without raising the severity.
+func (db *EOLDatabase) CheckBanner(product, version, banner string, now time.Time) *Vulnerability {
+	entry := db.lookup(product)
+	if entry == nil || version == "" {
+		return nil
+	}
+	return entry.withKnownVulnerabilities(entry.lifecycle(version, now), version, DistroPackaged(banner))
+}
+
+// DistroPackaged reports whether a banner names a distribution build, whose
+// version stays at the upstream release while security fixes are backported.
+func DistroPackaged(banner string) bool {
+	return distroTokenRegex.MatchString(banner)
+}
+
+// lifecycle flags a version whose release cycle is end-of-life. The
+// minimum version only judges versions outside every listed cycle, so a
+// cycle that is still supported is never reported as outdated.
+func (entry *EOLEntry) lifecycle(version string, now time.Time) *Vulnerability {
+	for _, cycle := range entry.Cycles {
+		if !versionInCycle(version, cycle.Version) {
+			continue
+		}
+
+		eolDate, err := time.Parse("2006-01-02", cycle.EOL)
+		if err != nil || now.Before(eolDate) {
+			return nil
+		}
+
+		return &Vulnerability{
+			Name:        fmt.Sprintf("End-of-Life Software: %s %s", entry.Product, version),
+			Severity:    entry.Severity,
+			Description: fmt.Sprintf("%s %s is end-of-life and no longer receives security updates", entry.Product, cycle.Version),
+			Solution:    fmt.Sprintf("Upgrade %s to a supported release (%s or later)", entry.Product, entry.MinVersion),
+			Evidence:    fmt.Sprintf("%s %s reached end of life on %s", entry.Product, cycle.Version, cycle.EOL),
+			Confidence:  90,
+		}
+	}
+
+	if entry.MinVersion != "" && compareVersions(version, entry.MinVersion) < 0 {
+		return &Vulnerability{
+			Name:        fmt.Sprintf("Outdated Software: %s %s", entry.Product, version),
+			Severity:    entry.Severity,
+			Description: fmt.Sprintf("%s %s is older than the minimum supported version %s", entry.Product, version, entry.MinVersion),
+			Solution:    fmt.Sprintf("Upgrade %s to a supported release (%s or later)", entry.Product, entry.MinVersion),
+			Evidence:    fmt.Sprintf("%s %s is below minimum supported version %s", entry.Product, version, entry.MinVersion),
+			Confidence:  85,
+		}
+	}
+
+	return nil
+}
+
+func (db *EOLDatabase) lookup(product string) *EOLEntry {
+	product = strings.ToLower(strings.TrimSpace(product))
+	for i := range db.entries {
+		for _, name := range db.entries[i].Names {
+			if product == name {
+				return &db.entries[i]
+			}
+		}
+	}
+	return nil
+}
+
+// ParseProducts extracts product/version pairs from header values such as
+// "Apache/2.2.15 (Unix) PHP/5.6.40".
+func ParseProducts(header string) map[string]string {
+	products := make(map[string]string)
+	for _, match := range productTokenRegex.FindAllStringSubmatch(header, -1) {
+		products[strings.ToLower(match[1])] = match[2]
+	}
+	return products
+}
+
+func parseGenerator(body string) (string, string) {
+	matches := generatorRegex.FindStringSubmatch(body)
+	if len(matches) < 2 {
+		return "", ""
+	}
+
+	parts := productNameRegex.FindStringSubmatch(matches[1])
+	if len(parts) < 3 {
+		return "", ""
+	}
+	return parts[1], parts[2]
+}
+
+func versionInCycle(version, cycle string) bool {
+	versionParts := versionNumbers(version)
+	cycleParts := versionNumbers(cycle)
+	if len(versionParts) < len(cycleParts) {
+		return false
+	}
+
+	for i := range cycleParts {
+		if versionParts[i] != cycleParts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func compareVersions(a, b string) int {
+	aParts := versionNumbers(a)
+	bParts := versionNumbers(b)
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x = aParts[i]
+		}
+		if i < len(bParts) {
+			y = bParts[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionNumbers(version string) []int {
+	var numbers []int
+	for _, part := range strings.Split(version, ".") {
+		digits := strings.TrimRightFunc(part, func(r rune) bool { return r < '0' || r > '9' })
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			break
+		}
+		numbers = append(numbers, n)
+		// Letter releases such as OpenSSL 1.0.1f sort after 1.0.1
+		if suffix := part[len(digits):]; len(suffix) == 1 && suffix[0] >= 'a' && suffix[0] <= 'z' {
+			numbers = append(numbers, int(suffix[0]-'a')+1)
+			break
+		}
+	}
+	return numbers
+}
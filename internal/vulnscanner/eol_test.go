@@ -0,0 +1,94 @@
+package vulnscanner
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testEOLData = `[
+  {
+    "product": "Example Server",
+    "names": ["example"],
+    "severity": "High",
+    "min_version": "3.0",
+    "cycles": [
+      {"version": "1.0", "eol": "2010-01-01"},
+      {"version": "2.0", "eol": "2099-01-01"}
+    ]
+  }
+]`
+
+func TestEOLCheck(t *testing.T) {
+	db, err := ParseEOLDatabase([]byte(testEOLData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		product string
+		version string
+		want    string
+	}{
+		{"end-of-life cycle", "example", "1.0.5", "End-of-Life Software: Example Server 1.0.5"},
+		{"supported cycle below min_version", "example", "2.0.4", ""},
+		{"outside every cycle, below min_version", "example", "0.9", "Outdated Software: Example Server 0.9"},
+		{"current", "example", "3.2", ""},
+		{"unknown product", "other", "1.0", ""},
+		{"no version", "example", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vuln := db.Check(tt.product, tt.version, now)
+			got := ""
+			if vuln != nil {
+				got = vuln.Name
+			}
+			if got != tt.want {
+				t.Errorf("Check(%q, %q) = %q, want %q", tt.product, tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckOutdatedSoftwareReportsOnce(t *testing.T) {
+	db, err := ParseEOLDatabase([]byte(testEOLData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := &VulnScanner{eol: db}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		body    string
+		want    int
+	}{
+		{"server header", map[string]string{"Server": "example/1.0.5"}, "", 1},
+		{"server and powered-by", map[string]string{"Server": "example/1.0.5", "X-Powered-By": "example/1.0.5"}, "", 1},
+		{"header and generator", map[string]string{"Server": "example/1.0.5"}, `<meta name="generator" content="example 1.0.5">`, 1},
+		{"two versions", map[string]string{"Server": "example/1.0.5", "X-Powered-By": "example/0.9"}, "", 2},
+		{"supported", map[string]string{"Server": "example/2.0.4"}, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: make(http.Header)}
+			for key, value := range tt.headers {
+				resp.Header.Set(key, value)
+			}
+			vulns := scanner.checkOutdatedSoftware(tt.body, resp)
+			if len(vulns) != tt.want {
+				names := make([]string, 0, len(vulns))
+				for _, vuln := range vulns {
+					names = append(names, vuln.Name)
+				}
+				t.Errorf("got %d findings (%s), want %d", len(vulns), strings.Join(names, "; "), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package vulnscanner
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+type htmlForm struct {
+	action      string
+	hasPassword bool
+}
+
+// checkCleartextCredentials flags forms with a password input whose
+// submission target, resolved against the page URL, is plain HTTP.
+func (vs *VulnScanner) checkCleartextCredentials(pageURL *url.URL, body string) []Vulnerability {
+	var vulns []Vulnerability
+	if pageURL == nil {
+		return vulns
+	}
+
+	for _, form := range parseForms(body) {
+		if !form.hasPassword {
+			continue
+		}
+
+		target, err := pageURL.Parse(strings.TrimSpace(form.action))
+		if err != nil || !strings.EqualFold(target.Scheme, "http") {
+			continue
+		}
+
+		vulns = append(vulns, Vulnerability{
+			Name:        "Cleartext Credential Submission",
+			Severity:    "High",
+			Description: fmt.Sprintf("Login form on %s submits a password over unencrypted HTTP", pageURL.String()),
+			Solution:    "Serve the page over HTTPS and point the form action at an https:// URL",
+			Evidence:    fmt.Sprintf("form action=%q resolves to %s", form.action, target.String()),
+			Confidence:  90,
+		})
+	}
+
+	return vulns
+}
+
+func parseForms(body string) []htmlForm {
+	var forms []htmlForm
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	current := -1
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return forms
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "form":
+				forms = append(forms, htmlForm{action: attribute(token, "action")})
+				current = len(forms) - 1
+			case "input":
+				if current >= 0 && strings.EqualFold(strings.TrimSpace(attribute(token, "type")), "password") {
+					forms[current].hasPassword = true
+				}
+			}
+		case html.EndTagToken:
+			if tokenizer.Token().Data == "form" {
+				current = -1
+			}
+		}
+	}
+}
+
+func attribute(token html.Token, name string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
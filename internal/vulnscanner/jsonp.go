@@ -0,0 +1,124 @@
+package vulnscanner
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	neturl "net/url"
+	"strings"
+)
+
+// jsonpParams are the callback parameter names JSONP handlers usually read.
+// Both carry the same canary, so one request covers them.
+var jsonpParams = []string{"callback", "jsonp"}
+
+// CheckJSONP requests each endpoint that serves JSON again with a canary
+// callback and reports those that wrap the response in it. Endpoints that
+// do not return a JSON content type are skipped, and nothing is sent unless
+// the jsonp check is enabled.
+func (vs *VulnScanner) CheckJSONP(ctx context.Context, endpoints []string) []Vulnerability {
+	if !vs.checks[CheckJSONP] {
+		return nil
+	}
+
+	var vulns []Vulnerability
+	for _, endpoint := range endpoints {
+		resp, ok := vs.jsonpRequest(ctx, endpoint)
+		if !ok || !isJSONResponse(resp.Header) {
+			continue
+		}
+		if vuln := vs.checkJSONPCallback(ctx, endpoint); vuln != nil {
+			vulns = append(vulns, *vuln)
+		}
+	}
+	return vulns
+}
+
+func (vs *VulnScanner) checkJSONPCallback(ctx context.Context, endpoint string) *Vulnerability {
+	callback, err := jsonpCanary()
+	if err != nil {
+		return nil
+	}
+
+	probeURL, err := neturl.Parse(endpoint)
+	if err != nil {
+		return nil
+	}
+	query := probeURL.Query()
+	for _, param := range jsonpParams {
+		query.Set(param, callback)
+	}
+	probeURL.RawQuery = query.Encode()
+
+	resp, ok := vs.jsonpRequest(ctx, probeURL.String())
+	if !ok || !wrapsInCallback(resp.body, callback) {
+		return nil
+	}
+
+	return &Vulnerability{
+		Name:        "JSONP Endpoint",
+		Severity:    "Low",
+		Description: "Wraps its JSON response in a caller-chosen callback, so any site can read the data with a script tag, including data tied to the visitor's cookies",
+		Solution:    "Remove JSONP support and serve cross-origin data through CORS with an explicit origin allowlist",
+		Evidence:    fmt.Sprintf("%s wrapped the response in callback %s (Content-Type: %s)", endpoint, callback, orDash(resp.Header.Get("Content-Type"))),
+		Confidence:  90,
+	}
+}
+
+type jsonpResponse struct {
+	Header http.Header
+	body   string
+}
+
+func (vs *VulnScanner) jsonpRequest(ctx context.Context, url string) (*jsonpResponse, bool) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, false
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+
+	resp, err := vs.client.Do(req)
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false
+	}
+	// The callback, if any, opens the body
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return nil, false
+	}
+	return &jsonpResponse{Header: resp.Header, body: string(body)}, true
+}
+
+func isJSONResponse(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// wrapsInCallback matches callback(...) at the start of body, allowing the
+// /**/ prefix and typeof guard that common JSONP libraries emit.
+func wrapsInCallback(body, callback string) bool {
+	body = strings.TrimSpace(body)
+	body = strings.TrimSpace(strings.TrimPrefix(body, "/**/"))
+	if strings.HasPrefix(body, "typeof ") {
+		_, body, _ = strings.Cut(body, "&&")
+		body = strings.TrimSpace(body)
+	}
+	return strings.HasPrefix(body, callback+"(")
+}
+
+func jsonpCanary() (string, error) {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "sfjsonp" + hex.EncodeToString(buf), nil
+}
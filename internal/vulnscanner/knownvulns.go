@@ -0,0 +1,115 @@
+package vulnscanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"subdomain-finder/internal/types"
+)
+
+// KnownVulnerability is a published vulnerability in some releases of an
+// EOLEntry's product.
+type KnownVulnerability struct {
+	CVE      string         `json:"cve"`
+	CVSS     float64        `json:"cvss"`
+	Summary  string         `json:"summary"`
+	Affected []VersionRange `json:"affected"`
+}
+
+// VersionRange covers the releases from Introduced up to, but not
+// including, Fixed.
+type VersionRange struct {
+	Introduced string `json:"introduced"`
+	Fixed      string `json:"fixed"`
+}
+
+// contains reports whether version is in the range. A version less precise
+// than Fixed, such as "7" against "7.58", cannot be placed and never is.
+func (r VersionRange) contains(version string) bool {
+	if strings.Count(version, ".") < strings.Count(r.Fixed, ".") {
+		return false
+	}
+	return compareVersions(version, r.Introduced) >= 0 && compareVersions(version, r.Fixed) < 0
+}
+
+// knownVulnerabilities returns the vulnerabilities affecting version, most
+// severe first, and the release that fixes all of them.
+func (entry *EOLEntry) knownVulnerabilities(version string) ([]KnownVulnerability, string) {
+	var known []KnownVulnerability
+	fixed := ""
+	for _, vuln := range entry.Vulnerabilities {
+		for _, affected := range vuln.Affected {
+			if affected.contains(version) {
+				known = append(known, vuln)
+				if fixed == "" || compareVersions(affected.Fixed, fixed) > 0 {
+					fixed = affected.Fixed
+				}
+				break
+			}
+		}
+	}
+	sort.SliceStable(known, func(i, j int) bool { return known[i].CVSS > known[j].CVSS })
+	return known, fixed
+}
+
+// withKnownVulnerabilities adds the CVEs affecting version to vuln, raising
+// its severity to match the worst of them. A supported release with CVEs is
+// reported on its own. A distribution build may have backported the fixes,
+// so its CVEs never raise the severity and on their own make a low
+// confidence Info finding.
+func (entry *EOLEntry) withKnownVulnerabilities(vuln *Vulnerability, version string, backported bool) *Vulnerability {
+	known, fixed := entry.knownVulnerabilities(version)
+	if len(known) == 0 {
+		return vuln
+	}
+
+	if vuln == nil {
+		summaries := make([]string, 0, len(known))
+		for _, k := range known {
+			summaries = append(summaries, k.Summary)
+		}
+		vuln = &Vulnerability{
+			Name:        fmt.Sprintf("Known Vulnerable Software: %s %s", entry.Product, version),
+			Description: fmt.Sprintf("%s %s is affected by published vulnerabilities: %s", entry.Product, version, strings.Join(summaries, "; ")),
+			Solution:    fmt.Sprintf("Upgrade %s to %s or later", entry.Product, fixed),
+			Evidence:    fmt.Sprintf("%s %s is older than %s", entry.Product, version, fixed),
+			Confidence:  85,
+		}
+		if backported {
+			vuln.Severity = "Info"
+			vuln.Confidence = 40
+		}
+	}
+
+	ids := make([]string, 0, len(known))
+	for _, k := range known {
+		ids = append(ids, k.CVE)
+		vuln.References = append(vuln.References, "https://nvd.nist.gov/vuln/detail/"+k.CVE)
+	}
+	vuln.CVE = strings.Join(ids, ", ")
+	vuln.CVSS = fmt.Sprintf("%.1f", known[0].CVSS)
+
+	if backported {
+		vuln.Description += ". The banner names a distribution package, which may have backported the fixes"
+		return vuln
+	}
+	if severity := cvssSeverity(known[0].CVSS); types.SeverityRank(severity) > types.SeverityRank(vuln.Severity) {
+		vuln.Severity = severity
+	}
+	return vuln
+}
+
+// cvssSeverity is the CVSS v3 qualitative rating of a score.
+func cvssSeverity(score float64) string {
+	switch {
+	case score >= 9:
+		return "Critical"
+	case score >= 7:
+		return "High"
+	case score >= 4:
+		return "Medium"
+	default:
+		return "Low"
+	}
+}
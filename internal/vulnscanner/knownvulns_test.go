@@ -0,0 +1,66 @@
+package vulnscanner
+
+import (
+	"testing"
+	"time"
+)
+
+const testKnownVulnData = `[
+  {
+    "product": "Example Server",
+    "names": ["example"],
+    "severity": "Medium",
+    "cycles": [
+      {"version": "1.0", "eol": "2010-01-01"},
+      {"version": "2.0", "eol": "2099-01-01"}
+    ],
+    "vulnerabilities": [
+      {
+        "cve": "CVE-2020-0001",
+        "cvss": 9.8,
+        "summary": "remote code execution",
+        "affected": [{"introduced": "1.0.0", "fixed": "2.0.5"}]
+      }
+    ]
+  }
+]`
+
+func TestCheckBannerDistroPackages(t *testing.T) {
+	db, err := ParseEOLDatabase([]byte(testKnownVulnData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		version        string
+		banner         string
+		wantName       string
+		wantSeverity   string
+		wantConfidence int
+	}{
+		{"upstream build", "2.0.4", "Example/2.0.4", "Known Vulnerable Software: Example Server 2.0.4", "Critical", 85},
+		{"ubuntu build", "2.0.4", "Example/2.0.4 (Ubuntu)", "Known Vulnerable Software: Example Server 2.0.4", "Info", 40},
+		{"red hat build", "2.0.4", "Example/2.0.4 (Red Hat Enterprise Linux)", "Known Vulnerable Software: Example Server 2.0.4", "Info", 40},
+		{"debian package suffix", "2.0.4", "Example/2.0.4-1+deb11u2", "Known Vulnerable Software: Example Server 2.0.4", "Info", 40},
+		{"end-of-life upstream", "1.0.2", "Example/1.0.2", "End-of-Life Software: Example Server 1.0.2", "Critical", 90},
+		{"end-of-life distro build", "1.0.2", "Example/1.0.2 (CentOS)", "End-of-Life Software: Example Server 1.0.2", "Medium", 90},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vuln := db.CheckBanner("example", tt.version, tt.banner, now)
+			if vuln == nil {
+				t.Fatalf("CheckBanner(%q) = nil, want %q", tt.banner, tt.wantName)
+			}
+			if vuln.Name != tt.wantName || vuln.Severity != tt.wantSeverity || vuln.Confidence != tt.wantConfidence {
+				t.Errorf("CheckBanner(%q) = %q %s (%d), want %q %s (%d)", tt.banner,
+					vuln.Name, vuln.Severity, vuln.Confidence, tt.wantName, tt.wantSeverity, tt.wantConfidence)
+			}
+			if vuln.CVE != "CVE-2020-0001" {
+				t.Errorf("CVE = %q, want CVE-2020-0001", vuln.CVE)
+			}
+		})
+	}
+}
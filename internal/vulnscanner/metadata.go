@@ -0,0 +1,124 @@
+package vulnscanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// metadataFile is a file that leaks directory structure or server
+// configuration. match checks the content signature so soft-404 pages
+// served with a 200 are not reported, and returns the evidence.
+type metadataFile struct {
+	path     string
+	name     string
+	severity string
+	match    func(body string) (string, bool)
+}
+
+// Metadata files probed when content discovery is enabled
+var metadataFiles = []metadataFile{
+	{"/.DS_Store", ".DS_Store", "Medium", matchDSStore},
+	{"/.git/config", "Git Repository Configuration", "High", matchGitConfig},
+	{"/.svn/wc.db", "Subversion Working Copy Database", "High", matchSVNDatabase},
+	{"/.htaccess", ".htaccess", "Medium", matchHtaccess},
+	{"/web.config", "web.config", "Medium", matchWebConfig},
+}
+
+func (vs *VulnScanner) checkMetadataFiles(ctx context.Context, url string) []Vulnerability {
+	var vulns []Vulnerability
+	baseURL := strings.TrimSuffix(url, "/")
+
+	for _, file := range metadataFiles {
+		body, ok := vs.fetchProbe(ctx, baseURL+file.path)
+		if !ok {
+			continue
+		}
+
+		evidence, ok := file.match(body)
+		if !ok {
+			continue
+		}
+		vulns = append(vulns, Vulnerability{
+			Name:        "Exposed " + file.name,
+			Severity:    file.severity,
+			Description: fmt.Sprintf("%s is publicly readable at %s and discloses directory structure or configuration", file.name, file.path),
+			Solution:    "Remove the file from the web root or deny access to it in the server configuration",
+			Evidence:    fmt.Sprintf("%s at %s", evidence, baseURL+file.path),
+			Confidence:  95,
+		})
+	}
+
+	return vulns
+}
+
+func matchDSStore(body string) (string, bool) {
+	if !isDSStore([]byte(body)) {
+		return "", false
+	}
+	names := parseDSStore([]byte(body))
+	if len(names) == 0 {
+		return ".DS_Store file", true
+	}
+	return fmt.Sprintf("Reveals %d entries: %s", len(names), truncateList(names, 20)), true
+}
+
+func matchGitConfig(body string) (string, bool) {
+	if !strings.Contains(body, "[core]") || !strings.Contains(body, "repositoryformatversion") {
+		return "", false
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok && strings.TrimSpace(key) == "url" {
+			return "Git config with remote " + strings.TrimSpace(value), true
+		}
+	}
+	return "Git config with [core] section", true
+}
+
+func matchSVNDatabase(body string) (string, bool) {
+	if !strings.HasPrefix(body, "SQLite format 3\x00") {
+		return "", false
+	}
+	return "SQLite working copy database", true
+}
+
+var htaccessDirectives = []string{
+	"RewriteEngine", "RewriteRule", "RewriteCond", "AuthType", "AuthUserFile",
+	"Require ", "Deny from", "Allow from", "Options ", "<IfModule", "ErrorDocument",
+}
+
+func matchHtaccess(body string) (string, bool) {
+	if strings.Contains(strings.ToLower(body), "<html") {
+		return "", false
+	}
+
+	var found []string
+	for _, directive := range htaccessDirectives {
+		if strings.Contains(body, directive) {
+			found = append(found, strings.TrimSpace(directive))
+		}
+	}
+	if len(found) == 0 {
+		return "", false
+	}
+	return "Apache directives: " + strings.Join(found, ", "), true
+}
+
+func matchWebConfig(body string) (string, bool) {
+	if !strings.Contains(body, "<configuration") {
+		return "", false
+	}
+	for _, section := range []string{"<system.webServer", "<system.web", "<appSettings", "<connectionStrings"} {
+		if strings.Contains(body, section) {
+			return "IIS configuration with " + strings.TrimPrefix(section, "<") + " section", true
+		}
+	}
+	return "", false
+}
+
+func truncateList(values []string, max int) string {
+	if len(values) <= max {
+		return strings.Join(values, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(values[:max], ", "), len(values)-max)
+}
@@ -0,0 +1,223 @@
+package vulnscanner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// SetSafeMode stops ScanURL from sending attack payloads: the traversal,
+// SQL injection and XSS probes and the active checks are skipped, leaving
+// checks that only observe responses. It is on by default.
+func (vs *VulnScanner) SetSafeMode(enabled bool) {
+	vs.safeMode = enabled
+}
+
+// SetActiveScope limits attack payloads to hosts matching an entry, such as
+// "app.example.com" or "*.example.com". An empty scope allows any host.
+func (vs *VulnScanner) SetActiveScope(entries []string) {
+	vs.activeScope = entries
+}
+
+// ParseActiveScope checks and lowercases active scope entries. Paths are
+// refused: the probes are sent to each host's base URL, so a path prefix
+// could never match them.
+func ParseActiveScope(entries []string) ([]string, error) {
+	var scope []string
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if strings.ContainsAny(entry, "/:") {
+			return nil, fmt.Errorf("%q is not a host; the scope lists hosts such as app.example.com or *.example.com, not URLs or paths", entry)
+		}
+		if host, ok := strings.CutPrefix(entry, "*."); strings.Contains(host, "*") || (ok && host == "") {
+			return nil, fmt.Errorf("%q is not a host; only a leading *. wildcard is supported", entry)
+		}
+		scope = append(scope, entry)
+	}
+	return scope, nil
+}
+
+// sendsAttacks reports whether any enabled check sends attack payloads.
+func (vs *VulnScanner) sendsAttacks() bool {
+	if vs.safeMode {
+		return false
+	}
+	for _, check := range AttackChecks {
+		if vs.checks[check] {
+			return true
+		}
+	}
+	return vs.activeChecks
+}
+
+// attacksAllowed reports whether attack payloads may be sent to url: safe
+// mode is off, its host is in the active scope and robots.txt does not
+// disallow its path.
+func (vs *VulnScanner) attacksAllowed(ctx context.Context, url string) bool {
+	if !vs.sendsAttacks() {
+		return false
+	}
+	parsed, err := neturl.Parse(url)
+	if err != nil || !vs.inActiveScope(parsed) {
+		return false
+	}
+	return vs.robots.allows(ctx, vs.client, parsed)
+}
+
+func (vs *VulnScanner) inActiveScope(url *neturl.URL) bool {
+	if len(vs.activeScope) == 0 {
+		return true
+	}
+
+	host := strings.ToLower(url.Hostname())
+	for _, entry := range vs.activeScope {
+		entry = strings.ToLower(entry)
+		if suffix, ok := strings.CutPrefix(entry, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// maxRobotsSize bounds how much of a robots.txt file is read.
+const maxRobotsSize = 512 << 10
+
+// robotsCache fetches robots.txt once per origin.
+type robotsCache struct {
+	mu    sync.Mutex
+	rules map[string]*robotsRules
+}
+
+func (c *robotsCache) allows(ctx context.Context, client *http.Client, url *neturl.URL) bool {
+	origin := url.Scheme + "://" + url.Host
+
+	c.mu.Lock()
+	rules, ok := c.rules[origin]
+	c.mu.Unlock()
+	if !ok {
+		rules = fetchRobots(ctx, client, origin)
+		c.mu.Lock()
+		if c.rules == nil {
+			c.rules = make(map[string]*robotsRules)
+		}
+		c.rules[origin] = rules
+		c.mu.Unlock()
+	}
+
+	path := url.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return rules.allows(path)
+}
+
+// fetchRobots loads the rules of an origin. A missing or unreadable file
+// allows everything.
+func fetchRobots(ctx context.Context, client *http.Client, origin string) *robotsRules {
+	req, err := http.NewRequestWithContext(ctx, "GET", origin+"/robots.txt", nil)
+	if err != nil {
+		return &robotsRules{}
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return &robotsRules{}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &robotsRules{}
+	}
+	return parseRobots(io.LimitReader(resp.Body, maxRobotsSize))
+}
+
+type robotsRule struct {
+	pattern *regexp.Regexp
+	length  int
+	allow   bool
+}
+
+// robotsRules are the Allow and Disallow lines of the groups for any user
+// agent ("*").
+type robotsRules struct {
+	rules []robotsRule
+}
+
+func parseRobots(r io.Reader) *robotsRules {
+	parsed := &robotsRules{}
+	applies, inAgents := false, false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share one group
+			if !inAgents {
+				applies = false
+			}
+			inAgents = true
+			if value == "*" {
+				applies = true
+			}
+		case "allow", "disallow":
+			inAgents = false
+			if applies && value != "" {
+				parsed.rules = append(parsed.rules, robotsRule{
+					pattern: robotsPattern(value),
+					length:  len(value),
+					allow:   key == "allow",
+				})
+			}
+		default:
+			inAgents = false
+		}
+	}
+	return parsed
+}
+
+// robotsPattern turns a path rule, which may use * and a trailing $, into
+// an anchored regular expression.
+func robotsPattern(rule string) *regexp.Regexp {
+	anchored := strings.HasSuffix(rule, "$")
+	rule = strings.TrimSuffix(rule, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(rule), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allows applies the longest matching rule, with Allow winning ties.
+func (r *robotsRules) allows(path string) bool {
+	allowed, longest := true, -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > longest || (rule.length == longest && rule.allow) {
+			allowed, longest = rule.allow, rule.length
+		}
+	}
+	return allowed
+}
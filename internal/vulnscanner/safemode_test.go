@@ -0,0 +1,131 @@
+package vulnscanner
+
+import (
+	neturl "net/url"
+	"strings"
+	"testing"
+)
+
+func TestInActiveScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope []string
+		url   string
+		want  bool
+	}{
+		{"empty scope", nil, "https://www.example.com/", true},
+		{"exact host", []string{"app.example.com"}, "https://app.example.com/", true},
+		{"host case", []string{"App.Example.com"}, "https://APP.example.com/", true},
+		{"host with port", []string{"app.example.com"}, "http://app.example.com:8080/", true},
+		{"other host", []string{"app.example.com"}, "https://www.example.com/", false},
+		{"exact host is not a suffix", []string{"example.com"}, "https://app.example.com/", false},
+		{"wildcard subdomain", []string{"*.staging.example.com"}, "https://api.staging.example.com/", true},
+		{"wildcard excludes its base", []string{"*.staging.example.com"}, "https://staging.example.com/", false},
+		{"wildcard needs a dot boundary", []string{"*.example.com"}, "https://www.badexample.com/", false},
+		{"any entry", []string{"app.example.com", "*.dev.example.com"}, "https://x.dev.example.com/", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs := &VulnScanner{activeScope: tt.scope}
+			url, err := neturl.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := vs.inActiveScope(url); got != tt.want {
+				t.Errorf("inActiveScope(%q) with %v = %v, want %v", tt.url, tt.scope, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseActiveScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    []string
+		wantErr bool
+	}{
+		{"hosts", []string{" App.example.com ", "*.staging.example.com", ""}, []string{"app.example.com", "*.staging.example.com"}, false},
+		{"path", []string{"www.example.com/search"}, nil, true},
+		{"url", []string{"https://www.example.com"}, nil, true},
+		{"inner wildcard", []string{"app.*.example.com"}, nil, true},
+		{"bare wildcard", []string{"*."}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseActiveScope(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseActiveScope(%q) error = %v, want error %v", tt.entries, err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ParseActiveScope(%q) = %q, want %q", tt.entries, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	const robots = `# comment
+User-agent: Googlebot
+Disallow: /
+
+User-agent: other
+User-agent: *
+Disallow: /admin   # trailing comment
+Allow: /admin/public
+Disallow: /*.php$
+Disallow:
+
+User-agent: Bingbot
+Disallow: /search
+`
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		{"/search", true},
+		{"/admin", false},
+		{"/admin/users", false},
+		{"/admin/public", true},
+		{"/admin/public/logo.png", true},
+		{"/index.php", false},
+		{"/index.php?id=1", true},
+		{"/docs/page.php", false},
+	}
+
+	rules := parseRobots(strings.NewReader(robots))
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := rules.allows(tt.path); got != tt.want {
+				t.Errorf("allows(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRobotsLongestRuleWins(t *testing.T) {
+	tests := []struct {
+		name   string
+		robots string
+		path   string
+		want   bool
+	}{
+		{"longer disallow", "User-agent: *\nAllow: /a\nDisallow: /a/b\n", "/a/b/c", false},
+		{"longer allow", "User-agent: *\nDisallow: /a\nAllow: /a/b\n", "/a/b/c", true},
+		{"allow wins a tie", "User-agent: *\nDisallow: /a\nAllow: /a\n", "/a", true},
+		{"no group for any agent", "User-agent: Googlebot\nDisallow: /\n", "/", true},
+		{"empty file", "", "/", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRobots(strings.NewReader(tt.robots)).allows(tt.path); got != tt.want {
+				t.Errorf("allows(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,16 +1,31 @@
 package vulnscanner
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"sort"
 	"strings"
 	"time"
+
+	httpclient "subdomain-finder/internal/http"
+	"subdomain-finder/internal/securitytxt"
 )
 
 type VulnScanner struct {
-	client  *http.Client
-	timeout time.Duration
+	client           *http.Client
+	timeout          time.Duration
+	eol              *EOLDatabase
+	defaultPages     *DefaultPageDatabase
+	contentDiscovery bool
+	activeChecks     bool
+	checks           map[Check]bool
+	signatures       []Signature
+	safeMode         bool
+	activeScope      []string
+	robots           robotsCache
 }
 
 type VulnCheck struct {
@@ -32,17 +47,115 @@ type Vulnerability struct {
 	Confidence  int      `json:"confidence"`
 }
 
+// Directory traversal test patterns
+var traversalPatterns = []string{
+	"../",
+	"..\\",
+	"....//",
+	"....\\\\",
+	"%2e%2e%2f",
+	"%2e%2e%5c",
+}
+
+// SQL injection test patterns
+var sqlInjectionPatterns = []string{
+	"' OR '1'='1",
+	"' UNION SELECT NULL--",
+	"'; DROP TABLE users--",
+	"' OR 1=1--",
+	"admin'--",
+	"admin'/*",
+}
+
+// XSS test patterns
+var xssPatterns = []string{
+	"<script>alert('XSS')</script>",
+	"<img src=x onerror=alert('XSS')>",
+	"javascript:alert('XSS')",
+	"<svg onload=alert('XSS')>",
+	"<iframe src=javascript:alert('XSS')>",
+}
+
 func NewVulnScanner(timeout time.Duration) *VulnScanner {
-	return &VulnScanner{
-		client: &http.Client{
-			Timeout: timeout,
-		},
-		timeout: timeout,
+	return NewVulnScannerWithClient(&http.Client{
+		Timeout:   timeout,
+		Transport: httpclient.NewDecodingTransport(nil),
+	})
+}
+
+// NewVulnScannerWithClient returns a scanner that sends every probe through
+// client, sharing its transport, cookie jar and timeout.
+func NewVulnScannerWithClient(client *http.Client) *VulnScanner {
+	vs := &VulnScanner{
+		client:       client,
+		timeout:      client.Timeout,
+		eol:          DefaultEOLDatabase(),
+		defaultPages: BuiltinDefaultPageDatabase(),
+		safeMode:     true,
 	}
+	vs.SetChecks(AllChecks)
+	return vs
+}
+
+func (vs *VulnScanner) SetTransport(transport http.RoundTripper) {
+	vs.client.Transport = transport
+}
+
+func (vs *VulnScanner) SetCookieJar(jar http.CookieJar) {
+	vs.client.Jar = jar
+}
+
+// SetContentDiscovery enables probes for well-known paths such as API
+// documentation, which cost extra requests per host.
+func (vs *VulnScanner) SetContentDiscovery(enabled bool) {
+	vs.contentDiscovery = enabled
+}
+
+// SetActiveChecks enables probes that send attack payloads, such as CRLF
+// sequences, rather than only observing responses. They only run with safe
+// mode off.
+func (vs *VulnScanner) SetActiveChecks(enabled bool) {
+	vs.activeChecks = enabled
+}
+
+// SetChecks limits ScanURL to the given categories. All of them run by
+// default; an empty list runs none, leaving only content discovery and
+// active checks when those are enabled.
+func (vs *VulnScanner) SetChecks(checks []Check) {
+	vs.checks = make(map[Check]bool, len(checks))
+	for _, check := range checks {
+		vs.checks[check] = true
+	}
+}
+
+func (vs *VulnScanner) SetEOLDatabase(db *EOLDatabase) {
+	vs.eol = db
+}
+
+func (vs *VulnScanner) SetDefaultPageDatabase(db *DefaultPageDatabase) {
+	vs.defaultPages = db
+}
+
+// CheckDefaultPage reports the default installation page type served by a
+// host, if any, with an informational finding.
+func (vs *VulnScanner) CheckDefaultPage(title, body string) (string, *Vulnerability) {
+	return vs.defaultPages.Check(title, body)
+}
+
+// CheckTechnology checks a detected technology version; banner is the
+// header it was likely read from, see EOLDatabase.CheckBanner.
+func (vs *VulnScanner) CheckTechnology(name, version, banner string) *Vulnerability {
+	return vs.eol.CheckBanner(name, version, banner, time.Now())
 }
 
 func (vs *VulnScanner) ScanURL(url string) ([]Vulnerability, error) {
-	req, err := http.NewRequest("GET", url, nil)
+	return vs.ScanURLContext(context.Background(), url)
+}
+
+// ScanURLContext is ScanURL with every probe request bound to ctx, so a
+// cancelled scan stops between probes instead of running them all.
+func (vs *VulnScanner) ScanURLContext(ctx context.Context, url string) ([]Vulnerability, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -61,38 +174,137 @@ func (vs *VulnScanner) ScanURL(url string) ([]Vulnerability, error) {
 	}
 
 	var vulnerabilities []Vulnerability
+	attacks := vs.attacksAllowed(ctx, url)
 
 	// HTTP Security Headers Check
-	vulns := vs.checkSecurityHeaders(resp)
-	vulnerabilities = append(vulnerabilities, vulns...)
+	if vs.checks[CheckHeaders] {
+		vulns := vs.checkSecurityHeaders(resp)
+		vulnerabilities = append(vulnerabilities, vulns...)
+	}
+
+	// Server Information Disclosure, End-of-Life Software and
+	// Information Disclosure
+	if vs.checks[CheckDisclosure] {
+		vulns := vs.checkServerInfo(resp)
+		vulnerabilities = append(vulnerabilities, vulns...)
+
+		vulns = vs.checkOutdatedSoftware(string(body), resp)
+		vulnerabilities = append(vulnerabilities, vulns...)
 
-	// Server Information Disclosure
-	vulns = vs.checkServerInfo(resp)
-	vulnerabilities = append(vulnerabilities, vulns...)
+		vulns = vs.checkInformationDisclosure(string(body), resp)
+		vulnerabilities = append(vulnerabilities, vulns...)
+	}
 
 	// Directory Traversal
-	vulns = vs.checkDirectoryTraversal(url, resp)
-	vulnerabilities = append(vulnerabilities, vulns...)
+	if vs.checks[CheckTraversal] && attacks {
+		vulns := vs.checkDirectoryTraversal(ctx, url, resp)
+		vulnerabilities = append(vulnerabilities, vulns...)
+	}
 
 	// SQL Injection
-	vulns = vs.checkSQLInjection(url, resp)
-	vulnerabilities = append(vulnerabilities, vulns...)
+	if vs.checks[CheckSQLi] && attacks {
+		vulns := vs.checkSQLInjection(ctx, url, resp)
+		vulnerabilities = append(vulnerabilities, vulns...)
+	}
 
 	// XSS
-	vulns = vs.checkXSS(url, resp)
-	vulnerabilities = append(vulnerabilities, vulns...)
+	if vs.checks[CheckXSS] && attacks {
+		vulns := vs.checkXSS(ctx, url, resp)
+		vulnerabilities = append(vulnerabilities, vulns...)
+	}
 
-	// Information Disclosure
-	vulns = vs.checkInformationDisclosure(string(body), resp)
-	vulnerabilities = append(vulnerabilities, vulns...)
+	// Cleartext Credentials
+	if vs.checks[CheckForms] {
+		vulns := vs.checkCleartextCredentials(resp.Request.URL, string(body))
+		vulnerabilities = append(vulnerabilities, vulns...)
+	}
 
 	// SSL/TLS Issues
-	vulns = vs.checkSSLIssues(url, resp)
-	vulnerabilities = append(vulnerabilities, vulns...)
+	if vs.checks[CheckSSL] {
+		vulns := vs.checkSSLIssues(ctx, url, resp)
+		vulnerabilities = append(vulnerabilities, vulns...)
+	}
+
+	// CORS Preflight
+	if vs.checks[CheckCORS] {
+		vulns := vs.checkCORSPreflight(ctx, url)
+		vulnerabilities = append(vulnerabilities, vulns...)
+	}
+
+	// security.txt
+	if vs.checks[CheckSecurityTxt] {
+		vulns := vs.checkSecurityTxt(ctx, url)
+		vulnerabilities = append(vulnerabilities, vulns...)
+	}
+
+	// JSONP
+	if vs.checks[CheckJSONP] && isJSONResponse(resp.Header) {
+		if vuln := vs.checkJSONPCallback(ctx, url); vuln != nil {
+			vulnerabilities = append(vulnerabilities, *vuln)
+		}
+	}
+
+	// Custom Signatures
+	if len(vs.signatures) > 0 {
+		vulns := vs.checkSignatures(resp, string(body))
+		vulnerabilities = append(vulnerabilities, vulns...)
+	}
+
+	// Content Discovery
+	if vs.contentDiscovery {
+		vulns := vs.checkAPIDocumentation(ctx, url)
+		vulnerabilities = append(vulnerabilities, vulns...)
+
+		vulns = vs.checkMetadataFiles(ctx, url)
+		vulnerabilities = append(vulnerabilities, vulns...)
+	}
+
+	// Active Checks
+	if vs.activeChecks && attacks {
+		vulns := vs.checkCRLFInjection(ctx, url)
+		vulnerabilities = append(vulnerabilities, vulns...)
+	}
 
 	return vulnerabilities, nil
 }
 
+func (vs *VulnScanner) RequestsPerURL() int {
+	// Initial fetch, plus mixed content re-fetch, JSONP callback, robots.txt
+	// and one request per probe for the enabled checks
+	requests := 1
+	if vs.checks[CheckSSL] {
+		requests++
+	}
+	if vs.checks[CheckJSONP] {
+		requests++
+	}
+	if vs.sendsAttacks() {
+		requests++
+	}
+	if vs.checks[CheckTraversal] && !vs.safeMode {
+		requests += len(traversalPatterns)
+	}
+	if vs.checks[CheckSQLi] && !vs.safeMode {
+		requests += len(sqlInjectionPatterns)
+	}
+	if vs.checks[CheckXSS] && !vs.safeMode {
+		requests += len(xssPatterns)
+	}
+	if vs.checks[CheckSecurityTxt] {
+		requests += len(securitytxt.Paths)
+	}
+	if vs.checks[CheckCORS] {
+		requests += len(corsProbeOrigins(""))
+	}
+	if vs.contentDiscovery {
+		requests += len(apiDocPaths) + len(metadataFiles)
+	}
+	if vs.activeChecks && !vs.safeMode {
+		requests += len(crlfSequences) * crlfInjectionPoints
+	}
+	return requests
+}
+
 func (vs *VulnScanner) checkSecurityHeaders(resp *http.Response) []Vulnerability {
 	var vulns []Vulnerability
 
@@ -150,16 +362,6 @@ func (vs *VulnScanner) checkServerInfo(resp *http.Response) []Vulnerability {
 			})
 		}
 
-		// Outdated server versions
-		if strings.Contains(server, "Apache/2.2") || strings.Contains(server, "Apache/2.0") {
-			vulns = append(vulns, Vulnerability{
-				Name:        "Outdated Apache Version",
-				Severity:    "High",
-				Description: fmt.Sprintf("Outdated Apache version: %s", server),
-				Solution:    "Update Apache to latest version",
-				Confidence:  90,
-			})
-		}
 	}
 
 	// X-Powered-By disclosure
@@ -176,22 +378,40 @@ func (vs *VulnScanner) checkServerInfo(resp *http.Response) []Vulnerability {
 	return vulns
 }
 
-func (vs *VulnScanner) checkDirectoryTraversal(url string, resp *http.Response) []Vulnerability {
+// checkOutdatedSoftware checks the products named in the headers and the
+// generator tag, reporting each product and version once however many
+// places name it.
+func (vs *VulnScanner) checkOutdatedSoftware(body string, resp *http.Response) []Vulnerability {
 	var vulns []Vulnerability
+	seen := make(map[string]bool)
+	now := time.Now()
+	check := func(product, version, banner string) {
+		if vuln := vs.eol.CheckBanner(product, version, banner, now); vuln != nil && !seen[vuln.Name] {
+			seen[vuln.Name] = true
+			vulns = append(vulns, *vuln)
+		}
+	}
 
-	// Check for directory traversal patterns
-	patterns := []string{
-		"../",
-		"..\\",
-		"....//",
-		"....\\\\",
-		"%2e%2e%2f",
-		"%2e%2e%5c",
+	for _, header := range []string{"Server", "X-Powered-By"} {
+		banner := resp.Header.Get(header)
+		for product, version := range ParseProducts(banner) {
+			check(product, version, banner)
+		}
 	}
 
-	for _, pattern := range patterns {
+	if product, version := parseGenerator(body); product != "" {
+		check(product, version, "")
+	}
+
+	return vulns
+}
+
+func (vs *VulnScanner) checkDirectoryTraversal(ctx context.Context, url string, resp *http.Response) []Vulnerability {
+	var vulns []Vulnerability
+
+	for _, pattern := range traversalPatterns {
 		testURL := url + "/" + pattern + "etc/passwd"
-		req, _ := http.NewRequest("GET", testURL, nil)
+		req, _ := http.NewRequestWithContext(ctx, "GET", testURL, nil)
 		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 
 		testResp, err := vs.client.Do(req)
@@ -215,22 +435,12 @@ func (vs *VulnScanner) checkDirectoryTraversal(url string, resp *http.Response)
 	return vulns
 }
 
-func (vs *VulnScanner) checkSQLInjection(url string, resp *http.Response) []Vulnerability {
+func (vs *VulnScanner) checkSQLInjection(ctx context.Context, url string, resp *http.Response) []Vulnerability {
 	var vulns []Vulnerability
 
-	// SQL injection test patterns
-	patterns := []string{
-		"' OR '1'='1",
-		"' UNION SELECT NULL--",
-		"'; DROP TABLE users--",
-		"' OR 1=1--",
-		"admin'--",
-		"admin'/*",
-	}
-
-	for _, pattern := range patterns {
+	for _, pattern := range sqlInjectionPatterns {
 		testURL := url + "?id=" + pattern
-		req, _ := http.NewRequest("GET", testURL, nil)
+		req, _ := http.NewRequestWithContext(ctx, "GET", testURL, nil)
 		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 
 		testResp, err := vs.client.Do(req)
@@ -269,21 +479,12 @@ func (vs *VulnScanner) checkSQLInjection(url string, resp *http.Response) []Vuln
 	return vulns
 }
 
-func (vs *VulnScanner) checkXSS(url string, resp *http.Response) []Vulnerability {
+func (vs *VulnScanner) checkXSS(ctx context.Context, url string, resp *http.Response) []Vulnerability {
 	var vulns []Vulnerability
 
-	// XSS test patterns
-	patterns := []string{
-		"<script>alert('XSS')</script>",
-		"<img src=x onerror=alert('XSS')>",
-		"javascript:alert('XSS')",
-		"<svg onload=alert('XSS')>",
-		"<iframe src=javascript:alert('XSS')>",
-	}
-
-	for _, pattern := range patterns {
-		testURL := url + "?q=" + pattern
-		req, _ := http.NewRequest("GET", testURL, nil)
+	for _, pattern := range xssPatterns {
+		testURL := url + "?q=" + neturl.QueryEscape(pattern)
+		req, _ := http.NewRequestWithContext(ctx, "GET", testURL, nil)
 		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 
 		testResp, err := vs.client.Do(req)
@@ -291,13 +492,15 @@ func (vs *VulnScanner) checkXSS(url string, resp *http.Response) []Vulnerability
 			defer testResp.Body.Close()
 			body, _ := io.ReadAll(testResp.Body)
 
-			if strings.Contains(string(body), pattern) {
+			// Encoded or inert reflections are not reported
+			if evidence, ok := executableReflection(testResp, string(body), pattern); ok {
 				vulns = append(vulns, Vulnerability{
 					Name:        "Cross-Site Scripting (XSS)",
 					Severity:    "High",
-					Description: "XSS vulnerability detected",
+					Description: "The q parameter is reflected unencoded where the browser runs it",
 					Solution:    "Implement proper output encoding and input validation",
-					Confidence:  75,
+					Evidence:    evidence,
+					Confidence:  85,
 				})
 				break
 			}
@@ -323,14 +526,22 @@ func (vs *VulnScanner) checkInformationDisclosure(body string, resp *http.Respon
 		"exception":   "Exception information disclosed",
 	}
 
+	patterns := make([]string, 0, len(sensitivePatterns))
+	for pattern := range sensitivePatterns {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
 	bodyLower := strings.ToLower(body)
-	for pattern, description := range sensitivePatterns {
+	for _, pattern := range patterns {
+		description := sensitivePatterns[pattern]
 		if strings.Contains(bodyLower, pattern) {
 			vulns = append(vulns, Vulnerability{
 				Name:        "Information Disclosure",
 				Severity:    "Medium",
 				Description: description,
 				Solution:    "Remove sensitive information from responses",
+				Evidence:    fmt.Sprintf("response contains %q", pattern),
 				Confidence:  70,
 			})
 		}
@@ -350,7 +561,7 @@ func (vs *VulnScanner) checkInformationDisclosure(body string, resp *http.Respon
 	return vulns
 }
 
-func (vs *VulnScanner) checkSSLIssues(url string, resp *http.Response) []Vulnerability {
+func (vs *VulnScanner) checkSSLIssues(ctx context.Context, url string, resp *http.Response) []Vulnerability {
 	var vulns []Vulnerability
 
 	// Check if HTTPS is used
@@ -366,7 +577,7 @@ func (vs *VulnScanner) checkSSLIssues(url string, resp *http.Response) []Vulnera
 
 	// Check for mixed content
 	if strings.HasPrefix(url, "https://") {
-		req, _ := http.NewRequest("GET", url, nil)
+		req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
 		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
 
 		testResp, err := vs.client.Do(req)
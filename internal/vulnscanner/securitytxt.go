@@ -0,0 +1,62 @@
+package vulnscanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"subdomain-finder/internal/securitytxt"
+)
+
+// checkSecurityTxt reports a security.txt as an informational finding and
+// flags it when its Expires date has passed.
+func (vs *VulnScanner) checkSecurityTxt(ctx context.Context, url string) []Vulnerability {
+	baseURL := strings.TrimSuffix(url, "/")
+
+	for _, path := range securitytxt.Paths {
+		body, ok := vs.fetchProbe(ctx, baseURL+path)
+		if !ok {
+			continue
+		}
+
+		file, valid := securitytxt.Parse(body)
+		if !valid {
+			continue
+		}
+
+		location := baseURL + path
+		vulns := []Vulnerability{{
+			Name:        "security.txt Present",
+			Severity:    "Info",
+			Description: fmt.Sprintf("Vulnerability disclosure contact published at %s", location),
+			Evidence:    describeSecurityTxt(file),
+			Confidence:  100,
+		}}
+
+		if file.Expired(time.Now()) {
+			vulns = append(vulns, Vulnerability{
+				Name:        "Expired security.txt",
+				Severity:    "Low",
+				Description: "The security.txt Expires date has passed, so its contact details may be stale",
+				Solution:    "Review the contacts and publish a security.txt with a future Expires date",
+				Evidence:    fmt.Sprintf("Expires: %s at %s", file.ExpiresRaw, location),
+				Confidence:  95,
+			})
+		}
+		return vulns
+	}
+
+	return nil
+}
+
+func describeSecurityTxt(file *securitytxt.File) string {
+	parts := []string{"Contact: " + strings.Join(file.Contacts, ", ")}
+	if len(file.Policies) > 0 {
+		parts = append(parts, "Policy: "+strings.Join(file.Policies, ", "))
+	}
+	if file.ExpiresRaw != "" {
+		parts = append(parts, "Expires: "+file.ExpiresRaw)
+	}
+	return strings.Join(parts, "; ")
+}
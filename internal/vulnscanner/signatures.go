@@ -0,0 +1,155 @@
+package vulnscanner
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"subdomain-finder/internal/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Signature match types.
+const (
+	MatchHeader = "header"
+	MatchBody   = "body"
+	MatchStatus = "status"
+)
+
+// Signature is a user-defined check that ScanURL runs on the page it
+// fetches. Pattern is a case-insensitive regular expression tested against
+// the value of Header, the body or the status code, depending on Match. A
+// header signature without a pattern matches when the header is present.
+type Signature struct {
+	Name        string   `yaml:"name"`
+	Severity    string   `yaml:"severity"`
+	Match       string   `yaml:"match"`
+	Header      string   `yaml:"header"`
+	Pattern     string   `yaml:"pattern"`
+	Description string   `yaml:"description"`
+	Solution    string   `yaml:"solution"`
+	CVE         string   `yaml:"cve"`
+	References  []string `yaml:"references"`
+
+	pattern *regexp.Regexp
+}
+
+// ParseSignatures reads a YAML (or JSON) list of signatures. Each needs a
+// name, a known severity and a match type; body and status signatures also
+// need a pattern.
+func ParseSignatures(data []byte) ([]Signature, error) {
+	var signatures []Signature
+	if err := yaml.Unmarshal(data, &signatures); err != nil {
+		return nil, fmt.Errorf("failed to parse vulnerability signatures: %w", err)
+	}
+
+	for i := range signatures {
+		signature := &signatures[i]
+		if signature.Name == "" {
+			return nil, fmt.Errorf("signature %d has no name", i+1)
+		}
+		if types.SeverityRank(signature.Severity) < 0 {
+			return nil, fmt.Errorf("signature %q has unknown severity %q", signature.Name, signature.Severity)
+		}
+
+		signature.Match = strings.ToLower(signature.Match)
+		if signature.Match == "body-regex" {
+			signature.Match = MatchBody
+		}
+		switch signature.Match {
+		case MatchHeader:
+			if signature.Header == "" {
+				return nil, fmt.Errorf("signature %q matches a header but names none", signature.Name)
+			}
+		case MatchBody, MatchStatus:
+			if signature.Pattern == "" {
+				return nil, fmt.Errorf("signature %q has no pattern", signature.Name)
+			}
+		default:
+			return nil, fmt.Errorf("signature %q has unknown match %q (use header, body or status)", signature.Name, signature.Match)
+		}
+
+		if signature.Pattern != "" {
+			expr := "(?i)" + signature.Pattern
+			// Status patterns cover the whole code, so 5.. does not match 1500
+			if signature.Match == MatchStatus {
+				expr = "^(?:" + signature.Pattern + ")$"
+			}
+			pattern, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("signature %q: %w", signature.Name, err)
+			}
+			signature.pattern = pattern
+		}
+	}
+	return signatures, nil
+}
+
+func LoadSignatures(path string) ([]Signature, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vulnerability signatures: %w", err)
+	}
+	return ParseSignatures(data)
+}
+
+// SetSignatures adds user-defined signatures to every ScanURL, whichever
+// check categories are enabled.
+func (vs *VulnScanner) SetSignatures(signatures []Signature) {
+	vs.signatures = signatures
+}
+
+func (vs *VulnScanner) checkSignatures(resp *http.Response, body string) []Vulnerability {
+	var vulns []Vulnerability
+	for _, signature := range vs.signatures {
+		evidence, ok := signature.evaluate(resp, body)
+		if !ok {
+			continue
+		}
+
+		description := signature.Description
+		if description == "" {
+			description = "The response matches the signature " + signature.Name
+		}
+		vulns = append(vulns, Vulnerability{
+			Name:        signature.Name,
+			Severity:    signature.Severity,
+			Description: description,
+			CVE:         signature.CVE,
+			Solution:    signature.Solution,
+			References:  signature.References,
+			Evidence:    evidence,
+			Confidence:  80,
+		})
+	}
+	return vulns
+}
+
+// evaluate reports whether the response matches and what matched.
+func (s Signature) evaluate(resp *http.Response, body string) (string, bool) {
+	switch s.Match {
+	case MatchHeader:
+		for _, value := range resp.Header.Values(s.Header) {
+			if s.pattern == nil || s.pattern.MatchString(value) {
+				return fmt.Sprintf("%s: %s", http.CanonicalHeaderKey(s.Header), value), true
+			}
+		}
+	case MatchBody:
+		if loc := s.pattern.FindStringIndex(body); loc != nil {
+			match := body[loc[0]:loc[1]]
+			if len(match) > 100 {
+				match = match[:100] + "..."
+			}
+			return fmt.Sprintf("body contains %q", match), true
+		}
+	case MatchStatus:
+		if s.pattern.MatchString(strconv.Itoa(resp.StatusCode)) {
+			return fmt.Sprintf("status %d", resp.StatusCode), true
+		}
+	}
+	return "", false
+}
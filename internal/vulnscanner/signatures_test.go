@@ -0,0 +1,118 @@
+package vulnscanner
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseSignatures(t *testing.T) {
+	tests := []struct {
+		name      string
+		yaml      string
+		wantMatch string
+		wantErr   bool
+	}{
+		{"header", "- {name: PHP, severity: Low, match: header, header: X-Powered-By, pattern: 'PHP/5'}", MatchHeader, false},
+		{"header presence", "- {name: Debug, severity: Info, match: header, header: X-Debug-Token}", MatchHeader, false},
+		{"body", "- {name: Listing, severity: Medium, match: body, pattern: 'Index of /'}", MatchBody, false},
+		{"body-regex alias", "- {name: Listing, severity: Medium, match: body-regex, pattern: 'Index of /'}", MatchBody, false},
+		{"match case", "- {name: Errors, severity: Low, match: Status, pattern: '5..'}", MatchStatus, false},
+		{"json", `[{"name": "Errors", "severity": "Low", "match": "status", "pattern": "5.."}]`, MatchStatus, false},
+		{"no name", "- {severity: Low, match: body, pattern: x}", "", true},
+		{"unknown severity", "- {name: X, severity: Severe, match: body, pattern: x}", "", true},
+		{"unknown match", "- {name: X, severity: Low, match: cookie, pattern: x}", "", true},
+		{"header without name", "- {name: X, severity: Low, match: header, pattern: x}", "", true},
+		{"body without pattern", "- {name: X, severity: Low, match: body}", "", true},
+		{"status without pattern", "- {name: X, severity: Low, match: status}", "", true},
+		{"bad pattern", "- {name: X, severity: Low, match: body, pattern: '(['}", "", true},
+		{"not a list", "name: X", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signatures, err := ParseSignatures([]byte(tt.yaml))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSignatures error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(signatures) != 1 || signatures[0].Match != tt.wantMatch {
+				t.Errorf("ParseSignatures = %+v, want one %s signature", signatures, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestSignatureMatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Powered-By", "PHP/5.6.40")
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, "Internal error")
+			return
+		}
+		io.WriteString(w, "<title>Index of /backup</title>")
+	}))
+	defer server.Close()
+
+	signatures, err := ParseSignatures([]byte(`
+- {name: PHP 5, severity: Low, match: header, header: x-powered-by, pattern: 'php/5\.'}
+- {name: PHP 7, severity: Low, match: header, header: X-Powered-By, pattern: 'PHP/7\.'}
+- {name: Debug Token, severity: Info, match: header, header: X-Debug-Token}
+- {name: Listing, severity: Medium, match: body-regex, pattern: 'index of /'}
+- {name: Server Error, severity: Low, match: status, pattern: '5..'}
+- {name: Partial Status, severity: Low, match: status, pattern: '50'}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs := &VulnScanner{}
+	vs.SetSignatures(signatures)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/", []string{"PHP 5", "Listing"}},
+		{"/error", []string{"PHP 5", "Server Error"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, vuln := range vs.checkSignatures(resp, string(body)) {
+				got = append(got, vuln.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matched %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusSignatureIsAnchored(t *testing.T) {
+	signatures, err := ParseSignatures([]byte("- {name: Server Error, severity: Low, match: status, pattern: '5..'}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for status, want := range map[int]bool{500: true, 503: true, 1500: false, 5000: false, 404: false} {
+		if _, got := signatures[0].evaluate(&http.Response{StatusCode: status}, ""); got != want {
+			t.Errorf("status %d matched = %v, want %v", status, got, want)
+		}
+	}
+}
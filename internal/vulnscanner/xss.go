@@ -0,0 +1,130 @@
+package vulnscanner
+
+import (
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// rawTextElements hold text that is never parsed as markup, so a payload
+// reflected inside one does not run.
+var rawTextElements = []string{"script", "style", "textarea", "title", "xmp", "noembed", "noframes", "noscript", "iframe", "plaintext"}
+
+// urlAttributeRegex matches the start of an attribute whose value is loaded
+// or followed as a URL, up to where the value begins.
+var urlAttributeRegex = regexp.MustCompile(`(?i)\s(?:href|src|action|formaction|data)\s*=\s*["']?$`)
+
+// executableReflection reports whether payload comes back unencoded in a
+// place where a browser would run it, and returns the surrounding markup as
+// evidence. Responses that are not HTML are skipped, and so are
+// reflections inside comments, raw text elements such as <textarea> or
+// <script>, and quoted attribute values other than a javascript: URL
+// starting a link or source.
+func executableReflection(resp *http.Response, body, payload string) (string, bool) {
+	if !isHTMLResponse(resp.Header) {
+		return "", false
+	}
+
+	for offset := 0; ; {
+		i := strings.Index(body[offset:], payload)
+		if i < 0 {
+			return "", false
+		}
+		position := offset + i
+		offset = position + len(payload)
+
+		if executableContext(body[:position], payload) {
+			return snippet(body, position, len(payload)), true
+		}
+	}
+}
+
+func executableContext(before, payload string) bool {
+	lower := strings.ToLower(before)
+
+	if strings.LastIndex(lower, "<!--") > strings.LastIndex(lower, "-->") {
+		return false
+	}
+	for _, element := range rawTextElements {
+		open := lastTagStart(lower, element)
+		if open >= 0 && open > strings.LastIndex(lower, "</"+element) {
+			return false
+		}
+	}
+
+	// Inside a tag a javascript: URL runs when it begins a URL attribute's
+	// value. A quoted value holds any other payload, but an unquoted value,
+	// or the space between attributes, ends at whitespace or ">", after
+	// which the payload adds attributes such as onerror or closes the tag.
+	if tagStart := strings.LastIndex(before, "<"); tagStart > strings.LastIndex(before, ">") {
+		tag := before[tagStart:]
+		if isScriptURL(payload) && urlAttributeRegex.MatchString(tag) {
+			return true
+		}
+		return !inQuotedValue(tag) && strings.ContainsAny(payload, " \t\n\r\f>")
+	}
+
+	// In text only injected markup runs, not a bare javascript: URL
+	return strings.HasPrefix(payload, "<")
+}
+
+// inQuotedValue reports whether an unfinished tag, starting at its "<",
+// ends inside a quoted attribute value.
+func inQuotedValue(tag string) bool {
+	var quote byte
+	afterEquals := false
+	for i := 1; i < len(tag); i++ {
+		c := tag[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case afterEquals && (c == '"' || c == '\''):
+			quote = c
+			afterEquals = false
+		case c == '=':
+			afterEquals = true
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r' && c != '\f':
+			afterEquals = false
+		}
+	}
+	return quote != 0
+}
+
+// lastTagStart finds the last opening tag of element, such as "<script>"
+// or "<script type=...>", but not "<scripts>".
+func lastTagStart(lower, element string) int {
+	for end := len(lower); ; {
+		i := strings.LastIndex(lower[:end], "<"+element)
+		if i < 0 {
+			return -1
+		}
+		next := i + 1 + len(element)
+		if next == len(lower) || strings.ContainsRune(" \t\n\r/>", rune(lower[next])) {
+			return i
+		}
+		end = i
+	}
+}
+
+func isScriptURL(payload string) bool {
+	return strings.HasPrefix(strings.ToLower(payload), "javascript:")
+}
+
+func isHTMLResponse(header http.Header) bool {
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "text/html" || mediaType == "application/xhtml+xml")
+}
+
+// snippet returns the reflection with some markup on either side.
+func snippet(body string, position, length int) string {
+	start := max(position-40, 0)
+	end := min(position+length+40, len(body))
+	return strings.Join(strings.Fields(body[start:end]), " ")
+}
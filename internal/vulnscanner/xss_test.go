@@ -0,0 +1,75 @@
+package vulnscanner
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExecutableReflection(t *testing.T) {
+	const (
+		script   = "<script>alert('XSS')</script>"
+		img      = "<img src=x onerror=alert('XSS')>"
+		jsURL    = "javascript:alert('XSS')"
+		attrOnly = "x onmouseover=alert(1)"
+	)
+
+	tests := []struct {
+		name    string
+		body    string
+		payload string
+		want    bool
+	}{
+		{"text", "<p>Results for " + script + "</p>", script, true},
+		{"text without markup", "<p>" + jsURL + "</p>", jsURL, false},
+		{"encoded", "<p>&lt;script&gt;alert('XSS')&lt;/script&gt;</p>", script, false},
+		{"comment", "<!-- " + script + " -->", script, false},
+		{"closed comment", "<!-- q --><p>" + script + "</p>", script, true},
+		{"textarea", "<textarea>" + script + "</textarea>", script, false},
+		{"title", "<title>" + img + "</title>", img, false},
+		{"script string", "<script>var q = \"" + img + "\";</script>", img, false},
+		{"after script", "<script>var a;</script><p>" + img, img, true},
+		{"double-quoted value", "<input value=\"" + img + "\">", img, false},
+		{"single-quoted value", "<input value='" + script + "'>", script, false},
+		{"unquoted value", "<input value=" + img + ">", img, true},
+		{"unquoted value after quoted", "<input class=\"a b\" value=" + attrOnly + ">", attrOnly, true},
+		{"between attributes", "<input " + attrOnly + " type=text>", attrOnly, true},
+		{"unquoted value without break-out", "<input value=" + jsURL + ">", jsURL, false},
+		{"quoted value with equals inside", "<a title=\"a=b\" data-x=\"" + img + "\">", img, false},
+		{"href javascript", "<a href=\"" + jsURL + "\">x</a>", jsURL, true},
+		{"unquoted href javascript", "<a href=" + jsURL + ">x</a>", jsURL, true},
+		{"href later in value", "<a href=\"/search?q=" + jsURL + "\">x</a>", jsURL, false},
+		{"title attribute javascript", "<a title=\"" + jsURL + "\">x</a>", jsURL, false},
+	}
+
+	html := &http.Response{Header: http.Header{"Content-Type": []string{"text/html; charset=utf-8"}}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := executableReflection(html, tt.body, tt.payload); got != tt.want {
+				t.Errorf("executableReflection(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutableReflectionSkipsNonHTML(t *testing.T) {
+	payload := "<script>alert('XSS')</script>"
+	for _, contentType := range []string{"application/json", "text/plain"} {
+		resp := &http.Response{Header: http.Header{"Content-Type": []string{contentType}}}
+		if _, ok := executableReflection(resp, `{"q":"`+payload+`"}`, payload); ok {
+			t.Errorf("reflection in %s reported", contentType)
+		}
+	}
+}
+
+func TestExecutableReflectionEvidence(t *testing.T) {
+	payload := "<svg onload=alert('XSS')>"
+	body := "<!-- " + payload + " --><div>" + payload + "</div>"
+	evidence, ok := executableReflection(&http.Response{Header: http.Header{}}, body, payload)
+	if !ok {
+		t.Fatal("second, executable reflection not found")
+	}
+	if want := "<div>" + payload + "</div>"; !strings.HasSuffix(evidence, want) {
+		t.Errorf("evidence = %q, want it to end with %q", evidence, want)
+	}
+}
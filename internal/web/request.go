@@ -0,0 +1,152 @@
+package web
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	httpclient "subdomain-finder/internal/http"
+	"subdomain-finder/internal/portscanner"
+	"subdomain-finder/internal/screenshot"
+	"subdomain-finder/internal/vulnscanner"
+)
+
+const wordlistDir = "wordlists"
+
+// Screenshot viewport defaults and bounds, in pixels
+const (
+	defaultScreenshotWidth  = 1280
+	defaultScreenshotHeight = 720
+	minScreenshotSize       = 240
+	maxScreenshotSize       = 3840
+)
+
+// domainPattern accepts ASCII names; the TLD may be punycode (xn--...)
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+([a-z]{2,63}|xn--[a-z0-9-]{1,59})$`)
+
+type ScanRequest struct {
+	Domain        string `json:"domain"`
+	Threads       int    `json:"threads"`
+	Timeout       int    `json:"timeout"`
+	Wordlist      string `json:"wordlist"`
+	SNI           string `json:"sni"`
+	NoSNI         bool   `json:"no_sni"`
+	ExcludeParked bool   `json:"exclude_parked"`
+	Vuln          bool   `json:"vuln"`
+	TLSMinVersion string `json:"tls_min_version"`
+	Ports         string `json:"ports"`
+	Checks        string `json:"checks"`
+	Takeover      bool   `json:"takeover"`
+	Screenshot    bool   `json:"screenshot"`
+	// Viewport of the screenshots; zero keeps the default size
+	ScreenshotWidth  int `json:"screenshot_width"`
+	ScreenshotHeight int `json:"screenshot_height"`
+
+	tlsMinVersion uint16
+	checks        []vulnscanner.Check
+}
+
+// Validate normalizes the request and rejects values the CLI would refuse.
+// Wordlists are limited to files inside wordlists/ so the form cannot read
+// arbitrary paths on the server, and checks that send attack payloads are
+// refused since the web UI always scans in safe mode.
+func (sr *ScanRequest) Validate() error {
+	sr.Domain = strings.ToLower(strings.TrimSpace(sr.Domain))
+	if !domainPattern.MatchString(sr.Domain) {
+		return fmt.Errorf("invalid domain %q", sr.Domain)
+	}
+
+	if sr.Threads < 1 || sr.Threads > 1000 {
+		return fmt.Errorf("threads must be between 1 and 1000")
+	}
+	if sr.Timeout < 1 || sr.Timeout > 60 {
+		return fmt.Errorf("timeout must be between 1 and 60 seconds")
+	}
+
+	sr.Wordlist = strings.TrimSpace(sr.Wordlist)
+	if sr.Wordlist != "" {
+		if filepath.Base(sr.Wordlist) != sr.Wordlist || strings.HasPrefix(sr.Wordlist, ".") {
+			return fmt.Errorf("wordlist must be a file name inside %s/", wordlistDir)
+		}
+		if _, err := os.Stat(filepath.Join(wordlistDir, sr.Wordlist)); err != nil {
+			return fmt.Errorf("wordlist %q not found in %s/", sr.Wordlist, wordlistDir)
+		}
+	}
+
+	sr.SNI = strings.TrimSpace(sr.SNI)
+	if sr.SNI != "" && sr.NoSNI {
+		return fmt.Errorf("sni and no_sni cannot be combined")
+	}
+
+	version, err := httpclient.ParseTLSVersion(sr.TLSMinVersion)
+	if err != nil {
+		return err
+	}
+	sr.tlsMinVersion = version
+
+	sr.Ports = strings.ToLower(strings.TrimSpace(sr.Ports))
+	if sr.Ports == "" {
+		sr.Ports = "quick"
+	}
+	if _, err := portscanner.SelectPorts(sr.Ports); err != nil {
+		return fmt.Errorf("invalid ports: %w", err)
+	}
+
+	sr.checks = nil
+	if spec := strings.TrimSpace(sr.Checks); spec != "" {
+		if !sr.Vuln {
+			return fmt.Errorf("checks require vuln")
+		}
+		checks, err := vulnscanner.ParseChecks(spec)
+		if err != nil {
+			return fmt.Errorf("invalid checks: %w", err)
+		}
+		for _, check := range checks {
+			if slices.Contains(vulnscanner.AttackChecks, check) {
+				return fmt.Errorf("check %s sends attack payloads and is not available from the web UI", check)
+			}
+		}
+		sr.checks = checks
+	}
+
+	if !sr.Screenshot && (sr.ScreenshotWidth != 0 || sr.ScreenshotHeight != 0) {
+		return fmt.Errorf("screenshot options require screenshot")
+	}
+	if sr.ScreenshotWidth == 0 {
+		sr.ScreenshotWidth = defaultScreenshotWidth
+	}
+	if sr.ScreenshotHeight == 0 {
+		sr.ScreenshotHeight = defaultScreenshotHeight
+	}
+	if sr.ScreenshotWidth < minScreenshotSize || sr.ScreenshotWidth > maxScreenshotSize ||
+		sr.ScreenshotHeight < minScreenshotSize || sr.ScreenshotHeight > maxScreenshotSize {
+		return fmt.Errorf("screenshot size must be between %d and %d pixels", minScreenshotSize, maxScreenshotSize)
+	}
+
+	return nil
+}
+
+func (sr *ScanRequest) screenshotConfig(timeout time.Duration) screenshot.ScreenshotConfig {
+	return screenshot.ScreenshotConfig{
+		Width:   sr.ScreenshotWidth,
+		Height:  sr.ScreenshotHeight,
+		Quality: 90,
+		Timeout: timeout,
+	}
+}
+
+func (sr *ScanRequest) wordlistPath() string {
+	if sr.Wordlist != "" {
+		return filepath.Join(wordlistDir, sr.Wordlist)
+	}
+
+	defaultPath := filepath.Join(wordlistDir, "common.txt")
+	if _, err := os.Stat(defaultPath); err == nil {
+		return defaultPath
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package web
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanRequestValidate(t *testing.T) {
+	valid := func() ScanRequest {
+		return ScanRequest{Domain: "example.com", Threads: 10, Timeout: 10}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*ScanRequest)
+		wantErr string
+	}{
+		{"defaults", func(*ScanRequest) {}, ""},
+		{"punycode tld", func(sr *ScanRequest) { sr.Domain = "example.xn--p1ai" }, ""},
+		{"punycode label", func(sr *ScanRequest) { sr.Domain = "xn--mnchen-3ya.de" }, ""},
+		{"numeric tld", func(sr *ScanRequest) { sr.Domain = "example.123" }, "invalid domain"},
+		{"bare xn-- tld", func(sr *ScanRequest) { sr.Domain = "example.xn--" }, "invalid domain"},
+		{"threads", func(sr *ScanRequest) { sr.Threads = 0 }, "threads"},
+		{"ports list", func(sr *ScanRequest) { sr.Ports = "80,443,8000-8010" }, ""},
+		{"ports mode", func(sr *ScanRequest) { sr.Ports = "FULL" }, ""},
+		{"bad ports", func(sr *ScanRequest) { sr.Ports = "70000" }, "invalid ports"},
+		{"bad ports mode", func(sr *ScanRequest) { sr.Ports = "some" }, "invalid ports"},
+		{"checks", func(sr *ScanRequest) { sr.Vuln, sr.Checks = true, "headers, ssl" }, ""},
+		{"checks without vuln", func(sr *ScanRequest) { sr.Checks = "headers" }, "require vuln"},
+		{"unknown check", func(sr *ScanRequest) { sr.Vuln, sr.Checks = true, "headers,bogus" }, "invalid checks"},
+		{"attack check", func(sr *ScanRequest) { sr.Vuln, sr.Checks = true, "sqli" }, "attack payloads"},
+		{"screenshot size", func(sr *ScanRequest) { sr.Screenshot, sr.ScreenshotWidth, sr.ScreenshotHeight = true, 1920, 1080 }, ""},
+		{"screenshot too small", func(sr *ScanRequest) { sr.Screenshot, sr.ScreenshotWidth = true, 100 }, "screenshot size"},
+		{"screenshot too large", func(sr *ScanRequest) { sr.Screenshot, sr.ScreenshotHeight = true, 10000 }, "screenshot size"},
+		{"size without screenshot", func(sr *ScanRequest) { sr.ScreenshotWidth = 800 }, "require screenshot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := valid()
+			tt.modify(&sr)
+			err := sr.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScanRequestValidateNormalizes(t *testing.T) {
+	sr := ScanRequest{Domain: " Example.COM ", Threads: 10, Timeout: 10, Vuln: true, Checks: "ssl,headers,ssl", Screenshot: true}
+	if err := sr.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	config := scanConfig(sr)
+	if config.Domain != "example.com" || config.Ports != "quick" {
+		t.Errorf("scanConfig() domain, ports = %q, %q", config.Domain, config.Ports)
+	}
+	if len(config.VulnChecks) != 2 || config.VulnChecks[0] != "ssl" || config.VulnChecks[1] != "headers" {
+		t.Errorf("scanConfig() VulnChecks = %v", config.VulnChecks)
+	}
+	if sr.ScreenshotWidth != defaultScreenshotWidth || sr.ScreenshotHeight != defaultScreenshotHeight {
+		t.Errorf("screenshot size = %dx%d", sr.ScreenshotWidth, sr.ScreenshotHeight)
+	}
+}
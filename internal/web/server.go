@@ -5,14 +5,18 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
-	"os"
 	"subdomain-finder/internal/finder"
+	"subdomain-finder/internal/output"
+	"subdomain-finder/internal/reporter"
+	"subdomain-finder/internal/screenshot"
 	"subdomain-finder/internal/types"
+	"sync"
 	"time"
 )
 
 type WebServer struct {
 	port    int
+	mu      sync.RWMutex
 	results []types.Result
 	summary *types.ScanSummary
 }
@@ -30,6 +34,7 @@ func (ws *WebServer) Start() error {
 	http.HandleFunc("/api/results", ws.handleResults)
 	http.HandleFunc("/api/summary", ws.handleSummary)
 	http.HandleFunc("/api/scan", ws.handleScan)
+	http.HandleFunc("/api/scan/stream", ws.handleScanStream)
 	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("web/static/"))))
 
 	fmt.Printf("Web interface starting on http://localhost:%d\n", ws.port)
@@ -37,6 +42,8 @@ func (ws *WebServer) Start() error {
 }
 
 func (ws *WebServer) UpdateResults(results []types.Result, summary *types.ScanSummary) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
 	ws.results = results
 	ws.summary = summary
 }
@@ -341,6 +348,47 @@ func (ws *WebServer) handleIndex(w http.ResponseWriter, r *http.Request) {
                         <option value="30">30</option>
                     </select>
                 </div>
+                <details class="form-group">
+                    <summary>Advanced Options</summary>
+                    <div class="form-group">
+                        <label for="wordlist">Wordlist (file in wordlists/):</label>
+                        <input type="text" id="wordlist" name="wordlist" placeholder="common.txt">
+                    </div>
+                    <div class="form-group">
+                        <label for="sni">SNI Override:</label>
+                        <input type="text" id="sni" name="sni" placeholder="host name sent during SSL analysis">
+                    </div>
+                    <div class="form-group">
+                        <label for="tlsMinVersion">Minimum TLS Version:</label>
+                        <select id="tlsMinVersion" name="tlsMinVersion">
+                            <option value="" selected>Default</option>
+                            <option value="1.0">1.0</option>
+                            <option value="1.1">1.1</option>
+                            <option value="1.2">1.2</option>
+                            <option value="1.3">1.3</option>
+                        </select>
+                    </div>
+                    <div class="form-group">
+                        <label><input type="checkbox" id="noSni" name="noSni"> Send no SNI</label>
+                        <label><input type="checkbox" id="excludeParked" name="excludeParked"> Exclude parked domains</label>
+                        <label><input type="checkbox" id="vuln" name="vuln"> Run vulnerability checks</label>
+                        <label><input type="checkbox" id="takeover" name="takeover"> Check for subdomain takeover</label>
+                        <label><input type="checkbox" id="screenshot" name="screenshot"> Capture screenshots</label>
+                    </div>
+                    <div class="form-group">
+                        <label for="ports">Ports:</label>
+                        <input type="text" id="ports" name="ports" placeholder="none, quick, full or 80,443,8000-8100">
+                    </div>
+                    <div class="form-group">
+                        <label for="checks">Vulnerability checks:</label>
+                        <input type="text" id="checks" name="checks" placeholder="headers,disclosure,ssl,forms,cors,securitytxt,jsonp">
+                    </div>
+                    <div class="form-group">
+                        <label for="screenshotWidth">Screenshot size (pixels):</label>
+                        <input type="number" id="screenshotWidth" name="screenshotWidth" placeholder="1280">
+                        <input type="number" id="screenshotHeight" name="screenshotHeight" placeholder="720">
+                    </div>
+                </details>
                 <button type="submit" class="btn" id="scanBtn">Start Scan</button>
             </form>
         </div>
@@ -379,7 +427,7 @@ func (ws *WebServer) handleIndex(w http.ResponseWriter, r *http.Request) {
     <script>
         let isScanning = false;
         
-        document.getElementById('scanForm').addEventListener('submit', async function(e) {
+        document.getElementById('scanForm').addEventListener('submit', function(e) {
             e.preventDefault();
             
             if (isScanning) return;
@@ -387,96 +435,155 @@ func (ws *WebServer) handleIndex(w http.ResponseWriter, r *http.Request) {
             const domain = document.getElementById('domain').value;
             const threads = document.getElementById('threads').value;
             const timeout = document.getElementById('timeout').value;
+            const wordlist = document.getElementById('wordlist').value;
+            const sni = document.getElementById('sni').value;
+            const tlsMinVersion = document.getElementById('tlsMinVersion').value;
+            const noSni = document.getElementById('noSni').checked;
+            const excludeParked = document.getElementById('excludeParked').checked;
+            const vuln = document.getElementById('vuln').checked;
+            const takeover = document.getElementById('takeover').checked;
+            const screenshot = document.getElementById('screenshot').checked;
+            const ports = document.getElementById('ports').value;
+            const checks = document.getElementById('checks').value;
+            const screenshotWidth = document.getElementById('screenshotWidth').value;
+            const screenshotHeight = document.getElementById('screenshotHeight').value;
             
             isScanning = true;
             document.getElementById('scanBtn').disabled = true;
             document.getElementById('scanBtn').textContent = 'Scanning...';
             
-            try {
-                const response = await fetch('/api/scan', {
-                    method: 'POST',
-                    headers: {
-                        'Content-Type': 'application/json',
-                    },
-                    body: JSON.stringify({
-                        domain: domain,
-                        threads: parseInt(threads),
-                        timeout: parseInt(timeout)
-                    })
-                });
-                
-                if (!response.ok) {
-                    throw new Error('Scan failed');
+            const params = new URLSearchParams({
+                domain: domain,
+                threads: threads,
+                timeout: timeout,
+                wordlist: wordlist,
+                sni: sni,
+                no_sni: noSni,
+                exclude_parked: excludeParked,
+                vuln: vuln,
+                tls_min_version: tlsMinVersion,
+                ports: ports,
+                checks: checks,
+                takeover: takeover,
+                screenshot: screenshot,
+                screenshot_width: screenshotWidth,
+                screenshot_height: screenshotHeight
+            });
+            
+            let found = 0;
+            document.getElementById('totalSubdomains').textContent = 0;
+            document.getElementById('foundSubdomains').textContent = 0;
+            document.getElementById('openPorts').textContent = 0;
+            document.getElementById('vulnerabilities').textContent = 0;
+            document.getElementById('summary').style.display = 'grid';
+            document.getElementById('results').innerHTML = 
+                '<div class="loading" id="scanProgress">Starting scan...</div>';
+            
+            const source = new EventSource('/api/scan/stream?' + params.toString());
+            
+            source.addEventListener('result', function(e) {
+                const result = JSON.parse(e.data);
+                found++;
+                document.getElementById('foundSubdomains').textContent = found;
+                document.getElementById('results').insertAdjacentHTML('beforeend', renderResult(result));
+            });
+            
+            source.addEventListener('progress', function(e) {
+                const stats = JSON.parse(e.data);
+                document.getElementById('totalSubdomains').textContent = stats.total;
+                document.getElementById('scanProgress').textContent = 
+                    'Scanning... ' + stats.completed + '/' + stats.total + ' checked, ' + stats.found + ' found';
+            });
+            
+            source.addEventListener('done', function(e) {
+                source.close();
+                const summary = JSON.parse(e.data);
+                updateSummary(summary);
+                const progress = document.getElementById('scanProgress');
+                if (summary.found_subdomains === 0) {
+                    progress.textContent = 'No subdomains found.';
+                } else {
+                    progress.remove();
                 }
-                
-                const data = await response.json();
-                updateResults(data.results, data.summary);
-                
-            } catch (error) {
-                document.getElementById('results').innerHTML = 
-                    '<div class="error">Scan failed: ' + error.message + '</div>';
-            } finally {
-                isScanning = false;
-                document.getElementById('scanBtn').disabled = false;
-                document.getElementById('scanBtn').textContent = 'Start Scan';
-            }
+                finishScan();
+            });
+            
+            source.onerror = function() {
+                if (!isScanning) return;
+                source.close();
+                document.getElementById('results').insertAdjacentHTML('afterbegin', 
+                    '<div class="error">Scan failed: connection to the server was lost</div>');
+                finishScan();
+            };
         });
         
-        function updateResults(results, summary) {
-            // Update summary cards
+        function finishScan() {
+            isScanning = false;
+            document.getElementById('scanBtn').disabled = false;
+            document.getElementById('scanBtn').textContent = 'Start Scan';
+        }
+        
+        function updateSummary(summary) {
             document.getElementById('totalSubdomains').textContent = summary.total_subdomains;
             document.getElementById('foundSubdomains').textContent = summary.found_subdomains;
             document.getElementById('openPorts').textContent = summary.open_ports;
             document.getElementById('vulnerabilities').textContent = summary.vulnerabilities;
             document.getElementById('summary').style.display = 'grid';
+        }
+        
+        function updateResults(results, summary) {
+            updateSummary(summary);
             
-            // Update results
             let resultsHtml = '';
             if (results.length === 0) {
                 resultsHtml = '<div class="loading">No subdomains found.</div>';
             } else {
                 results.forEach(function(result) {
-                    resultsHtml += '<div class="subdomain-item">' +
-                        '<div class="subdomain-header" onclick="toggleDetails(this)">' +
-                        '<div class="subdomain-name">' + result.subdomain + '</div>' +
-                        '<div class="subdomain-status status-' + result.status + '">' + result.status + '</div>' +
-                        '<span class="toggle-icon">▼</span>' +
-                        '</div>' +
-                        '<div class="subdomain-details">' +
-                        '<div class="detail-grid">' +
-                        '<div class="detail-item">' +
-                        '<div class="detail-label">IP Address</div>' +
-                        '<div class="detail-value">' + result.ip + '</div>' +
-                        '</div>' +
-                        '<div class="detail-item">' +
-                        '<div class="detail-label">Server</div>' +
-                        '<div class="detail-value">' + (result.server || 'Unknown') + '</div>' +
-                        '</div>' +
-                        '<div class="detail-item">' +
-                        '<div class="detail-label">Title</div>' +
-                        '<div class="detail-value">' + (result.title || 'N/A') + '</div>' +
-                        '</div>' +
-                        '<div class="detail-item">' +
-                        '<div class="detail-label">Risk Level</div>' +
-                        '<div class="detail-value">' + result.risk_level + '</div>' +
-                        '</div>' +
-                        '<div class="detail-item">' +
-                        '<div class="detail-label">Confidence</div>' +
-                        '<div class="detail-value">' + result.confidence + '%</div>' +
-                        '</div>' +
-                        '<div class="detail-item">' +
-                        '<div class="detail-label">Response Time</div>' +
-                        '<div class="detail-value">' + result.response_time + '</div>' +
-                        '</div>' +
-                        '</div>' +
-                        '</div>' +
-                        '</div>';
+                    resultsHtml += renderResult(result);
                 });
             }
             
             document.getElementById('results').innerHTML = resultsHtml;
         }
         
+        function renderResult(result) {
+            return '<div class="subdomain-item">' +
+                '<div class="subdomain-header" onclick="toggleDetails(this)">' +
+                '<div class="subdomain-name">' + result.subdomain + '</div>' +
+                '<div class="subdomain-status status-' + result.status + '">' + result.status + '</div>' +
+                '<span class="toggle-icon">▼</span>' +
+                '</div>' +
+                '<div class="subdomain-details">' +
+                '<div class="detail-grid">' +
+                '<div class="detail-item">' +
+                '<div class="detail-label">IP Address</div>' +
+                '<div class="detail-value">' + result.ip + '</div>' +
+                '</div>' +
+                '<div class="detail-item">' +
+                '<div class="detail-label">Server</div>' +
+                '<div class="detail-value">' + (result.server || 'Unknown') + '</div>' +
+                '</div>' +
+                '<div class="detail-item">' +
+                '<div class="detail-label">Title</div>' +
+                '<div class="detail-value">' + (result.title || 'N/A') + '</div>' +
+                '</div>' +
+                '<div class="detail-item">' +
+                '<div class="detail-label">Risk Level</div>' +
+                '<div class="detail-value">' + result.risk_level + '</div>' +
+                '</div>' +
+                '<div class="detail-item">' +
+                '<div class="detail-label">Confidence</div>' +
+                '<div class="detail-value">' + result.confidence + '%</div>' +
+                '</div>' +
+                '<div class="detail-item">' +
+                '<div class="detail-label">Response Time</div>' +
+                '<div class="detail-value">' + result.response_time + '</div>' +
+                '</div>' +
+                '</div>' +
+                '</div>' +
+                '</div>';
+        }
+        
         function toggleDetails(element) {
             const details = element.nextElementSibling;
             const icon = element.querySelector('.toggle-icon');
@@ -516,11 +623,15 @@ func (ws *WebServer) handleIndex(w http.ResponseWriter, r *http.Request) {
 }
 
 func (ws *WebServer) handleResults(w http.ResponseWriter, r *http.Request) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(ws.results)
 }
 
 func (ws *WebServer) handleSummary(w http.ResponseWriter, r *http.Request) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(ws.summary)
 }
@@ -531,19 +642,20 @@ func (ws *WebServer) handleScan(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var scanRequest struct {
-		Domain  string `json:"domain"`
-		Threads int    `json:"threads"`
-		Timeout int    `json:"timeout"`
-	}
+	var scanRequest ScanRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&scanRequest); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
+	if err := scanRequest.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Gerçek tarama yap
-	results, summary := ws.performRealScan(scanRequest.Domain, scanRequest.Threads, scanRequest.Timeout)
+	results, summary := ws.performRealScan(scanRequest)
 
 	ws.UpdateResults(results, summary)
 
@@ -556,106 +668,119 @@ func (ws *WebServer) handleScan(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-func (ws *WebServer) performRealScan(domain string, threads, timeout int) ([]types.Result, *types.ScanSummary) {
+func (ws *WebServer) performRealScan(scanRequest ScanRequest) ([]types.Result, *types.ScanSummary) {
 	// Gerçek tarama yapmak için finder modülünü kullan
 	// Önce finder modülünü import edelim
-	results, summary := ws.runActualScan(domain, threads, timeout)
+	results, summary := ws.runActualScan(scanRequest)
 	return results, summary
 }
 
-func (ws *WebServer) runActualScan(domain string, threads, timeout int) ([]types.Result, *types.ScanSummary) {
+func (ws *WebServer) runActualScan(scanRequest ScanRequest) ([]types.Result, *types.ScanSummary) {
 	// Gerçek tarama yap
 	startTime := time.Now()
+	domain := scanRequest.Domain
 
 	// Önce mevcut sonuçları kontrol et
 	jsonFile := fmt.Sprintf("results/%s.json", domain)
-	if data, err := os.ReadFile(jsonFile); err == nil {
-		var results []types.Result
-		if err := json.Unmarshal(data, &results); err == nil {
-			// Summary oluştur
-			summary := &types.ScanSummary{
-				TotalSubdomains: len(results),
-				FoundSubdomains: 0,
-				OpenPorts:       0,
-				Vulnerabilities: 0,
-				HighRiskItems:   0,
-				ScanDuration:    time.Since(startTime),
-				StartTime:       startTime,
-				EndTime:         time.Now(),
-			}
-
-			for _, result := range results {
-				if result.IP != "" {
-					summary.FoundSubdomains++
-				}
-				summary.OpenPorts += len(result.Ports)
-				summary.Vulnerabilities += len(result.Vulnerabilities)
+	if results, summary, ok := cachedResults(jsonFile, startTime); ok {
+		return results, summary
+	}
 
-				for _, vuln := range result.Vulnerabilities {
-					if vuln.Severity == "Critical" || vuln.Severity == "High" {
-						summary.HighRiskItems++
-					}
-				}
-			}
+	// Eğer dosya yoksa, gerçek tarama yap
+	finderInstance := finder.NewFinder(scanConfig(scanRequest))
+	results := finderInstance.Find()
 
-			return results, summary
-		}
+	if scanRequest.Screenshot {
+		captureScreenshots(results, scanRequest)
 	}
 
-	// Eğer dosya yoksa, gerçek tarama yap
-	config := finder.Config{
-		Domain:     domain,
-		Wordlist:   "wordlists/common.txt",
-		Threads:    threads,
-		Timeout:    timeout,
-		RateLimit:  10,
-		OutputFile: fmt.Sprintf("results/%s.txt", domain),
-		Verbose:    false,
-		JSON:       true,
-		XML:        false,
-		Progress:   false,
-		Stats:      false,
-		NoColor:    true,
-		UserAgent:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
-		Headers:    []string{},
-		Retries:    3,
-		Delay:      100,
+	summary := summarize(results, startTime)
+	summary.WildcardIPs = finderInstance.WildcardIPs()
+	summary.WildcardDetected = len(summary.WildcardIPs) > 0
+
+	saveResults(jsonFile, results, summary)
+
+	return results, summary
+}
+
+func scanConfig(scanRequest ScanRequest) finder.Config {
+	domain := scanRequest.Domain
+	return finder.Config{
+		Domain:        domain,
+		Wordlist:      scanRequest.wordlistPath(),
+		Threads:       scanRequest.Threads,
+		Timeout:       scanRequest.Timeout,
+		OutputFile:    fmt.Sprintf("results/%s.txt", domain),
+		Verbose:       false,
+		JSON:          true,
+		XML:           false,
+		Progress:      false,
+		Stats:         false,
+		NoColor:       true,
+		UserAgent:     "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
+		Headers:       []string{},
+		Retries:       3,
+		Delay:         100,
+		SNI:           scanRequest.SNI,
+		NoSNI:         scanRequest.NoSNI,
+		ExcludeParked: scanRequest.ExcludeParked,
+		Vuln:          scanRequest.Vuln,
+		TLSMinVersion: scanRequest.tlsMinVersion,
+		Ports:         scanRequest.Ports,
+		VulnChecks:    scanRequest.checks,
+		Takeover:      scanRequest.Takeover,
+		MaxSockets:    256,
 	}
+}
 
-	// Finder oluştur ve gerçek tarama yap
-	finderInstance := finder.NewFinder(config)
-	results := finderInstance.Find()
+func summarize(results []types.Result, startTime time.Time) *types.ScanSummary {
+	summary := reporter.NewReporter("").GenerateSummaryReport(results)
+	summary.StartTime = startTime
+	summary.EndTime = time.Now()
+	summary.ScanDuration = summary.EndTime.Sub(startTime)
+	return summary
+}
 
-	// Summary oluştur
-	summary := &types.ScanSummary{
-		TotalSubdomains: len(results),
-		FoundSubdomains: 0,
-		OpenPorts:       0,
-		Vulnerabilities: 0,
-		HighRiskItems:   0,
-		ScanDuration:    time.Since(startTime),
-		StartTime:       startTime,
-		EndTime:         time.Now(),
+// cachedResults loads the stored results of an earlier scan. Files saved
+// before summaries were stored get one computed from the results.
+func cachedResults(jsonFile string, startTime time.Time) ([]types.Result, *types.ScanSummary, bool) {
+	results, summary, err := reporter.LoadJSON(jsonFile)
+	if err != nil {
+		return nil, nil, false
 	}
+	if summary == nil {
+		summary = summarize(results, startTime)
+	}
+	return results, summary, true
+}
 
-	for _, result := range results {
-		if result.IP != "" {
-			summary.FoundSubdomains++
+// saveResults writes results and their summary where the next scan of the
+// domain reads them.
+// captureScreenshots saves a screenshot of each result that answered over
+// HTTP and records its path in the result's metadata.
+func captureScreenshots(results []types.Result, scanRequest ScanRequest) {
+	capture := screenshot.NewScreenshotCapture(scanRequest.screenshotConfig(time.Duration(scanRequest.Timeout) * time.Second))
+	for i := range results {
+		if len(results[i].Headers) == 0 {
+			continue
 		}
-		summary.OpenPorts += len(result.Ports)
-		summary.Vulnerabilities += len(result.Vulnerabilities)
-
-		for _, vuln := range result.Vulnerabilities {
-			if vuln.Severity == "Critical" || vuln.Severity == "High" {
-				summary.HighRiskItems++
-			}
+		scheme := "http"
+		if results[i].SSL != nil {
+			scheme = "https"
 		}
+		shot, err := capture.Capture(scheme + "://" + results[i].Subdomain)
+		if err != nil || shot == nil || shot.FilePath == "" {
+			continue
+		}
+		if results[i].Metadata == nil {
+			results[i].Metadata = make(map[string]interface{})
+		}
+		results[i].Metadata["screenshot"] = shot.FilePath
 	}
+}
 
-	// Sonuçları JSON dosyasına kaydet
-	if data, err := json.MarshalIndent(results, "", "  "); err == nil {
-		os.WriteFile(jsonFile, data, 0644)
+func saveResults(jsonFile string, results []types.Result, summary *types.ScanSummary) {
+	if err := output.WriteReport(jsonFile, summary, results); err != nil {
+		fmt.Printf("Failed to save scan results: %v\n", err)
 	}
-
-	return results, summary
 }
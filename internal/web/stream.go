@@ -0,0 +1,214 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"subdomain-finder/internal/finder"
+	"subdomain-finder/internal/progress"
+	"subdomain-finder/internal/types"
+)
+
+// progressInterval is the minimum time between progress events.
+const progressInterval = 500 * time.Millisecond
+
+type streamEvent struct {
+	name string
+	data interface{}
+}
+
+type progressEvent struct {
+	Total     int     `json:"total"`
+	Completed int     `json:"completed"`
+	Found     int     `json:"found"`
+	Errors    int     `json:"errors"`
+	Rate      float64 `json:"rate"`
+}
+
+// handleScanStream runs a scan and reports it as Server-Sent Events: a
+// "result" event per subdomain as it is confirmed, throttled "progress"
+// events, then "done" with the summary. EventSource can
+// only GET, so the scan options come from the query string. Closing the
+// stream cancels the scan.
+func (ws *WebServer) handleScanStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	scanRequest, err := scanRequestFromQuery(r)
+	if err == nil {
+		err = scanRequest.Validate()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// stop ends the forwarding so callbacks never block once the handler
+	// has returned
+	events := make(chan streamEvent, 64)
+	stop := make(chan struct{})
+	defer close(stop)
+	send := func(event streamEvent) {
+		select {
+		case events <- event:
+		case <-stop:
+		}
+	}
+
+	go ws.streamScan(r.Context(), scanRequest, send, events)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// streamScan runs the scan, reporting through send, and closes events when
+// it is finished. A cancelled scan is neither saved nor shown as the latest
+// results.
+func (ws *WebServer) streamScan(ctx context.Context, scanRequest ScanRequest, send func(streamEvent), events chan streamEvent) {
+	defer close(events)
+	startTime := time.Now()
+
+	// Stored results are replayed the same way /api/scan returns them
+	jsonFile := fmt.Sprintf("results/%s.json", scanRequest.Domain)
+	if results, summary, ok := cachedResults(jsonFile, startTime); ok {
+		for _, result := range results {
+			send(streamEvent{"result", result})
+		}
+		ws.UpdateResults(results, summary)
+		send(streamEvent{"done", summary})
+		return
+	}
+
+	finderInstance := finder.NewFinder(scanConfig(scanRequest))
+	finderInstance.OnResult(func(result types.Result) {
+		send(streamEvent{"result", result})
+	})
+
+	var lastProgress time.Time
+	finderInstance.OnProgress(func(stats progress.Stats) {
+		if time.Since(lastProgress) < progressInterval && stats.Completed < stats.Total {
+			return
+		}
+		lastProgress = time.Now()
+		send(streamEvent{"progress", progressEvent{
+			Total:     stats.Total,
+			Completed: stats.Completed,
+			Found:     stats.Found,
+			Errors:    stats.Errors,
+			Rate:      stats.Rate,
+		}})
+	})
+
+	results := finderInstance.FindWithContext(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	if scanRequest.Screenshot {
+		captureScreenshots(results, scanRequest)
+	}
+
+	summary := summarize(results, startTime)
+	summary.WildcardIPs = finderInstance.WildcardIPs()
+	summary.WildcardDetected = len(summary.WildcardIPs) > 0
+
+	ws.UpdateResults(results, summary)
+	saveResults(jsonFile, results, summary)
+	send(streamEvent{"done", summary})
+}
+
+func writeEvent(w http.ResponseWriter, event streamEvent) error {
+	data, err := json.Marshal(event.data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, data)
+	return err
+}
+
+// scanRequestFromQuery reads the fields of a ScanRequest from URL query
+// parameters named like its JSON fields.
+func scanRequestFromQuery(r *http.Request) (ScanRequest, error) {
+	query := r.URL.Query()
+	scanRequest := ScanRequest{
+		Domain:        query.Get("domain"),
+		Wordlist:      query.Get("wordlist"),
+		SNI:           query.Get("sni"),
+		TLSMinVersion: query.Get("tls_min_version"),
+		Ports:         query.Get("ports"),
+		Checks:        query.Get("checks"),
+	}
+
+	var err error
+	if scanRequest.Threads, err = queryInt(query.Get("threads"), 10); err != nil {
+		return scanRequest, fmt.Errorf("invalid threads: %w", err)
+	}
+	if scanRequest.Timeout, err = queryInt(query.Get("timeout"), 10); err != nil {
+		return scanRequest, fmt.Errorf("invalid timeout: %w", err)
+	}
+	if scanRequest.NoSNI, err = queryBool(query.Get("no_sni")); err != nil {
+		return scanRequest, fmt.Errorf("invalid no_sni: %w", err)
+	}
+	if scanRequest.ExcludeParked, err = queryBool(query.Get("exclude_parked")); err != nil {
+		return scanRequest, fmt.Errorf("invalid exclude_parked: %w", err)
+	}
+	if scanRequest.Vuln, err = queryBool(query.Get("vuln")); err != nil {
+		return scanRequest, fmt.Errorf("invalid vuln: %w", err)
+	}
+	if scanRequest.Takeover, err = queryBool(query.Get("takeover")); err != nil {
+		return scanRequest, fmt.Errorf("invalid takeover: %w", err)
+	}
+	if scanRequest.Screenshot, err = queryBool(query.Get("screenshot")); err != nil {
+		return scanRequest, fmt.Errorf("invalid screenshot: %w", err)
+	}
+	if scanRequest.ScreenshotWidth, err = queryInt(query.Get("screenshot_width"), 0); err != nil {
+		return scanRequest, fmt.Errorf("invalid screenshot_width: %w", err)
+	}
+	if scanRequest.ScreenshotHeight, err = queryInt(query.Get("screenshot_height"), 0); err != nil {
+		return scanRequest, fmt.Errorf("invalid screenshot_height: %w", err)
+	}
+	return scanRequest, nil
+}
+
+func queryInt(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func queryBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
@@ -0,0 +1,96 @@
+package wordlist
+
+import (
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+)
+
+// GeneratePermutations mutates the first label of each known subdomain the
+// way altdns does: words joined with a dash before and after it, each
+// dash-separated part swapped for a word, a word added as a new label in
+// front, and numbers appended or a trailing number changed. Each name is
+// yielded once, never a known one. Names are generated as the caller ranges
+// over them, so a caller that breaks at a cap never builds the rest.
+func GeneratePermutations(known []string, words []string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		knownSet := make(map[string]bool, len(known))
+		for _, name := range known {
+			knownSet[strings.ToLower(name)] = true
+		}
+
+		seen := make(map[string]bool)
+		add := func(name string) bool {
+			if seen[name] || knownSet[name] {
+				return true
+			}
+			seen[name] = true
+			return yield(name)
+		}
+
+		for _, name := range known {
+			label, rest, ok := strings.Cut(strings.ToLower(name), ".")
+			if !ok || label == "" {
+				continue
+			}
+
+			for _, variant := range numberVariants(label) {
+				if !add(variant + "." + rest) {
+					return
+				}
+			}
+
+			parts := strings.Split(label, "-")
+			for _, word := range words {
+				word = strings.ToLower(word)
+				if word == "" || word == label {
+					continue
+				}
+				if !add(word+"-"+label+"."+rest) || !add(label+"-"+word+"."+rest) || !add(word+"."+label+"."+rest) {
+					return
+				}
+				if len(parts) > 1 {
+					for i, part := range parts {
+						if part == word {
+							continue
+						}
+						swapped := append([]string(nil), parts...)
+						swapped[i] = word
+						if !add(strings.Join(swapped, "-") + "." + rest) {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// numberVariants steps a trailing number up and down, or appends 1 to 3
+// with and without a dash when the label has none.
+func numberVariants(label string) []string {
+	stem := strings.TrimRight(label, "0123456789")
+	if stem == label {
+		variants := make([]string, 0, 6)
+		for n := 1; n <= 3; n++ {
+			variants = append(variants, label+strconv.Itoa(n), label+"-"+strconv.Itoa(n))
+		}
+		return variants
+	}
+
+	digits := label[len(stem):]
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return nil
+	}
+	// Zero padding is kept, so web02 becomes web03
+	number := func(n int) string {
+		return stem + fmt.Sprintf("%0*d", len(digits), n)
+	}
+	variants := []string{number(n + 1), number(n + 2)}
+	if n > 0 {
+		variants = append(variants, number(n-1))
+	}
+	return variants
+}
@@ -0,0 +1,59 @@
+package wordlist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGeneratePermutations(t *testing.T) {
+	tests := []struct {
+		name  string
+		known []string
+		words []string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "numbers and words",
+			known: []string{"web02.example.com"},
+			words: []string{"dev"},
+			want: []string{
+				"web03.example.com", "web04.example.com", "web01.example.com",
+				"dev-web02.example.com", "web02-dev.example.com", "dev.web02.example.com",
+			},
+		},
+		{
+			name:  "swaps dash parts and skips known names",
+			known: []string{"api-v1.example.com", "dev-v1.example.com"},
+			words: []string{"dev"},
+			limit: 7,
+			want: []string{
+				"api-v2.example.com", "api-v3.example.com", "api-v0.example.com",
+				"dev-api-v1.example.com", "api-v1-dev.example.com", "dev.api-v1.example.com",
+				"api-dev.example.com",
+			},
+		},
+		{
+			name:  "stops when the caller breaks",
+			known: []string{"www.example.com", "mail.example.com"},
+			words: []string{"dev", "staging"},
+			limit: 2,
+			want:  []string{"www1.example.com", "www-1.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for name := range GeneratePermutations(tt.known, tt.words) {
+				got = append(got, name)
+				if len(got) == tt.limit {
+					break
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}